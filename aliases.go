@@ -5,9 +5,11 @@ package rudi
 
 import (
 	"context"
+	"io/fs"
 
 	"go.xrstf.de/rudi/pkg/builtin"
 	"go.xrstf.de/rudi/pkg/coalescing"
+	"go.xrstf.de/rudi/pkg/runtime/compiler"
 	"go.xrstf.de/rudi/pkg/runtime/functions"
 	"go.xrstf.de/rudi/pkg/runtime/interpreter"
 	"go.xrstf.de/rudi/pkg/runtime/types"
@@ -29,6 +31,24 @@ type Function = types.Function
 // Document is the global document that is being processed by a Rudi script.
 type Document = types.Document
 
+// Change is a single mutation of a Document, recorded after calling
+// Document.TrackChanges.
+type Change = types.Change
+
+// Tracer receives events for every evaluated tuple, symbol and function call,
+// see Context.WithTracer().
+type Tracer = types.Tracer
+
+// TraceEvent describes a single evaluation step reported to a Tracer.
+type TraceEvent = types.TraceEvent
+
+// Loader provides the source code of libraries for the import function, see
+// Context.WithLoader().
+type Loader = types.Loader
+
+// LoaderFunc allows to use a plain function as a Loader.
+type LoaderFunc = types.LoaderFunc
+
 // Coalescer is responsible for type handling and equality rules. Build your own
 // or use any of the predefined versions:
 //
@@ -48,6 +68,14 @@ func NewContext(runtime types.Runtime, ctx context.Context, doc Document, variab
 	return types.NewContext(runtime, ctx, doc, variables, funcs, coalescer)
 }
 
+// NewCompiledRuntime returns a runtime that compiles programs before evaluating
+// them, which is faster than the default interpreter when the same programs are
+// run many times. All contexts used with this runtime must be created with the
+// given set of functions.
+func NewCompiledRuntime(funcs Functions) types.Runtime {
+	return compiler.New(funcs)
+}
+
 // NewFunctions returns an empty set of runtime functions.
 func NewFunctions() Functions {
 	return types.NewFunctions()
@@ -73,11 +101,18 @@ func NewVariables() Variables {
 	return types.NewVariables()
 }
 
-// NewDocument wraps any sort of data as a Rudi document.
+// NewDocument wraps any sort of data as a Rudi document. Go values that are not
+// JSON-like, like structs, are wrapped so they can be used by path expressions.
 func NewDocument(data any) (Document, error) {
 	return types.NewDocument(data)
 }
 
+// NewFSLoader returns a loader that reads libraries from the given filesystem,
+// for example an embed.FS or os.DirFS().
+func NewFSLoader(fsys fs.FS) Loader {
+	return types.NewFSLoader(fsys)
+}
+
 // NewFunctionBuilder is the recommended way to define new Rudi functions. The function builder can
 // take multiple forms (e.g. if you have (foo INT) and (foo STRING)) and will create a function that
 // automatically evaluates and coalesces Rudi expressions and matches them to the given forms. The
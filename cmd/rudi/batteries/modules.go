@@ -16,12 +16,16 @@ import (
 	encodingdocs "go.xrstf.de/rudi/pkg/builtin/encoding/docs"
 	hashingmod "go.xrstf.de/rudi/pkg/builtin/hashing"
 	hashingdocs "go.xrstf.de/rudi/pkg/builtin/hashing/docs"
+	importsmod "go.xrstf.de/rudi/pkg/builtin/imports"
+	importsdocs "go.xrstf.de/rudi/pkg/builtin/imports/docs"
 	listsmod "go.xrstf.de/rudi/pkg/builtin/lists"
 	listsdocs "go.xrstf.de/rudi/pkg/builtin/lists/docs"
 	logicmod "go.xrstf.de/rudi/pkg/builtin/logic"
 	logicdocs "go.xrstf.de/rudi/pkg/builtin/logic/docs"
 	mathmod "go.xrstf.de/rudi/pkg/builtin/math"
 	mathdocs "go.xrstf.de/rudi/pkg/builtin/math/docs"
+	pathsmod "go.xrstf.de/rudi/pkg/builtin/paths"
+	pathsdocs "go.xrstf.de/rudi/pkg/builtin/paths/docs"
 	rudifuncmod "go.xrstf.de/rudi/pkg/builtin/rudifunc"
 	rudifuncdocs "go.xrstf.de/rudi/pkg/builtin/rudifunc/docs"
 	stringsmod "go.xrstf.de/rudi/pkg/builtin/strings"
@@ -29,6 +33,7 @@ import (
 	typesmod "go.xrstf.de/rudi/pkg/builtin/types"
 	typesdocs "go.xrstf.de/rudi/pkg/builtin/types/docs"
 	"go.xrstf.de/rudi/pkg/docs"
+	"go.xrstf.de/rudi/pkg/runtime/types"
 
 	semvermod "go.xrstf.de/rudi-contrib/semver"
 	semverdocs "go.xrstf.de/rudi-contrib/semver/docs"
@@ -90,6 +95,11 @@ var (
 			Functions:     mathmod.Functions,
 			Documentation: mathdocs.Functions,
 		},
+		{
+			Name:          "paths",
+			Functions:     pathsmod.Functions,
+			Documentation: pathsdocs.Functions,
+		},
 		{
 			Name:          "strings",
 			Functions:     stringsmod.Functions,
@@ -108,8 +118,15 @@ var (
 		Documentation: rudifuncdocs.Functions,
 	}
 
+	ImportsModule = docs.Module{
+		Name:          "imports",
+		Functions:     importsmod.Functions,
+		Documentation: importsdocs.Functions,
+	}
+
 	UnsafeBuiltInModules = []docs.Module{
 		RudifuncModule,
+		ImportsModule,
 	}
 
 	ExtendedModules = []docs.Module{
@@ -139,3 +156,46 @@ var (
 		},
 	}
 )
+
+// UnqualifiedFunctions returns the functions of an extended module that can
+// also be called without their module name as a namespace. Functions whose
+// names collide with a built-in function or a function of another extended
+// module are left out and can only be called with their namespace, so that
+// they never shadow each other.
+func UnqualifiedFunctions(module docs.Module) types.Functions {
+	result := types.NewFunctions()
+
+	for name, fun := range module.Functions {
+		if !collides(module, name) {
+			result.Set(name, fun)
+		}
+	}
+
+	return result
+}
+
+func collides(module docs.Module, name string) bool {
+	for _, mod := range SafeBuiltInModules {
+		if _, exists := mod.Functions.Get(name); exists {
+			return true
+		}
+	}
+
+	for _, mod := range UnsafeBuiltInModules {
+		if _, exists := mod.Functions.Get(name); exists {
+			return true
+		}
+	}
+
+	for _, mod := range ExtendedModules {
+		if mod.Name == module.Name {
+			continue
+		}
+
+		if _, exists := mod.Functions.Get(name); exists {
+			return true
+		}
+	}
+
+	return false
+}
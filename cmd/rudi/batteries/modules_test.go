@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package batteries
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"go.xrstf.de/rudi/pkg/builtin/core"
+	"go.xrstf.de/rudi/pkg/docs"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+)
+
+func TestUnqualifiedFunctions(t *testing.T) {
+	dummy := core.Functions["do"]
+
+	module := docs.Module{
+		Name: "test",
+		Functions: types.Functions{
+			// collides with the core module
+			"if": dummy,
+			// collides with the set module
+			"new-set": dummy,
+			"unique":  dummy,
+		},
+	}
+
+	if names := functionNames(UnqualifiedFunctions(module)); names != "unique" {
+		t.Fatalf("Expected only unique to be available without a namespace, got %q.", names)
+	}
+
+	for _, mod := range ExtendedModules {
+		unqualified := UnqualifiedFunctions(mod)
+
+		if names, expected := functionNames(unqualified), functionNames(mod.Functions); names != expected {
+			t.Errorf("Expected all functions of %s to be available without a namespace, got %q instead of %q.", mod.Name, names, expected)
+		}
+	}
+}
+
+func functionNames(funcs types.Functions) string {
+	names := []string{}
+	for name := range funcs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ",")
+}
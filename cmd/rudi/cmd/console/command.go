@@ -48,8 +48,18 @@ var replCommands = map[string]replCommandFunc{
 	"help": helpCommand,
 }
 
+type debuggerCommandFunc func(dbg *debugger, args string) error
+
+var debuggerCommands = map[string]debuggerCommandFunc{
+	":break":   breakCommand,
+	":unbreak": unbreakCommand,
+	":step":    stepCommand,
+}
+
+const prompt = "⮞ "
+
 func Run(handler *util.SignalHandler, opts *options.Options, library rudi.Program, args []string, rudiVersion string) error {
-	rl, err := readline.New("⮞ ")
+	rl, err := readline.New(prompt)
 	if err != nil {
 		return fmt.Errorf("failed to setup readline prompt: %w", err)
 	}
@@ -64,6 +74,8 @@ func Run(handler *util.SignalHandler, opts *options.Options, library rudi.Progra
 		return fmt.Errorf("failed to setup context: %w", err)
 	}
 
+	dbg := newDebugger(rl, prompt)
+
 	fmt.Printf("Welcome to 🚂Rudi %s\n", rudiVersion)
 	fmt.Println("Type `help` for more information, `exit` or Ctrl-D to exit, Ctrl-C to interrupt statements.")
 	fmt.Println("")
@@ -71,7 +83,7 @@ func Run(handler *util.SignalHandler, opts *options.Options, library rudi.Progra
 	// Evaluate the library (its return value is irrelevant, as the main program has to have
 	// at least 1 statement, which will overwrite the total return value anyway).
 	if library != nil {
-		_, err = runProgram(handler, rudiCtx, library)
+		_, err = runProgram(handler, rudiCtx, library, nil)
 		if err != nil {
 			return fmt.Errorf("failed to evaluate library: %w", err)
 		}
@@ -95,7 +107,7 @@ func Run(handler *util.SignalHandler, opts *options.Options, library rudi.Progra
 			continue
 		}
 
-		stop, err := processInput(handler, rudiCtx, opts, line)
+		stop, err := processInput(handler, rudiCtx, opts, dbg, line)
 		if err != nil {
 			parseErr := &rudi.ParseError{}
 			if errors.As(err, parseErr) {
@@ -115,11 +127,22 @@ func Run(handler *util.SignalHandler, opts *options.Options, library rudi.Progra
 	return nil
 }
 
-func processInput(handler *util.SignalHandler, rudiCtx types.Context, opts *options.Options, input string) (stop bool, err error) {
+func processInput(handler *util.SignalHandler, rudiCtx types.Context, opts *options.Options, dbg *debugger, input string) (stop bool, err error) {
 	if command, exists := replCommands[input]; exists {
 		return false, command(rudiCtx, opts)
 	}
 
+	if strings.HasPrefix(input, ":") {
+		name, args, _ := strings.Cut(input, " ")
+
+		command, exists := debuggerCommands[name]
+		if !exists {
+			return false, fmt.Errorf("unknown command %s", name)
+		}
+
+		return false, command(dbg, strings.TrimSpace(args))
+	}
+
 	if prefix := "help "; strings.HasPrefix(input, prefix) {
 		topicName := strings.TrimPrefix(input, prefix)
 		return false, helpTopicCommand(topicName)
@@ -135,32 +158,46 @@ func processInput(handler *util.SignalHandler, rudiCtx types.Context, opts *opti
 		return false, err
 	}
 
+	var tracer types.Tracer
+	if opts.Trace {
+		tracer = util.NewTreePrinter(os.Stderr)
+	}
+
+	if dbg.active() {
+		dbg.reset(tracer)
+		tracer = dbg
+	}
+
 	// run the program
-	evaluated, err := runProgram(handler, rudiCtx, program)
+	evaluated, err := runProgram(handler, rudiCtx, program, tracer)
 	if err != nil {
 		return false, err
 	}
 
+	return false, printValue(evaluated)
+}
+
+func printValue(value any) error {
 	f := colorjson.NewFormatter()
 	f.Indent = 0
 	f.EscapeHTML = false
 
-	encoded, err := f.Marshal(evaluated)
+	encoded, err := f.Marshal(value)
 	if err != nil {
-		return false, fmt.Errorf("failed to encode %v: %w", evaluated, err)
+		return fmt.Errorf("failed to encode %v: %w", value, err)
 	}
 
 	fmt.Println(string(encoded))
 
-	return false, nil
+	return nil
 }
 
-func runProgram(handler *util.SignalHandler, rudiCtx types.Context, prog rudi.Program) (any, error) {
+func runProgram(handler *util.SignalHandler, rudiCtx types.Context, prog rudi.Program, tracer types.Tracer) (any, error) {
 	// allow to interrupt the statement
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	handler.SetCancelFn(cancel)
 
-	return prog.RunContext(rudiCtx.WithGoContext(ctx))
+	return prog.RunContext(rudiCtx.WithGoContext(ctx).WithTracer(tracer))
 }
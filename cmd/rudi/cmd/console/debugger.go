@@ -0,0 +1,265 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package console
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"go.xrstf.de/rudi/cmd/rudi/util"
+	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/lang/parser"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+
+	"github.com/chzyer/readline"
+)
+
+// errAborted wraps context.Canceled, so that functions like try do not
+// swallow it and the evaluation really stops.
+var errAborted = fmt.Errorf("aborted by debugger: %w", context.Canceled)
+
+const debuggerHelp = `The evaluation is paused. Available commands:
+
+  step, s      Continue until the next evaluation step.
+  continue, c  Continue until the next breakpoint.
+  abort, a     Abort the evaluation.
+  help, h      Show this help text.
+
+Anything else is evaluated as a Rudi expression in the current scope, so
+variables like $foo can be inspected.`
+
+// debugger is a tracer that can pause the evaluation before tuples, symbols
+// and function calls are evaluated, either because a breakpoint on a function
+// was hit or because stepping is enabled.
+type debugger struct {
+	rl          *readline.Instance
+	prompt      string
+	breakpoints map[string]struct{}
+	stepping    bool
+
+	// next is an optional tracer that is informed about all events as well
+	next types.Tracer
+
+	// continued is set when the user chooses to continue until the next
+	// breakpoint; it is reset for every new program
+	continued bool
+	stack     []frame
+}
+
+type frame struct {
+	kind types.TraceEventKind
+	// nested is true for statements and function calls that are part of a
+	// tuple; these are not separate steps and do not increase the indentation
+	nested bool
+}
+
+var _ types.Tracer = &debugger{}
+
+func newDebugger(rl *readline.Instance, prompt string) *debugger {
+	return &debugger{
+		rl:          rl,
+		prompt:      prompt,
+		breakpoints: map[string]struct{}{},
+	}
+}
+
+// active returns true if the debugger could pause the evaluation.
+func (d *debugger) active() bool {
+	return d.stepping || len(d.breakpoints) > 0
+}
+
+func (d *debugger) reset(next types.Tracer) {
+	d.next = next
+	d.continued = false
+	d.stack = nil
+}
+
+func (d *debugger) Enter(ctx types.Context, event types.TraceEvent) error {
+	// Function calls as part of a tuple are stepped over, as the tuple
+	// itself was already a step; the same goes for statements and their
+	// expressions.
+	nested := event.Kind == types.StatementEvent ||
+		(event.Kind == types.CallEvent && len(d.stack) > 0 && d.stack[len(d.stack)-1].kind == types.TupleEvent)
+
+	// Pause before forwarding the event, so that aborting the evaluation does
+	// not leave the next tracer with an Enter that is never followed by an Exit.
+	if d.shouldPause(event, nested) {
+		if err := d.pause(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	if d.next != nil {
+		if err := d.next.Enter(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	d.stack = append(d.stack, frame{kind: event.Kind, nested: nested})
+
+	return nil
+}
+
+func (d *debugger) Exit(ctx types.Context, event types.TraceEvent) {
+	current := d.stack[len(d.stack)-1]
+	d.stack = d.stack[:len(d.stack)-1]
+
+	if d.stepping && !d.continued && !current.nested {
+		fmt.Fprintf(os.Stderr, "%s%s %s\n", d.indent(), util.FormatTraceExpression(event), util.FormatTraceOutcome(event))
+	}
+
+	if d.next != nil {
+		d.next.Exit(ctx, event)
+	}
+}
+
+func (d *debugger) shouldPause(event types.TraceEvent, nested bool) bool {
+	if event.Kind == types.CallEvent {
+		if ident, ok := event.Expression.(ast.Identifier); ok {
+			if _, exists := d.breakpoints[ident.FullName()]; exists {
+				return true
+			}
+		}
+	}
+
+	return d.stepping && !d.continued && !nested
+}
+
+func (d *debugger) indent() string {
+	depth := 0
+
+	for _, f := range d.stack {
+		if !f.nested {
+			depth++
+		}
+	}
+
+	return strings.Repeat("  ", depth)
+}
+
+func (d *debugger) pause(ctx types.Context, event types.TraceEvent) error {
+	fmt.Fprintf(os.Stderr, "%s⏸ %s\n", d.indent(), util.FormatTraceExpression(event))
+
+	d.rl.SetPrompt("(debug) " + d.prompt)
+	defer d.rl.SetPrompt(d.prompt)
+
+	// the expression that is about to be evaluated must not be traced itself
+	inspectCtx := ctx.WithTracer(nil)
+
+	for {
+		line, err := d.rl.Readline()
+		if err != nil {
+			// Ctrl-C or Ctrl-D
+			return errAborted
+		}
+
+		switch strings.TrimSpace(line) {
+		case "", "step", "s":
+			d.continued = false
+			return nil
+
+		case "continue", "c":
+			d.continued = true
+			return nil
+
+		case "abort", "a":
+			return errAborted
+
+		case "help", "h":
+			fmt.Println(debuggerHelp)
+
+		default:
+			if err := d.inspect(inspectCtx, line); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+		}
+	}
+}
+
+func (d *debugger) inspect(ctx types.Context, input string) error {
+	got, err := parser.Parse("(debug)", []byte(input))
+	if err != nil {
+		return err
+	}
+
+	program, ok := got.(ast.Program)
+	if !ok {
+		return fmt.Errorf("parsed input is not a program, but %T", got)
+	}
+
+	// Statements are evaluated directly in the paused context (instead of
+	// running the program, which would create a new scope), so that all
+	// variables in the current scope are available.
+	var result any
+
+	for _, stmt := range program.Statements {
+		result, err = ctx.Runtime().EvalStatement(ctx, stmt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return printValue(result)
+}
+
+func (d *debugger) breakpointNames() []string {
+	names := make([]string, 0, len(d.breakpoints))
+	for name := range d.breakpoints {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func breakCommand(dbg *debugger, args string) error {
+	if args == "" {
+		if len(dbg.breakpoints) == 0 {
+			fmt.Println("No breakpoints set.")
+		} else {
+			fmt.Printf("Breakpoints: %s\n", strings.Join(dbg.breakpointNames(), ", "))
+		}
+
+		return nil
+	}
+
+	for _, name := range strings.Fields(args) {
+		dbg.breakpoints[name] = struct{}{}
+	}
+
+	return nil
+}
+
+func unbreakCommand(dbg *debugger, args string) error {
+	if args == "" {
+		dbg.breakpoints = map[string]struct{}{}
+		return nil
+	}
+
+	for _, name := range strings.Fields(args) {
+		if _, exists := dbg.breakpoints[name]; !exists {
+			return fmt.Errorf("no breakpoint set for %s", name)
+		}
+
+		delete(dbg.breakpoints, name)
+	}
+
+	return nil
+}
+
+func stepCommand(dbg *debugger, _ string) error {
+	dbg.stepping = !dbg.stepping
+
+	if dbg.stepping {
+		fmt.Println("Step mode enabled, evaluation pauses before every step.")
+	} else {
+		fmt.Println("Step mode disabled.")
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package format
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.xrstf.de/rudi"
+	"go.xrstf.de/rudi/cmd/rudi/docs"
+
+	"github.com/spf13/pflag"
+)
+
+type options struct {
+	write     bool
+	check     bool
+	showHelp  bool
+	indent    int
+	lineWidth int
+}
+
+func (o *options) addFlags(fs *pflag.FlagSet) {
+	fs.SortFlags = false
+
+	fs.BoolVarP(&o.write, "write", "w", o.write, "Write the formatted code back to the file(s) instead of printing it.")
+	fs.BoolVar(&o.check, "check", o.check, "Do not print or write anything, but fail if any file is not formatted.")
+	fs.IntVar(&o.indent, "indent", o.indent, "Number of spaces to use for indenting nested expressions.")
+	fs.IntVar(&o.lineWidth, "line-width", o.lineWidth, "Maximum desired line width.")
+	fs.BoolVarP(&o.showHelp, "help", "h", o.showHelp, "Show help and exit.")
+}
+
+func (o *options) validate() error {
+	if o.write && o.check {
+		return errors.New("cannot combine --write with --check")
+	}
+
+	if o.indent < 1 {
+		return errors.New("--indent must be at least 1")
+	}
+
+	if o.lineWidth < 1 {
+		return errors.New("--line-width must be at least 1")
+	}
+
+	return nil
+}
+
+// Run implements the `rudi fmt` subcommand. args are the command line arguments
+// following "fmt".
+func Run(args []string) error {
+	defaults := rudi.DefaultFormatOptions()
+
+	opts := options{
+		indent:    defaults.Indent,
+		lineWidth: defaults.LineWidth,
+	}
+
+	fs := pflag.NewFlagSet("rudi fmt", pflag.ContinueOnError)
+	opts.addFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if opts.showHelp {
+		content, err := docs.RenderFile("cmd-format.md", nil)
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(content)
+		fmt.Println("Usage of rudi fmt:")
+		fs.PrintDefaults()
+
+		return nil
+	}
+
+	if err := opts.validate(); err != nil {
+		return fmt.Errorf("invalid command line: %w", err)
+	}
+
+	formatOpts := rudi.FormatOptions{
+		Indent:    opts.indent,
+		LineWidth: opts.lineWidth,
+	}
+
+	filenames := fs.Args()
+
+	// format stdin to stdout
+	if len(filenames) == 0 {
+		if opts.write {
+			return errors.New("cannot use --write when reading from stdin")
+		}
+
+		return formatFile("(stdin)", os.Stdin, &opts, formatOpts)
+	}
+
+	unformatted := 0
+
+	for _, filename := range filenames {
+		changed, err := formatFilename(filename, &opts, formatOpts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+
+		if changed && opts.check {
+			fmt.Fprintln(os.Stderr, filename)
+			unformatted++
+		}
+	}
+
+	if unformatted > 0 {
+		return fmt.Errorf("%d file(s) are not formatted", unformatted)
+	}
+
+	return nil
+}
+
+func formatFile(name string, r io.Reader, opts *options, formatOpts rudi.FormatOptions) error {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+
+	formatted, err := rudi.Format(name, string(content), formatOpts)
+	if err != nil {
+		return err
+	}
+
+	if opts.check {
+		if formatted != string(content) {
+			return errors.New("input is not formatted")
+		}
+
+		return nil
+	}
+
+	_, err = fmt.Print(formatted)
+
+	return err
+}
+
+// formatFilename formats a single file and returns whether the formatted
+// code differs from the current file content.
+func formatFilename(filename string, opts *options, formatOpts rudi.FormatOptions) (bool, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return false, err
+	}
+
+	formatted, err := rudi.Format(filename, string(content), formatOpts)
+	if err != nil {
+		return false, err
+	}
+
+	changed := formatted != string(content)
+
+	switch {
+	case opts.check:
+		// only report
+
+	case opts.write:
+		if changed {
+			info, err := os.Stat(filename)
+			if err != nil {
+				return false, err
+			}
+
+			if err := os.WriteFile(filename, []byte(formatted), info.Mode().Perm()); err != nil {
+				return false, fmt.Errorf("failed to write file: %w", err)
+			}
+		}
+
+	default:
+		if _, err := fmt.Print(formatted); err != nil {
+			return false, err
+		}
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"go.xrstf.de/rudi"
+	"go.xrstf.de/rudi/cmd/rudi/options"
+	"go.xrstf.de/rudi/cmd/rudi/types"
+	"go.xrstf.de/rudi/cmd/rudi/util"
+)
+
+// errAssertionFailed is returned by the assert function when the script
+// evaluated to false.
+var errAssertionFailed = errors.New("assertion failed")
+
+// assertResult is the outcome of running the script against a single file.
+type assertResult struct {
+	fileName string
+	duration time.Duration
+	// err is nil if the assertion passed, errAssertionFailed if the script
+	// evaluated to false or any other error if the file could not be checked.
+	err error
+}
+
+func (r assertResult) passed() bool {
+	return r.err == nil
+}
+
+func (r assertResult) failed() bool {
+	return errors.Is(r.err, errAssertionFailed)
+}
+
+// runAssert runs the program once for every file and coalesces its result to
+// a bool. All files are checked, even if some fail, and the results are
+// reported in the chosen --assert-format. An error is returned if at least
+// one file did not pass.
+func runAssert(handler *util.SignalHandler, opts *options.Options, library rudi.Program, program rudi.Program, fileNames []string) error {
+	if len(fileNames) == 0 {
+		return errors.New("--assert requires at least one input")
+	}
+
+	// allow to interrupt the checks
+	subCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handler.SetCancelFn(cancel)
+
+	results := make([]assertResult, 0, len(fileNames))
+	failures := 0
+
+	for _, fileName := range fileNames {
+		start := time.Now()
+		err := assertFile(subCtx, opts, library, program, fileName)
+
+		if subCtx.Err() != nil {
+			return errors.New("interrupted")
+		}
+
+		result := assertResult{
+			fileName: fileName,
+			duration: time.Since(start),
+			err:      err,
+		}
+
+		if !result.passed() {
+			failures++
+		}
+
+		results = append(results, result)
+	}
+
+	if err := writeAssertReport(results, opts.AssertFormat); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d of %d input(s) failed the assertion", failures, len(results))
+	}
+
+	return nil
+}
+
+func assertFile(ctx context.Context, opts *options.Options, library rudi.Program, program rudi.Program, fileName string) error {
+	data, err := util.LoadFile(opts, fileName)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	rudiCtx, err := util.SetupRudiContext(opts, []string{fileName}, []any{data})
+	if err != nil {
+		return fmt.Errorf("failed to setup context: %w", err)
+	}
+
+	rudiCtx = rudiCtx.WithGoContext(ctx)
+
+	if library != nil {
+		if _, err := library.RunContext(rudiCtx); err != nil {
+			return fmt.Errorf("failed to evaluate library: %w", err)
+		}
+	}
+
+	if opts.Trace {
+		rudiCtx = rudiCtx.WithTracer(util.NewTreePrinter(os.Stderr))
+	}
+
+	evaluated, err := program.RunContext(rudiCtx)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate script: %w", err)
+	}
+
+	ok, err := rudiCtx.Coalesce().ToBool(evaluated)
+	if err != nil {
+		return fmt.Errorf("script result is not a bool: %w", err)
+	}
+
+	if !ok {
+		return errAssertionFailed
+	}
+
+	return nil
+}
+
+func writeAssertReport(results []assertResult, format types.AssertFormat) error {
+	switch format {
+	case types.TapAssertFormat:
+		return writeTapReport(os.Stdout, results)
+	case types.JUnitAssertFormat:
+		return writeJUnitReport(os.Stdout, results)
+	default:
+		return writeTextReport(os.Stderr, results)
+	}
+}
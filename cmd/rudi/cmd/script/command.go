@@ -4,6 +4,7 @@
 package script
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -14,6 +15,8 @@ import (
 	"go.xrstf.de/rudi/cmd/rudi/encoding"
 	"go.xrstf.de/rudi/cmd/rudi/options"
 	"go.xrstf.de/rudi/cmd/rudi/util"
+	"go.xrstf.de/rudi/pkg/patch"
+	"go.xrstf.de/rudi/pkg/profiler"
 )
 
 func Run(handler *util.SignalHandler, opts *options.Options, library rudi.Program, args []string) error {
@@ -42,6 +45,9 @@ func Run(handler *util.SignalHandler, opts *options.Options, library rudi.Progra
 		scriptName = "(cli)"
 	}
 
+	// all remaining args are inputs, which determine the automatic output format
+	opts.OutputFormat = util.OutputFormat(opts, args)
+
 	// parse the script
 	program, err := rudi.Parse(scriptName, script)
 	if err != nil {
@@ -57,18 +63,44 @@ func Run(handler *util.SignalHandler, opts *options.Options, library rudi.Progra
 		return nil
 	}
 
+	if opts.InPlace {
+		return runInPlace(handler, opts, library, program, args)
+	}
+
+	if opts.Stream {
+		return runStream(handler, opts, library, program, args)
+	}
+
+	if opts.Assert {
+		return runAssert(handler, opts, library, program, args)
+	}
+
 	// load all remaining args as input fileContents
 	fileContents, err := util.LoadFiles(opts, args)
 	if err != nil {
 		return fmt.Errorf("failed to read inputs: %w", err)
 	}
 
+	if opts.Each {
+		return runEach(handler, opts, library, program, args, fileContents)
+	}
+
 	// setup the evaluation context
 	rudiCtx, err := util.SetupRudiContext(opts, args, fileContents)
 	if err != nil {
 		return fmt.Errorf("failed to setup context: %w", err)
 	}
 
+	// remember the original document to output the changes made to it
+	rudiCtx.GetDocument().TrackChanges()
+
+	// the profile includes the library, as it might contain expensive code as well
+	var prof *profiler.Profiler
+	if opts.Profile || opts.ProfileOutput != "" {
+		prof = profiler.New()
+		rudiCtx = rudiCtx.WithTracer(prof)
+	}
+
 	// allow to interrupt the script
 	subCtx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -84,16 +116,99 @@ func Run(handler *util.SignalHandler, opts *options.Options, library rudi.Progra
 		}
 	}
 
+	// only trace the script itself, not the library
+	if opts.Trace {
+		rudiCtx = rudiCtx.WithTracer(util.NewTreePrinter(os.Stderr))
+	}
+
 	// evaluate the script
 	evaluated, err := program.RunContext(rudiCtx.WithGoContext(subCtx))
+
+	// profiles are also useful if the script failed
+	if prof != nil {
+		if err := writeProfile(prof, opts); err != nil {
+			return err
+		}
+	}
+
 	if err != nil {
 		return fmt.Errorf("failed to evaluate script: %w", err)
 	}
 
 	// print the output
-	if err := encoding.Encode(evaluated, opts.OutputFormat, os.Stdout); err != nil {
+	if opts.PrintPatch || opts.PrintMergePatch || opts.PrintDiff {
+		return printChanges(rudiCtx.GetDocument(), opts)
+	}
+
+	if err := encoding.EncodeWithOptions(evaluated, opts.OutputFormat, os.Stdout, opts.EncodeOptions()); err != nil {
+		return fmt.Errorf("failed to encode data: %w", err)
+	}
+
+	return nil
+}
+
+func printChanges(doc *rudi.Document, opts *options.Options) error {
+	var (
+		output any
+		err    error
+	)
+
+	switch {
+	case opts.PrintPatch:
+		output, err = patch.JSONPatch(doc.Original(), doc.Data())
+	case opts.PrintMergePatch:
+		output, err = patch.MergePatch(doc.Original(), doc.Data())
+	case opts.PrintDiff:
+		return printDiff(doc, opts)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to compute changes: %w", err)
+	}
+
+	if err := encoding.EncodeWithOptions(output, opts.OutputFormat, os.Stdout, opts.EncodeOptions()); err != nil {
 		return fmt.Errorf("failed to encode data: %w", err)
 	}
 
 	return nil
 }
+
+func printDiff(doc *rudi.Document, opts *options.Options) error {
+	var original, updated bytes.Buffer
+
+	// colors would garble the diff
+	encodeOpts := opts.EncodeOptions()
+	encodeOpts.Color = false
+
+	if err := encoding.EncodeWithOptions(doc.Original(), opts.OutputFormat, &original, encodeOpts); err != nil {
+		return fmt.Errorf("failed to encode original document: %w", err)
+	}
+
+	if err := encoding.EncodeWithOptions(doc.Data(), opts.OutputFormat, &updated, encodeOpts); err != nil {
+		return fmt.Errorf("failed to encode updated document: %w", err)
+	}
+
+	return util.WriteUnifiedDiff(os.Stdout, "original", "updated", original.String(), updated.String())
+}
+
+func writeProfile(prof *profiler.Profiler, opts *options.Options) error {
+	if opts.Profile {
+		if err := prof.WriteText(os.Stderr); err != nil {
+			return fmt.Errorf("failed to print profile: %w", err)
+		}
+	}
+
+	if opts.ProfileOutput != "" {
+		f, err := os.Create(opts.ProfileOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create profile: %w", err)
+		}
+		defer f.Close()
+
+		if err := prof.WritePprof(f); err != nil {
+			return fmt.Errorf("failed to write profile: %w", err)
+		}
+	}
+
+	return nil
+}
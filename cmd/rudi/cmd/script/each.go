@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+
+	"go.xrstf.de/rudi"
+	"go.xrstf.de/rudi/cmd/rudi/each"
+	"go.xrstf.de/rudi/cmd/rudi/encoding"
+	"go.xrstf.de/rudi/cmd/rudi/options"
+	"go.xrstf.de/rudi/cmd/rudi/util"
+)
+
+// runEach runs the program once for every item in the first input file. All
+// items are processed in parallel, but the results are output in order.
+func runEach(handler *util.SignalHandler, opts *options.Options, library rudi.Program, program rudi.Program, fileNames []string, fileContents []any) error {
+	if len(fileContents) == 0 {
+		return errors.New("--each requires at least one input")
+	}
+
+	inputs, ok := fileContents[0].([]any)
+	if !ok {
+		return fmt.Errorf("--each requires the first input to be a vector of documents, but got %T", fileContents[0])
+	}
+
+	// allow to interrupt the scripts
+	subCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handler.SetCancelFn(cancel)
+
+	var (
+		outputs  = make([][]any, len(inputs))
+		indices  = make(chan int)
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(inputs) {
+		workers = len(inputs)
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for index := range indices {
+				result, err := runDocument(subCtx, opts, library, program, fileNames, fileContents, index)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("document %d: %w", index, err)
+						cancel()
+					})
+
+					continue
+				}
+
+				outputs[index] = each.Outputs(result)
+			}
+		}()
+	}
+
+feed:
+	for index := range inputs {
+		select {
+		case indices <- index:
+		case <-subCtx.Done():
+			break feed
+		}
+	}
+
+	close(indices)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	documents := []any{}
+	for _, output := range outputs {
+		documents = append(documents, output...)
+	}
+
+	if err := encoding.EncodeDocuments(documents, opts.OutputFormat, os.Stdout, opts.EncodeOptions()); err != nil {
+		return fmt.Errorf("failed to encode data: %w", err)
+	}
+
+	return nil
+}
+
+func runDocument(ctx context.Context, opts *options.Options, library rudi.Program, program rudi.Program, fileNames []string, fileContents []any, index int) (any, error) {
+	// every document gets its own context, so that variables and functions
+	// defined by the script do not leak into other documents
+	rudiCtx, err := util.SetupEachContext(opts, fileNames, fileContents, index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup context: %w", err)
+	}
+
+	rudiCtx = rudiCtx.WithGoContext(ctx)
+
+	if library != nil {
+		if _, err := library.RunContext(rudiCtx); err != nil {
+			return nil, fmt.Errorf("failed to evaluate library: %w", err)
+		}
+	}
+
+	result, err := program.RunContext(rudiCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate script: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.xrstf.de/rudi"
+	"go.xrstf.de/rudi/cmd/rudi/options"
+	"go.xrstf.de/rudi/cmd/rudi/util"
+)
+
+// runInPlace runs the program once for every file and writes the final
+// document back into the file. Each file is processed on its own, so $files
+// and $filenames only ever contain the current file.
+func runInPlace(handler *util.SignalHandler, opts *options.Options, library rudi.Program, program rudi.Program, fileNames []string) error {
+	if len(fileNames) == 0 {
+		return errors.New("--in-place requires at least one input file")
+	}
+
+	for _, fileName := range fileNames {
+		if fileName == "-" {
+			return errors.New("--in-place cannot be used with stdin")
+		}
+	}
+
+	for _, fileName := range fileNames {
+		if err := processFileInPlace(handler, opts, library, program, fileName); err != nil {
+			return fmt.Errorf("%s: %w", fileName, err)
+		}
+	}
+
+	return nil
+}
+
+func processFileInPlace(handler *util.SignalHandler, opts *options.Options, library rudi.Program, program rudi.Program, fileName string) error {
+	data, format, err := util.LoadFileForWriting(opts, fileName)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	rudiCtx, err := util.SetupRudiContext(opts, []string{fileName}, []any{data})
+	if err != nil {
+		return fmt.Errorf("failed to setup context: %w", err)
+	}
+
+	// allow to interrupt the script
+	subCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handler.SetCancelFn(cancel)
+
+	rudiCtx = rudiCtx.WithGoContext(subCtx)
+
+	if library != nil {
+		if _, err := library.RunContext(rudiCtx); err != nil {
+			return fmt.Errorf("failed to evaluate library: %w", err)
+		}
+	}
+
+	if opts.Trace {
+		rudiCtx = rudiCtx.WithTracer(util.NewTreePrinter(os.Stderr))
+	}
+
+	if _, err := program.RunContext(rudiCtx); err != nil {
+		return fmt.Errorf("failed to evaluate script: %w", err)
+	}
+
+	// files are formatted like the regular output, but are always encoded
+	// properly and never contain terminal colors
+	encodeOpts := opts.EncodeOptions()
+	encodeOpts.Color = false
+	encodeOpts.RawStrings = false
+
+	if err := util.WriteFile(fileName, rudiCtx.GetDocument().Data(), format, opts.BackupSuffix, encodeOpts); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
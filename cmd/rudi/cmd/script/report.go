@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package script
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// writeTextReport prints one line per input that did not pass.
+func writeTextReport(out io.Writer, results []assertResult) error {
+	for _, result := range results {
+		if result.passed() {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(out, "%s: %v\n", result.fileName, result.err); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeTapReport writes the results in the Test Anything Protocol (version 13)
+// format, with the error message as a YAML diagnostic block.
+func writeTapReport(out io.Writer, results []assertResult) error {
+	if _, err := fmt.Fprintf(out, "TAP version 13\n1..%d\n", len(results)); err != nil {
+		return err
+	}
+
+	for i, result := range results {
+		var err error
+
+		if result.passed() {
+			_, err = fmt.Fprintf(out, "ok %d - %s\n", i+1, result.fileName)
+		} else {
+			_, err = fmt.Fprintf(out, "not ok %d - %s\n  ---\n  message: %s\n  ...\n", i+1, result.fileName, strconv.Quote(result.err.Error()))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+}
+
+// writeJUnitReport writes the results as a JUnit XML report with a single test
+// suite and one test case per input. Inputs that evaluated to false are
+// failures, inputs that could not be checked at all are errors.
+func writeJUnitReport(out io.Writer, results []assertResult) error {
+	suite := junitTestSuite{
+		Name:  "rudi",
+		Tests: len(results),
+	}
+
+	var total time.Duration
+
+	for _, result := range results {
+		testCase := junitTestCase{
+			Name:      result.fileName,
+			ClassName: "rudi",
+			Time:      junitDuration(result.duration),
+		}
+
+		switch {
+		case result.failed():
+			testCase.Failure = &junitProblem{Message: result.err.Error()}
+			suite.Failures++
+		case !result.passed():
+			testCase.Error = &junitProblem{Message: result.err.Error()}
+			suite.Errors++
+		}
+
+		total += result.duration
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	suite.Time = junitDuration(total)
+
+	report := junitTestSuites{
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	encoded, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "%s%s\n", xml.Header, encoded)
+
+	return err
+}
+
+func junitDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package script
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.xrstf.de/rudi"
+	"go.xrstf.de/rudi/cmd/rudi/each"
+	"go.xrstf.de/rudi/cmd/rudi/encoding"
+	"go.xrstf.de/rudi/cmd/rudi/options"
+	"go.xrstf.de/rudi/cmd/rudi/types"
+	"go.xrstf.de/rudi/cmd/rudi/util"
+	"go.xrstf.de/rudi/pkg/ordered"
+)
+
+type streamItem struct {
+	value any
+	err   error
+}
+
+// runStream runs the program once for every value in an NDJSON stream (or for
+// every line, if the input is raw) and outputs the results immediately. The
+// next value is only read once the previous result has been written. All
+// values share the same context, so global variables keep their values and
+// can be used for aggregations. Interrupting the stream ends it gracefully.
+func runStream(handler *util.SignalHandler, opts *options.Options, library rudi.Program, program rudi.Program, fileNames []string) error {
+	if len(fileNames) != 1 {
+		return errors.New("--stream requires exactly one input")
+	}
+
+	input, format, err := util.OpenFile(opts, fileNames[0])
+	if err != nil {
+		return fmt.Errorf("failed to open input: %w", err)
+	}
+	defer input.Close()
+
+	// allow to interrupt the stream
+	subCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handler.SetCancelFn(cancel)
+
+	rudiCtx, err := util.SetupStreamContext(opts, fileNames)
+	if err != nil {
+		return fmt.Errorf("failed to setup context: %w", err)
+	}
+
+	rudiCtx = rudiCtx.WithGoContext(subCtx)
+
+	if library != nil {
+		if _, err := library.RunContext(rudiCtx); err != nil {
+			return fmt.Errorf("failed to evaluate library: %w", err)
+		}
+	}
+
+	if opts.Trace {
+		rudiCtx = rudiCtx.WithTracer(util.NewTreePrinter(os.Stderr))
+	}
+
+	items := make(chan streamItem)
+	go readStream(subCtx, input, format, opts, items)
+
+	// every result is written on a single line, even vectors
+	outputFormat := opts.OutputFormat
+	if outputFormat == types.NdjsonEncoding {
+		outputFormat = types.JsonEncoding
+	}
+
+	encodeOpts := opts.EncodeOptions()
+	encodeOpts.Compact = true
+
+	for index := 0; ; index++ {
+		var item streamItem
+
+		select {
+		case <-subCtx.Done():
+			return nil
+
+		case received, ok := <-items:
+			if !ok {
+				return nil
+			}
+
+			item = received
+		}
+
+		if item.err != nil {
+			return fmt.Errorf("failed to read document %d: %w", index, item.err)
+		}
+
+		document, err := rudi.NewDocument(item.value)
+		if err != nil {
+			return fmt.Errorf("document %d: %w", index, err)
+		}
+
+		docCtx := rudiCtx.WithDocument(document)
+		docCtx.SetVariable("index", index)
+
+		result, err := program.RunContext(docCtx)
+		if err != nil {
+			if subCtx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("document %d: failed to evaluate script: %w", index, err)
+		}
+
+		for _, output := range each.Outputs(result) {
+			if err := encoding.EncodeWithOptions(output, outputFormat, os.Stdout, encodeOpts); err != nil {
+				return fmt.Errorf("failed to encode data: %w", err)
+			}
+		}
+	}
+}
+
+// readStream sends every value read from the input to the channel, until the
+// input ends or the context is cancelled. The channel is unbuffered, so that
+// values are only read when they are needed.
+func readStream(ctx context.Context, input io.Reader, format types.Encoding, opts *options.Options, items chan<- streamItem) {
+	defer close(items)
+
+	next := jsonStreamReader(input, opts)
+	if format == types.RawEncoding {
+		next = rawStreamReader(input)
+	}
+
+	for {
+		value, err := next()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+
+		select {
+		case items <- streamItem{value: value, err: err}:
+		case <-ctx.Done():
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+func jsonStreamReader(input io.Reader, opts *options.Options) func() (any, error) {
+	decoder := json.NewDecoder(input)
+
+	return func() (any, error) {
+		if opts.PreserveOrder {
+			return ordered.DecodeJSON(decoder)
+		}
+
+		var value any
+		err := decoder.Decode(&value)
+
+		return value, err
+	}
+}
+
+func rawStreamReader(input io.Reader) func() (any, error) {
+	reader := bufio.NewReader(input)
+
+	return func() (any, error) {
+		line, err := reader.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			return nil, err
+		}
+
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+}
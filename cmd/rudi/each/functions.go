@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+// Package each contains the functions that are available when running the
+// CLI with --each or --stream, which allow scripts to control how many
+// documents are output for every input document.
+package each
+
+import (
+	"go.xrstf.de/rudi/pkg/runtime/functions"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+)
+
+var Functions = types.Functions{
+	"drop": functions.NewBuilder(dropFunction).WithDescription("when returned from a script in --each or --stream mode, no document is output").Build(),
+	"emit": functions.NewBuilder(emitFunction).WithDescription("when returned from a script in --each or --stream mode, all given values are output as separate documents").Build(),
+}
+
+// documents is a sentinel value that replaces the script's result with any
+// number of documents.
+type documents struct {
+	values []any
+}
+
+func dropFunction() (any, error) {
+	return documents{values: []any{}}, nil
+}
+
+func emitFunction(values ...any) (any, error) {
+	return documents{values: values}, nil
+}
+
+// Outputs returns the documents to output for a script's result. Unless the
+// script returned the result of drop or emit, this is just the result itself.
+func Outputs(result any) []any {
+	if docs, ok := result.(documents); ok {
+		return docs.values
+	}
+
+	return []any{result}
+}
@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package encoding
+
+import (
+	"bytes"
+
+	"github.com/fatih/color"
+)
+
+// the same colors as used by the console
+var (
+	jsonKeyColor    = newColor(color.FgWhite)
+	jsonStringColor = newColor(color.FgGreen)
+	jsonBoolColor   = newColor(color.FgYellow)
+	jsonNumberColor = newColor(color.FgCyan)
+	jsonNullColor   = newColor(color.FgMagenta)
+)
+
+func newColor(attr color.Attribute) *color.Color {
+	c := color.New(attr)
+
+	// whether to use colors is decided by the caller, not by
+	// auto-detecting the terminal
+	c.EnableColor()
+
+	return c
+}
+
+// colorizeJson highlights already encoded JSON. Working on the encoded data
+// instead of the values ensures that colorizing does not change the
+// formatting or the order of object keys.
+func colorizeJson(encoded []byte) []byte {
+	var buf bytes.Buffer
+
+	for i := 0; i < len(encoded); {
+		var (
+			end int
+			c   *color.Color
+		)
+
+		switch b := encoded[i]; {
+		case b == '"':
+			end = jsonStringEnd(encoded, i)
+			c = jsonStringColor
+
+			if isJsonKey(encoded, end) {
+				c = jsonKeyColor
+			}
+
+		case b == '-' || (b >= '0' && b <= '9'):
+			end = jsonTokenEnd(encoded, i)
+			c = jsonNumberColor
+
+		case b == 't' || b == 'f':
+			end = jsonTokenEnd(encoded, i)
+			c = jsonBoolColor
+
+		case b == 'n':
+			end = jsonTokenEnd(encoded, i)
+			c = jsonNullColor
+
+		default:
+			buf.WriteByte(b)
+			i++
+
+			continue
+		}
+
+		buf.WriteString(c.Sprint(string(encoded[i:end])))
+		i = end
+	}
+
+	return buf.Bytes()
+}
+
+// jsonStringEnd returns the position after the closing quote of the string
+// starting at start.
+func jsonStringEnd(encoded []byte, start int) int {
+	for i := start + 1; i < len(encoded); i++ {
+		switch encoded[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+
+	return len(encoded)
+}
+
+// jsonTokenEnd returns the position after the number or literal (true, false,
+// null) starting at start.
+func jsonTokenEnd(encoded []byte, start int) int {
+	for i := start; i < len(encoded); i++ {
+		switch encoded[i] {
+		case ',', ':', ']', '}', ' ', '\t', '\r', '\n':
+			return i
+		}
+	}
+
+	return len(encoded)
+}
+
+func isJsonKey(encoded []byte, stringEnd int) bool {
+	for i := stringEnd; i < len(encoded); i++ {
+		switch encoded[i] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case ':':
+			return true
+		default:
+			return false
+		}
+	}
+
+	return false
+}
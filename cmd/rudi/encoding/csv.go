@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package encoding
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.xrstf.de/rudi/pkg/ordered"
+)
+
+// decodeCsv reads a CSV file with a header row into a vector of objects, one
+// per row, with all values being strings.
+func decodeCsv(input io.Reader, opts DecodeOptions) (any, error) {
+	reader := csv.NewReader(input)
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return []any{}, nil
+		}
+
+		return nil, err
+	}
+
+	seen := map[string]struct{}{}
+	for _, column := range header {
+		if _, exists := seen[column]; exists {
+			return nil, fmt.Errorf("column %q is defined multiple times", column)
+		}
+
+		seen[column] = struct{}{}
+	}
+
+	rows := []any{}
+	for {
+		record, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, err
+		}
+
+		row := newObjectBuilder(opts)
+		for i, column := range header {
+			row.Set(column, record[i])
+		}
+
+		rows = append(rows, row.Object())
+	}
+
+	return rows, nil
+}
+
+// csvEncoder writes a vector of objects as a CSV file with a header row, or a
+// vector of vectors as plain rows. The header contains all keys in the order
+// they first appear in the objects.
+type csvEncoder struct {
+	out io.Writer
+}
+
+func (e *csvEncoder) Encode(data any) error {
+	normalized, err := normalize(data)
+	if err != nil {
+		return err
+	}
+
+	rows, ok := normalized.([]any)
+	if !ok {
+		return fmt.Errorf("CSV output requires a vector of objects or vectors, got %T", data)
+	}
+
+	records, err := csvRecords(rows)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(e.out)
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func csvRecords(rows []any) ([][]string, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	if _, ok := rows[0].([]any); ok {
+		records := make([][]string, len(rows))
+		for i, row := range rows {
+			vector, ok := row.([]any)
+			if !ok {
+				return nil, fmt.Errorf("row %d: expected vector, got %T", i, row)
+			}
+
+			record, err := csvRecord(vector)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i, err)
+			}
+
+			records[i] = record
+		}
+
+		return records, nil
+	}
+
+	header := []string{}
+	columns := map[string]struct{}{}
+
+	for i, row := range rows {
+		object, ok := row.(ordered.Object)
+		if !ok {
+			return nil, fmt.Errorf("row %d: expected object, got %T", i, row)
+		}
+
+		for _, key := range object.Keys() {
+			if _, exists := columns[key]; !exists {
+				columns[key] = struct{}{}
+				header = append(header, key)
+			}
+		}
+	}
+
+	records := [][]string{header}
+	for i, row := range rows {
+		object := row.(ordered.Object)
+
+		values := make([]any, len(header))
+		for j, column := range header {
+			values[j], _ = object.Get(column)
+		}
+
+		record, err := csvRecord(values)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i, err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func csvRecord(values []any) ([]string, error) {
+	record := make([]string, len(values))
+	for i, value := range values {
+		formatted, err := scalarString(value)
+		if err != nil {
+			return nil, err
+		}
+
+		record[i] = formatted
+	}
+
+	return record, nil
+}
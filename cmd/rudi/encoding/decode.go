@@ -10,6 +10,8 @@ import (
 	"io"
 
 	"go.xrstf.de/rudi/cmd/rudi/types"
+	"go.xrstf.de/rudi/cmd/rudi/yamlnode"
+	"go.xrstf.de/rudi/pkg/ordered"
 
 	"github.com/BurntSushi/toml"
 	"github.com/titanous/json5"
@@ -36,9 +38,54 @@ func decodeYaml(input io.Reader) ([]any, error) {
 	return documents, nil
 }
 
+func decodeYamlNodes(input io.Reader) ([]any, error) {
+	documents, err := yamlnode.Decode(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file as YAML: %w", err)
+	}
+
+	return documents, nil
+}
+
+func decodeOrderedYaml(input io.Reader) ([]any, error) {
+	documents, err := decodeYamlNodes(input)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, document := range documents {
+		if documents[i], err = yamlnode.ToOrdered(document); err != nil {
+			return nil, fmt.Errorf("failed to parse file as YAML: %w", err)
+		}
+	}
+
+	return documents, nil
+}
+
+// DecodeOptions control which representation decoded values use.
+type DecodeOptions struct {
+	// PreserveYaml decodes YAML into values that keep comments, key order and
+	// formatting when encoded as YAML again.
+	PreserveYaml bool
+
+	// PreserveOrder decodes JSON and YAML objects into ordered.Objects.
+	PreserveOrder bool
+}
+
 func Decode(input io.Reader, enc types.Encoding) (any, error) {
+	return DecodeWithOptions(input, enc, DecodeOptions{})
+}
+
+func DecodeWithOptions(input io.Reader, enc types.Encoding, opts DecodeOptions) (any, error) {
 	var data any
 
+	decodeYaml := decodeYaml
+	if opts.PreserveYaml {
+		decodeYaml = decodeYamlNodes
+	} else if opts.PreserveOrder {
+		decodeYaml = decodeOrderedYaml
+	}
+
 	switch enc {
 	case types.RawEncoding:
 		content, err := io.ReadAll(input)
@@ -50,7 +97,15 @@ func Decode(input io.Reader, enc types.Encoding) (any, error) {
 
 	case types.JsonEncoding:
 		decoder := json.NewDecoder(input)
-		if err := decoder.Decode(&data); err != nil {
+
+		if opts.PreserveOrder {
+			decoded, err := ordered.DecodeJSON(decoder)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse file as JSON: %w", err)
+			}
+
+			data = decoded
+		} else if err := decoder.Decode(&data); err != nil {
 			return nil, fmt.Errorf("failed to parse file as JSON: %w", err)
 		}
 
@@ -89,6 +144,54 @@ func Decode(input io.Reader, enc types.Encoding) (any, error) {
 			return nil, fmt.Errorf("failed to parse file as TOML: %w", err)
 		}
 
+	case types.CsvEncoding:
+		decoded, err := decodeCsv(input, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse file as CSV: %w", err)
+		}
+
+		data = decoded
+
+	case types.NdjsonEncoding:
+		decoded, err := decodeNdjson(input, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse file as NDJSON: %w", err)
+		}
+
+		data = decoded
+
+	case types.XmlEncoding:
+		decoded, err := decodeXml(input, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse file as XML: %w", err)
+		}
+
+		data = decoded
+
+	case types.HclEncoding:
+		decoded, err := decodeHcl(input, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse file as HCL: %w", err)
+		}
+
+		data = decoded
+
+	case types.PropertiesEncoding:
+		decoded, err := decodeProperties(input, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse file as properties: %w", err)
+		}
+
+		data = decoded
+
+	case types.IniEncoding:
+		decoded, err := decodeIni(input, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse file as INI: %w", err)
+		}
+
+		data = decoded
+
 	default:
 		return nil, fmt.Errorf("unexpected encoding %q", enc)
 	}
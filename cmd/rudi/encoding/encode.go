@@ -4,40 +4,135 @@
 package encoding
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
+	"strings"
 
 	"go.xrstf.de/rudi/cmd/rudi/types"
+	"go.xrstf.de/rudi/cmd/rudi/yamlnode"
 
 	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v3"
 )
 
-func newYamlEncoder(out io.Writer) *yaml.Encoder {
+func newYamlEncoder(out io.Writer, opts EncodeOptions) *yaml.Encoder {
 	encoder := yaml.NewEncoder(out)
-	encoder.SetIndent(2)
+	encoder.SetIndent(opts.indent())
 
 	return encoder
 }
 
+// EncodeOptions control how encoded data is formatted.
+type EncodeOptions struct {
+	// Indent is the number of spaces used to indent JSON, YAML, TOML and XML.
+	// If not set, 2 spaces are used.
+	Indent int
+
+	// Compact outputs JSON and XML on a single line and YAML in flow style.
+	Compact bool
+
+	// SortKeys sorts the keys of all objects, including ordered objects and
+	// values decoded with DecodeOptions.PreserveYaml.
+	SortKeys bool
+
+	// EscapeHTML escapes <, > and & in JSON strings.
+	EscapeHTML bool
+
+	// Color highlights JSON using terminal colors.
+	Color bool
+
+	// RawStrings outputs strings without quoting or escaping them (like
+	// jq -r), all other values are encoded as usual.
+	RawStrings bool
+}
+
+func (o EncodeOptions) indent() int {
+	if o.Indent <= 0 {
+		return 2
+	}
+
+	return o.Indent
+}
+
+func (o EncodeOptions) indentString() string {
+	if o.Compact {
+		return ""
+	}
+
+	return strings.Repeat(" ", o.indent())
+}
+
+// yamlDocument is implemented by values decoded with DecodeOptions.PreserveYaml
+// to keep comments at the start and end of documents.
+type yamlDocument interface {
+	YAMLDocument() *yaml.Node
+}
+
+func toYamlDocument(data any) any {
+	if doc, ok := data.(yamlDocument); ok {
+		return doc.YAMLDocument()
+	}
+
+	return data
+}
+
 func Encode(data any, enc types.Encoding, out io.Writer) error {
+	return EncodeWithOptions(data, enc, out, EncodeOptions{EscapeHTML: true})
+}
+
+func EncodeWithOptions(data any, enc types.Encoding, out io.Writer, opts EncodeOptions) error {
+	if str, ok := data.(string); ok && opts.RawStrings {
+		_, err := fmt.Fprintln(out, str)
+		return err
+	}
+
 	var encoder interface {
 		Encode(v any) error
 	}
 
+	// only JSON and YAML know how to handle ordered objects and values decoded
+	// with DecodeOptions.PreserveYaml; plain objects are always sorted
+	if opts.SortKeys || enc == types.TomlEncoding || enc == types.RawEncoding {
+		plain, err := yamlnode.ToPlain(data)
+		if err != nil {
+			return err
+		}
+
+		data = plain
+	}
+
 	switch enc {
 	case types.JsonEncoding:
-		encoder = json.NewEncoder(out)
-		encoder.(*json.Encoder).SetIndent("", "  ")
+		encoder = &jsonEncoder{out: out, opts: opts}
 	case types.YamlEncoding:
-		encoder = newYamlEncoder(out)
+		encoder = newYamlEncoder(out, opts)
+
+		marshalable, err := yamlMarshalable(data, opts)
+		if err != nil {
+			return err
+		}
+
+		data = marshalable
 	case types.YamlDocumentsEncoding:
-		encoder = &yamldocsEncoder{out: out}
+		encoder = &yamldocsEncoder{out: out, opts: opts}
 	case types.TomlEncoding:
 		encoder = toml.NewEncoder(out)
-		encoder.(*toml.Encoder).Indent = "  "
+		encoder.(*toml.Encoder).Indent = opts.indentString()
+	case types.CsvEncoding:
+		encoder = &csvEncoder{out: out}
+	case types.NdjsonEncoding:
+		encoder = &ndjsonEncoder{out: out, opts: opts}
+	case types.XmlEncoding:
+		encoder = &xmlEncoder{out: out, opts: opts}
+	case types.HclEncoding:
+		encoder = &hclEncoder{out: out}
+	case types.PropertiesEncoding:
+		encoder = &propertiesEncoder{out: out}
+	case types.IniEncoding:
+		encoder = &iniEncoder{out: out}
 	default:
 		encoder = &rawEncoder{out: out}
 	}
@@ -45,6 +140,52 @@ func Encode(data any, enc types.Encoding, out io.Writer) error {
 	return encoder.Encode(data)
 }
 
+// EncodeDocuments writes multiple documents. For YAML, the documents are
+// separated by "---", for all other encodings they are simply written one
+// after another.
+func EncodeDocuments(documents []any, enc types.Encoding, out io.Writer, opts EncodeOptions) error {
+	if enc == types.YamlEncoding || enc == types.YamlDocumentsEncoding {
+		return EncodeWithOptions(documents, types.YamlDocumentsEncoding, out, opts)
+	}
+
+	for _, document := range documents {
+		if err := EncodeWithOptions(document, enc, out, opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type jsonEncoder struct {
+	out  io.Writer
+	opts EncodeOptions
+}
+
+func (e *jsonEncoder) Encode(data any) error {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(e.opts.EscapeHTML)
+
+	if !e.opts.Compact {
+		encoder.SetIndent("", e.opts.indentString())
+	}
+
+	if err := encoder.Encode(data); err != nil {
+		return err
+	}
+
+	encoded := buf.Bytes()
+	if e.opts.Color {
+		encoded = colorizeJson(encoded)
+	}
+
+	_, err := e.out.Write(encoded)
+
+	return err
+}
+
 type rawEncoder struct {
 	out io.Writer
 }
@@ -55,10 +196,20 @@ func (e *rawEncoder) Encode(v any) error {
 }
 
 type yamldocsEncoder struct {
-	out io.Writer
+	out  io.Writer
+	opts EncodeOptions
 }
 
 func (e *yamldocsEncoder) Encode(data any) error {
+	if seq, ok := data.(yamlnode.Sequence); ok {
+		items, err := seq.CoalesceToVector(nil)
+		if err != nil {
+			return err
+		}
+
+		data = items
+	}
+
 	rValue := reflect.ValueOf(data)
 	rType := reflect.TypeOf(data)
 	if rType.Kind() == reflect.Pointer {
@@ -66,13 +217,12 @@ func (e *yamldocsEncoder) Encode(data any) error {
 		rType = rValue.Type()
 	}
 
-	encoder := newYamlEncoder(e.out)
+	encoder := newYamlEncoder(e.out, e.opts)
 
 	switch rType.Kind() {
 	case reflect.Slice, reflect.Array:
 		for i := 0; i < rValue.Len(); i++ {
-			value := rValue.Index(i).Interface()
-			if err := encoder.Encode(value); err != nil {
+			if err := e.encode(encoder, rValue.Index(i).Interface()); err != nil {
 				return err
 			}
 		}
@@ -80,5 +230,41 @@ func (e *yamldocsEncoder) Encode(data any) error {
 		return nil
 	}
 
-	return encoder.Encode(data)
+	return e.encode(encoder, data)
+}
+
+func (e *yamldocsEncoder) encode(encoder *yaml.Encoder, document any) error {
+	marshalable, err := yamlMarshalable(document, e.opts)
+	if err != nil {
+		return err
+	}
+
+	return encoder.Encode(marshalable)
+}
+
+func yamlMarshalable(document any, opts EncodeOptions) (any, error) {
+	marshalable, err := yamlnode.Marshalable(toYamlDocument(document))
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.Compact {
+		return marshalable, nil
+	}
+
+	// encoding the value into a node creates a copy that can be modified
+	node := &yaml.Node{}
+	if err := node.Encode(marshalable); err != nil {
+		return nil, err
+	}
+
+	// nested collections inherit the flow style from the root
+	root := node
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+
+	root.Style |= yaml.FlowStyle
+
+	return node, nil
 }
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package encoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.xrstf.de/rudi/cmd/rudi/types"
+)
+
+func TestRoundtrip(t *testing.T) {
+	testcases := []struct {
+		name   string
+		format types.Encoding
+		input  string
+		// expected is the JSON encoding of the decoded input
+		expected string
+	}{
+		{
+			name:     "CSV",
+			format:   types.CsvEncoding,
+			input:    "name,note\nfoo,plain\nbar,\"with, comma\"\nbaz,\"with \"\"quotes\"\"\"\nqux,\"multiple\nlines\"\n",
+			expected: `[{"name":"foo","note":"plain"},{"name":"bar","note":"with, comma"},{"name":"baz","note":"with \"quotes\""},{"name":"qux","note":"multiple\nlines"}]`,
+		},
+		{
+			name:     "XML",
+			format:   types.XmlEncoding,
+			input:    "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<root version=\"1\">\n  <item id=\"a\">first &amp; &lt;second&gt;</item>\n  <item id=\"b\">\n    <name>nested</name>\n  </item>\n  <empty></empty>\n  <quote>&#34;quoted&#34;</quote>\n</root>\n",
+			expected: `{"root":{"@version":"1","item":[{"@id":"a","#text":"first \u0026 \u003csecond\u003e"},{"@id":"b","name":"nested"}],"empty":"","quote":"\"quoted\""}}`,
+		},
+		{
+			name:     "HCL",
+			format:   types.HclEncoding,
+			input:    "name    = \"with \\\"quotes\\\"\"\ncount   = 3\nenabled = true\ntags    = [\"a\", \"b\"]\nref     = var.name\nlabel   = \"web-${var.name}\"\n\nresource \"a\" \"b\" {\n  x = 1\n\n  nested {\n    y = \"z\"\n  }\n}\n",
+			expected: `{"name":"with \"quotes\"","count":3,"enabled":true,"tags":["a","b"],"ref":"${var.name}","label":"web-${var.name}","resource":{"a":{"b":[{"x":1,"nested":[{"y":"z"}]}]}}}`,
+		},
+		{
+			name:     "INI",
+			format:   types.IniEncoding,
+			input:    "top = level\n\n[server]\nhost = example.com\nport = 8080\n\n[server.tls]\nenabled = true\n",
+			expected: `{"top":"level","server":{"host":"example.com","port":"8080"},"server.tls":{"enabled":"true"}}`,
+		},
+		{
+			name:     "properties",
+			format:   types.PropertiesEncoding,
+			input:    "a.b = 1\npath = C:\\\\temp\nunicode = café\nmulti = first\\nsecond\nspaces = \\  leading\nkey\\=with\\:specials = value\n\\#hash = not a comment\n",
+			expected: `{"a.b":"1","path":"C:\\temp","unicode":"café","multi":"first\nsecond","spaces":"  leading","key=with:specials":"value","#hash":"not a comment"}`,
+		},
+		{
+			name:     "NDJSON",
+			format:   types.NdjsonEncoding,
+			input:    "{\"a\":1,\"b\":\"line\\nbreak\"}\n[1,2]\n\"string\"\nnull\n",
+			expected: `[{"a":1,"b":"line\nbreak"},[1,2],"string",null]`,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			decoded, err := DecodeWithOptions(strings.NewReader(testcase.input), testcase.format, DecodeOptions{PreserveOrder: true})
+			if err != nil {
+				t.Fatalf("Failed to decode: %v", err)
+			}
+
+			encoded, err := json.Marshal(decoded)
+			if err != nil {
+				t.Fatalf("Failed to encode decoded data as JSON: %v", err)
+			}
+
+			if string(encoded) != testcase.expected {
+				t.Fatalf("Expected input to decode to\n\n%s\n\nbut got\n\n%s", testcase.expected, string(encoded))
+			}
+
+			var buf bytes.Buffer
+			if err := EncodeWithOptions(decoded, testcase.format, &buf, EncodeOptions{Indent: 2}); err != nil {
+				t.Fatalf("Failed to encode: %v", err)
+			}
+
+			if output := buf.String(); output != testcase.input {
+				t.Fatalf("Expected\n\n%s\nbut got\n\n%s", testcase.input, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,481 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package encoding
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+
+	"go.xrstf.de/rudi/pkg/ordered"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// HCL files are represented like this:
+//
+//   - Attributes become keys with their values. Expressions that cannot be
+//     evaluated without context (like references to variables) are kept as
+//     strings in the form of "${expression}", string templates are kept as
+//     they are (e.g. "web-${var.name}").
+//   - Blocks are nested by their type and labels, with a vector of block
+//     bodies at the innermost level, as blocks can be repeated. For example,
+//     `resource "a" "b" { x = 1 }` becomes {resource {a {b [{x 1}]}}}.
+//
+// When encoding, vectors of objects (optionally nested in objects) are
+// written as blocks, all other values as attributes. Strings containing
+// "${...}" are written as expressions or templates.
+
+func decodeHcl(input io.Reader, opts DecodeOptions) (any, error) {
+	src, err := io.ReadAll(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	file, diags := hclsyntax.ParseConfig(src, "input.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("unexpected HCL body %T", file.Body)
+	}
+
+	return decodeHclBody(body, src, opts)
+}
+
+func decodeHclBody(body *hclsyntax.Body, src []byte, opts DecodeOptions) (any, error) {
+	type bodyItem struct {
+		offset int
+		attr   *hclsyntax.Attribute
+		block  *hclsyntax.Block
+	}
+
+	items := []bodyItem{}
+	for _, attr := range body.Attributes {
+		items = append(items, bodyItem{offset: attr.SrcRange.Start.Byte, attr: attr})
+	}
+
+	for _, block := range body.Blocks {
+		items = append(items, bodyItem{offset: block.TypeRange.Start.Byte, block: block})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].offset < items[j].offset
+	})
+
+	result := newObjectBuilder(opts)
+
+	for _, item := range items {
+		if item.attr != nil {
+			value, err := decodeHclExpression(item.attr.Expr, src, opts)
+			if err != nil {
+				return nil, fmt.Errorf("attribute %q: %w", item.attr.Name, err)
+			}
+
+			result.Set(item.attr.Name, value)
+			continue
+		}
+
+		content, err := decodeHclBody(item.block.Body, src, opts)
+		if err != nil {
+			return nil, fmt.Errorf("block %q: %w", item.block.Type, err)
+		}
+
+		path := append([]string{item.block.Type}, item.block.Labels...)
+		if err := addHclBlock(result, path, content, opts); err != nil {
+			return nil, fmt.Errorf("block %q: %w", item.block.Type, err)
+		}
+	}
+
+	return result.Object(), nil
+}
+
+func addHclBlock(parent objectBuilder, path []string, content any, opts DecodeOptions) error {
+	existing, exists := parent.Get(path[0])
+
+	if len(path) == 1 {
+		blocks := []any{}
+		if exists {
+			var ok bool
+			if blocks, ok = existing.([]any); !ok {
+				return errors.New("conflicts with an attribute or block with labels")
+			}
+		}
+
+		parent.Set(path[0], append(blocks, content))
+
+		return nil
+	}
+
+	child := newObjectBuilder(opts)
+	if exists {
+		var ok bool
+		if child, ok = toObjectBuilder(existing); !ok {
+			return errors.New("conflicts with an attribute or block without labels")
+		}
+	}
+
+	if err := addHclBlock(child, path[1:], content, opts); err != nil {
+		return err
+	}
+
+	parent.Set(path[0], child.Object())
+
+	return nil
+}
+
+func decodeHclExpression(expr hclsyntax.Expression, src []byte, opts DecodeOptions) (any, error) {
+	switch asserted := expr.(type) {
+	case *hclsyntax.TupleConsExpr:
+		result := make([]any, len(asserted.Exprs))
+		for i, item := range asserted.Exprs {
+			value, err := decodeHclExpression(item, src, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			result[i] = value
+		}
+
+		return result, nil
+
+	case *hclsyntax.ObjectConsExpr:
+		result := newObjectBuilder(opts)
+		for _, item := range asserted.Items {
+			key, diags := item.KeyExpr.Value(nil)
+			if diags.HasErrors() || key.IsNull() || key.Type() != cty.String {
+				return hclExpressionString(expr, src), nil
+			}
+
+			value, err := decodeHclExpression(item.ValueExpr, src, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			result.Set(key.AsString(), value)
+		}
+
+		return result.Object(), nil
+
+	case *hclsyntax.TemplateExpr:
+		value, diags := asserted.Value(nil)
+		if diags.HasErrors() {
+			// keep "foo-${var.bar}" as it is, without the quotes
+			source := string(asserted.SrcRange.SliceBytes(src))
+			if len(source) >= 2 && strings.HasPrefix(source, `"`) && strings.HasSuffix(source, `"`) {
+				return source[1 : len(source)-1], nil
+			}
+
+			return hclExpressionString(expr, src), nil
+		}
+
+		return decodeCtyValue(value, opts)
+
+	case *hclsyntax.TemplateWrapExpr:
+		// "${var.foo}" is the same as var.foo
+		value, diags := asserted.Value(nil)
+		if diags.HasErrors() {
+			return hclExpressionString(asserted.Wrapped, src), nil
+		}
+
+		return decodeCtyValue(value, opts)
+
+	default:
+		value, diags := expr.Value(nil)
+		if diags.HasErrors() {
+			return hclExpressionString(expr, src), nil
+		}
+
+		return decodeCtyValue(value, opts)
+	}
+}
+
+func hclExpressionString(expr hclsyntax.Expression, src []byte) string {
+	return "${" + string(expr.Range().SliceBytes(src)) + "}"
+}
+
+func decodeCtyValue(value cty.Value, opts DecodeOptions) (any, error) {
+	if !value.IsWhollyKnown() {
+		return nil, errors.New("value is not known")
+	}
+
+	if value.IsNull() {
+		return nil, nil
+	}
+
+	valueType := value.Type()
+
+	switch {
+	case valueType == cty.String:
+		return value.AsString(), nil
+
+	case valueType == cty.Bool:
+		return value.True(), nil
+
+	case valueType == cty.Number:
+		number := value.AsBigFloat()
+		if number.IsInt() {
+			if i, accuracy := number.Int64(); accuracy == big.Exact {
+				return i, nil
+			}
+		}
+
+		f, _ := number.Float64()
+
+		return f, nil
+
+	case valueType.IsListType() || valueType.IsSetType() || valueType.IsTupleType():
+		result := []any{}
+		for it := value.ElementIterator(); it.Next(); {
+			_, item := it.Element()
+
+			decoded, err := decodeCtyValue(item, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, decoded)
+		}
+
+		return result, nil
+
+	case valueType.IsMapType() || valueType.IsObjectType():
+		result := newObjectBuilder(opts)
+		for it := value.ElementIterator(); it.Next(); {
+			key, item := it.Element()
+
+			decoded, err := decodeCtyValue(item, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			result.Set(key.AsString(), decoded)
+		}
+
+		return result.Object(), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported value type %s", valueType.FriendlyName())
+	}
+}
+
+type hclEncoder struct {
+	out io.Writer
+}
+
+func (e *hclEncoder) Encode(data any) error {
+	normalized, err := normalize(data)
+	if err != nil {
+		return err
+	}
+
+	object, ok := normalized.(ordered.Object)
+	if !ok {
+		return fmt.Errorf("HCL output requires an object, got %T", data)
+	}
+
+	file := hclwrite.NewEmptyFile()
+	if err := encodeHclBody(file.Body(), object); err != nil {
+		return err
+	}
+
+	_, err = e.out.Write(hclwrite.Format(file.Bytes()))
+
+	return err
+}
+
+// encodeHclBody writes all attributes first, followed by all blocks.
+func encodeHclBody(body *hclwrite.Body, object ordered.Object) error {
+	blocks := []string{}
+
+	for _, key := range object.Keys() {
+		if !hclsyntax.ValidIdentifier(key) {
+			return fmt.Errorf("%q is not a valid HCL identifier", key)
+		}
+
+		value, _ := object.Get(key)
+
+		if isHclBlockTree(value) {
+			blocks = append(blocks, key)
+			continue
+		}
+
+		body.SetAttributeRaw(key, hclTokens(value))
+	}
+
+	for _, key := range blocks {
+		value, _ := object.Get(key)
+
+		if err := encodeHclBlocks(body, key, nil, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isHclBlockTree returns true for values that have the shape of decoded
+// blocks, i.e. vectors of objects, optionally nested in objects (the labels).
+func isHclBlockTree(value any) bool {
+	switch asserted := value.(type) {
+	case []any:
+		for _, item := range asserted {
+			if _, ok := item.(ordered.Object); !ok {
+				return false
+			}
+		}
+
+		return len(asserted) > 0
+
+	case ordered.Object:
+		for _, key := range asserted.Keys() {
+			item, _ := asserted.Get(key)
+			if !isHclBlockTree(item) {
+				return false
+			}
+		}
+
+		return asserted.Len() > 0
+
+	default:
+		return false
+	}
+}
+
+func encodeHclBlocks(body *hclwrite.Body, typeName string, labels []string, value any) error {
+	switch asserted := value.(type) {
+	case []any:
+		for _, item := range asserted {
+			if len(body.Attributes()) > 0 || len(body.Blocks()) > 0 {
+				body.AppendNewline()
+			}
+
+			block := body.AppendNewBlock(typeName, labels)
+			if err := encodeHclBody(block.Body(), item.(ordered.Object)); err != nil {
+				return err
+			}
+		}
+
+	case ordered.Object:
+		for _, label := range asserted.Keys() {
+			item, _ := asserted.Get(label)
+
+			if err := encodeHclBlocks(body, typeName, append(labels[:len(labels):len(labels)], label), item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func hclTokens(value any) hclwrite.Tokens {
+	switch asserted := value.(type) {
+	case ordered.Object:
+		attrs := make([]hclwrite.ObjectAttrTokens, 0, asserted.Len())
+		for _, key := range asserted.Keys() {
+			item, _ := asserted.Get(key)
+
+			name := hclwrite.TokensForValue(cty.StringVal(key))
+			if hclsyntax.ValidIdentifier(key) {
+				name = hclwrite.TokensForIdentifier(key)
+			}
+
+			attrs = append(attrs, hclwrite.ObjectAttrTokens{
+				Name:  name,
+				Value: hclTokens(item),
+			})
+		}
+
+		return hclwrite.TokensForObject(attrs)
+
+	case []any:
+		items := make([]hclwrite.Tokens, len(asserted))
+		for i, item := range asserted {
+			items[i] = hclTokens(item)
+		}
+
+		return hclwrite.TokensForTuple(items)
+
+	case string:
+		if tokens, ok := hclExpressionTokens(asserted); ok {
+			return tokens
+		}
+
+		return hclwrite.TokensForValue(cty.StringVal(asserted))
+
+	case nil:
+		return hclwrite.TokensForValue(cty.NullVal(cty.DynamicPseudoType))
+
+	case bool:
+		return hclwrite.TokensForValue(cty.BoolVal(asserted))
+
+	case int:
+		return hclwrite.TokensForValue(cty.NumberIntVal(int64(asserted)))
+
+	case int64:
+		return hclwrite.TokensForValue(cty.NumberIntVal(asserted))
+
+	case float64:
+		return hclwrite.TokensForValue(cty.NumberFloatVal(asserted))
+
+	default:
+		// this cannot fail for scalar values
+		formatted, _ := scalarString(asserted)
+		return hclwrite.TokensForValue(cty.StringVal(formatted))
+	}
+}
+
+// hclExpressionTokens turns strings in the form of "${expression}" and string
+// templates, as created when decoding expressions that cannot be evaluated,
+// back into expressions.
+func hclExpressionTokens(s string) (hclwrite.Tokens, bool) {
+	if !strings.Contains(s, "${") {
+		return nil, false
+	}
+
+	// "${var.foo}" can be written simply as var.foo
+	if strings.HasPrefix(s, "${") && strings.HasSuffix(s, "}") {
+		if tokens, ok := lexHclExpression(s[2 : len(s)-1]); ok {
+			return tokens, true
+		}
+	}
+
+	return lexHclExpression(`"` + s + `"`)
+}
+
+func lexHclExpression(expr string) (hclwrite.Tokens, bool) {
+	src := []byte(expr)
+
+	if _, diags := hclsyntax.ParseExpression(src, "", hcl.InitialPos); diags.HasErrors() {
+		return nil, false
+	}
+
+	lexed, diags := hclsyntax.LexExpression(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, false
+	}
+
+	tokens := hclwrite.Tokens{}
+	for _, token := range lexed {
+		if token.Type == hclsyntax.TokenEOF {
+			continue
+		}
+
+		tokens = append(tokens, &hclwrite.Token{
+			Type:  token.Type,
+			Bytes: token.Bytes,
+		})
+	}
+
+	return tokens, true
+}
@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package encoding
+
+import (
+	"fmt"
+	"io"
+
+	"go.xrstf.de/rudi/pkg/ordered"
+
+	"github.com/go-ini/ini"
+)
+
+// decodeIni reads an INI file into an object. Keys outside of any section are
+// top-level keys, every section becomes a nested object. All values are
+// strings.
+func decodeIni(input io.Reader, opts DecodeOptions) (any, error) {
+	content, err := io.ReadAll(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	file, err := ini.Load(content)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newObjectBuilder(opts)
+
+	for _, section := range file.Sections() {
+		target := result
+		if section.Name() != ini.DefaultSection {
+			target = newObjectBuilder(opts)
+		}
+
+		for _, key := range section.Keys() {
+			target.Set(key.Name(), key.Value())
+		}
+
+		if section.Name() != ini.DefaultSection {
+			result.Set(section.Name(), target.Object())
+		}
+	}
+
+	return result.Object(), nil
+}
+
+// iniEncoder writes an object as an INI file. Objects become sections, all
+// other values are written as keys outside of any section. Sections cannot
+// contain further objects or vectors.
+type iniEncoder struct {
+	out io.Writer
+}
+
+func (e *iniEncoder) Encode(data any) error {
+	normalized, err := normalize(data)
+	if err != nil {
+		return err
+	}
+
+	object, ok := normalized.(ordered.Object)
+	if !ok {
+		return fmt.Errorf("INI output requires an object, got %T", data)
+	}
+
+	// write "key = value" instead of aligning all "=" in a section
+	ini.PrettyFormat = false
+	ini.PrettyEqual = true
+
+	file := ini.Empty()
+
+	for _, key := range object.Keys() {
+		value, _ := object.Get(key)
+
+		if _, isSection := value.(ordered.Object); isSection {
+			continue
+		}
+
+		if err := setIniKey(file.Section(ini.DefaultSection), key, value); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range object.Keys() {
+		value, _ := object.Get(name)
+
+		sectionData, isSection := value.(ordered.Object)
+		if !isSection {
+			continue
+		}
+
+		section, err := file.NewSection(name)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range sectionData.Keys() {
+			value, _ := sectionData.Get(key)
+			if err := setIniKey(section, key, value); err != nil {
+				return fmt.Errorf("section %q: %w", name, err)
+			}
+		}
+	}
+
+	_, err = file.WriteTo(e.out)
+
+	return err
+}
+
+func setIniKey(section *ini.Section, key string, value any) error {
+	switch value.(type) {
+	case ordered.Object, []any:
+		return fmt.Errorf("key %q: INI values cannot be objects or vectors", key)
+	}
+
+	formatted, err := scalarString(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = section.NewKey(key, formatted)
+
+	return err
+}
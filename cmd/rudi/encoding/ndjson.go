@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package encoding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.xrstf.de/rudi/pkg/jsonpath"
+	"go.xrstf.de/rudi/pkg/ordered"
+)
+
+// decodeNdjson reads newline-delimited JSON into a vector with one item per
+// line.
+func decodeNdjson(input io.Reader, opts DecodeOptions) (any, error) {
+	decoder := json.NewDecoder(input)
+
+	items := []any{}
+	for {
+		var (
+			item any
+			err  error
+		)
+
+		if opts.PreserveOrder {
+			item, err = ordered.DecodeJSON(decoder)
+		} else {
+			err = decoder.Decode(&item)
+		}
+
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, fmt.Errorf("record %d: %w", len(items)+1, err)
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// ndjsonEncoder writes every item of a vector as a single line of JSON. All
+// other values are written as a single line.
+type ndjsonEncoder struct {
+	out  io.Writer
+	opts EncodeOptions
+}
+
+func (e *ndjsonEncoder) Encode(data any) error {
+	var items []any
+
+	switch asserted := data.(type) {
+	case []any:
+		items = asserted
+	case jsonpath.VectorSizer:
+		normalized, err := normalize(asserted)
+		if err != nil {
+			return err
+		}
+
+		items = normalized.([]any)
+	default:
+		items = []any{data}
+	}
+
+	opts := e.opts
+	opts.Compact = true
+
+	encoder := &jsonEncoder{out: e.out, opts: opts}
+	for _, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
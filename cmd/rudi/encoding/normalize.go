@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package encoding
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"go.xrstf.de/rudi/pkg/jsonpath"
+	"go.xrstf.de/rudi/pkg/ordered"
+)
+
+// normalize recursively turns all objects into ordered objects (sorting the
+// keys of unordered ones) and all vectors into slices, so that encoders only
+// have to deal with a single representation of each type.
+func normalize(value any) (any, error) {
+	switch asserted := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(asserted))
+		for key := range asserted {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		result := ordered.NewObject()
+		for _, key := range keys {
+			item, err := normalize(asserted[key])
+			if err != nil {
+				return nil, err
+			}
+
+			result.Set(key, item)
+		}
+
+		return result, nil
+
+	case []any:
+		result := make([]any, len(asserted))
+		for i, item := range asserted {
+			normalized, err := normalize(item)
+			if err != nil {
+				return nil, err
+			}
+
+			result[i] = normalized
+		}
+
+		return result, nil
+
+	// ordered objects, YAML nodes and wrapped Go values
+	case jsonpath.ObjectKeyLister:
+		result := ordered.NewObject()
+		for _, key := range asserted.ObjectKeys() {
+			item, err := asserted.GetObjectKey(key)
+			if err != nil {
+				return nil, err
+			}
+
+			normalized, err := normalize(item)
+			if err != nil {
+				return nil, err
+			}
+
+			result.Set(key, normalized)
+		}
+
+		return result, nil
+
+	case jsonpath.VectorSizer:
+		result := make([]any, asserted.VectorLength())
+		for i := range result {
+			item, err := asserted.GetVectorItem(i)
+			if err != nil {
+				return nil, err
+			}
+
+			if result[i], err = normalize(item); err != nil {
+				return nil, err
+			}
+		}
+
+		return result, nil
+
+	default:
+		return value, nil
+	}
+}
+
+// scalarString formats a scalar value for formats that only know strings.
+// Objects and vectors are encoded as JSON.
+func scalarString(value any) (string, error) {
+	switch asserted := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return asserted, nil
+	case float64:
+		return strconv.FormatFloat(asserted, 'f', -1, 64), nil
+	case ordered.Object, []any:
+		encoded, err := json.Marshal(asserted)
+		if err != nil {
+			return "", err
+		}
+
+		return string(encoded), nil
+	default:
+		return fmt.Sprint(asserted), nil
+	}
+}
+
+// objectBuilder is used by decoders to build objects, which are either maps or
+// ordered objects, depending on DecodeOptions.PreserveOrder.
+type objectBuilder interface {
+	Get(key string) (any, bool)
+	Set(key string, value any)
+	Object() any
+}
+
+func newObjectBuilder(opts DecodeOptions) objectBuilder {
+	if opts.PreserveOrder {
+		return &orderedBuilder{object: ordered.NewObject()}
+	}
+
+	return mapBuilder{}
+}
+
+type mapBuilder map[string]any
+
+func (b mapBuilder) Get(key string) (any, bool) {
+	value, ok := b[key]
+	return value, ok
+}
+
+func (b mapBuilder) Set(key string, value any) {
+	b[key] = value
+}
+
+func (b mapBuilder) Object() any {
+	return map[string]any(b)
+}
+
+type orderedBuilder struct {
+	object ordered.Object
+}
+
+func (b *orderedBuilder) Get(key string) (any, bool) {
+	return b.object.Get(key)
+}
+
+func (b *orderedBuilder) Set(key string, value any) {
+	b.object.Set(key, value)
+}
+
+func (b *orderedBuilder) Object() any {
+	return b.object
+}
+
+// toObjectBuilder returns a builder that continues to build the given object.
+func toObjectBuilder(object any) (objectBuilder, bool) {
+	switch asserted := object.(type) {
+	case map[string]any:
+		return mapBuilder(asserted), true
+	case ordered.Object:
+		return &orderedBuilder{object: asserted}, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package encoding
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"go.xrstf.de/rudi/pkg/ordered"
+
+	"github.com/magiconair/properties"
+)
+
+// decodeProperties reads a Java properties file into a flat object of strings.
+// ${...} references are not expanded.
+func decodeProperties(input io.Reader, opts DecodeOptions) (any, error) {
+	content, err := io.ReadAll(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	loader := properties.Loader{
+		Encoding:         properties.UTF8,
+		DisableExpansion: true,
+	}
+
+	props, err := loader.LoadBytes(content)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newObjectBuilder(opts)
+	for _, key := range props.Keys() {
+		value, _ := props.Get(key)
+		result.Set(key, value)
+	}
+
+	return result.Object(), nil
+}
+
+// propertiesEncoder writes an object as a Java properties file. Nested objects
+// and vectors are flattened, so {a {b [1]}} becomes "a.b.0 = 1".
+type propertiesEncoder struct {
+	out io.Writer
+}
+
+func (e *propertiesEncoder) Encode(data any) error {
+	normalized, err := normalize(data)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := normalized.(ordered.Object); !ok {
+		return fmt.Errorf("properties output requires an object, got %T", data)
+	}
+
+	props := properties.NewProperties()
+	props.DisableExpansion = true
+
+	if err := flattenProperties(props, "", normalized); err != nil {
+		return err
+	}
+
+	// the properties package neither escapes leading whitespace in values nor
+	// "=" in keys, so the file would not be read back the same way
+	for _, key := range props.Keys() {
+		value, _ := props.Get(key)
+
+		if _, err := fmt.Fprintf(e.out, "%s = %s\n", escapePropertyKey(key), escapePropertyValue(value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func escapePropertyKey(key string) string {
+	var out strings.Builder
+
+	for i, r := range key {
+		switch {
+		case r == ' ' || r == ':' || r == '=' || (i == 0 && (r == '#' || r == '!')):
+			out.WriteRune('\\')
+			out.WriteRune(r)
+		default:
+			out.WriteString(escapePropertyRune(r))
+		}
+	}
+
+	return out.String()
+}
+
+func escapePropertyValue(value string) string {
+	var out strings.Builder
+
+	// whitespace after the separator is skipped until the first escaped rune
+	if strings.HasPrefix(value, " ") {
+		out.WriteRune('\\')
+	}
+
+	for _, r := range value {
+		out.WriteString(escapePropertyRune(r))
+	}
+
+	return out.String()
+}
+
+func escapePropertyRune(r rune) string {
+	switch r {
+	case '\\':
+		return `\\`
+	case '\n':
+		return `\n`
+	case '\r':
+		return `\r`
+	case '\t':
+		return `\t`
+	case '\f':
+		return `\f`
+	default:
+		return string(r)
+	}
+}
+
+func flattenProperties(props *properties.Properties, prefix string, value any) error {
+	switch asserted := value.(type) {
+	case ordered.Object:
+		for _, key := range asserted.Keys() {
+			item, _ := asserted.Get(key)
+			if err := flattenProperties(props, joinPropertyKey(prefix, key), item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	case []any:
+		for i, item := range asserted {
+			if err := flattenProperties(props, joinPropertyKey(prefix, strconv.Itoa(i)), item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	default:
+		formatted, err := scalarString(value)
+		if err != nil {
+			return err
+		}
+
+		_, _, err = props.Set(prefix, formatted)
+
+		return err
+	}
+}
+
+func joinPropertyKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
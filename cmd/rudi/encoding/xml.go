@@ -0,0 +1,238 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package encoding
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.xrstf.de/rudi/pkg/ordered"
+)
+
+// XML documents are represented as an object with a single key, the root
+// element. Elements with neither attributes nor child elements become strings,
+// all others become objects, with attributes prefixed by "@", child elements
+// as regular keys (repeated elements become vectors) and text content as
+// "#text". Namespaces are not supported and only the local names are used.
+const (
+	xmlAttributePrefix = "@"
+	xmlTextKey         = "#text"
+)
+
+func decodeXml(input io.Reader, opts DecodeOptions) (any, error) {
+	decoder := xml.NewDecoder(input)
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, nil
+			}
+
+			return nil, err
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			element, err := decodeXmlElement(decoder, start, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			root := newObjectBuilder(opts)
+			root.Set(start.Name.Local, element)
+
+			return root.Object(), nil
+		}
+	}
+}
+
+func decodeXmlElement(decoder *xml.Decoder, start xml.StartElement, opts DecodeOptions) (any, error) {
+	element := newObjectBuilder(opts)
+	isLeaf := true
+
+	for _, attr := range start.Attr {
+		name := attr.Name.Local
+		if attr.Name.Space == "xmlns" {
+			name = "xmlns:" + name
+		}
+
+		element.Set(xmlAttributePrefix+name, attr.Value)
+		isLeaf = false
+	}
+
+	var text strings.Builder
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch asserted := token.(type) {
+		case xml.StartElement:
+			child, err := decodeXmlElement(decoder, asserted, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			name := asserted.Name.Local
+
+			// element values are never vectors, so an existing vector means
+			// that the element has been repeated before
+			if existing, exists := element.Get(name); exists {
+				if vector, ok := existing.([]any); ok {
+					element.Set(name, append(vector, child))
+				} else {
+					element.Set(name, []any{existing, child})
+				}
+			} else {
+				element.Set(name, child)
+			}
+
+			isLeaf = false
+
+		case xml.CharData:
+			text.Write(asserted)
+
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			if isLeaf {
+				return content, nil
+			}
+
+			if content != "" {
+				element.Set(xmlTextKey, content)
+			}
+
+			return element.Object(), nil
+		}
+	}
+}
+
+type xmlEncoder struct {
+	out  io.Writer
+	opts EncodeOptions
+}
+
+func (e *xmlEncoder) Encode(data any) error {
+	normalized, err := normalize(data)
+	if err != nil {
+		return err
+	}
+
+	root, ok := normalized.(ordered.Object)
+	if !ok || root.Len() != 1 {
+		return errors.New("XML output requires an object with exactly one key, the root element")
+	}
+
+	name := root.Keys()[0]
+	value, _ := root.Get(name)
+
+	if _, ok := value.([]any); ok {
+		return errors.New("the XML root element cannot be a vector")
+	}
+
+	if _, err := io.WriteString(e.out, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(e.out)
+	encoder.Indent("", e.opts.indentString())
+
+	if err := encodeXmlElement(encoder, name, value); err != nil {
+		return err
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(e.out)
+
+	return err
+}
+
+func encodeXmlElement(encoder *xml.Encoder, name string, value any) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	var (
+		text     string
+		children []string
+		object   ordered.Object
+	)
+
+	switch asserted := value.(type) {
+	case ordered.Object:
+		object = asserted
+
+		for _, key := range object.Keys() {
+			child, _ := object.Get(key)
+
+			switch {
+			case strings.HasPrefix(key, xmlAttributePrefix):
+				attrValue, err := scalarString(child)
+				if err != nil {
+					return err
+				}
+
+				start.Attr = append(start.Attr, xml.Attr{
+					Name:  xml.Name{Local: strings.TrimPrefix(key, xmlAttributePrefix)},
+					Value: attrValue,
+				})
+
+			case key == xmlTextKey:
+				content, err := scalarString(child)
+				if err != nil {
+					return err
+				}
+
+				text = content
+
+			default:
+				children = append(children, key)
+			}
+		}
+
+	case []any:
+		return fmt.Errorf("element %q: nested vectors cannot be represented in XML", name)
+
+	default:
+		content, err := scalarString(value)
+		if err != nil {
+			return err
+		}
+
+		text = content
+	}
+
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if text != "" {
+		if err := encoder.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range children {
+		child, _ := object.Get(key)
+
+		items, ok := child.([]any)
+		if !ok {
+			items = []any{child}
+		}
+
+		for _, item := range items {
+			if err := encodeXmlElement(encoder, key, item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return encoder.EncodeToken(start.End())
+}
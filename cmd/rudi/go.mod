@@ -8,9 +8,14 @@ require (
 	github.com/BurntSushi/toml v1.3.2
 	github.com/TylerBrock/colorjson v0.0.0-20200706003622-8a50f05110d2
 	github.com/chzyer/readline v1.5.1
+	github.com/fatih/color v1.16.0
+	github.com/go-ini/ini v1.67.0
+	github.com/hashicorp/hcl/v2 v2.19.1
+	github.com/magiconair/properties v1.8.7
 	github.com/muesli/termenv v0.15.2
 	github.com/spf13/pflag v1.0.5
 	github.com/titanous/json5 v1.0.0
+	github.com/zclconf/go-cty v1.14.4
 	go.xrstf.de/rudi v0.5.1
 	go.xrstf.de/rudi-contrib/semver v0.1.5
 	go.xrstf.de/rudi-contrib/set v0.1.1
@@ -20,17 +25,21 @@ require (
 )
 
 require (
+	github.com/agext/levenshtein v1.2.1 // indirect
+	github.com/apparentlymart/go-textseg/v15 v15.0.0 // indirect
 	github.com/aymanbagabas/go-osc52/v2 v2.0.1 // indirect
 	github.com/blang/semver/v4 v4.0.0 // indirect
-	github.com/fatih/color v1.16.0 // indirect
+	github.com/google/go-cmp v0.6.0 // indirect
 	github.com/google/uuid v1.5.0 // indirect
 	github.com/hokaccha/go-prettyjson v0.0.0-20211117102719-0474bc63780f // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/mattn/go-runewidth v0.0.14 // indirect
+	github.com/mitchellh/go-wordwrap v0.0.0-20150314170334-ad45545899c7 // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
 	golang.org/x/sys v0.14.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
 	k8s.io/apimachinery v0.29.0 // indirect
 )
 
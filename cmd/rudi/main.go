@@ -14,6 +14,7 @@ import (
 	"go.xrstf.de/rudi"
 	"go.xrstf.de/rudi/cmd/rudi/batteries"
 	"go.xrstf.de/rudi/cmd/rudi/cmd/console"
+	"go.xrstf.de/rudi/cmd/rudi/cmd/format"
 	"go.xrstf.de/rudi/cmd/rudi/cmd/help"
 	"go.xrstf.de/rudi/cmd/rudi/cmd/script"
 	"go.xrstf.de/rudi/cmd/rudi/options"
@@ -86,7 +87,28 @@ func printVersion() {
 	}
 }
 
+func printError(err error) {
+	parseErr := &rudi.ParseError{}
+	if errors.As(err, parseErr) {
+		fmt.Fprintln(os.Stderr, parseErr.Snippet())
+		fmt.Fprintln(os.Stderr, parseErr)
+	} else {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+}
+
 func main() {
+	// "rudi fmt" has its own set of flags and so must be handled before the
+	// global flags are parsed.
+	if len(os.Args) > 1 && os.Args[1] == "fmt" {
+		if err := format.Run(os.Args[2:]); err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
 	opts := options.NewDefaultOptions()
 
 	opts.AddFlags(pflag.CommandLine)
@@ -104,6 +126,12 @@ func main() {
 
 	args := pflag.Args()
 
+	// everything after "--" is not an input file, but an argument for the script
+	if dash := pflag.CommandLine.ArgsLenAtDash(); dash >= 0 {
+		opts.ScriptArgs = args[dash:]
+		args = args[:dash]
+	}
+
 	if opts.ShowHelp || (len(args) > 0 && args[0] == "help") {
 		if err := help.Run(&opts, args); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -151,14 +179,7 @@ func main() {
 	}
 
 	if err := script.Run(handler, &opts, baseProgram, args); err != nil {
-		parseErr := &rudi.ParseError{}
-		if errors.As(err, parseErr) {
-			fmt.Fprintln(os.Stderr, parseErr.Snippet())
-			fmt.Fprintln(os.Stderr, parseErr)
-		} else {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		}
-
+		printError(err)
 		os.Exit(1)
 	}
 }
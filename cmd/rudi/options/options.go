@@ -14,6 +14,7 @@ import (
 	"go.xrstf.de/rudi/cmd/rudi/encoding"
 	"go.xrstf.de/rudi/cmd/rudi/types"
 
+	"github.com/fatih/color"
 	"github.com/spf13/pflag"
 )
 
@@ -24,10 +25,32 @@ type Options struct {
 	LibraryFiles             []string
 	StdinFormat              types.Encoding
 	OutputFormat             types.Encoding
+	Indent                   int
+	Compact                  bool
+	SortKeys                 bool
+	EscapeHTML               bool
+	Color                    types.ColorMode
+	RawOutput                bool
 	PrintAst                 bool
+	Trace                    bool
+	Profile                  bool
+	ProfileOutput            string
+	PrintPatch               bool
+	PrintMergePatch          bool
+	PrintDiff                bool
+	Each                     bool
+	InPlace                  bool
+	Stream                   bool
+	Assert                   bool
+	AssertFormat             types.AssertFormat
+	PreserveYaml             bool
+	PreserveOrder            bool
+	BackupSuffix             string
 	ShowVersion              bool
 	Coalescing               types.Coalescing
 	EnableRudispaceFunctions bool
+	EnableEnvironment        bool
+	ScriptArgs               []string
 	ExtraVariables           map[string]any
 	extraVariableFlags       []string
 }
@@ -37,6 +60,10 @@ func NewDefaultOptions() Options {
 		Coalescing:     types.StrictCoalescing,
 		StdinFormat:    types.YamlEncoding,
 		OutputFormat:   types.JsonEncoding,
+		Indent:         2,
+		EscapeHTML:     true,
+		Color:          types.AutoColorMode,
+		AssertFormat:   types.TextAssertFormat,
 		ExtraVariables: map[string]any{},
 	}
 }
@@ -47,25 +74,159 @@ func (o *Options) AddFlags(fs *pflag.FlagSet) {
 	stdinFormatFlag := newEnumFlag(&o.StdinFormat, types.InputEncodings...)
 	outputFormatFlag := newEnumFlag(&o.OutputFormat, types.OutputEncodings...)
 	coalescingFlag := newEnumFlag(&o.Coalescing, types.AllCoalescings...)
+	colorFlag := newEnumFlag(&o.Color, types.AllColorModes...)
+	assertFormatFlag := newEnumFlag(&o.AssertFormat, types.AllAssertFormats...)
 
 	fs.BoolVarP(&o.Interactive, "interactive", "i", o.Interactive, "Start an interactive REPL to run expressions.")
 	fs.StringVarP(&o.ScriptFile, "script", "s", o.ScriptFile, "Load Rudi script from file instead of first argument (only in non-interactive mode).")
 	fs.StringArrayVarP(&o.LibraryFiles, "library", "l", o.LibraryFiles, "Load additional Rudi file(s) to be be evaluated before the script (can be given multiple times).")
 	fs.StringArrayVar(&o.extraVariableFlags, "var", o.extraVariableFlags, "Define additional global variables (can be given multiple times).")
 	stdinFormatFlag.Add(fs, "stdin-format", "f", "What data format is used for data provided on stdin")
-	outputFormatFlag.Add(fs, "output-format", "o", "What data format to use for outputting data")
-	fs.BoolVar(&o.EnableRudispaceFunctions, "enable-funcs", o.EnableRudispaceFunctions, "Enable the func! function to allow defining new functions in Rudi code.")
+	outputFormatFlag.Add(fs, "output-format", "o", "What data format to use for outputting data (auto uses the format of the first input)")
+	fs.IntVar(&o.Indent, "indent", o.Indent, "Number of spaces to indent JSON, YAML, TOML and XML output with.")
+	fs.BoolVar(&o.Compact, "compact", o.Compact, "Output JSON and XML on a single line and YAML in flow style.")
+	fs.BoolVar(&o.SortKeys, "sort-keys", o.SortKeys, "Sort object keys on output, even when using --preserve-order or --preserve-yaml.")
+	fs.BoolVar(&o.EscapeHTML, "escape-html", o.EscapeHTML, "Escape <, > and & in JSON strings.")
+	colorFlag.Add(fs, "color", "", "Whether to colorize JSON output")
+	fs.BoolVarP(&o.RawOutput, "raw-output", "r", o.RawOutput, "Output strings without quotes (like jq -r); all other values are encoded using the output format.")
+	fs.BoolVar(&o.PreserveYaml, "preserve-yaml", o.PreserveYaml, "Keep comments, key order and anchors of YAML inputs when outputting them as YAML again.")
+	fs.BoolVar(&o.PreserveOrder, "preserve-order", o.PreserveOrder, "Keep the key order of JSON and YAML objects, including objects created in the script, instead of sorting keys on output.")
+	fs.BoolVar(&o.EnableRudispaceFunctions, "enable-funcs", o.EnableRudispaceFunctions, "Enable the func! and import functions to allow defining new functions in Rudi code and importing them from other files.")
+	fs.BoolVar(&o.EnableEnvironment, "enable-env", o.EnableEnvironment, "Make all environment variables available as the $env object.")
 	coalescingFlag.Add(fs, "coalesce", "c", "Type conversion handling")
 	fs.BoolVarP(&o.ShowHelp, "help", "h", o.ShowHelp, "Show help and documentation.")
 	fs.BoolVarP(&o.ShowVersion, "version", "V", o.ShowVersion, "Show version and exit.")
 	fs.BoolVarP(&o.PrintAst, "debug-ast", "", o.PrintAst, "Output syntax tree of the parsed script in non-interactive mode.")
+	fs.BoolVar(&o.Trace, "trace", o.Trace, "Print the evaluation tree with all intermediate results to stderr.")
+	fs.BoolVar(&o.Profile, "profile", o.Profile, "Print the number of calls and time spent per function and statement to stderr in non-interactive mode.")
+	fs.StringVar(&o.ProfileOutput, "profile-output", o.ProfileOutput, "Write a pprof-compatible profile to the given file in non-interactive mode.")
+	fs.BoolVar(&o.Each, "each", o.Each, "Run the script once for every item in the first input (e.g. every document in a YAML stream) and output all results.")
+	fs.BoolVar(&o.Stream, "stream", o.Stream, "Run the script once for every value in an NDJSON stream (or every line, if the input format is raw) and output every result as soon as it is available.")
+	fs.BoolVarP(&o.InPlace, "in-place", "w", o.InPlace, "Run the script once for every input file and write the final document back to the file instead of printing the result.")
+	fs.StringVar(&o.BackupSuffix, "backup-suffix", o.BackupSuffix, "When using --in-place, keep the original files by renaming them with this suffix (e.g. \".bak\").")
+	fs.BoolVar(&o.Assert, "assert", o.Assert, "Run the script once for every input file and fail if its result is not true, reporting all failed files.")
+	assertFormatFlag.Add(fs, "assert-format", "", "How to report the results of --assert")
+	fs.BoolVar(&o.PrintPatch, "patch", o.PrintPatch, "Instead of the script's result, output a JSON Patch (RFC 6902) of the changes made to the document.")
+	fs.BoolVar(&o.PrintMergePatch, "merge-patch", o.PrintMergePatch, "Instead of the script's result, output a JSON Merge Patch (RFC 7386) of the changes made to the document.")
+	fs.BoolVar(&o.PrintDiff, "diff", o.PrintDiff, "Instead of the script's result, output a unified diff of the document before and after the script ran.")
 }
 
 func (o *Options) Validate() error {
+	if o.Indent < 1 {
+		return errors.New("--indent must be at least 1")
+	}
+
 	if o.Interactive && o.PrintAst {
 		return errors.New("cannot combine --interactive with --debug-ast")
 	}
 
+	if o.Interactive && (o.Profile || o.ProfileOutput != "") {
+		return errors.New("cannot combine --interactive with --profile or --profile-output")
+	}
+
+	// tracing itself takes time and would distort the profile
+	if o.Trace && (o.Profile || o.ProfileOutput != "") {
+		return errors.New("cannot combine --trace with --profile or --profile-output")
+	}
+
+	changeOutputs := 0
+	for _, flag := range []bool{o.PrintPatch, o.PrintMergePatch, o.PrintDiff} {
+		if flag {
+			changeOutputs++
+		}
+	}
+
+	if changeOutputs > 1 {
+		return errors.New("--patch, --merge-patch and --diff are mutually exclusive")
+	}
+
+	if o.Interactive && changeOutputs > 0 {
+		return errors.New("cannot combine --interactive with --patch, --merge-patch or --diff")
+	}
+
+	if o.Each {
+		if o.Interactive {
+			return errors.New("cannot combine --each with --interactive")
+		}
+
+		// documents are processed in parallel, which would garble traces and profiles
+		if o.Trace || o.Profile || o.ProfileOutput != "" {
+			return errors.New("cannot combine --each with --trace, --profile or --profile-output")
+		}
+
+		if changeOutputs > 0 {
+			return errors.New("cannot combine --each with --patch, --merge-patch or --diff")
+		}
+	}
+
+	if o.Stream {
+		if o.Interactive {
+			return errors.New("cannot combine --stream with --interactive")
+		}
+
+		if o.Each || o.InPlace {
+			return errors.New("cannot combine --stream with --each or --in-place")
+		}
+
+		if o.Profile || o.ProfileOutput != "" {
+			return errors.New("cannot combine --stream with --profile or --profile-output")
+		}
+
+		if changeOutputs > 0 {
+			return errors.New("cannot combine --stream with --patch, --merge-patch or --diff")
+		}
+
+		switch o.OutputFormat {
+		case types.JsonEncoding, types.NdjsonEncoding, types.RawEncoding, types.AutoEncoding:
+		default:
+			return fmt.Errorf("--stream only supports %s, %s or %s output", types.JsonEncoding, types.NdjsonEncoding, types.RawEncoding)
+		}
+	}
+
+	if o.AssertFormat != types.TextAssertFormat && !o.Assert {
+		return errors.New("--assert-format requires --assert")
+	}
+
+	if o.Assert {
+		if o.Interactive {
+			return errors.New("cannot combine --assert with --interactive")
+		}
+
+		if o.Each || o.InPlace || o.Stream {
+			return errors.New("cannot combine --assert with --each, --in-place or --stream")
+		}
+
+		if o.Profile || o.ProfileOutput != "" {
+			return errors.New("cannot combine --assert with --profile or --profile-output")
+		}
+
+		if changeOutputs > 0 {
+			return errors.New("cannot combine --assert with --patch, --merge-patch or --diff")
+		}
+	}
+
+	if o.BackupSuffix != "" && !o.InPlace {
+		return errors.New("--backup-suffix requires --in-place")
+	}
+
+	if o.InPlace {
+		if o.Interactive {
+			return errors.New("cannot combine --in-place with --interactive")
+		}
+
+		if o.Each {
+			return errors.New("cannot combine --in-place with --each")
+		}
+
+		if o.Profile || o.ProfileOutput != "" {
+			return errors.New("cannot combine --in-place with --profile or --profile-output")
+		}
+
+		if changeOutputs > 0 {
+			return errors.New("cannot combine --in-place with --patch, --merge-patch or --diff")
+		}
+	}
+
 	if err := o.parseExtraVariables(); err != nil {
 		return fmt.Errorf("invalid --var flags: %w", err)
 	}
@@ -77,7 +238,21 @@ func (o *Options) Validate() error {
 	return nil
 }
 
-var extraVariableFlagFormat = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_]*)=([a-z]+):([a-z]+):(.+)$`)
+// EncodeOptions returns the options to format data written to stdout with.
+func (o *Options) EncodeOptions() encoding.EncodeOptions {
+	return encoding.EncodeOptions{
+		Indent:     o.Indent,
+		Compact:    o.Compact,
+		SortKeys:   o.SortKeys,
+		EscapeHTML: o.EscapeHTML,
+		Color:      o.Color == types.AlwaysColorMode || (o.Color == types.AutoColorMode && !color.NoColor),
+		RawStrings: o.RawOutput,
+	}
+}
+
+// extraVariableFlagFormat matches "varname=encoding:source:data"; the data can
+// span multiple lines and encodings like json5 contain digits.
+var extraVariableFlagFormat = regexp.MustCompile(`(?s)^([a-zA-Z_][a-zA-Z0-9_]*)=([a-z0-9]+):([a-z]+):(.+)$`)
 
 func (o *Options) parseExtraVariables() error {
 	for i, flagValue := range o.extraVariableFlags {
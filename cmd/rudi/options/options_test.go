@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package options
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseExtraVariable(t *testing.T) {
+	testcases := []struct {
+		flag     string
+		name     string
+		expected any
+		invalid  bool
+	}{
+		{
+			flag:     `foo=json:string:{"a": "b"}`,
+			name:     "foo",
+			expected: map[string]any{"a": "b"},
+		},
+		{
+			flag:     `foo=json5:string:{a: 'b', // comment` + "\n" + `}`,
+			name:     "foo",
+			expected: map[string]any{"a": "b"},
+		},
+		{
+			flag:     "list=yaml:string:- a\n- b",
+			name:     "list",
+			expected: []any{"a", "b"},
+		},
+		{
+			flag:    `foo=json:string:`,
+			invalid: true,
+		},
+		{
+			flag:    `1foo=json:string:{}`,
+			invalid: true,
+		},
+		{
+			flag:    `foo=unknown:string:{}`,
+			invalid: true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.flag, func(t *testing.T) {
+			opts := NewDefaultOptions()
+
+			name, value, err := opts.parseExtraVariable(testcase.flag)
+			if err != nil {
+				if !testcase.invalid {
+					t.Fatalf("Failed to parse flag: %v", err)
+				}
+
+				return
+			}
+
+			if testcase.invalid {
+				t.Fatalf("Should not have been able to parse flag, but got %v.", value)
+			}
+
+			if name != testcase.name {
+				t.Fatalf("Expected variable %q, got %q.", testcase.name, name)
+			}
+
+			if !cmp.Equal(testcase.expected, value) {
+				t.Fatalf("Unexpected value:\n%s", cmp.Diff(testcase.expected, value))
+			}
+		})
+	}
+}
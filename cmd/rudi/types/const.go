@@ -29,6 +29,16 @@ const (
 	YamlEncoding          Encoding = "yaml"
 	YamlDocumentsEncoding Encoding = "yamldocs"
 	TomlEncoding          Encoding = "toml"
+	CsvEncoding           Encoding = "csv"
+	NdjsonEncoding        Encoding = "ndjson"
+	XmlEncoding           Encoding = "xml"
+	HclEncoding           Encoding = "hcl"
+	PropertiesEncoding    Encoding = "properties"
+	IniEncoding           Encoding = "ini"
+
+	// AutoEncoding is only valid for outputs and means to use the same
+	// format as the first input.
+	AutoEncoding Encoding = "auto"
 )
 
 var (
@@ -39,6 +49,12 @@ var (
 		YamlEncoding,
 		YamlDocumentsEncoding,
 		TomlEncoding,
+		CsvEncoding,
+		NdjsonEncoding,
+		XmlEncoding,
+		HclEncoding,
+		PropertiesEncoding,
+		IniEncoding,
 	}
 
 	InputEncodings = []Encoding{
@@ -48,6 +64,12 @@ var (
 		YamlEncoding,
 		YamlDocumentsEncoding,
 		TomlEncoding,
+		CsvEncoding,
+		NdjsonEncoding,
+		XmlEncoding,
+		HclEncoding,
+		PropertiesEncoding,
+		IniEncoding,
 	}
 
 	OutputEncodings = []Encoding{
@@ -56,6 +78,13 @@ var (
 		YamlEncoding,
 		YamlDocumentsEncoding,
 		TomlEncoding,
+		CsvEncoding,
+		NdjsonEncoding,
+		XmlEncoding,
+		HclEncoding,
+		PropertiesEncoding,
+		IniEncoding,
+		AutoEncoding,
 	}
 )
 
@@ -87,6 +116,62 @@ var AllCoalescings = []Coalescing{
 	HumaneCoalescing,
 }
 
+type ColorMode string
+
+func (c ColorMode) String() string {
+	return string(c)
+}
+
+func (c ColorMode) IsValid() bool {
+	for _, mode := range AllColorModes {
+		if mode == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+const (
+	AutoColorMode   ColorMode = "auto"
+	AlwaysColorMode ColorMode = "always"
+	NeverColorMode  ColorMode = "never"
+)
+
+var AllColorModes = []ColorMode{
+	AutoColorMode,
+	AlwaysColorMode,
+	NeverColorMode,
+}
+
+type AssertFormat string
+
+func (f AssertFormat) String() string {
+	return string(f)
+}
+
+func (f AssertFormat) IsValid() bool {
+	for _, format := range AllAssertFormats {
+		if format == f {
+			return true
+		}
+	}
+
+	return false
+}
+
+const (
+	TextAssertFormat  AssertFormat = "text"
+	TapAssertFormat   AssertFormat = "tap"
+	JUnitAssertFormat AssertFormat = "junit"
+)
+
+var AllAssertFormats = []AssertFormat{
+	TextAssertFormat,
+	TapAssertFormat,
+	JUnitAssertFormat,
+}
+
 type VariableSource string
 
 func (c VariableSource) String() string {
@@ -5,9 +5,13 @@ package util
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"go.xrstf.de/rudi"
 	"go.xrstf.de/rudi/cmd/rudi/batteries"
+	"go.xrstf.de/rudi/cmd/rudi/each"
 	"go.xrstf.de/rudi/cmd/rudi/options"
 	"go.xrstf.de/rudi/cmd/rudi/types"
 	"go.xrstf.de/rudi/pkg/coalescing"
@@ -29,6 +33,39 @@ func SetupRudiContext(opts *options.Options, fileNames []string, fileContents []
 		document, _ = rudi.NewDocument(nil)
 	}
 
+	return setupRudiContext(opts, document, newVariables(opts, fileNames, fileContents))
+}
+
+// SetupEachContext is like SetupRudiContext, but uses the item with the given
+// index in the first input as the document and makes the index available as
+// $index. This is used for --each.
+func SetupEachContext(opts *options.Options, fileNames []string, fileContents []any, index int) (rudi.Context, error) {
+	items, ok := fileContents[0].([]any)
+	if !ok {
+		return rudi.Context{}, fmt.Errorf("first input is %T, not a vector", fileContents[0])
+	}
+
+	document, err := rudi.NewDocument(items[index])
+	if err != nil {
+		return rudi.Context{}, fmt.Errorf("cannot use item %d as document: %w", index, err)
+	}
+
+	vars := newVariables(opts, fileNames, fileContents).Set("index", index)
+
+	return setupRudiContext(opts, document, vars)
+}
+
+// SetupStreamContext is like SetupRudiContext, but starts with an empty
+// document and an $index of 0. This is used for --stream, where the document
+// and $index are replaced for every value in the stream.
+func SetupStreamContext(opts *options.Options, fileNames []string) (rudi.Context, error) {
+	document, _ := rudi.NewDocument(nil)
+	vars := newVariables(opts, fileNames, nil).Set("index", 0)
+
+	return setupRudiContext(opts, document, vars)
+}
+
+func newVariables(opts *options.Options, fileNames []string, fileContents []any) rudi.Variables {
 	vars := rudi.NewVariables()
 	for k, v := range opts.ExtraVariables {
 		vars.Set(k, v)
@@ -37,8 +74,37 @@ func SetupRudiContext(opts *options.Options, fileNames []string, fileContents []
 	// system-defined variables come last, so they override anything user specified
 	vars.
 		Set("files", fileContents).
-		Set("filenames", fileNames)
+		Set("filenames", fileNames).
+		Set("args", scriptArguments(opts.ScriptArgs))
+
+	if opts.EnableEnvironment {
+		vars.Set("env", environment())
+	}
+
+	return vars
+}
 
+func scriptArguments(args []string) []any {
+	result := make([]any, len(args))
+	for i, arg := range args {
+		result[i] = arg
+	}
+
+	return result
+}
+
+func environment() map[string]any {
+	env := map[string]any{}
+
+	for _, pair := range os.Environ() {
+		name, value, _ := strings.Cut(pair, "=")
+		env[name] = value
+	}
+
+	return env
+}
+
+func setupRudiContext(opts *options.Options, document rudi.Document, vars rudi.Variables) (rudi.Context, error) {
 	var coalescer coalescing.Coalescer
 	switch opts.Coalescing {
 	case types.StrictCoalescing:
@@ -58,16 +124,41 @@ func SetupRudiContext(opts *options.Options, fileNames []string, fileContents []
 	}
 
 	// Only add rudispace function support when explicitly enabled, as defining functions at
-	// runtime can lead to non-terminating programs and resource exhaustion.
+	// runtime can lead to non-terminating programs and resource exhaustion. The same applies
+	// to importing libraries, which mostly consist of such functions.
 	if opts.EnableRudispaceFunctions {
 		funcs.Add(batteries.RudifuncModule.Functions)
+		funcs.Add(batteries.ImportsModule.Functions)
 	}
 
+	// Extended modules are available both with and without their module name
+	// as a namespace (e.g. "semver/semver" and "semver"), so that scripts can
+	// use the namespaced form to avoid ambiguity. Functions whose names collide
+	// with the standard library or another extended module are only available
+	// in their namespace.
 	for _, mod := range batteries.ExtendedModules {
-		funcs.Add(mod.Functions)
+		funcs.Add(batteries.UnqualifiedFunctions(mod))
+		funcs.AddNamespaced(mod.Name, mod.Functions)
+	}
+
+	if opts.Each || opts.Stream {
+		funcs.Add(each.Functions)
 	}
 
 	// No context set here, caller is expected to provide their own (the Rudi context is re-used
 	// in the console, but the Go context should not be, hence the separation).
-	return rudi.NewContext(interpreter.New(), nil, document, vars, funcs, coalescer)
+	ctx, err := rudi.NewContext(interpreter.New(), nil, document, vars, funcs, coalescer)
+	if err != nil {
+		return rudi.Context{}, err
+	}
+
+	// libraries are imported relative to the script file
+	baseDir := "."
+	if opts.ScriptFile != "" {
+		baseDir = filepath.Dir(opts.ScriptFile)
+	}
+
+	return ctx.
+		WithOrderedObjects(opts.PreserveOrder).
+		WithLoader(NewFileLoader(baseDir)), nil
 }
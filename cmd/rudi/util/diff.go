@@ -0,0 +1,206 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const diffContext = 3
+
+type diffLine struct {
+	// one of ' ', '-' or '+'
+	kind byte
+	text string
+}
+
+// WriteUnifiedDiff writes a line-based diff between two texts in the unified
+// diff format. Nothing is written if both texts are identical.
+func WriteUnifiedDiff(out io.Writer, fromName, toName, from, to string) error {
+	lines := diffLines(splitLines(from), splitLines(to))
+
+	changes := []int{}
+	for i, line := range lines {
+		if line.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(out, "--- %s\n+++ %s\n", fromName, toName); err != nil {
+		return err
+	}
+
+	// like GNU diff, changes with at most 2*diffContext unchanged lines
+	// between them are grouped into the same hunk
+	for len(changes) > 0 {
+		last := 0
+		for last+1 < len(changes) && changes[last+1]-changes[last]-1 <= 2*diffContext {
+			last++
+		}
+
+		start := changes[0] - diffContext
+		if start < 0 {
+			start = 0
+		}
+
+		end := changes[last] + diffContext + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		if err := writeHunk(out, lines, start, end); err != nil {
+			return err
+		}
+
+		changes = changes[last+1:]
+	}
+
+	return nil
+}
+
+func writeHunk(out io.Writer, lines []diffLine, start, end int) error {
+	fromLine, toLine := 1, 1
+	for _, line := range lines[:start] {
+		if line.kind != '+' {
+			fromLine++
+		}
+		if line.kind != '-' {
+			toLine++
+		}
+	}
+
+	fromCount, toCount := 0, 0
+	for _, line := range lines[start:end] {
+		if line.kind != '+' {
+			fromCount++
+		}
+		if line.kind != '-' {
+			toCount++
+		}
+	}
+
+	// like GNU diff, empty ranges refer to the line before them
+	if fromCount == 0 {
+		fromLine--
+	}
+	if toCount == 0 {
+		toLine--
+	}
+
+	if _, err := fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", fromLine, fromCount, toLine, toCount); err != nil {
+		return err
+	}
+
+	for _, line := range lines[start:end] {
+		if _, err := fmt.Fprintf(out, "%c%s\n", line.kind, line.text); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return []string{}
+	}
+
+	return strings.Split(text, "\n")
+}
+
+// diffLines computes the shortest edit script to turn a into b, using the
+// algorithm described in "An O(ND) Difference Algorithm and Its Variations"
+// by Eugene W. Myers. To walk back through the search, the state of every
+// step is recorded, but only for the diagonals that step could reach, so that
+// memory usage depends on the number of differences, not the input size.
+func diffLines(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+
+	v := make([]int, 2*offset+1)
+
+	// trace[d] holds v[-d..d] as it was before step d
+	trace := [][]int{}
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int{}, v[offset-d:offset+d+1]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// walk back through the recorded states to find the actual edits
+	reversed := []diffLine{}
+	x, y := n, m
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		// the first step always starts at the beginning of both inputs
+		prevX, prevY := 0, 0
+
+		if d > 0 {
+			state := trace[d]
+			at := func(k int) int { return state[k+d] }
+			k := x - y
+
+			var prevK int
+			if k == -d || (k != d && at(k-1) < at(k+1)) {
+				prevK = k + 1
+			} else {
+				prevK = k - 1
+			}
+
+			prevX = at(prevK)
+			prevY = prevX - prevK
+		}
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, diffLine{kind: ' ', text: a[x-1]})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, diffLine{kind: '+', text: b[y-1]})
+			} else {
+				reversed = append(reversed, diffLine{kind: '-', text: a[x-1]})
+			}
+
+			x, y = prevX, prevY
+		}
+	}
+
+	lines := make([]diffLine, len(reversed))
+	for i, line := range reversed {
+		lines[len(reversed)-1-i] = line
+	}
+
+	return lines
+}
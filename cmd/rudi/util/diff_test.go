@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package util
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func numberedLines(from, to int) string {
+	var b strings.Builder
+	for i := from; i <= to; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+
+	return b.String()
+}
+
+func TestWriteUnifiedDiff(t *testing.T) {
+	testcases := []struct {
+		name     string
+		from     string
+		to       string
+		expected string
+	}{
+		{
+			name:     "both empty",
+			from:     "",
+			to:       "",
+			expected: "",
+		},
+		{
+			name:     "identical",
+			from:     "a\nb\n",
+			to:       "a\nb\n",
+			expected: "",
+		},
+		{
+			name:     "from empty",
+			from:     "",
+			to:       "a\nb\n",
+			expected: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:     "to empty",
+			from:     "a\nb\n",
+			to:       "",
+			expected: "--- old\n+++ new\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:     "replaced line",
+			from:     "a\nb\nc\n",
+			to:       "a\nx\nc\n",
+			expected: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name:     "inserted line in the middle of a long file",
+			from:     numberedLines(1, 20),
+			to:       numberedLines(1, 10) + "new\n" + numberedLines(11, 20),
+			expected: "--- old\n+++ new\n@@ -8,6 +8,7 @@\n line 8\n line 9\n line 10\n+new\n line 11\n line 12\n line 13\n",
+		},
+		{
+			name: "distant changes produce separate hunks",
+			from: numberedLines(1, 20),
+			to:   "first\n" + numberedLines(2, 19) + "last\n",
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,4 +1,4 @@\n-line 1\n+first\n line 2\n line 3\n line 4\n" +
+				"@@ -17,4 +17,4 @@\n line 17\n line 18\n line 19\n-line 20\n+last\n",
+		},
+		{
+			name: "close changes are merged into one hunk",
+			from: numberedLines(1, 8),
+			to:   "first\n" + numberedLines(2, 7) + "last\n",
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,8 +1,8 @@\n-line 1\n+first\n line 2\n line 3\n line 4\n line 5\n line 6\n line 7\n-line 8\n+last\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out strings.Builder
+
+			if err := WriteUnifiedDiff(&out, "old", "new", tc.from, tc.to); err != nil {
+				t.Fatalf("Failed to write diff: %v", err)
+			}
+
+			if out.String() != tc.expected {
+				t.Fatalf("Expected\n%s\nbut got\n%s", tc.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestDiffLinesLargeInput(t *testing.T) {
+	from := strings.Split(strings.TrimSuffix(numberedLines(1, 5000), "\n"), "\n")
+	to := append(append(append([]string{}, from[:2500]...), "inserted"), from[2501:]...)
+
+	lines := diffLines(from, to)
+
+	changed := 0
+	for _, line := range lines {
+		if line.kind != ' ' {
+			changed++
+		}
+	}
+
+	if changed != 2 {
+		t.Fatalf("Expected 2 changed lines, got %d", changed)
+	}
+
+	if len(lines) != 5001 {
+		t.Fatalf("Expected 5001 lines in the edit script, got %d", len(lines))
+	}
+}
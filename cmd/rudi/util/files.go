@@ -4,8 +4,10 @@
 package util
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -52,17 +54,150 @@ func LoadFile(opts *options.Options, filename string) (any, error) {
 		return nil, errors.New("no filename provided")
 	}
 
+	f, format, err := OpenFile(opts, filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return decode(opts, f, format)
+}
+
+// LoadFileForWriting works like LoadFile, but also returns the format that
+// WriteFile has to use to write the data back into the file. YAML files with
+// more than one document are loaded as a vector of documents and have to be
+// written as multiple documents again, not as a single document holding a list.
+func LoadFileForWriting(opts *options.Options, filename string) (any, types.Encoding, error) {
+	if filename == "" {
+		return nil, "", errors.New("no filename provided")
+	}
+
+	f, format, err := OpenFile(opts, filename)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+
+	if format != types.YamlEncoding {
+		data, err := decode(opts, f, format)
+
+		return data, outputFormat(format), err
+	}
+
+	decoded, err := decode(opts, f, types.YamlDocumentsEncoding)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch documents := decoded.([]any); len(documents) {
+	case 0:
+		return nil, types.YamlEncoding, nil
+	case 1:
+		return documents[0], types.YamlEncoding, nil
+	default:
+		return documents, types.YamlDocumentsEncoding, nil
+	}
+}
+
+// OpenFile opens the given file (or stdin, if filename is "-") and returns the
+// format its content is encoded in.
+func OpenFile(opts *options.Options, filename string) (io.ReadCloser, types.Encoding, error) {
 	if filename == "-" {
-		return encoding.Decode(os.Stdin, opts.StdinFormat)
+		return io.NopCloser(os.Stdin), opts.StdinFormat, nil
 	}
 
 	f, err := os.Open(filename)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	return f, getFileFormat(filename), nil
+}
+
+func decode(opts *options.Options, input io.Reader, format types.Encoding) (any, error) {
+	return encoding.DecodeWithOptions(input, format, encoding.DecodeOptions{
+		PreserveYaml:  opts.PreserveYaml,
+		PreserveOrder: opts.PreserveOrder,
+	})
+}
+
+// OutputFormat returns the format to output data in. If the output format is
+// auto, the format of the first input is used (JSON if there are no inputs).
+func OutputFormat(opts *options.Options, filenames []string) types.Encoding {
+	if opts.OutputFormat != types.AutoEncoding {
+		return opts.OutputFormat
+	}
+
+	if len(filenames) == 0 {
+		return types.JsonEncoding
+	}
+
+	format := opts.StdinFormat
+	if filenames[0] != "-" {
+		format = getFileFormat(filenames[0])
+	}
+
+	// streams are always read as NDJSON, unless raw lines are requested
+	if opts.Stream && format != types.RawEncoding {
+		return types.NdjsonEncoding
+	}
+
+	return outputFormat(format)
+}
+
+// outputFormat returns the format to write data in that was read in the given
+// format.
+func outputFormat(format types.Encoding) types.Encoding {
+	// there is no JSON5 encoder, but JSON is valid JSON5
+	if format == types.Json5Encoding {
+		return types.JsonEncoding
+	}
+
+	return format
+}
+
+// WriteFile replaces the file's content with the given data, encoded in the
+// given format (as returned by LoadFileForWriting). If a backup suffix is
+// given, the original file is kept with the suffix appended to its name.
+func WriteFile(filename string, data any, format types.Encoding, backupSuffix string, opts encoding.EncodeOptions) error {
+	var buf bytes.Buffer
+	if err := encoding.EncodeWithOptions(data, format, &buf, opts); err != nil {
+		return fmt.Errorf("failed to encode data: %w", err)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that the original file is never
+	// left half-written
+	tmpFile, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(buf.Bytes()); err != nil {
+		tmpFile.Close()
+		return err
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmpFile.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	if backupSuffix != "" {
+		if err := os.Rename(filename, filename+backupSuffix); err != nil {
+			return fmt.Errorf("failed to create backup: %w", err)
+		}
 	}
-	defer f.Close()
 
-	return encoding.Decode(f, getFileFormat(filename))
+	return os.Rename(tmpFile.Name(), filename)
 }
 
 func getFileFormat(filename string) types.Encoding {
@@ -73,6 +208,18 @@ func getFileFormat(filename string) types.Encoding {
 		return types.Json5Encoding
 	case ".tml", ".toml":
 		return types.TomlEncoding
+	case ".csv":
+		return types.CsvEncoding
+	case ".ndjson", ".jsonl":
+		return types.NdjsonEncoding
+	case ".xml":
+		return types.XmlEncoding
+	case ".hcl", ".tf":
+		return types.HclEncoding
+	case ".properties":
+		return types.PropertiesEncoding
+	case ".ini":
+		return types.IniEncoding
 	default:
 		return types.YamlEncoding
 	}
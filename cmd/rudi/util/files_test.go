@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.xrstf.de/rudi/cmd/rudi/options"
+	"go.xrstf.de/rudi/cmd/rudi/types"
+)
+
+func TestWriteFileRoundtrip(t *testing.T) {
+	testcases := []struct {
+		name     string
+		filename string
+		content  string
+		format   types.Encoding
+	}{
+		{
+			name:     "single YAML document",
+			filename: "data.yaml",
+			content:  "a: 1\n",
+			format:   types.YamlEncoding,
+		},
+		{
+			name:     "YAML document holding a list",
+			filename: "data.yaml",
+			content:  "- a: 1\n- b: 2\n",
+			format:   types.YamlEncoding,
+		},
+		{
+			name:     "multiple YAML documents",
+			filename: "data.yaml",
+			content:  "a: 1\n---\nb: 2\n",
+			format:   types.YamlDocumentsEncoding,
+		},
+		{
+			name:     "JSON file",
+			filename: "data.json",
+			content:  "{\n  \"a\": 1\n}\n",
+			format:   types.JsonEncoding,
+		},
+	}
+
+	for _, testcase := range testcases {
+		for _, preserveYaml := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s (preserve YAML: %v)", testcase.name, preserveYaml), func(t *testing.T) {
+				filename := filepath.Join(t.TempDir(), testcase.filename)
+
+				if err := os.WriteFile(filename, []byte(testcase.content), 0644); err != nil {
+					t.Fatalf("Failed to create file: %v", err)
+				}
+
+				opts := options.NewDefaultOptions()
+				opts.PreserveYaml = preserveYaml
+				opts.Color = types.NeverColorMode
+
+				data, format, err := LoadFileForWriting(&opts, filename)
+				if err != nil {
+					t.Fatalf("Failed to load file: %v", err)
+				}
+
+				if format != testcase.format {
+					t.Fatalf("Expected format %q, got %q.", testcase.format, format)
+				}
+
+				if err := WriteFile(filename, data, format, "", opts.EncodeOptions()); err != nil {
+					t.Fatalf("Failed to write file: %v", err)
+				}
+
+				written, err := os.ReadFile(filename)
+				if err != nil {
+					t.Fatalf("Failed to read file: %v", err)
+				}
+
+				if string(written) != testcase.content {
+					t.Fatalf("Expected file to contain\n\n%s\nbut got\n\n%s", testcase.content, string(written))
+				}
+			})
+		}
+	}
+}
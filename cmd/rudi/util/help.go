@@ -28,7 +28,6 @@ func RenderHelpTopic(selectedTopic string, indent int) (string, error) {
 	modules := []rudidocs.Module{}
 	modules = append(modules, batteries.SafeBuiltInModules...)
 	modules = append(modules, batteries.UnsafeBuiltInModules...)
-	modules = append(modules, batteries.ExtendedModules...)
 
 	for _, mod := range modules {
 		for funcName := range mod.Functions {
@@ -38,5 +37,20 @@ func RenderHelpTopic(selectedTopic string, indent int) (string, error) {
 		}
 	}
 
+	// Extended modules are also available in their namespace.
+	for _, mod := range batteries.ExtendedModules {
+		unqualified := batteries.UnqualifiedFunctions(mod)
+
+		for funcName := range mod.Functions {
+			if strings.EqualFold(mod.Name+"/"+funcName, selectedTopic) {
+				return docs.RenderFunction(funcName, nil)
+			}
+
+			if _, ok := unqualified.Get(funcName); ok && strings.EqualFold(funcName, selectedTopic) {
+				return docs.RenderFunction(funcName, nil)
+			}
+		}
+	}
+
 	return "", fmt.Errorf("no help available for %q", selectedTopic)
 }
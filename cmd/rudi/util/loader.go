@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package util
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"go.xrstf.de/rudi"
+)
+
+type fileLoader struct {
+	baseDir string
+}
+
+// NewFileLoader returns a loader for the import function that reads libraries
+// from the local filesystem. Relative paths are resolved relative to baseDir.
+func NewFileLoader(baseDir string) rudi.Loader {
+	return fileLoader{baseDir: baseDir}
+}
+
+func (l fileLoader) Load(_ context.Context, path string) (string, string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(l.baseDir, path)
+	}
+
+	// the absolute path is the best identifier to detect import cycles
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", err
+	}
+
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	return absPath, string(content), nil
+}
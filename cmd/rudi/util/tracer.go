@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+)
+
+const maxTracedValueLength = 60
+
+type treeNode struct {
+	event types.TraceEvent
+	// hidden nodes are not printed and do not increase the indentation
+	hidden bool
+	// printed is set once the node's expression has been printed because
+	// one of its children needed to be printed
+	printed bool
+}
+
+// TreePrinter is a tracer that prints an indented evaluation tree.
+type TreePrinter struct {
+	out   io.Writer
+	stack []*treeNode
+}
+
+var _ types.Tracer = &TreePrinter{}
+
+func NewTreePrinter(out io.Writer) *TreePrinter {
+	return &TreePrinter{
+		out:   out,
+		stack: []*treeNode{},
+	}
+}
+
+func (p *TreePrinter) Enter(_ types.Context, event types.TraceEvent) error {
+	node := &treeNode{event: event}
+
+	switch event.Kind {
+	case types.StatementEvent:
+		// Statements are already represented by their expression.
+		node.hidden = true
+	case types.CallEvent:
+		// Function calls as part of a tuple are already represented by the tuple.
+		if len(p.stack) > 0 && p.stack[len(p.stack)-1].event.Kind == types.TupleEvent {
+			node.hidden = true
+		}
+	}
+
+	if !node.hidden {
+		p.flush()
+	}
+
+	p.stack = append(p.stack, node)
+
+	return nil
+}
+
+func (p *TreePrinter) Exit(_ types.Context, event types.TraceEvent) {
+	node := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+
+	if node.hidden {
+		return
+	}
+
+	indent := p.indent()
+	outcome := FormatTraceOutcome(event)
+
+	if node.printed {
+		fmt.Fprintf(p.out, "%s└ %s\n", indent, outcome)
+	} else {
+		fmt.Fprintf(p.out, "%s%s %s\n", indent, FormatTraceExpression(event), outcome)
+	}
+}
+
+// flush prints all pending expressions on the stack, so that a child node can
+// be printed below them.
+func (p *TreePrinter) flush() {
+	depth := 0
+
+	for _, node := range p.stack {
+		if node.hidden {
+			continue
+		}
+
+		if !node.printed {
+			fmt.Fprintf(p.out, "%s%s\n", strings.Repeat("  ", depth), FormatTraceExpression(node.event))
+			node.printed = true
+		}
+
+		depth++
+	}
+}
+
+func (p *TreePrinter) indent() string {
+	depth := 0
+
+	for _, node := range p.stack {
+		if !node.hidden {
+			depth++
+		}
+	}
+
+	return strings.Repeat("  ", depth)
+}
+
+// FormatTraceExpression returns a short description of what is being evaluated.
+func FormatTraceExpression(event types.TraceEvent) string {
+	if event.Kind != types.CallEvent {
+		return event.Expression.String()
+	}
+
+	args := make([]string, len(event.Arguments))
+	for i, arg := range event.Arguments {
+		// functions like map pass already evaluated values as shims
+		if shim, ok := arg.(ast.Shim); ok {
+			args[i] = FormatTraceValue(shim.Value)
+		} else {
+			args[i] = arg.String()
+		}
+	}
+
+	return fmt.Sprintf("call %s(%s)", event.Expression.String(), strings.Join(args, ", "))
+}
+
+// FormatTraceOutcome returns the result or error of an evaluation, including
+// its duration.
+func FormatTraceOutcome(event types.TraceEvent) string {
+	duration := event.Duration.Round(time.Microsecond)
+
+	if event.Error != nil {
+		return fmt.Sprintf("✗ %v [%v]", event.Error, duration)
+	}
+
+	return fmt.Sprintf("⇒ %s [%v]", FormatTraceValue(event.Result), duration)
+}
+
+// FormatTraceValue returns a short, single-line representation of a value.
+func FormatTraceValue(value any) string {
+	var formatted string
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		formatted = fmt.Sprintf("%v", value)
+	} else {
+		formatted = string(encoded)
+	}
+
+	if runes := []rune(formatted); len(runes) > maxTracedValueLength {
+		formatted = string(runes[:maxTracedValueLength-1]) + "…"
+	}
+
+	return formatted
+}
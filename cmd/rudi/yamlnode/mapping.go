@@ -0,0 +1,277 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package yamlnode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.xrstf.de/rudi/pkg/coalescing"
+	"go.xrstf.de/rudi/pkg/jsonpath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Mapping is a YAML mapping. Keys merged from other mappings using "<<" can
+// be read and overwritten, but not deleted.
+type Mapping struct {
+	node *yaml.Node
+	// document is only set for the root value of a document.
+	document *yaml.Node
+	// aliased is true if the mapping was reached via an alias.
+	aliased bool
+}
+
+var (
+	_ jsonpath.ObjectKeyLister             = Mapping{}
+	_ jsonpath.ObjectWriter                = Mapping{}
+	_ jsonpath.ObjectKeyDeleter            = Mapping{}
+	_ jsonpath.ShallowCopier               = Mapping{}
+	_ coalescing.CustomNullCoalescer       = Mapping{}
+	_ coalescing.CustomBoolCoalescer       = Mapping{}
+	_ coalescing.CustomObjectCoalescer     = Mapping{}
+	_ json.Marshaler                       = Mapping{}
+	_ yaml.Marshaler                       = Mapping{}
+	_ interface{ DeepCopy() (any, error) } = Mapping{}
+)
+
+// find returns the position of the key node in the mapping's content.
+func (m Mapping) find(name string) int {
+	for i := 0; i+1 < len(m.node.Content); i += 2 {
+		key := m.node.Content[i]
+		if !isMergeKey(key) && key.Value == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// merged returns all mappings merged into this one, in order of precedence.
+func (m Mapping) merged() []Mapping {
+	result := []Mapping{}
+
+	for i := 0; i+1 < len(m.node.Content); i += 2 {
+		if !isMergeKey(m.node.Content[i]) {
+			continue
+		}
+
+		value := resolve(m.node.Content[i+1])
+
+		switch value.Kind {
+		case yaml.MappingNode:
+			result = append(result, Mapping{node: value, aliased: true})
+		case yaml.SequenceNode:
+			for _, item := range value.Content {
+				if item = resolve(item); item.Kind == yaml.MappingNode {
+					result = append(result, Mapping{node: item, aliased: true})
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+func resolve(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	return node
+}
+
+func (m Mapping) GetObjectKey(name string) (any, error) {
+	if index := m.find(name); index >= 0 {
+		return wrap(m.node.Content[index+1])
+	}
+
+	for _, source := range m.merged() {
+		value, err := source.GetObjectKey(name)
+		if !errors.Is(err, jsonpath.ErrNotFound) {
+			return value, err
+		}
+	}
+
+	return nil, fmt.Errorf("no such key %q: %w", name, jsonpath.ErrNotFound)
+}
+
+func (m Mapping) SetObjectKey(name string, value any) (any, error) {
+	if index := m.find(name); index >= 0 {
+		node, err := toNode(value, m.node.Content[index+1])
+		if err != nil {
+			return nil, fmt.Errorf("cannot set key %q: %w", name, err)
+		}
+
+		m.node.Content[index+1] = node
+
+		return m, nil
+	}
+
+	node, err := toNode(value, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot set key %q: %w", name, err)
+	}
+
+	key := &yaml.Node{
+		Kind:  yaml.ScalarNode,
+		Tag:   "!!str",
+		Value: name,
+	}
+
+	m.node.Content = append(m.node.Content, key, node)
+
+	return m, nil
+}
+
+func (m Mapping) DeleteObjectKey(name string) (any, error) {
+	index := m.find(name)
+	if index < 0 {
+		for _, source := range m.merged() {
+			if _, err := source.GetObjectKey(name); err == nil {
+				return nil, fmt.Errorf("cannot delete key %q, because it is merged from another mapping", name)
+			}
+		}
+
+		return m, nil
+	}
+
+	content := make([]*yaml.Node, 0, len(m.node.Content)-2)
+	content = append(content, m.node.Content[:index]...)
+	content = append(content, m.node.Content[index+2:]...)
+
+	m.node.Content = content
+
+	return m, nil
+}
+
+// ObjectKeys returns all keys in the order they appear in the document,
+// followed by all keys merged from other mappings.
+func (m Mapping) ObjectKeys() []string {
+	keys := []string{}
+	seen := map[string]struct{}{}
+
+	for i := 0; i+1 < len(m.node.Content); i += 2 {
+		key := m.node.Content[i]
+		if isMergeKey(key) {
+			continue
+		}
+
+		if _, exists := seen[key.Value]; !exists {
+			keys = append(keys, key.Value)
+			seen[key.Value] = struct{}{}
+		}
+	}
+
+	for _, source := range m.merged() {
+		for _, key := range source.ObjectKeys() {
+			if _, exists := seen[key]; !exists {
+				keys = append(keys, key)
+				seen[key] = struct{}{}
+			}
+		}
+	}
+
+	return keys
+}
+
+// toMap returns a shallow map of all wrapped values.
+func (m Mapping) toMap() (map[string]any, error) {
+	result := map[string]any{}
+	for _, key := range m.ObjectKeys() {
+		value, err := m.GetObjectKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+func (m Mapping) CoalesceToNull(c coalescing.Coalescer) (bool, error) {
+	object, err := m.toMap()
+	if err != nil {
+		return false, err
+	}
+
+	return c.ToNull(object)
+}
+
+func (m Mapping) CoalesceToBool(c coalescing.Coalescer) (bool, error) {
+	object, err := m.toMap()
+	if err != nil {
+		return false, err
+	}
+
+	return c.ToBool(object)
+}
+
+func (m Mapping) CoalesceToObject(_ coalescing.Coalescer) (map[string]any, error) {
+	return m.toMap()
+}
+
+func (m Mapping) ShallowCopy() (any, error) {
+	node := shallowCopyNode(m.node)
+
+	// an anchor must only be defined once
+	if m.aliased {
+		node.Anchor = ""
+	}
+
+	return Mapping{node: node, document: m.document}, nil
+}
+
+func (m Mapping) DeepCopy() (any, error) {
+	return Mapping{node: deepCopyNode(m.node), document: m.document, aliased: m.aliased}, nil
+}
+
+// MarshalJSON encodes the mapping, keeping the order of its keys.
+func (m Mapping) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, key := range m.ObjectKeys() {
+		value, err := m.GetObjectKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		encodedValue, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		buf.Write(encodedValue)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func (m Mapping) MarshalYAML() (any, error) {
+	return restoreAnchors(m.node), nil
+}
+
+// YAMLDocument returns the mapping as a document node, including the
+// comments at the start and end of the original document.
+func (m Mapping) YAMLDocument() *yaml.Node {
+	return documentNode(m.document, m.node)
+}
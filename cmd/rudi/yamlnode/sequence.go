@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package yamlnode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.xrstf.de/rudi/pkg/coalescing"
+	"go.xrstf.de/rudi/pkg/jsonpath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sequence is a YAML sequence. Like for native vectors, negative indices
+// count from the end.
+type Sequence struct {
+	node *yaml.Node
+	// document is only set for the root value of a document.
+	document *yaml.Node
+	// aliased is true if the sequence was reached via an alias.
+	aliased bool
+}
+
+var (
+	_ jsonpath.VectorSizer                 = Sequence{}
+	_ jsonpath.VectorWriter                = Sequence{}
+	_ jsonpath.VectorItemDeleter           = Sequence{}
+	_ jsonpath.ShallowCopier               = Sequence{}
+	_ coalescing.CustomNullCoalescer       = Sequence{}
+	_ coalescing.CustomBoolCoalescer       = Sequence{}
+	_ coalescing.CustomVectorCoalescer     = Sequence{}
+	_ json.Marshaler                       = Sequence{}
+	_ yaml.Marshaler                       = Sequence{}
+	_ interface{ DeepCopy() (any, error) } = Sequence{}
+)
+
+func (s Sequence) index(index int) (int, error) {
+	length := len(s.node.Content)
+	if index < 0 {
+		index += length
+	}
+
+	if index < 0 || index >= length {
+		return 0, fmt.Errorf("index %d out of bounds: %w", index, jsonpath.ErrNotFound)
+	}
+
+	return index, nil
+}
+
+func (s Sequence) GetVectorItem(index int) (any, error) {
+	index, err := s.index(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrap(s.node.Content[index])
+}
+
+func (s Sequence) SetVectorItem(index int, value any) (any, error) {
+	index, err := s.index(index)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := toNode(value, s.node.Content[index])
+	if err != nil {
+		return nil, fmt.Errorf("cannot set item %d: %w", index, err)
+	}
+
+	s.node.Content[index] = node
+
+	return s, nil
+}
+
+func (s Sequence) DeleteVectorItem(index int) (any, error) {
+	index, err := s.index(index)
+	if err != nil {
+		return nil, err
+	}
+
+	content := make([]*yaml.Node, 0, len(s.node.Content)-1)
+	content = append(content, s.node.Content[:index]...)
+	content = append(content, s.node.Content[index+1:]...)
+
+	s.node.Content = content
+
+	return s, nil
+}
+
+func (s Sequence) VectorLength() int {
+	return len(s.node.Content)
+}
+
+// toSlice returns a shallow vector of all wrapped items.
+func (s Sequence) toSlice() ([]any, error) {
+	result := make([]any, len(s.node.Content))
+	for i, item := range s.node.Content {
+		value, err := wrap(item)
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = value
+	}
+
+	return result, nil
+}
+
+func (s Sequence) CoalesceToNull(c coalescing.Coalescer) (bool, error) {
+	vector, err := s.toSlice()
+	if err != nil {
+		return false, err
+	}
+
+	return c.ToNull(vector)
+}
+
+func (s Sequence) CoalesceToBool(c coalescing.Coalescer) (bool, error) {
+	vector, err := s.toSlice()
+	if err != nil {
+		return false, err
+	}
+
+	return c.ToBool(vector)
+}
+
+func (s Sequence) CoalesceToVector(_ coalescing.Coalescer) ([]any, error) {
+	return s.toSlice()
+}
+
+func (s Sequence) ShallowCopy() (any, error) {
+	node := shallowCopyNode(s.node)
+
+	// an anchor must only be defined once
+	if s.aliased {
+		node.Anchor = ""
+	}
+
+	return Sequence{node: node, document: s.document}, nil
+}
+
+func (s Sequence) DeepCopy() (any, error) {
+	return Sequence{node: deepCopyNode(s.node), document: s.document, aliased: s.aliased}, nil
+}
+
+func (s Sequence) MarshalJSON() ([]byte, error) {
+	vector, err := s.toSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(vector)
+}
+
+func (s Sequence) MarshalYAML() (any, error) {
+	return restoreAnchors(s.node), nil
+}
+
+// YAMLDocument returns the sequence as a document node, including the
+// comments at the start and end of the original document.
+func (s Sequence) YAMLDocument() *yaml.Node {
+	return documentNode(s.document, s.node)
+}
@@ -0,0 +1,436 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+// Package yamlnode makes YAML documents available to Rudi without losing
+// their comments, key order, anchors or formatting. Mappings and sequences
+// stay backed by their yaml.Node and implement the jsonpath interfaces, so
+// that path expressions can read and update them, while all untouched parts
+// of a document are written back exactly as they were parsed.
+package yamlnode
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"go.xrstf.de/rudi/pkg/ordered"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	mergeTag = "!!merge"
+	mergeKey = "<<"
+)
+
+// Decode reads all YAML documents from the input.
+func Decode(input io.Reader) ([]any, error) {
+	decoder := yaml.NewDecoder(input)
+
+	documents := []any{}
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, err
+		}
+
+		value, err := wrapDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+
+		documents = append(documents, value)
+	}
+
+	return documents, nil
+}
+
+func wrapDocument(doc *yaml.Node) (any, error) {
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	clearMergeTags(doc)
+
+	value, err := wrap(doc.Content[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// remember the document node to keep comments at the start and the end
+	// of the document
+	switch asserted := value.(type) {
+	case Mapping:
+		asserted.document = doc
+		return asserted, nil
+	case Sequence:
+		asserted.document = doc
+		return asserted, nil
+	default:
+		return value, nil
+	}
+}
+
+// clearMergeTags removes the explicit tag from all merge keys, as yaml.v3
+// would otherwise output them as "!!merge <<".
+func clearMergeTags(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i < len(node.Content); i += 2 {
+			if isMergeKey(node.Content[i]) {
+				node.Content[i].Tag = ""
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		clearMergeTags(child)
+	}
+}
+
+func isMergeKey(key *yaml.Node) bool {
+	return key.Value == mergeKey && (key.Tag == mergeTag || key.Tag == "")
+}
+
+// wrap turns mappings and sequences into Mapping and Sequence values and
+// scalars into regular Go values.
+func wrap(node *yaml.Node) (any, error) {
+	aliased := false
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+		aliased = true
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		return Mapping{node: node, aliased: aliased}, nil
+	case yaml.SequenceNode:
+		return Sequence{node: node, aliased: aliased}, nil
+	case yaml.ScalarNode:
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+
+		return value, nil
+	default:
+		return nil, fmt.Errorf("line %d: unexpected YAML node kind %d", node.Line, node.Kind)
+	}
+}
+
+// toNode turns a value into a YAML node. If the value replaces an existing
+// node, its comments and, for scalars of the same type, its style are kept.
+func toNode(value any, previous *yaml.Node) (*yaml.Node, error) {
+	var node *yaml.Node
+
+	switch asserted := value.(type) {
+	case Mapping:
+		node = asserted.node
+	case Sequence:
+		node = asserted.node
+	case ordered.Object:
+		var err error
+		if node, err = orderedNode(asserted); err != nil {
+			return nil, err
+		}
+	default:
+		marshalable, err := Marshalable(value)
+		if err != nil {
+			return nil, err
+		}
+
+		node = &yaml.Node{}
+		if err := node.Encode(marshalable); err != nil {
+			return nil, err
+		}
+	}
+
+	if previous == nil || previous.Kind == yaml.AliasNode || node == previous {
+		return node, nil
+	}
+
+	// keep the anchor, so that all aliases refer to the new value
+	if previous.Anchor != "" && node.Anchor != previous.Anchor {
+		node = shallowCopyNode(node)
+		node.Anchor = previous.Anchor
+	}
+
+	if previous.HeadComment != "" || previous.LineComment != "" || previous.FootComment != "" {
+		// do not modify nodes that might be shared with other documents
+		node = shallowCopyNode(node)
+
+		if node.HeadComment == "" {
+			node.HeadComment = previous.HeadComment
+		}
+
+		if node.LineComment == "" {
+			node.LineComment = previous.LineComment
+		}
+
+		if node.FootComment == "" {
+			node.FootComment = previous.FootComment
+		}
+	}
+
+	if node.Kind == yaml.ScalarNode && previous.Kind == yaml.ScalarNode && node.Tag == previous.Tag && node.Style != previous.Style {
+		node = shallowCopyNode(node)
+		node.Style = previous.Style
+	}
+
+	return node, nil
+}
+
+// orderedNode turns an ordered object into a mapping node with the same key
+// order.
+func orderedNode(obj ordered.Object) (*yaml.Node, error) {
+	node := &yaml.Node{
+		Kind:    yaml.MappingNode,
+		Tag:     "!!map",
+		Content: make([]*yaml.Node, 0, 2*obj.Len()),
+	}
+
+	for _, key := range obj.Keys() {
+		value, _ := obj.Get(key)
+
+		valueNode, err := toNode(value, nil)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+
+		keyNode := &yaml.Node{}
+		if err := keyNode.Encode(key); err != nil {
+			return nil, err
+		}
+
+		node.Content = append(node.Content, keyNode, valueNode)
+	}
+
+	return node, nil
+}
+
+// Marshalable recursively replaces all ordered objects with YAML nodes, so that
+// the YAML encoder keeps their key order.
+func Marshalable(value any) (any, error) {
+	switch asserted := value.(type) {
+	case ordered.Object:
+		return orderedNode(asserted)
+
+	case map[string]any:
+		result := make(map[string]any, len(asserted))
+		for key, item := range asserted {
+			marshalable, err := Marshalable(item)
+			if err != nil {
+				return nil, err
+			}
+
+			result[key] = marshalable
+		}
+
+		return result, nil
+
+	case []any:
+		result := make([]any, len(asserted))
+		for i, item := range asserted {
+			marshalable, err := Marshalable(item)
+			if err != nil {
+				return nil, err
+			}
+
+			result[i] = marshalable
+		}
+
+		return result, nil
+
+	default:
+		return value, nil
+	}
+}
+
+// ToOrdered recursively converts all Mapping and Sequence values into ordered
+// objects and regular slices, dropping comments and anchors but keeping the
+// key order.
+func ToOrdered(value any) (any, error) {
+	switch asserted := value.(type) {
+	case Mapping:
+		result := ordered.NewObject()
+		for _, key := range asserted.ObjectKeys() {
+			item, err := asserted.GetObjectKey(key)
+			if err != nil {
+				return nil, err
+			}
+
+			converted, err := ToOrdered(item)
+			if err != nil {
+				return nil, err
+			}
+
+			result.Set(key, converted)
+		}
+
+		return result, nil
+
+	case Sequence:
+		vec, err := asserted.toSlice()
+		if err != nil {
+			return nil, err
+		}
+
+		return ToOrdered(vec)
+
+	case []any:
+		result := make([]any, len(asserted))
+		for i, item := range asserted {
+			converted, err := ToOrdered(item)
+			if err != nil {
+				return nil, err
+			}
+
+			result[i] = converted
+		}
+
+		return result, nil
+
+	default:
+		return value, nil
+	}
+}
+
+func shallowCopyNode(node *yaml.Node) *yaml.Node {
+	clone := *node
+	clone.Content = append([]*yaml.Node{}, node.Content...)
+
+	return &clone
+}
+
+// documentNode returns a copy of the original document node with the given
+// content.
+func documentNode(document *yaml.Node, content *yaml.Node) *yaml.Node {
+	content = restoreAnchors(content)
+
+	if document == nil {
+		return content
+	}
+
+	clone := *document
+	clone.Content = []*yaml.Node{content}
+
+	return &clone
+}
+
+// restoreAnchors replaces every alias whose anchor is not defined before it
+// with a copy of the aliased node, so that deleting an anchored node does not
+// leave dangling aliases behind. The first such alias takes over the anchor,
+// all further aliases keep referring to it. Only nodes containing such aliases
+// are copied, the given node is never modified.
+func restoreAnchors(node *yaml.Node) *yaml.Node {
+	return restoreAnchorsIn(node, map[string]struct{}{})
+}
+
+func restoreAnchorsIn(node *yaml.Node, defined map[string]struct{}) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		if _, exists := defined[node.Value]; exists || node.Alias == nil {
+			return node
+		}
+
+		// comments belong to the position of the alias, not the aliased node
+		value := deepCopyNode(node.Alias)
+		value.Anchor = node.Value
+		value.HeadComment = node.HeadComment
+		value.LineComment = node.LineComment
+		value.FootComment = node.FootComment
+
+		return restoreAnchorsIn(value, defined)
+	}
+
+	if node.Anchor != "" {
+		defined[node.Anchor] = struct{}{}
+	}
+
+	var clone *yaml.Node
+
+	for i, child := range node.Content {
+		if restored := restoreAnchorsIn(child, defined); restored != child {
+			if clone == nil {
+				clone = shallowCopyNode(node)
+			}
+
+			clone.Content[i] = restored
+		}
+	}
+
+	if clone != nil {
+		return clone
+	}
+
+	return node
+}
+
+// ToPlain recursively converts all Mapping, Sequence and ordered.Object values
+// into regular maps and slices, for encoders that do not support them.
+func ToPlain(value any) (any, error) {
+	switch asserted := value.(type) {
+	case ordered.Object:
+		return ToPlain(asserted.ToMap())
+
+	case Mapping:
+		obj, err := asserted.toMap()
+		if err != nil {
+			return nil, err
+		}
+
+		return ToPlain(obj)
+
+	case Sequence:
+		vec, err := asserted.toSlice()
+		if err != nil {
+			return nil, err
+		}
+
+		return ToPlain(vec)
+
+	case map[string]any:
+		result := make(map[string]any, len(asserted))
+		for key, item := range asserted {
+			plain, err := ToPlain(item)
+			if err != nil {
+				return nil, err
+			}
+
+			result[key] = plain
+		}
+
+		return result, nil
+
+	case []any:
+		result := make([]any, len(asserted))
+		for i, item := range asserted {
+			plain, err := ToPlain(item)
+			if err != nil {
+				return nil, err
+			}
+
+			result[i] = plain
+		}
+
+		return result, nil
+
+	default:
+		return value, nil
+	}
+}
+
+func deepCopyNode(node *yaml.Node) *yaml.Node {
+	clone := *node
+
+	if node.Content != nil {
+		clone.Content = make([]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			clone.Content[i] = deepCopyNode(child)
+		}
+	}
+
+	return &clone
+}
@@ -0,0 +1,397 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package yamlnode
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.xrstf.de/rudi/pkg/jsonpath"
+	"go.xrstf.de/rudi/pkg/ordered"
+
+	"gopkg.in/yaml.v3"
+)
+
+func decodeDocument(t *testing.T, input string) any {
+	t.Helper()
+
+	documents, err := Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to decode YAML: %v", err)
+	}
+
+	if len(documents) != 1 {
+		t.Fatalf("Expected a single document, got %d.", len(documents))
+	}
+
+	return documents[0]
+}
+
+func encodeDocument(t *testing.T, value any) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	var err error
+	if document, ok := value.(interface{ YAMLDocument() *yaml.Node }); ok {
+		err = encoder.Encode(document.YAMLDocument())
+	} else {
+		err = encoder.Encode(value)
+	}
+
+	if err != nil {
+		t.Fatalf("Failed to encode YAML: %v", err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Failed to encode YAML: %v", err)
+	}
+
+	return buf.String()
+}
+
+func encodeJSON(t *testing.T, value any) string {
+	t.Helper()
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("Failed to encode JSON: %v", err)
+	}
+
+	return string(encoded)
+}
+
+func TestRoundtrip(t *testing.T) {
+	testcases := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "scalars",
+			input: "a: 1\nb: \"quoted\"\nc: 'single'\nd: true\ne: null\n",
+		},
+		{
+			name:  "comments",
+			input: "# head comment\n\n# key comment\na: 1 # line comment\nb:\n  # nested comment\n  c: 2\n# foot comment\n",
+		},
+		{
+			name:  "sequence document",
+			input: "- a # first\n- b\n- [c, d]\n",
+		},
+		{
+			name:  "unsorted keys",
+			input: "z: 1\na: 2\nm:\n  y: 3\n  b: 4\n",
+		},
+		{
+			name:  "anchors and merge keys",
+			input: "base: &base\n  a: 1\n  b: 2\nderived:\n  <<: *base\n  b: 3\nlist: &list [1, 2]\nalias: *list\n",
+		},
+		{
+			name:  "block scalars",
+			input: "script: |\n  echo hello\n  echo world\nfolded: >-\n  some text\n",
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			document := decodeDocument(t, testcase.input)
+
+			if output := encodeDocument(t, document); output != testcase.input {
+				t.Fatalf("Expected\n\n%s\nbut got\n\n%s", testcase.input, output)
+			}
+		})
+	}
+}
+
+func TestSetKeepsComments(t *testing.T) {
+	document := decodeDocument(t, "# head\na: 1 # line comment\nb: \"x\"\n")
+
+	updated, err := jsonpath.Set(document, jsonpath.Path{"a"}, 2)
+	if err != nil {
+		t.Fatalf("Failed to set key: %v", err)
+	}
+
+	updated, err = jsonpath.Set(updated, jsonpath.Path{"b"}, "y")
+	if err != nil {
+		t.Fatalf("Failed to set key: %v", err)
+	}
+
+	expected := "# head\na: 2 # line comment\nb: \"y\"\n"
+	if output := encodeDocument(t, updated); output != expected {
+		t.Fatalf("Expected\n\n%s\nbut got\n\n%s", expected, output)
+	}
+}
+
+func TestKeyOrder(t *testing.T) {
+	document := decodeDocument(t, "z: 1\n<<: {m: 2, z: 3}\na: 4\n")
+
+	mapping, ok := document.(Mapping)
+	if !ok {
+		t.Fatalf("Expected a Mapping, got %T.", document)
+	}
+
+	// keys of the mapping itself come first, merged keys after
+	keys := strings.Join(mapping.ObjectKeys(), ",")
+	if keys != "z,a,m" {
+		t.Fatalf("Expected keys z,a,m, got %s.", keys)
+	}
+
+	obj := ordered.NewObject()
+	obj.Set("x", 1)
+	obj.Set("b", 2)
+
+	updated, err := jsonpath.Set(document, jsonpath.Path{"new"}, obj)
+	if err != nil {
+		t.Fatalf("Failed to set key: %v", err)
+	}
+
+	updated, err = jsonpath.Set(updated, jsonpath.Path{"b"}, 5)
+	if err != nil {
+		t.Fatalf("Failed to set key: %v", err)
+	}
+
+	// new keys are appended, keeping the order of ordered objects
+	expected := "z: 1\n<<: {m: 2, z: 3}\na: 4\nnew:\n  x: 1\n  b: 2\nb: 5\n"
+	if output := encodeDocument(t, updated); output != expected {
+		t.Fatalf("Expected\n\n%s\nbut got\n\n%s", expected, output)
+	}
+}
+
+func TestMergeKeys(t *testing.T) {
+	input := "base: &base\n  a: 1\n  b: 2\nderived:\n  <<: *base\n  b: 3\n"
+	document := decodeDocument(t, input)
+
+	testcases := []struct {
+		path     jsonpath.Path
+		expected any
+	}{
+		{path: jsonpath.Path{"derived", "a"}, expected: 1},
+		{path: jsonpath.Path{"derived", "b"}, expected: 3},
+		{path: jsonpath.Path{"base", "b"}, expected: 2},
+	}
+
+	for _, testcase := range testcases {
+		value, err := jsonpath.Get(document, testcase.path)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", testcase.path, err)
+		}
+
+		if value != testcase.expected {
+			t.Fatalf("Expected %s to be %v, got %v.", testcase.path, testcase.expected, value)
+		}
+	}
+
+	// overriding a merged key must not change the anchored mapping
+	updated, err := jsonpath.Set(document, jsonpath.Path{"derived", "a"}, 4)
+	if err != nil {
+		t.Fatalf("Failed to set merged key: %v", err)
+	}
+
+	expected := "base: &base\n  a: 1\n  b: 2\nderived:\n  <<: *base\n  b: 3\n  a: 4\n"
+	if output := encodeDocument(t, updated); output != expected {
+		t.Fatalf("Expected\n\n%s\nbut got\n\n%s", expected, output)
+	}
+
+	// changing the anchored mapping keeps the anchor, so that the merge key
+	// refers to the updated mapping when the document is read again
+	updated, err = jsonpath.Set(document, jsonpath.Path{"base", "a"}, 5)
+	if err != nil {
+		t.Fatalf("Failed to set anchored key: %v", err)
+	}
+
+	expected = "base: &base\n  a: 5\n  b: 2\nderived:\n  <<: *base\n  b: 3\n"
+	if output := encodeDocument(t, updated); output != expected {
+		t.Fatalf("Expected\n\n%s\nbut got\n\n%s", expected, output)
+	}
+
+	mapping, err := jsonpath.Get(document, jsonpath.Path{"derived"})
+	if err != nil {
+		t.Fatalf("Failed to get mapping: %v", err)
+	}
+
+	if _, err := mapping.(Mapping).DeleteObjectKey("a"); err == nil {
+		t.Fatal("Should not have been able to delete a merged key.")
+	}
+}
+
+func TestCopyOnWrite(t *testing.T) {
+	input := "# comment\nmeta:\n  name: foo\n  labels: &labels\n    app: foo\nlist:\n  - 1\n  - 2\nalias: *labels\n"
+	document := decodeDocument(t, input)
+
+	changes := []struct {
+		path  jsonpath.Path
+		value any
+	}{
+		{path: jsonpath.Path{"meta", "name"}, value: "bar"},
+		{path: jsonpath.Path{"meta", "labels", "app"}, value: "bar"},
+		{path: jsonpath.Path{"meta", "new"}, value: true},
+		{path: jsonpath.Path{"list", 1}, value: 3},
+		{path: jsonpath.Path{"alias", "app"}, value: "baz"},
+	}
+
+	updated := document
+	for _, change := range changes {
+		var err error
+
+		updated, err = jsonpath.Set(updated, change.path, change.value)
+		if err != nil {
+			t.Fatalf("Failed to set %s: %v", change.path, err)
+		}
+	}
+
+	updated, err := jsonpath.Delete(updated, jsonpath.Path{"list", 0})
+	if err != nil {
+		t.Fatalf("Failed to delete item: %v", err)
+	}
+
+	expected := "# comment\nmeta:\n  name: bar\n  labels: &labels\n    app: bar\n  new: true\nlist:\n  - 3\nalias:\n  app: baz\n"
+	if output := encodeDocument(t, updated); output != expected {
+		t.Fatalf("Expected\n\n%s\nbut got\n\n%s", expected, output)
+	}
+
+	if output := encodeDocument(t, document); output != input {
+		t.Fatalf("Original document was modified, expected\n\n%s\nbut got\n\n%s", input, output)
+	}
+}
+
+func TestAnchors(t *testing.T) {
+	testcases := []struct {
+		name     string
+		input    string
+		update   func(document any) (any, error)
+		expected string
+	}{
+		{
+			name:  "replacing an anchored value keeps the anchor",
+			input: "a: &x 2\nc: *x\n",
+			update: func(document any) (any, error) {
+				return jsonpath.Set(document, jsonpath.Path{"a"}, 9)
+			},
+			expected: "a: &x 9\nc: *x\n",
+		},
+		{
+			name:  "deleting an anchored value",
+			input: "a: &x 2\nc: *x\nd: *x\n",
+			update: func(document any) (any, error) {
+				return jsonpath.Delete(document, jsonpath.Path{"a"})
+			},
+			expected: "c: &x 2\nd: *x\n",
+		},
+		{
+			name:  "deleting an anchored item",
+			input: "list: [&x {k: v}, b]\nc: *x # comment\n",
+			update: func(document any) (any, error) {
+				return jsonpath.Delete(document, jsonpath.Path{"list", 0})
+			},
+			expected: "list: [b]\nc: &x {k: v} # comment\n",
+		},
+		{
+			name:  "deleting a merged mapping",
+			input: "base: &base\n  a: 1\nderived:\n  <<: *base\n  b: 2\n",
+			update: func(document any) (any, error) {
+				return jsonpath.Delete(document, jsonpath.Path{"base"})
+			},
+			expected: "derived:\n  <<: &base\n    a: 1\n  b: 2\n",
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			document := decodeDocument(t, testcase.input)
+
+			updated, err := testcase.update(document)
+			if err != nil {
+				t.Fatalf("Failed to update document: %v", err)
+			}
+
+			output := encodeDocument(t, updated)
+			if output != testcase.expected {
+				t.Fatalf("Expected\n\n%s\nbut got\n\n%s", testcase.expected, output)
+			}
+
+			// the result must be valid YAML again
+			decodeDocument(t, output)
+
+			if output := encodeDocument(t, document); output != testcase.input {
+				t.Fatalf("Original document was modified, expected\n\n%s\nbut got\n\n%s", testcase.input, output)
+			}
+		})
+	}
+}
+
+func TestSequence(t *testing.T) {
+	document := decodeDocument(t, "- a\n- b\n- c\n")
+
+	sequence, ok := document.(Sequence)
+	if !ok {
+		t.Fatalf("Expected a Sequence, got %T.", document)
+	}
+
+	value, err := sequence.GetVectorItem(-1)
+	if err != nil {
+		t.Fatalf("Failed to get item: %v", err)
+	}
+
+	if value != "c" {
+		t.Fatalf("Expected last item to be c, got %v.", value)
+	}
+
+	if _, err := sequence.GetVectorItem(3); err == nil {
+		t.Fatal("Should not have been able to get an out-of-bounds item.")
+	}
+
+	updated, err := jsonpath.Set(document, jsonpath.Path{-1}, "d")
+	if err != nil {
+		t.Fatalf("Failed to set item: %v", err)
+	}
+
+	updated, err = jsonpath.Delete(updated, jsonpath.Path{0})
+	if err != nil {
+		t.Fatalf("Failed to delete item: %v", err)
+	}
+
+	expected := "- b\n- d\n"
+	if output := encodeDocument(t, updated); output != expected {
+		t.Fatalf("Expected\n\n%s\nbut got\n\n%s", expected, output)
+	}
+}
+
+func TestConversions(t *testing.T) {
+	document := decodeDocument(t, "z: [1, {b: 2, a: 3}]\na: &x {k: v}\nm: *x\n")
+
+	converted, err := ToOrdered(document)
+	if err != nil {
+		t.Fatalf("Failed to convert to ordered: %v", err)
+	}
+
+	obj, ok := converted.(ordered.Object)
+	if !ok {
+		t.Fatalf("Expected an ordered.Object, got %T.", converted)
+	}
+
+	if keys := strings.Join(obj.Keys(), ","); keys != "z,a,m" {
+		t.Fatalf("Expected keys z,a,m, got %s.", keys)
+	}
+
+	plain, err := ToPlain(document)
+	if err != nil {
+		t.Fatalf("Failed to convert to plain: %v", err)
+	}
+
+	expected := `{"a":{"k":"v"},"m":{"k":"v"},"z":[1,{"a":3,"b":2}]}`
+	if output := encodeJSON(t, plain); output != expected {
+		t.Fatalf("Expected %s, got %s.", expected, output)
+	}
+
+	// mappings keep their key order when encoded as JSON directly
+	expected = `{"z":[1,{"b":2,"a":3}],"a":{"k":"v"},"m":{"k":"v"}}`
+	if output := encodeJSON(t, document); output != expected {
+		t.Fatalf("Expected %s, got %s.", expected, output)
+	}
+}
@@ -7,9 +7,26 @@ import (
 	"errors"
 	"strings"
 
+	"go.xrstf.de/rudi/pkg/jsonpath"
 	"go.xrstf.de/rudi/pkg/lang/parser"
 )
 
+// ErrNotFound is matched by errors.Is() for all errors caused by path
+// expressions pointing to missing object keys or vector indices.
+var ErrNotFound = jsonpath.ErrNotFound
+
+// KeyNotFoundError is returned when a path expression points to a missing
+// object key.
+type KeyNotFoundError = jsonpath.KeyNotFoundError
+
+// IndexOutOfBoundsError is returned when a path expression points to a vector
+// index that is out of range.
+type IndexOutOfBoundsError = jsonpath.IndexOutOfBoundsError
+
+// TypeMismatchError is returned when a path expression step cannot be applied
+// to a value, like using an object key on a vector.
+type TypeMismatchError = jsonpath.TypeMismatchError
+
 // ParseErrors can occur while parsing a Rudi program.
 type ParseError struct {
 	script string
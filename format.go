@@ -0,0 +1,32 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package rudi
+
+import "go.xrstf.de/rudi/pkg/formatter"
+
+// FormatOptions control the indentation and line width when formatting Rudi
+// code.
+type FormatOptions = formatter.Options
+
+// DefaultFormatOptions returns the formatting options used by `rudi fmt`.
+func DefaultFormatOptions() FormatOptions {
+	return formatter.DefaultOptions()
+}
+
+// Format takes a program name and a script and returns the script in its
+// canonical formatting. Unlike DumpRudi(), comments in the script are kept.
+// If the script cannot be parsed, a ParseError is returned.
+func Format(name, script string, opts FormatOptions) (string, error) {
+	// parse the script first to get proper ParseErrors
+	if _, err := Parse(name, script); err != nil {
+		return "", err
+	}
+
+	formatted, err := formatter.Format(name, []byte(script), opts)
+	if err != nil {
+		return "", err
+	}
+
+	return string(formatted), nil
+}
@@ -62,9 +62,7 @@ func updateFile(filename string, linkPrefix string) error {
 	body = inject(body, renderLibraryTOC(batteries.ExtendedModules, linkPrefix+"extlib/"), "EXTLIB")
 
 	// inject help lib TOC
-	allModules := builtInModules
-	allModules = append(allModules, batteries.ExtendedModules...)
-	body = inject(body, renderHelpLibraryTOC(allModules), "HELP_LIB")
+	body = inject(body, renderHelpLibraryTOC(builtInModules, batteries.ExtendedModules), "HELP_LIB")
 
 	// write updated file
 	if err := os.WriteFile(filename, []byte(body), 0644); err != nil {
@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 
+	"go.xrstf.de/rudi/cmd/rudi/batteries"
 	rudidocs "go.xrstf.de/rudi/cmd/rudi/docs"
 	"go.xrstf.de/rudi/pkg/docs"
 )
@@ -47,12 +48,28 @@ func renderLibraryTOC(lib []docs.Module, linkPrefix string) string {
 	return out.String()
 }
 
-func renderHelpLibraryTOC(lib []docs.Module) string {
+func renderHelpLibraryTOC(builtIn []docs.Module, extended []docs.Module) string {
 	var out strings.Builder
 
+	renderHelpModules(&out, builtIn, false)
+	out.WriteString("\n")
+	renderHelpModules(&out, extended, true)
+
+	return out.String()
+}
+
+// renderHelpModules lists the functions of all modules; functions of extended
+// modules that collide with other functions are prefixed with the module name,
+// just like the rudi interpreter registers them.
+func renderHelpModules(out *strings.Builder, lib []docs.Module, extended bool) {
 	for i, module := range lib {
 		out.WriteString(fmt.Sprintf("* **%s**\n", module.Name))
 
+		unqualified := module.Functions
+		if extended {
+			unqualified = batteries.UnqualifiedFunctions(module)
+		}
+
 		functions := []string{}
 		for funcName := range module.Functions {
 			// Hack: ignore aliases, mostly because the math functions have names
@@ -67,13 +84,17 @@ func renderHelpLibraryTOC(lib []docs.Module) string {
 
 		for _, funcName := range functions {
 			desc := module.Functions[funcName].Description()
-			out.WriteString(fmt.Sprintf("  * `%s` – %s\n", funcName, desc))
+
+			name := funcName
+			if _, ok := unqualified.Get(funcName); !ok {
+				name = module.Name + "/" + funcName
+			}
+
+			out.WriteString(fmt.Sprintf("  * `%s` – %s\n", name, desc))
 		}
 
 		if i < len(lib)-1 {
 			out.WriteString("\n")
 		}
 	}
-
-	return out.String()
 }
@@ -16,8 +16,8 @@ var (
 	humaneCoalescer   = coalescing.NewHumane()
 
 	Functions = types.Functions{
-		"strictly":     functions.NewBuilder(core.DoFunction).WithCoalescer(strictCoalescer).WithDescription("evaluates the child expressions using strict coalescing").Build(),
-		"pedantically": functions.NewBuilder(core.DoFunction).WithCoalescer(pedanticCoalescer).WithDescription("evaluates the child expressions using pedantic coalescing").Build(),
-		"humanely":     functions.NewBuilder(core.DoFunction).WithCoalescer(humaneCoalescer).WithDescription("evaluates the child expressions using humane coalescing").Build(),
+		"strictly":     functions.NewBuilder(core.DoFunction).Pure().WithCoalescer(strictCoalescer).WithDescription("evaluates the child expressions using strict coalescing").Build(),
+		"pedantically": functions.NewBuilder(core.DoFunction).Pure().WithCoalescer(pedanticCoalescer).WithDescription("evaluates the child expressions using pedantic coalescing").Build(),
+		"humanely":     functions.NewBuilder(core.DoFunction).Pure().WithCoalescer(humaneCoalescer).WithDescription("evaluates the child expressions using humane coalescing").Build(),
 	}
 )
@@ -5,8 +5,10 @@ package compare
 
 import (
 	"errors"
+	"fmt"
 
 	"go.xrstf.de/rudi/pkg/coalescing"
+	"go.xrstf.de/rudi/pkg/diff"
 	"go.xrstf.de/rudi/pkg/equality"
 	"go.xrstf.de/rudi/pkg/runtime/functions"
 	"go.xrstf.de/rudi/pkg/runtime/types"
@@ -18,14 +20,16 @@ var (
 	humaneCoalescer   = coalescing.NewHumane()
 
 	Functions = types.Functions{
-		"eq?":        functions.NewBuilder(eqFunction).WithDescription("equality check: return true if both arguments are the same").Build(),
-		"identical?": functions.NewBuilder(identicalFunction).WithDescription("like `eq?`, but always uses strict coalecsing").Build(),
-		"like?":      functions.NewBuilder(likeFunction).WithDescription("like `eq?`, but always uses humane coalecsing").Build(),
-
-		"lt?":  functions.NewBuilder(ltFunction).WithDescription("returns a < b").Build(),
-		"lte?": functions.NewBuilder(lteFunction).WithDescription("returns a <= b").Build(),
-		"gt?":  functions.NewBuilder(gtFunction).WithDescription("returns a > b").Build(),
-		"gte?": functions.NewBuilder(gteFunction).WithDescription("returns a >= b").Build(),
+		"eq?":        functions.NewBuilder(eqFunction).Pure().WithDescription("equality check: return true if both arguments are the same").Build(),
+		"identical?": functions.NewBuilder(identicalFunction).Pure().WithDescription("like `eq?`, but always uses strict coalecsing").Build(),
+		"like?":      functions.NewBuilder(likeFunction).Pure().WithDescription("like `eq?`, but always uses humane coalecsing").Build(),
+
+		"lt?":  functions.NewBuilder(ltFunction).Pure().WithDescription("returns a < b").Build(),
+		"lte?": functions.NewBuilder(lteFunction).Pure().WithDescription("returns a <= b").Build(),
+		"gt?":  functions.NewBuilder(gtFunction).Pure().WithDescription("returns a > b").Build(),
+		"gte?": functions.NewBuilder(gteFunction).Pure().WithDescription("returns a >= b").Build(),
+
+		"diff": functions.NewBuilder(diffFunction, diffWithOptionsFunction).Pure().WithDescription("returns a list of all differences between two values").Build(),
 	}
 )
 
@@ -120,3 +124,89 @@ func gteFunction(ctx types.Context, left, right any) (any, error) {
 		panic("Unexpected comparison result.")
 	}
 }
+
+func diffFunction(ctx types.Context, left, right any) (any, error) {
+	return diffValues(ctx, left, right, nil)
+}
+
+func diffWithOptionsFunction(ctx types.Context, left, right any, options map[string]any) (any, error) {
+	opts := &diff.Options{}
+	vectorMode := ""
+
+	for key, value := range options {
+		var err error
+
+		switch key {
+		case "ignore":
+			opts.IgnoreKeys, err = toStrings(ctx.Coalesce(), value)
+		case "vectors":
+			vectorMode, err = ctx.Coalesce().ToString(value)
+		case "key":
+			opts.Key, err = ctx.Coalesce().ToString(value)
+		default:
+			err = errors.New("unknown option")
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("option %q: %w", key, err)
+		}
+	}
+
+	switch vectorMode {
+	case "":
+		if opts.Key != "" {
+			opts.Vectors = diff.KeyedVectors
+		}
+	case "ordered":
+		opts.Vectors = diff.OrderedVectors
+	case "set":
+		opts.Vectors = diff.SetVectors
+	case "keyed":
+		opts.Vectors = diff.KeyedVectors
+	default:
+		return nil, fmt.Errorf("option \"vectors\": must be one of ordered, set or keyed, got %q", vectorMode)
+	}
+
+	return diffValues(ctx, left, right, opts)
+}
+
+func toStrings(c coalescing.Coalescer, value any) ([]string, error) {
+	vector, err := c.ToVector(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, len(vector))
+	for i, item := range vector {
+		result[i], err = c.ToString(item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func diffValues(ctx types.Context, left, right any, opts *diff.Options) (any, error) {
+	changes, err := diff.Diff(ctx.Coalesce(), left, right, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]any, len(changes))
+	for i, change := range changes {
+		pointer, err := change.Path.ToPointer()
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = map[string]any{
+			"path": pointer,
+			"op":   string(change.Op),
+			"old":  change.Old,
+			"new":  change.New,
+		}
+	}
+
+	return result, nil
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"testing"
 
+	"go.xrstf.de/rudi/pkg/coalescing"
 	"go.xrstf.de/rudi/pkg/runtime/interpreter"
 	"go.xrstf.de/rudi/pkg/runtime/types"
 	"go.xrstf.de/rudi/pkg/testutil"
@@ -545,3 +546,94 @@ func TestComparisonRudiFunctions(t *testing.T) {
 		}
 	}
 }
+
+func TestDiffFunction(t *testing.T) {
+	testcases := []testutil.Testcase{
+		{
+			Expression: `(diff)`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(diff 1)`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(diff 1 2 "options")`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(diff 1 2 {unknown true})`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(diff [1] [2] {vectors "random"})`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(diff [1] [2] {vectors "keyed"})`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(diff [1] [2] {key "name"})`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(diff 1 1)`,
+			Expected:   []any{},
+		},
+		{
+			Expression: `(diff 1 "1")`,
+			Expected: []any{
+				map[string]any{"path": "", "op": "replace", "old": int64(1), "new": "1"},
+			},
+		},
+		{
+			Expression: `(diff 1 "1")`,
+			Coalescer:  coalescing.NewHumane(),
+			Expected:   []any{},
+		},
+		{
+			Expression: `(diff {a 1 b 2 c {d 3}} {a 1 c {d 4} e 5})`,
+			Expected: []any{
+				map[string]any{"path": "/b", "op": "remove", "old": int64(2), "new": nil},
+				map[string]any{"path": "/c/d", "op": "replace", "old": int64(3), "new": int64(4)},
+				map[string]any{"path": "/e", "op": "add", "old": nil, "new": int64(5)},
+			},
+		},
+		{
+			Expression: `(diff {a 1 meta {time "x"}} {a 1 meta {time "y"}} {ignore ["time"]})`,
+			Expected:   []any{},
+		},
+		{
+			Expression: `(diff [1 2 3] [1 4])`,
+			Expected: []any{
+				map[string]any{"path": "/1", "op": "replace", "old": int64(2), "new": int64(4)},
+				map[string]any{"path": "/2", "op": "remove", "old": int64(3), "new": nil},
+			},
+		},
+		{
+			Expression: `(diff [1 2 3] [3 2 1] {vectors "set"})`,
+			Expected:   []any{},
+		},
+		{
+			Expression: `(diff [1 1 2] [2 1 4] {vectors "set"})`,
+			Expected: []any{
+				map[string]any{"path": "/1", "op": "remove", "old": int64(1), "new": nil},
+				map[string]any{"path": "/2", "op": "add", "old": nil, "new": int64(4)},
+			},
+		},
+		{
+			Expression: `(diff [{name "a" v 1} {name "b" v 2} {name "c"}] [{name "b" v 3} {name "a" v 1} {name "d"}] {key "name"})`,
+			Expected: []any{
+				map[string]any{"path": "/0/v", "op": "replace", "old": int64(2), "new": int64(3)},
+				map[string]any{"path": "/2", "op": "add", "old": nil, "new": map[string]any{"name": "d"}},
+				map[string]any{"path": "/2", "op": "remove", "old": map[string]any{"name": "c"}, "new": nil},
+			},
+		},
+	}
+
+	for _, testcase := range testcases {
+		testcase.Functions = Functions
+		t.Run(testcase.String(), testcase.Run)
+	}
+}
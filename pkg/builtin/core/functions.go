@@ -9,7 +9,6 @@ import (
 	"fmt"
 
 	"go.xrstf.de/rudi/pkg/coalescing"
-	"go.xrstf.de/rudi/pkg/deepcopy"
 	"go.xrstf.de/rudi/pkg/jsonpath"
 	"go.xrstf.de/rudi/pkg/lang/ast"
 	"go.xrstf.de/rudi/pkg/runtime/functions"
@@ -23,15 +22,15 @@ var (
 	humaneCoalescer   = coalescing.NewHumane()
 
 	Functions = types.Functions{
-		"default": functions.NewBuilder(defaultFunction).WithDescription("returns the default value if the first argument is empty").Build(),
-		"delete":  functions.NewBuilder(deleteFunction).WithBangHandler(overwriteEverythingBangHandler).WithDescription("removes a key from an object or an item from a vector").Build(),
-		"do":      functions.NewBuilder(DoFunction).WithDescription("eval a sequence of statements where only one expression is valid").Build(),
-		"empty?":  functions.NewBuilder(isEmptyFunction).WithCoalescer(humaneCoalescer).WithDescription("returns true when the given value is empty-ish (0, false, null, \"\", ...)").Build(),
+		"default": functions.NewBuilder(defaultFunction).Pure().WithDescription("returns the default value if the first argument is empty").Build(),
+		"delete":  functions.NewBuilder(deleteFunction).WithBangFunction(deleteBangFunction).WithDescription("removes a key from an object or an item from a vector").Build(),
+		"do":      functions.NewBuilder(DoFunction).Pure().WithDescription("eval a sequence of statements where only one expression is valid").Build(),
+		"empty?":  functions.NewBuilder(isEmptyFunction).Pure().WithCoalescer(humaneCoalescer).WithDescription("returns true when the given value is empty-ish (0, false, null, \"\", ...)").Build(),
 		"error":   functions.NewBuilder(errorFunction, fmtErrorFunction).WithDescription("returns an error").Build(),
 		"has?":    functions.NewBuilder(hasFunction).WithDescription("returns true if the given symbol's path expression points to an existing value").Build(),
-		"if":      functions.NewBuilder(ifElseFunction, ifFunction).WithDescription("evaluate one of two expressions based on a condition").Build(),
-		"case":    functions.NewBuilder(caseFunction).WithDescription("chooses the first expression for which the test is true").Build(),
-		"set":     functions.NewBuilder(setFunction).WithBangHandler(overwriteEverythingBangHandler).WithDescription("set a value in a variable/document, only really useful with ! modifier (set!)").Build(),
+		"if":      functions.NewBuilder(ifElseFunction, ifFunction).Pure().WithDescription("evaluate one of two expressions based on a condition").Build(),
+		"case":    functions.NewBuilder(caseFunction).Pure().WithDescription("chooses the first expression for which the test is true").Build(),
+		"set":     functions.NewBuilder(setFunction).WithBangFunction(setBangFunction).WithDescription("set a value in a variable/document, only really useful with ! modifier (set!)").Build(),
 		"try":     functions.NewBuilder(tryWithFallbackFunction, tryFunction).WithDescription("returns the fallback if the first expression errors out").Build(),
 	}
 )
@@ -123,22 +122,52 @@ func hasFunction(ctx types.Context, arg ast.Expression) (any, error) {
 		return nil, fmt.Errorf("invalid path expression: %w", err)
 	}
 
+	// optional steps turn missing keys into null, but for has? they must
+	// still mean that the path does not exist
+	steps := make([]ast.EvaluatedPathStep, len(evaluatedPath.Steps))
+	for i, step := range evaluatedPath.Steps {
+		step.Optional = false
+		steps[i] = step
+	}
+
 	// evaluate the base value
 	value, err := ctx.Runtime().EvalExpression(ctx, expr)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = pathexpr.Traverse(value, *evaluatedPath)
+	result, err := pathexpr.Traverse(value, ast.EvaluatedPathExpression{Steps: steps})
 	if err != nil {
-		return false, keepContextCanceled(err)
+		// only missing keys and indices mean the path does not exist, type
+		// errors (like using a string as a vector index) are real errors
+		if errors.Is(err, jsonpath.ErrNotFound) {
+			return false, nil
+		}
+
+		return nil, err
+	}
+
+	// paths like .items[*].name exist if they match at least one value
+	if evaluatedPath.IsMulti() {
+		matches, _ := result.([]any)
+		return len(matches) > 0, nil
 	}
 
 	return true, nil
 }
 
 // (default TEST:Expression FALLBACK:any)
-func defaultFunction(ctx types.Context, value any, fallback ast.Expression) (any, error) {
+func defaultFunction(ctx types.Context, candidate ast.Expression, fallback ast.Expression) (any, error) {
+	value, err := ctx.Runtime().EvalExpression(ctx, candidate)
+	if err != nil {
+		// paths that point to non-existing keys/indices are treated like null
+		if !errors.Is(err, jsonpath.ErrNotFound) {
+			return nil, fmt.Errorf("argument #0: %w", err)
+		}
+
+		value = nil
+	}
+
 	// this function purposefully always uses humane coalescing, but only for this check
 	boolified, err := coalescing.NewHumane().ToBool(value)
 	if err != nil {
@@ -169,6 +198,10 @@ func tryFunction(ctx types.Context, test ast.Expression) (any, error) {
 func tryWithFallbackFunction(ctx types.Context, test ast.Expression, fallback ast.Expression) (any, error) {
 	result, err := ctx.Runtime().EvalExpression(ctx, test)
 	if err != nil {
+		if err := keepContextCanceled(err); err != nil {
+			return nil, err
+		}
+
 		result, err = ctx.Runtime().EvalExpression(ctx, fallback)
 		if err != nil {
 			return nil, fmt.Errorf("argument #1: %w", err)
@@ -224,16 +257,8 @@ func setFunction(ctx types.Context, target ast.Expression, value any) (any, erro
 		return nil, err
 	}
 
-	// we need to operate on a _copy_ of the value, as updating happens in the bang handler later
-	// on; this is only necessary for symbols though, as functions (tuples) are expected to return
-	// non-pointer data and vector/objects nodes are literals.
-	if _, ok := target.(ast.Symbol); ok {
-		targetValue, err = deepcopy.Clone(targetValue)
-		if err != nil {
-			return nil, fmt.Errorf("invalid current value: %w", err)
-		}
-	}
-
+	// jsonpath does not modify the target value, so it is safe to use the current value of
+	// symbols; updating variables or the document happens in the bang handler later on.
 	return jsonpath.Set(targetValue, jsonpath.FromEvaluatedPath(*evaluatedPath), value)
 }
 
@@ -262,56 +287,145 @@ func deleteFunction(ctx types.Context, target ast.Expression) (any, error) {
 		return nil, err
 	}
 
-	// we need to operate on a _copy_ of the value, as updating happens in the bang handler later
-	// on; this is only necessary for symbols though, as functions (tuples) are expected to return
-	// non-pointer data and vector/objects nodes are literals.
-	if _, ok := target.(ast.Symbol); ok {
-		targetValue, err = deepcopy.Clone(targetValue)
-		if err != nil {
-			return nil, fmt.Errorf("invalid current value: %w", err)
-		}
-	}
-
 	// delete the desired path in the value
 	return jsonpath.Delete(targetValue, jsonpath.FromEvaluatedPath(*pathExpr))
 }
 
-// overwriteEverythingBangHandler is the custom BangHandler for the set and delete functions.
-// Normally, function calls like "(append $obj.list 1)" would not return the entire object, but only
-// the function result (in this case, a vector with one more element added to it). This is because
-// for most functions, the path expression is evaluated before the argument is created and the
-// append function is called (i.e. append doesn't even see that its first argument originates from
-// $obj.list).
-// If set behaved the same way, "(set $obj.value 42)" would return 42, not the entire object. That
+// setBangFunction and deleteBangFunction implement the bang modifier for the set and delete
+// functions. Normally, function calls like "(append! $obj.list 1)" would not return the entire
+// object, but only the function result (in this case, a vector with one more element added to
+// it). This is because for most functions, the path expression is evaluated before the argument
+// is created and the append function is called (i.e. append doesn't even see that its first
+// argument originates from $obj.list).
+// If set behaved the same way, "(set! $obj.value 42)" would return 42, not the entire object. That
 // is not really helpful though. If you wanted to take an object and just update one value in it,
 // you'd be forced to use a temporary variable ("(set! $o ....) (set! $o.value 42) $o").
 // Because of this, set returns the whole updated data structure, so in the example above, the
 // entire $obj. It can do this because the first argument is not pre-evaluated by the Rudi runtime,
 // but passed as a raw expression (like for delete).
-// All of this applies equally to the delete function, hence both share the same bang handler.
-// Since this behaviour makes set different from other regular functions, it needs a custom
-// BangHandler.
-func overwriteEverythingBangHandler(ctx types.Context, originalArgs []ast.Expression, value any) (any, error) {
-	if len(originalArgs) == 0 {
-		return nil, errors.New("must have at least 1 symbol argument")
+// Both functions evaluate the path expression exactly once and record the concrete paths they
+// modified, so that documents that track their changes know exactly what happened, even for
+// path expressions with side effects or filters.
+
+// (set! VAR:Variable VALUE:any)
+// (set! EXPR:Symbol VALUE:any)
+func setBangFunction(ctx types.Context, args []ast.Expression) (any, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("expected 2 arguments, got %d", len(args))
+	}
+
+	symbol, err := bangSymbol(args)
+	if err != nil {
+		return nil, err
 	}
 
-	firstArg := originalArgs[0]
-	symbol, ok := firstArg.(ast.Symbol)
+	value, err := ctx.Runtime().EvalExpression(ctx, args[1])
+	if err != nil {
+		return nil, fmt.Errorf("argument #1: %w", err)
+	}
+
+	// overwrite the entire variable or document
+	if symbol.PathExpression == nil {
+		updateSymbol(ctx, symbol, value, types.Change{
+			Operation: types.SetOperation,
+			Path:      jsonpath.Path{},
+			Value:     value,
+		})
+
+		return value, nil
+	}
+
+	evaluatedPath, err := pathexpr.Eval(ctx, symbol.PathExpression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path expression: %w", err)
+	}
+
+	targetValue, err := ctx.Runtime().EvalExpression(ctx, symbol.Pathless())
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []types.Change{}
+
+	updated, err := jsonpath.UpdateWithPaths(targetValue, jsonpath.FromEvaluatedPath(*evaluatedPath), func(path jsonpath.Path, _ any) (any, error) {
+		changes = append(changes, types.Change{
+			Operation: types.SetOperation,
+			Path:      path,
+			Value:     value,
+		})
+
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updateSymbol(ctx, symbol, updated, changes...)
+
+	return updated, nil
+}
+
+// (delete! EXPR:Symbol)
+func deleteBangFunction(ctx types.Context, args []ast.Expression) (any, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+	}
+
+	symbol, err := bangSymbol(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if symbol.PathExpression == nil {
+		return nil, errors.New("empty path expression")
+	}
+
+	evaluatedPath, err := pathexpr.Eval(ctx, symbol.PathExpression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path expression: %w", err)
+	}
+
+	targetValue, err := ctx.Runtime().EvalExpression(ctx, symbol.Pathless())
+	if err != nil {
+		return nil, err
+	}
+
+	updated, paths, err := jsonpath.DeleteWithPaths(targetValue, jsonpath.FromEvaluatedPath(*evaluatedPath))
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]types.Change, len(paths))
+	for i, path := range paths {
+		changes[i] = types.Change{
+			Operation: types.DeleteOperation,
+			Path:      path,
+		}
+	}
+
+	updateSymbol(ctx, symbol, updated, changes...)
+
+	return updated, nil
+}
+
+func bangSymbol(args []ast.Expression) (ast.Symbol, error) {
+	symbol, ok := args[0].(ast.Symbol)
 	if !ok {
-		return nil, fmt.Errorf("must use Symbol as first argument, got %T", firstArg)
+		return ast.Symbol{}, fmt.Errorf("must use Symbol as first argument, got %T", args[0])
 	}
 
-	// Since set always returns the entire data structure, all we must do here is to
-	// update the target, ignoring the path expression on the symbol.
+	return symbol, nil
+}
+
+// updateSymbol replaces the variable or document the symbol refers to with the
+// updated value; the changes are only relevant for documents.
+func updateSymbol(ctx types.Context, symbol ast.Symbol, updated any, changes ...types.Change) {
 	if symbol.Variable != nil {
-		varName := string(*symbol.Variable)
-		ctx.SetVariable(varName, value)
-	} else {
-		ctx.GetDocument().Set(value)
+		ctx.SetVariable(string(*symbol.Variable), updated)
+		return
 	}
 
-	return value, nil
+	ctx.GetDocument().Update(updated, changes...)
 }
 
 func isEmptyFunction(val bool) (any, error) {
@@ -4,8 +4,18 @@
 package core
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
+	"go.xrstf.de/rudi/pkg/jsonpath"
+	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/lang/parser"
+	"go.xrstf.de/rudi/pkg/runtime/functions"
+	"go.xrstf.de/rudi/pkg/runtime/interpreter"
 	"go.xrstf.de/rudi/pkg/runtime/types"
 	"go.xrstf.de/rudi/pkg/testutil"
 )
@@ -227,6 +237,32 @@ func TestSetFunction(t *testing.T) {
 			Expression: `(set! $a {foo "bar"}) (if true (set! $a.foo "updated")) $a.foo`,
 			Expected:   "updated",
 		},
+		// values are shared, but updating one must not affect the other
+		{
+			Expression: `(set! $a {foo {bar 1}}) (set! $b $a) (set! $b.foo.bar 2) [$a.foo.bar $b.foo.bar]`,
+			Expected:   []any{int64(1), int64(2)},
+		},
+		{
+			Expression: `(set! $a [{foo 1} {foo 2}]) (set! $b $a) (set! $b[*].foo 3) (delete! $a[0]) [$a $b]`,
+			Expected: []any{
+				[]any{map[string]any{"foo": int64(2)}},
+				[]any{map[string]any{"foo": int64(3)}, map[string]any{"foo": int64(3)}},
+			},
+		},
+		{
+			Expression: `(set! $copy .anObject) (set! .anObject.key1 false) (delete! .anObject.key3) $copy`,
+			Document:   testObjDocument(),
+			Expected: map[string]any{
+				"key1": true,
+				"key2": nil,
+				"key3": []any{9, map[string]any{"foo": "bar"}, 7},
+			},
+			ExpectedDocument: func() any {
+				doc := testObjDocument().(map[string]any)
+				doc["anObject"] = map[string]any{"key1": false, "key2": nil}
+				return doc
+			}(),
+		},
 		// handle bad paths
 		{
 			Expression: `(set! $obj[5.6] "new value")`,
@@ -352,6 +388,45 @@ func TestSetFunction(t *testing.T) {
 			Expression: `(set (set $foo {foo "bar"}).foo 4)`,
 			Expected:   map[string]any{"foo": int64(4)},
 		},
+		// update multiple values at once
+		{
+			Expression: `(set! .items[*].enabled true) .items`,
+			Document: map[string]any{
+				"items": []any{
+					map[string]any{"name": "a"},
+					map[string]any{"name": "b", "enabled": false},
+				},
+			},
+			Expected: []any{
+				map[string]any{"name": "a", "enabled": true},
+				map[string]any{"name": "b", "enabled": true},
+			},
+			ExpectedDocument: map[string]any{
+				"items": []any{
+					map[string]any{"name": "a", "enabled": true},
+					map[string]any{"name": "b", "enabled": true},
+				},
+			},
+		},
+		{
+			Expression: `(set! .items[?(has? .name)].enabled true) .items`,
+			Document: map[string]any{
+				"items": []any{
+					map[string]any{"id": "a"},
+					map[string]any{"name": "b"},
+				},
+			},
+			Expected: []any{
+				map[string]any{"id": "a"},
+				map[string]any{"name": "b", "enabled": true},
+			},
+			ExpectedDocument: map[string]any{
+				"items": []any{
+					map[string]any{"id": "a"},
+					map[string]any{"name": "b", "enabled": true},
+				},
+			},
+		},
 	}
 
 	for _, testcase := range testcases {
@@ -465,9 +540,16 @@ func TestDeleteFunction(t *testing.T) {
 			Expected:         []any{"a", "c"},
 			ExpectedDocument: []any{"a", "c"},
 		},
+		// negative indices count from the end
+		{
+			Expression:       `(delete .[-1])`,
+			Document:         []any{"a", "b", "c"},
+			Expected:         []any{"a", "b"},
+			ExpectedDocument: []any{"a", "b", "c"},
+		},
 		// vector bounds are checked
 		{
-			Expression: `(delete .[-1])`,
+			Expression: `(delete .[-4])`,
 			Document:   []any{"a", "b", "c"},
 			Invalid:    true,
 		},
@@ -567,6 +649,32 @@ func TestDeleteFunction(t *testing.T) {
 				},
 			},
 		},
+		// delete multiple values at once
+		{
+			Expression: `(delete! ..tmp)`,
+			Document: map[string]any{
+				"tmp":   1,
+				"items": []any{map[string]any{"tmp": 2, "name": "a"}},
+			},
+			Expected: map[string]any{
+				"items": []any{map[string]any{"name": "a"}},
+			},
+			ExpectedDocument: map[string]any{
+				"items": []any{map[string]any{"name": "a"}},
+			},
+		},
+		{
+			Expression: `(delete! .items[1:])`,
+			Document: map[string]any{
+				"items": []any{"a", "b", "c"},
+			},
+			Expected: map[string]any{
+				"items": []any{"a"},
+			},
+			ExpectedDocument: map[string]any{
+				"items": []any{"a"},
+			},
+		},
 	}
 
 	for _, testcase := range testcases {
@@ -575,6 +683,128 @@ func TestDeleteFunction(t *testing.T) {
 	}
 }
 
+func TestSetAndDeleteRecordChanges(t *testing.T) {
+	testcases := []struct {
+		name     string
+		document any
+		script   string
+		expected []types.Change
+		result   any
+	}{
+		{
+			name: "simple paths",
+			document: map[string]any{
+				"name":  "a",
+				"tmp":   true,
+				"items": []any{map[string]any{"tag": "x"}, map[string]any{"tag": "y"}},
+			},
+			script: `(set! .name "b") (delete! .tmp) (delete! .missing) (set! $var 1) (set! .items[-1].tag "z") (set! . {})`,
+			expected: []types.Change{
+				{Operation: types.SetOperation, Path: jsonpath.Path{"name"}, Value: "b"},
+				{Operation: types.DeleteOperation, Path: jsonpath.Path{"tmp"}},
+				{Operation: types.SetOperation, Path: jsonpath.Path{"items", 1, "tag"}, Value: "z"},
+				{Operation: types.SetOperation, Path: jsonpath.Path{}, Value: map[string]any{}},
+			},
+			result: map[string]any{},
+		},
+		{
+			name: "wildcards are expanded",
+			document: map[string]any{
+				"items": []any{map[string]any{"tag": "x"}, map[string]any{"tag": "y"}},
+			},
+			script: `(set! .items[*].tag "z")`,
+			expected: []types.Change{
+				{Operation: types.SetOperation, Path: jsonpath.Path{"items", 0, "tag"}, Value: "z"},
+				{Operation: types.SetOperation, Path: jsonpath.Path{"items", 1, "tag"}, Value: "z"},
+			},
+			result: map[string]any{
+				"items": []any{map[string]any{"tag": "z"}, map[string]any{"tag": "z"}},
+			},
+		},
+		{
+			name: "filters are expanded",
+			document: map[string]any{
+				"items": []any{map[string]any{"x": 1, "flag": true}, map[string]any{"x": 2}, map[string]any{"x": 3, "flag": true}},
+			},
+			script: `(set! .items[?(has? .flag)].x 5) (delete! .items[?(has? .flag)].flag)`,
+			expected: []types.Change{
+				{Operation: types.SetOperation, Path: jsonpath.Path{"items", 0, "x"}, Value: int64(5)},
+				{Operation: types.SetOperation, Path: jsonpath.Path{"items", 2, "x"}, Value: int64(5)},
+				{Operation: types.DeleteOperation, Path: jsonpath.Path{"items", 0, "flag"}},
+				{Operation: types.DeleteOperation, Path: jsonpath.Path{"items", 2, "flag"}},
+			},
+			result: map[string]any{
+				"items": []any{map[string]any{"x": int64(5)}, map[string]any{"x": 2}, map[string]any{"x": int64(5)}},
+			},
+		},
+		{
+			name: "deleted items are recorded from the end",
+			document: map[string]any{
+				"items": []any{map[string]any{"flag": true}, map[string]any{}, map[string]any{"flag": true}},
+			},
+			script: `(delete! .items[?(has? .flag)])`,
+			expected: []types.Change{
+				{Operation: types.DeleteOperation, Path: jsonpath.Path{"items", 2}},
+				{Operation: types.DeleteOperation, Path: jsonpath.Path{"items", 0}},
+			},
+			result: map[string]any{
+				"items": []any{map[string]any{}},
+			},
+		},
+		{
+			// the path expression must only be evaluated once
+			name: "side effects in path expressions",
+			document: map[string]any{
+				"items": []any{map[string]any{"x": 1}, map[string]any{"x": 2}, map[string]any{"x": 3}},
+			},
+			script: `(set! $once false) (set! .items[(if $once 2 (do (set! $once true) 1))].x 7) .`,
+			expected: []types.Change{
+				{Operation: types.SetOperation, Path: jsonpath.Path{"items", 1, "x"}, Value: int64(7)},
+			},
+			result: map[string]any{
+				"items": []any{map[string]any{"x": 1}, map[string]any{"x": int64(7)}, map[string]any{"x": 3}},
+			},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			doc, err := types.NewDocument(testcase.document)
+			if err != nil {
+				t.Fatalf("Failed to create document: %v", err)
+			}
+
+			doc.TrackChanges()
+
+			ctx, err := types.NewContext(interpreter.New(), nil, doc, nil, Functions, nil)
+			if err != nil {
+				t.Fatalf("Failed to create context: %v", err)
+			}
+
+			got, err := parser.ParseReader("test.go", strings.NewReader(testcase.script))
+			if err != nil {
+				t.Fatalf("Failed to parse: %v", err)
+			}
+
+			program := got.(ast.Program)
+
+			result, err := ctx.Runtime().EvalProgram(ctx, &program)
+			if err != nil {
+				t.Fatalf("Failed to run program: %v", err)
+			}
+
+			if !cmp.Equal(testcase.result, result) {
+				t.Fatalf("Unexpected result:\n%s", cmp.Diff(testcase.result, result))
+			}
+
+			changes := ctx.GetDocument().Changes()
+			if !cmp.Equal(testcase.expected, changes) {
+				t.Fatalf("Unexpected changes:\n%s", cmp.Diff(testcase.expected, changes))
+			}
+		})
+	}
+}
+
 func TestDoFunction(t *testing.T) {
 	testcases := []testutil.Testcase{
 		{
@@ -656,6 +886,29 @@ func TestDefaultFunction(t *testing.T) {
 			Expression: `(default false (error "foo"))`,
 			Invalid:    true,
 		},
+
+		// missing keys and indices count as empty, but type errors do not
+
+		{
+			Expression: `(default {foo "bar"}.missing 3)`,
+			Expected:   int64(3),
+		},
+		{
+			Expression: `(default [1 2][5] 3)`,
+			Expected:   int64(3),
+		},
+		{
+			Expression: `(default {foo "bar"}.foo 3)`,
+			Expected:   "bar",
+		},
+		{
+			Expression: `(default {foo "bar"}.foo[0] 3)`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(default [1 2]["foo"] 3)`,
+			Invalid:    true,
+		},
 	}
 
 	for _, testcase := range testcases {
@@ -707,10 +960,25 @@ func TestTryFunction(t *testing.T) {
 			Expression: `(try (error "foo") (error "foo"))`,
 			Invalid:    true,
 		},
+
+		// cancellations are never swallowed
+
+		{
+			Expression: `(try (cancel))`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(try (cancel) "fallback")`,
+			Invalid:    true,
+		},
 	}
 
+	funcs := Functions.DeepCopy().Set("cancel", functions.NewBuilder(func(ctx types.Context) (any, error) {
+		return nil, fmt.Errorf("aborted: %w", context.Canceled)
+	}).Build())
+
 	for _, testcase := range testcases {
-		testcase.Functions = Functions
+		testcase.Functions = funcs
 		t.Run(testcase.String(), testcase.Run)
 	}
 }
@@ -853,10 +1121,9 @@ func TestHasFunction(t *testing.T) {
 			ExpectedDocument: testObjDocument,
 		},
 		{
-			Expression:       `(has? .[0])`,
-			Expected:         false,
-			Document:         testObjDocument,
-			ExpectedDocument: testObjDocument,
+			Expression: `(has? .[0])`,
+			Invalid:    true,
+			Document:   testObjDocument,
 		},
 		{
 			Expression:       `(has? .aString)`,
@@ -902,31 +1169,53 @@ func TestHasFunction(t *testing.T) {
 			ExpectedDocument: testObjDocument,
 		},
 		{
-			Expression:       `(has? .aList.invalidObjKey)`,
-			Expected:         false,
+			Expression: `(has? .aList.invalidObjKey)`,
+			Invalid:    true,
+			Document:   testObjDocument,
+		},
+		{
+			Expression:       `(has? .anObject)`,
+			Expected:         true,
 			Document:         testObjDocument,
 			ExpectedDocument: testObjDocument,
 		},
 		{
-			Expression:       `(has? .anObject)`,
+			Expression: `(has? .anObject[99])`,
+			Invalid:    true,
+			Document:   testObjDocument,
+		},
+		{
+			Expression:       `(has? .anObject.key1)`,
 			Expected:         true,
 			Document:         testObjDocument,
 			ExpectedDocument: testObjDocument,
 		},
 		{
-			Expression:       `(has? .anObject[99])`,
+			Expression:       `(has? .anObject.key99)`,
 			Expected:         false,
 			Document:         testObjDocument,
 			ExpectedDocument: testObjDocument,
 		},
 		{
-			Expression:       `(has? .anObject.key1)`,
+			Expression:       `(has? .anObject?.key1)`,
 			Expected:         true,
 			Document:         testObjDocument,
 			ExpectedDocument: testObjDocument,
 		},
 		{
-			Expression:       `(has? .anObject.key99)`,
+			Expression:       `(has? .spec?.x)`,
+			Expected:         false,
+			Document:         testObjDocument,
+			ExpectedDocument: testObjDocument,
+		},
+		{
+			Expression:       `(has? .anObject.key2?.x)`,
+			Expected:         false,
+			Document:         testObjDocument,
+			ExpectedDocument: testObjDocument,
+		},
+		{
+			Expression:       `(has? .aList[99]?)`,
 			Expected:         false,
 			Document:         testObjDocument,
 			ExpectedDocument: testObjDocument,
@@ -943,6 +1232,30 @@ func TestHasFunction(t *testing.T) {
 			Document:         testObjDocument,
 			ExpectedDocument: testObjDocument,
 		},
+		{
+			Expression:       `(has? .anObject.key3[*].foo)`,
+			Expected:         true,
+			Document:         testObjDocument,
+			ExpectedDocument: testObjDocument,
+		},
+		{
+			Expression:       `(has? .anObject.key3[*].bar)`,
+			Expected:         false,
+			Document:         testObjDocument,
+			ExpectedDocument: testObjDocument,
+		},
+		{
+			Expression:       `(has? ..foo)`,
+			Expected:         true,
+			Document:         testObjDocument,
+			ExpectedDocument: testObjDocument,
+		},
+		{
+			Expression:       `(has? .aList[-1])`,
+			Expected:         true,
+			Document:         testObjDocument,
+			ExpectedDocument: testObjDocument,
+		},
 
 		// global document is an array
 
@@ -953,10 +1266,9 @@ func TestHasFunction(t *testing.T) {
 			ExpectedDocument: testVecDocument,
 		},
 		{
-			Expression:       `(has? .key)`,
-			Expected:         false,
-			Document:         testVecDocument,
-			ExpectedDocument: testVecDocument,
+			Expression: `(has? .key)`,
+			Invalid:    true,
+			Document:   testVecDocument,
 		},
 		{
 			Expression:       `(has? .[2].foo)`,
@@ -980,10 +1292,9 @@ func TestHasFunction(t *testing.T) {
 			ExpectedDocument: nil,
 		},
 		{
-			Expression:       `(has? .foo)`,
-			Expected:         false,
-			Document:         "testdata",
-			ExpectedDocument: "testdata",
+			Expression: `(has? .foo)`,
+			Invalid:    true,
+			Document:   "testdata",
 		},
 		{
 			Expression:       `(has? .)`,
@@ -1012,12 +1323,12 @@ func TestHasFunction(t *testing.T) {
 		},
 		{
 			Expression: `(has? $myvar.foo)`,
-			Expected:   false,
+			Invalid:    true,
 			Variables:  testVariables,
 		},
 		{
 			Expression: `(has? $myvar[0])`,
-			Expected:   false,
+			Invalid:    true,
 			Variables:  testVariables,
 		},
 		{
@@ -1057,6 +1368,18 @@ func TestHasFunction(t *testing.T) {
 			Expression: `(has? {foo "bar"}.bar)`,
 			Expected:   false,
 		},
+		{
+			Expression: `(has? {foo null}.foo.bar)`,
+			Expected:   false,
+		},
+		{
+			Expression: `(has? {foo "bar"}.foo.bar)`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(has? {foo {}}.foo?.bar)`,
+			Expected:   false,
+		},
 
 		// follow a path expression on a tuple node
 		// (don't even need "set!" here)
@@ -1075,7 +1398,7 @@ func TestHasFunction(t *testing.T) {
 		},
 		{
 			Expression: `(has? (set $foo {foo "bar"})[0])`,
-			Expected:   false,
+			Invalid:    true,
 		},
 	}
 
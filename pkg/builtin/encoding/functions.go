@@ -14,10 +14,10 @@ import (
 
 var (
 	Functions = types.Functions{
-		"to-base64":   functions.NewBuilder(toBase64Function).WithDescription("apply base64 encoding to the given string").Build(),
-		"from-base64": functions.NewBuilder(fromBase64Function).WithDescription("decode a base64 encoded string").Build(),
-		"to-json":     functions.NewBuilder(toJSONFunction).WithDescription("encode the given value using JSON").Build(),
-		"from-json":   functions.NewBuilder(fromJSONFunction).WithDescription("decode a JSON string").Build(),
+		"to-base64":   functions.NewBuilder(toBase64Function).Pure().WithDescription("apply base64 encoding to the given string").Build(),
+		"from-base64": functions.NewBuilder(fromBase64Function).Pure().WithDescription("decode a base64 encoded string").Build(),
+		"to-json":     functions.NewBuilder(toJSONFunction).Pure().WithDescription("encode the given value using JSON").Build(),
+		"from-json":   functions.NewBuilder(fromJSONFunction).Pure().WithDescription("decode a JSON string").Build(),
 	}
 )
 
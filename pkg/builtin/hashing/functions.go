@@ -18,9 +18,9 @@ import (
 
 var (
 	Functions = types.Functions{
-		"sha1":   functions.NewBuilder(sha1Function).WithDescription("return the lowercase hex representation of the SHA-1 hash").Build(),
-		"sha256": functions.NewBuilder(sha256Function).WithDescription("return the lowercase hex representation of the SHA-256 hash").Build(),
-		"sha512": functions.NewBuilder(sha512Function).WithDescription("return the lowercase hex representation of the SHA-512 hash").Build(),
+		"sha1":   functions.NewBuilder(sha1Function).Pure().WithDescription("return the lowercase hex representation of the SHA-1 hash").Build(),
+		"sha256": functions.NewBuilder(sha256Function).Pure().WithDescription("return the lowercase hex representation of the SHA-256 hash").Build(),
+		"sha512": functions.NewBuilder(sha512Function).Pure().WithDescription("return the lowercase hex representation of the SHA-512 hash").Build(),
 	}
 )
 
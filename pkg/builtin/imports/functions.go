@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package imports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/lang/parser"
+	"go.xrstf.de/rudi/pkg/runtime/functions"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+)
+
+var (
+	Functions = types.Functions{
+		"import": functions.NewBuilder(importFunction, importWithNamespaceFunction).WithDescription("loads a library and makes its functions available with a namespace prefix").Build(),
+	}
+)
+
+// importStackKey is used to store the names of all libraries that are currently
+// being imported in the Go context, to detect import cycles.
+type importStackKey struct{}
+
+func importFunction(ctx types.Context, libPath string) (any, error) {
+	base := path.Base(libPath)
+
+	return importWithNamespaceFunction(ctx, libPath, strings.TrimSuffix(base, path.Ext(base)))
+}
+
+func importWithNamespaceFunction(ctx types.Context, libPath string, namespace string) (any, error) {
+	if !ast.NamespacePattern.MatchString(namespace) {
+		return nil, fmt.Errorf("invalid namespace %q", namespace)
+	}
+
+	loader := ctx.Loader()
+	if loader == nil {
+		return nil, errors.New("no loader configured to import libraries with")
+	}
+
+	goCtx := ctx.GoContext()
+
+	name, source, err := loader.Load(goCtx, libPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %q: %w", libPath, err)
+	}
+
+	stack, _ := goCtx.Value(importStackKey{}).([]string)
+	for i, imported := range stack {
+		if imported == name {
+			cycle := append(append([]string{}, stack[i:]...), name)
+			return nil, fmt.Errorf("import cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	got, err := parser.Parse(name, []byte(source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	program, ok := got.(ast.Program)
+	if !ok {
+		// this should never happen
+		return nil, fmt.Errorf("parsed input is not an ast.Program, but %T", got)
+	}
+
+	// the library gets its own set of functions, so that its functions can
+	// call each other without leaking into the importing program
+	libFuncs := types.NewFunctions()
+	libCtx := ctx.
+		WithRudispaceFunctions(libFuncs).
+		WithGoContext(context.WithValue(goCtx, importStackKey{}, append(append([]string{}, stack...), name)))
+
+	if _, err := ctx.Runtime().EvalProgram(libCtx, &program); err != nil {
+		return nil, fmt.Errorf("failed to evaluate %s: %w", name, err)
+	}
+
+	for funcName, function := range libFuncs {
+		// do not re-export functions the library imported itself
+		if strings.Contains(funcName, "/") {
+			continue
+		}
+
+		ctx.SetRudispaceFunction(ast.Identifier{Namespace: namespace, Name: funcName}.FullName(), importedFunction{
+			function: function,
+			libFuncs: libFuncs,
+		})
+	}
+
+	return nil, nil
+}
+
+// importedFunction is a function defined by a library. Its arguments are
+// evaluated in the caller's context, but the function itself is evaluated
+// using the library's functions.
+type importedFunction struct {
+	function types.Function
+	libFuncs types.Functions
+}
+
+var _ types.Function = importedFunction{}
+
+func (f importedFunction) Description() string {
+	return f.function.Description()
+}
+
+func (f importedFunction) Evaluate(ctx types.Context, args []ast.Expression) (any, error) {
+	values := make([]ast.Expression, len(args))
+	for i, arg := range args {
+		value, err := ctx.Runtime().EvalExpression(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+
+		values[i] = types.MakeShim(value)
+	}
+
+	return f.function.Evaluate(ctx.WithRudispaceFunctions(f.libFuncs), values)
+}
@@ -0,0 +1,204 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package imports_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"go.xrstf.de/rudi/pkg/builtin/core"
+	"go.xrstf.de/rudi/pkg/builtin/imports"
+	"go.xrstf.de/rudi/pkg/builtin/lists"
+	"go.xrstf.de/rudi/pkg/builtin/rudifunc"
+	"go.xrstf.de/rudi/pkg/builtin/strings"
+	"go.xrstf.de/rudi/pkg/runtime/compiler"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+	"go.xrstf.de/rudi/pkg/testutil"
+)
+
+var libraries = fstest.MapFS{
+	"lib/strings.rudi": {Data: []byte(`
+(func! exclaim [s] (concat "" $s "!"))
+(func! shout [s] (exclaim (to-upper $s)))
+(set! $secret "hidden")
+`)},
+	"lib/doc.rudi": {Data: []byte(`
+(func! name [] .name)
+(func! rename [n] (set! .name $n))
+`)},
+	"lib/nested.rudi": {Data: []byte(`
+(import "lib/strings.rudi" "str")
+(func! greet [n] (str/shout (concat " " "hello" $n)))
+`)},
+	"lib/my.lib.rudi": {Data: []byte(`(func! foo [] 1)`)},
+	"cycle/a.rudi":    {Data: []byte(`(import "cycle/b.rudi")`)},
+	"cycle/b.rudi":    {Data: []byte(`(import "cycle/a.rudi")`)},
+	"cycle/self.rudi": {Data: []byte(`(import "cycle/self.rudi")`)},
+	"broken.rudi":     {Data: []byte(`(func! foo [`)},
+}
+
+func TestImportFunction(t *testing.T) {
+	testcases := []testutil.Testcase{
+		{
+			Expression: `(import)`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(import "lib/strings.rudi" "str" "too-many")`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(import "lib/missing.rudi")`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(import "broken.rudi")`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(import "lib/strings.rudi")`,
+			Expected:   nil,
+		},
+		{
+			Expression: `(import "lib/strings.rudi") (strings/shout "hello")`,
+			Expected:   "HELLO!",
+		},
+		{
+			Expression: `(import "lib/strings.rudi" "str") (str/shout "hello")`,
+			Expected:   "HELLO!",
+		},
+		{
+			Expression: `(import "lib/strings.rudi" "str") (strings/shout "hello")`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(import "lib/strings.rudi" "in/valid")`,
+			Invalid:    true,
+		},
+		{
+			// the namespace cannot be derived from this filename
+			Expression: `(import "lib/my.lib.rudi")`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(import "lib/my.lib.rudi" "mylib") (mylib/foo)`,
+			Expected:   int64(1),
+		},
+		{
+			// functions and variables do not leak without a prefix
+			Expression: `(import "lib/strings.rudi") (shout "hello")`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(import "lib/strings.rudi") $secret`,
+			Invalid:    true,
+		},
+		{
+			// arguments are evaluated in the caller's context
+			Expression: `(func! exclaim [s] "wrong") (func! word [] "hello") (import "lib/strings.rudi") (strings/shout (word))`,
+			Expected:   "HELLO!",
+		},
+		{
+			// imported functions can be used like any other function
+			Expression: `(import "lib/strings.rudi") (map ["a" "b"] strings/exclaim)`,
+			Expected:   []any{"a!", "b!"},
+		},
+		{
+			Expression:       `(import "lib/doc.rudi") (doc/name)`,
+			Document:         map[string]any{"name": "doc"},
+			Expected:         "doc",
+			ExpectedDocument: map[string]any{"name": "doc"},
+		},
+		{
+			Expression:       `(import "lib/doc.rudi") (doc/rename "new")`,
+			Document:         map[string]any{"name": "doc"},
+			Expected:         map[string]any{"name": "new"},
+			ExpectedDocument: map[string]any{"name": "new"},
+		},
+		{
+			Expression: `(import "lib/nested.rudi") (nested/greet "world")`,
+			Expected:   "HELLO WORLD!",
+		},
+		{
+			// functions imported by a library are not re-exported
+			Expression: `(import "lib/nested.rudi") (nested/str/shout "hello")`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(import "cycle/a.rudi")`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(import "cycle/self.rudi")`,
+			Invalid:    true,
+		},
+		{
+			// importing a library twice is not a cycle
+			Expression: `(import "lib/strings.rudi") (import "lib/strings.rudi" "str") (str/shout "hello")`,
+			Expected:   "HELLO!",
+		},
+	}
+
+	funcs := types.NewFunctions().
+		Add(core.Functions).
+		Add(strings.Functions).
+		Add(lists.Functions).
+		Add(rudifunc.Functions).
+		Add(imports.Functions)
+
+	for _, testcase := range testcases {
+		testcase.Functions = funcs
+		testcase.Loader = types.NewFSLoader(libraries)
+		t.Run(testcase.String(), testcase.Run)
+	}
+
+	for _, testcase := range testcases {
+		testcase.Functions = funcs
+		testcase.Loader = types.NewFSLoader(libraries)
+		testcase.Runtime = compiler.New(funcs)
+		t.Run("compiled "+testcase.String(), testcase.Run)
+	}
+}
+
+func TestImportWithoutLoader(t *testing.T) {
+	testcase := testutil.Testcase{
+		Expression: `(import "lib/strings.rudi")`,
+		Functions:  imports.Functions,
+		Invalid:    true,
+	}
+
+	testcase.Run(t)
+}
+
+func TestImportWithLoaderFunc(t *testing.T) {
+	loaded := []string{}
+
+	loader := types.LoaderFunc(func(_ context.Context, path string) (string, string, error) {
+		loaded = append(loaded, path)
+
+		switch path {
+		case "a":
+			return "a", `(import "b") (func! foo [] (b/bar))`, nil
+		case "b":
+			return "b", `(func! bar [] 42)`, nil
+		default:
+			return "", "", errors.New("not found")
+		}
+	})
+
+	testcase := testutil.Testcase{
+		Expression: `(import "a") (a/foo)`,
+		Functions:  types.NewFunctions().Add(rudifunc.Functions).Add(imports.Functions),
+		Loader:     loader,
+		Expected:   int64(42),
+	}
+
+	testcase.Run(t)
+
+	if len(loaded) != 2 || loaded[0] != "a" || loaded[1] != "b" {
+		t.Fatalf("Expected libraries a and b to be loaded, but got %v.", loaded)
+	}
+}
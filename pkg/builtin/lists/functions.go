@@ -361,7 +361,7 @@ func decodeNamingVector(expr ast.Expression) (indexName string, valueName string
 			return "", "", fmt.Errorf("value variable name must be an identifier, got %T", namingVector.Expressions[0])
 		}
 
-		valueName = varNameIdent.Name
+		valueName = varNameIdent.FullName()
 	} else {
 		indexIdent, ok := namingVector.Expressions[0].(ast.Identifier)
 		if !ok {
@@ -373,8 +373,8 @@ func decodeNamingVector(expr ast.Expression) (indexName string, valueName string
 			return "", "", fmt.Errorf("value variable name must be an identifier, got %T", namingVector.Expressions[0])
 		}
 
-		indexName = indexIdent.Name
-		valueName = varNameIdent.Name
+		indexName = indexIdent.FullName()
+		valueName = varNameIdent.FullName()
 
 		if indexName == valueName {
 			return "", "", fmt.Errorf("cannot use %s for both value and index variable", indexName)
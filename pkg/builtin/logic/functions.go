@@ -13,9 +13,9 @@ import (
 
 var (
 	Functions = types.Functions{
-		"and": functions.NewBuilder(andFunction).WithDescription("returns true if all arguments are true").Build(),
-		"or":  functions.NewBuilder(orFunction).WithDescription("returns true if any of the arguments is true").Build(),
-		"not": functions.NewBuilder(notFunction).WithDescription("negates the given argument").Build(),
+		"and": functions.NewBuilder(andFunction).Pure().WithDescription("returns true if all arguments are true").Build(),
+		"or":  functions.NewBuilder(orFunction).Pure().WithDescription("returns true if any of the arguments is true").Build(),
+		"not": functions.NewBuilder(notFunction).Pure().WithDescription("negates the given argument").Build(),
 	}
 )
 
@@ -12,10 +12,10 @@ import (
 )
 
 var (
-	addRudiFunction      = functions.NewBuilder(integerAddFunction, numberAddFunction).WithDescription("returns the sum of all of its arguments").Build()
-	subRudiFunction      = functions.NewBuilder(integerSubFunction, numberSubFunction).WithDescription("returns arg1 - arg2 - .. - argN").Build()
-	multiplyRudiFunction = functions.NewBuilder(integerMultFunction, numberMultFunction).WithDescription("returns the product of all of its arguments").Build()
-	divideRudiFunction   = functions.NewBuilder(numberDivFunction).WithDescription("returns arg1 / arg2 / .. / argN (always a floating point division, regardless of arguments)").Build()
+	addRudiFunction      = functions.NewBuilder(integerAddFunction, numberAddFunction).Pure().WithDescription("returns the sum of all of its arguments").Build()
+	subRudiFunction      = functions.NewBuilder(integerSubFunction, numberSubFunction).Pure().WithDescription("returns arg1 - arg2 - .. - argN").Build()
+	multiplyRudiFunction = functions.NewBuilder(integerMultFunction, numberMultFunction).Pure().WithDescription("returns the product of all of its arguments").Build()
+	divideRudiFunction   = functions.NewBuilder(numberDivFunction).Pure().WithDescription("returns arg1 / arg2 / .. / argN (always a floating point division, regardless of arguments)").Build()
 
 	Functions = types.Functions{
 		// These are the main functions, but within the documentation these are
@@ -10,9 +10,11 @@ import (
 	"go.xrstf.de/rudi/pkg/builtin/datetime"
 	"go.xrstf.de/rudi/pkg/builtin/encoding"
 	"go.xrstf.de/rudi/pkg/builtin/hashing"
+	"go.xrstf.de/rudi/pkg/builtin/imports"
 	"go.xrstf.de/rudi/pkg/builtin/lists"
 	"go.xrstf.de/rudi/pkg/builtin/logic"
 	"go.xrstf.de/rudi/pkg/builtin/math"
+	"go.xrstf.de/rudi/pkg/builtin/paths"
 	"go.xrstf.de/rudi/pkg/builtin/rudifunc"
 	"go.xrstf.de/rudi/pkg/builtin/strings"
 	"go.xrstf.de/rudi/pkg/builtin/types"
@@ -31,10 +33,14 @@ var (
 			Add(hashing.Functions).
 			Add(encoding.Functions).
 			Add(datetime.Functions).
+			Add(paths.Functions).
 			Add(types.Functions)
 
 	RudifuncFunctions = rudifunc.Functions
 
+	ImportFunctions = imports.Functions
+
 	UnsafeFunctions = evaltypes.Functions{}.
-			Add(RudifuncFunctions)
+			Add(RudifuncFunctions).
+			Add(ImportFunctions)
 )
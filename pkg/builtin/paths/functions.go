@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package paths
+
+import (
+	"go.xrstf.de/rudi/pkg/jsonpath"
+	"go.xrstf.de/rudi/pkg/runtime/functions"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+)
+
+var (
+	Functions = types.Functions{
+		"get-pointer": functions.NewBuilder(getPointerFunction).Pure().WithDescription("returns the value at the given JSON Pointer (RFC 6901)").Build(),
+		"set-pointer": functions.NewBuilder(setPointerFunction).Pure().WithDescription("sets the value at the given JSON Pointer (RFC 6901)").Build(),
+		"query":       functions.NewBuilder(queryFunction).Pure().WithDescription("returns all values matching the given JSONPath query (RFC 9535)").Build(),
+	}
+)
+
+func getPointerFunction(target any, pointer string) (any, error) {
+	path, err := jsonpath.ResolvePointer(target, pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonpath.Get(target, path)
+}
+
+func setPointerFunction(target any, pointer string, value any) (any, error) {
+	// this does not modify the original value, updating variables or the
+	// document is done by the bang modifier
+	return jsonpath.SetPointer(target, pointer, value)
+}
+
+func queryFunction(target any, query string) (any, error) {
+	return jsonpath.Query(target, query)
+}
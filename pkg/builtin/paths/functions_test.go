@@ -0,0 +1,185 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package paths
+
+import (
+	"testing"
+
+	"go.xrstf.de/rudi/pkg/builtin/core"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+	"go.xrstf.de/rudi/pkg/testutil"
+)
+
+func testDocument() any {
+	return map[string]any{
+		"spec": map[string]any{
+			"containers": []any{
+				map[string]any{"name": "a", "image": "img-a"},
+				map[string]any{"name": "b", "image": "img-b"},
+			},
+		},
+	}
+}
+
+func TestGetPointerFunction(t *testing.T) {
+	testcases := []testutil.Testcase{
+		{
+			Expression: `(get-pointer)`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(get-pointer {})`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(get-pointer {} 1)`,
+			Invalid:    true,
+		},
+		{
+			Expression:       `(get-pointer . "/spec/containers/1/image")`,
+			Document:         testDocument(),
+			ExpectedDocument: testDocument(),
+			Expected:         "img-b",
+		},
+		{
+			Expression: `(get-pointer {"0" "zero" "a/b" "slash"} "/0")`,
+			Expected:   "zero",
+		},
+		{
+			Expression: `(get-pointer {"0" "zero" "a/b" "slash"} "/a~1b")`,
+			Expected:   "slash",
+		},
+		{
+			Expression: `(get-pointer [1 2] "")`,
+			Expected:   []any{int64(1), int64(2)},
+		},
+		{
+			Expression: `(get-pointer [1 2] "/foo")`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(get-pointer [1 2] "/5")`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(get-pointer [1 2] "no-slash")`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(default (get-pointer {} "/missing") "fallback")`,
+			Expected:   "fallback",
+		},
+	}
+
+	for _, testcase := range testcases {
+		testcase.Functions = types.Functions{}.Add(Functions).Add(core.Functions)
+		t.Run(testcase.String(), testcase.Run)
+	}
+}
+
+func TestSetPointerFunction(t *testing.T) {
+	testcases := []testutil.Testcase{
+		{
+			Expression: `(set-pointer {} "/foo")`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(set-pointer {} "/foo/bar" 1)`,
+			Expected:   map[string]any{"foo": map[string]any{"bar": int64(1)}},
+		},
+		{
+			Expression: `(set-pointer [1 2] "/1" "x")`,
+			Expected:   []any{int64(1), "x"},
+		},
+		{
+			Expression: `(set-pointer [1 2] "/2" "x")`,
+			Expected:   []any{int64(1), int64(2), "x"},
+		},
+		{
+			Expression: `(set-pointer [1 2] "/-" "x")`,
+			Expected:   []any{int64(1), int64(2), "x"},
+		},
+		{
+			Expression: `(set-pointer [1 2] "/3" "x")`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(get-pointer [1 2] "/-")`,
+			Invalid:    true,
+		},
+		{
+			// without the bang modifier, the document is not changed
+			Expression:       `(set-pointer . "/spec/containers/0/image" "new")`,
+			Document:         testDocument(),
+			ExpectedDocument: testDocument(),
+			Expected: map[string]any{
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{"name": "a", "image": "new"},
+						map[string]any{"name": "b", "image": "img-b"},
+					},
+				},
+			},
+		},
+		{
+			Expression: `(set-pointer! . "/spec/containers/0/image" "new")`,
+			Document:   testDocument(),
+			ExpectedDocument: map[string]any{
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{"name": "a", "image": "new"},
+						map[string]any{"name": "b", "image": "img-b"},
+					},
+				},
+			},
+			Expected: map[string]any{
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{"name": "a", "image": "new"},
+						map[string]any{"name": "b", "image": "img-b"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, testcase := range testcases {
+		testcase.Functions = Functions
+		t.Run(testcase.String(), testcase.Run)
+	}
+}
+
+func TestQueryFunction(t *testing.T) {
+	testcases := []testutil.Testcase{
+		{
+			Expression: `(query .)`,
+			Invalid:    true,
+		},
+		{
+			Expression:       `(query . "$.spec.containers[*].image")`,
+			Document:         testDocument(),
+			ExpectedDocument: testDocument(),
+			Expected:         []any{"img-a", "img-b"},
+		},
+		{
+			Expression:       `(query . "$..containers[?@.name == 'b'].image")`,
+			Document:         testDocument(),
+			ExpectedDocument: testDocument(),
+			Expected:         []any{"img-b"},
+		},
+		{
+			Expression: `(query {foo "bar"} "$.missing")`,
+			Expected:   []any{},
+		},
+		{
+			Expression: `(query {foo "bar"} "foo")`,
+			Invalid:    true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		testcase.Functions = Functions
+		t.Run(testcase.String(), testcase.Run)
+	}
+}
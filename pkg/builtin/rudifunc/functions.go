@@ -37,11 +37,11 @@ func funcFunction(ctx types.Context, name ast.Expression, namingVector ast.Expre
 		if !ok {
 			return nil, fmt.Errorf("parameter vector must contain only identifiers, got %T instead", param)
 		}
-		paramNames = append(paramNames, paramIdent.Name)
+		paramNames = append(paramNames, paramIdent.FullName())
 	}
 
 	return rudispaceFunc{
-		name:   nameIdent.Name,
+		name:   nameIdent.FullName(),
 		params: paramNames,
 		body:   body,
 	}, nil
@@ -15,22 +15,22 @@ import (
 var (
 	Functions = types.Functions{
 		// these ones also work with lists
-		"len":       functions.NewBuilder(stringLenFunction, vectorLenFunction, objectLenFunction).WithDescription("returns the length of a string, vector or object").Build(),
-		"append":    functions.NewBuilder(appendToVectorFunction, appendToStringFunction).WithDescription("appends more strings to a string or arbitrary items into a vector").Build(),
-		"prepend":   functions.NewBuilder(prependToVectorFunction, prependToStringFunction).WithDescription("prepends more strings to a string or arbitrary items into a vector").Build(),
-		"reverse":   functions.NewBuilder(reverseStringFunction, reverseVectorFunction).WithDescription("reverses a string or the elements of a vector").Build(),
-		"contains?": functions.NewBuilder(stringContainsFunction, vectorContainsFunction).WithDescription("returns true if a string contains a substring or a vector contains the given element").Build(),
-
-		"concat":      functions.NewBuilder(concatFunction).WithDescription("concatenates items in a vector using a common glue string").Build(),
-		"split":       functions.NewBuilder(splitFunction, splitnFunction).WithDescription("splits a string into a vector").Build(),
-		"has-prefix?": functions.NewBuilder(hasPrefixFunction).WithDescription("returns true if the given string has the prefix").Build(),
-		"has-suffix?": functions.NewBuilder(hasSuffixFunction).WithDescription("returns true if the given string has the suffix").Build(),
-		"trim-prefix": functions.NewBuilder(trimPrefixFunction).WithDescription("removes the prefix from the string, if it exists").Build(),
-		"trim-suffix": functions.NewBuilder(trimSuffixFunction).WithDescription("removes the suffix from the string, if it exists").Build(),
-		"to-lower":    functions.NewBuilder(toLowerFunction).WithDescription("returns the lowercased version of the given string").Build(),
-		"to-upper":    functions.NewBuilder(toUpperFunction).WithDescription("returns the uppercased version of the given string").Build(),
-		"trim":        functions.NewBuilder(trimFunction).WithDescription("returns the given whitespace with leading/trailing whitespace removed").Build(),
-		"replace":     functions.NewBuilder(replaceAllFunction, replaceLimitFunction).WithDescription("returns a copy of a string with the a substring replaced by another").Build(),
+		"len":       functions.NewBuilder(stringLenFunction, vectorLenFunction, objectLenFunction).Pure().WithDescription("returns the length of a string, vector or object").Build(),
+		"append":    functions.NewBuilder(appendToVectorFunction, appendToStringFunction).Pure().WithDescription("appends more strings to a string or arbitrary items into a vector").Build(),
+		"prepend":   functions.NewBuilder(prependToVectorFunction, prependToStringFunction).Pure().WithDescription("prepends more strings to a string or arbitrary items into a vector").Build(),
+		"reverse":   functions.NewBuilder(reverseStringFunction, reverseVectorFunction).Pure().WithDescription("reverses a string or the elements of a vector").Build(),
+		"contains?": functions.NewBuilder(stringContainsFunction, vectorContainsFunction).Pure().WithDescription("returns true if a string contains a substring or a vector contains the given element").Build(),
+
+		"concat":      functions.NewBuilder(concatFunction).Pure().WithDescription("concatenates items in a vector using a common glue string").Build(),
+		"split":       functions.NewBuilder(splitFunction, splitnFunction).Pure().WithDescription("splits a string into a vector").Build(),
+		"has-prefix?": functions.NewBuilder(hasPrefixFunction).Pure().WithDescription("returns true if the given string has the prefix").Build(),
+		"has-suffix?": functions.NewBuilder(hasSuffixFunction).Pure().WithDescription("returns true if the given string has the suffix").Build(),
+		"trim-prefix": functions.NewBuilder(trimPrefixFunction).Pure().WithDescription("removes the prefix from the string, if it exists").Build(),
+		"trim-suffix": functions.NewBuilder(trimSuffixFunction).Pure().WithDescription("removes the suffix from the string, if it exists").Build(),
+		"to-lower":    functions.NewBuilder(toLowerFunction).Pure().WithDescription("returns the lowercased version of the given string").Build(),
+		"to-upper":    functions.NewBuilder(toUpperFunction).Pure().WithDescription("returns the uppercased version of the given string").Build(),
+		"trim":        functions.NewBuilder(trimFunction).Pure().WithDescription("returns the given whitespace with leading/trailing whitespace removed").Build(),
+		"replace":     functions.NewBuilder(replaceAllFunction, replaceLimitFunction).Pure().WithDescription("returns a copy of a string with the a substring replaced by another").Build(),
 	}
 )
 
@@ -121,6 +121,26 @@ func TestAppendFunction(t *testing.T) {
 			Expression: `(append "foo" "bar" "test")`,
 			Expected:   "foobartest",
 		},
+		{
+			// the bang modifier applies the function to every match
+			Expression: `(append! .items[*].tags "new")`,
+			Document: map[string]any{
+				"items": []any{
+					map[string]any{"tags": []any{"a"}},
+					map[string]any{"tags": []any{}},
+				},
+			},
+			Expected: []any{
+				[]any{"a", "new"},
+				[]any{"new"},
+			},
+			ExpectedDocument: map[string]any{
+				"items": []any{
+					map[string]any{"tags": []any{"a", "new"}},
+					map[string]any{"tags": []any{"new"}},
+				},
+			},
+		},
 	}
 
 	for _, testcase := range testcases {
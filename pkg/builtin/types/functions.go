@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"go.xrstf.de/rudi/pkg/coalescing"
+	"go.xrstf.de/rudi/pkg/jsonpath"
 	"go.xrstf.de/rudi/pkg/runtime/functions"
 	"go.xrstf.de/rudi/pkg/runtime/types"
 )
@@ -15,13 +16,13 @@ var (
 	humaneCoalescer = coalescing.NewHumane()
 
 	Functions = types.Functions{
-		"type-of": functions.NewBuilder(typeOfFunction).WithDescription(`returns the type of a given value (e.g. "string" or "number")`).Build(),
+		"type-of": functions.NewBuilder(typeOfFunction).Pure().WithDescription(`returns the type of a given value (e.g. "string" or "number")`).Build(),
 
 		// these functions purposefully always uses humane coalescing
-		"to-bool":   functions.NewBuilder(toBoolFunction).WithCoalescer(humaneCoalescer).WithDescription("try to convert the given argument losslessly to a bool").Build(),
-		"to-float":  functions.NewBuilder(toFloatFunction).WithCoalescer(humaneCoalescer).WithDescription("try to convert the given argument losslessly to a float64").Build(),
-		"to-int":    functions.NewBuilder(toIntFunction).WithCoalescer(humaneCoalescer).WithDescription("try to convert the given argument losslessly to an int64").Build(),
-		"to-string": functions.NewBuilder(toStringFunction).WithCoalescer(humaneCoalescer).WithDescription("try to convert the given argument losslessly to a string").Build(),
+		"to-bool":   functions.NewBuilder(toBoolFunction).Pure().WithCoalescer(humaneCoalescer).WithDescription("try to convert the given argument losslessly to a bool").Build(),
+		"to-float":  functions.NewBuilder(toFloatFunction).Pure().WithCoalescer(humaneCoalescer).WithDescription("try to convert the given argument losslessly to a float64").Build(),
+		"to-int":    functions.NewBuilder(toIntFunction).Pure().WithCoalescer(humaneCoalescer).WithDescription("try to convert the given argument losslessly to an int64").Build(),
+		"to-string": functions.NewBuilder(toStringFunction).Pure().WithCoalescer(humaneCoalescer).WithDescription("try to convert the given argument losslessly to a string").Build(),
 	}
 )
 
@@ -62,6 +63,11 @@ func typeOfFunction(value any) (any, error) {
 		typeName = "vector"
 	case map[string]any:
 		typeName = "object"
+	// custom types like ordered.Object or wrapped Go values
+	case jsonpath.ObjectReader:
+		typeName = "object"
+	case jsonpath.VectorReader:
+		typeName = "vector"
 	default:
 		// should never happen
 		typeName = fmt.Sprintf("%T", value)
@@ -6,6 +6,8 @@ package types
 import (
 	"testing"
 
+	"go.xrstf.de/rudi/pkg/native"
+	"go.xrstf.de/rudi/pkg/ordered"
 	"go.xrstf.de/rudi/pkg/testutil"
 )
 
@@ -318,3 +320,26 @@ func TestTypeOfFunction(t *testing.T) {
 		t.Run(testcase.String(), testcase.Run)
 	}
 }
+
+func TestTypeOfCustomTypes(t *testing.T) {
+	wrapped, err := native.Wrap([]string{"a"})
+	if err != nil {
+		t.Fatalf("Failed to wrap value: %v", err)
+	}
+
+	testcases := map[string]any{
+		"object": ordered.NewObject(),
+		"vector": wrapped,
+	}
+
+	for expected, value := range testcases {
+		typeName, err := typeOfFunction(value)
+		if err != nil {
+			t.Fatalf("Failed to determine type of %T: %v", value, err)
+		}
+
+		if typeName != expected {
+			t.Errorf("Expected %T to be %q, got %q", value, expected, typeName)
+		}
+	}
+}
@@ -72,19 +72,23 @@ func (pedantic) ToString(val any) (string, error) {
 	}
 }
 
-func (pedantic) ToVector(val any) ([]any, error) {
+func (p pedantic) ToVector(val any) ([]any, error) {
 	switch v := val.(type) {
 	case []any:
 		return v, nil
+	case CustomVectorCoalescer:
+		return v.CoalesceToVector(p)
 	default:
 		return nil, fmt.Errorf("cannot coalesce %T into vector", v)
 	}
 }
 
-func (pedantic) ToObject(val any) (map[string]any, error) {
+func (p pedantic) ToObject(val any) (map[string]any, error) {
 	switch v := val.(type) {
 	case map[string]any:
 		return v, nil
+	case CustomObjectCoalescer:
+		return v.CoalesceToObject(p)
 	default:
 		return nil, fmt.Errorf("cannot coalesce %T into object", v)
 	}
@@ -96,23 +96,27 @@ func (strict) ToString(val any) (string, error) {
 	}
 }
 
-func (strict) ToVector(val any) ([]any, error) {
+func (s strict) ToVector(val any) ([]any, error) {
 	switch v := val.(type) {
 	case nil:
 		return []any{}, nil
 	case []any:
 		return v, nil
+	case CustomVectorCoalescer:
+		return v.CoalesceToVector(s)
 	default:
 		return nil, fmt.Errorf("cannot coalesce %T into vector", v)
 	}
 }
 
-func (strict) ToObject(val any) (map[string]any, error) {
+func (s strict) ToObject(val any) (map[string]any, error) {
 	switch v := val.(type) {
 	case nil:
 		return map[string]any{}, nil
 	case map[string]any:
 		return v, nil
+	case CustomObjectCoalescer:
+		return v.CoalesceToObject(s)
 	default:
 		return nil, fmt.Errorf("cannot coalesce %T into object", v)
 	}
@@ -0,0 +1,368 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+// Package diff computes the structural differences between two values, for
+// example a desired and an actual object.
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"go.xrstf.de/rudi/pkg/coalescing"
+	"go.xrstf.de/rudi/pkg/equality"
+	"go.xrstf.de/rudi/pkg/jsonpath"
+)
+
+type Op string
+
+const (
+	OpAdd     Op = "add"
+	OpRemove  Op = "remove"
+	OpReplace Op = "replace"
+)
+
+// Change is a single difference between two values. For added values, Old is
+// nil, for removed values New is nil.
+type Change struct {
+	Path jsonpath.Path
+	Op   Op
+	Old  any
+	New  any
+}
+
+type VectorMode int
+
+const (
+	// OrderedVectors compares vector items by their position, after skipping
+	// over equal items at the start and end of both vectors.
+	OrderedVectors VectorMode = iota
+	// SetVectors ignores the order of vector items and only reports items
+	// that have been added or removed.
+	SetVectors
+	// KeyedVectors matches vector items, which must be objects, by the value
+	// of their Options.Key.
+	KeyedVectors
+)
+
+type Options struct {
+	// IgnoreKeys are object keys that are skipped, regardless of how deeply
+	// nested the object is.
+	IgnoreKeys []string
+	// Vectors determines how vector items are matched to each other.
+	Vectors VectorMode
+	// Key is the object key that identifies items when using KeyedVectors.
+	Key string
+}
+
+// Re-use a pedantic coalescer to detect objects and vectors, so that the
+// actual coalescer is only used to compare scalar values.
+var typeChecker = coalescing.NewPedantic()
+
+// Diff returns all changes necessary to turn left into right. Objects are
+// compared key by key, vectors according to the VectorMode in the options
+// and all other values using the given coalescer, just like equality.Equal.
+// Values that cannot be compared using the coalescer, like a string and a
+// number in strict mode, are reported as replaced.
+//
+// Paths for items in vectors use the index in left for removed items and the
+// index in right for all other changes.
+func Diff(c coalescing.Coalescer, left, right any, opts *Options) ([]Change, error) {
+	if c == nil {
+		c = coalescing.NewStrict()
+	}
+
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	if opts.Vectors == KeyedVectors && opts.Key == "" {
+		return nil, errors.New("keyed vectors require a key")
+	}
+
+	d := differ{
+		coalescer: c,
+		opts:      opts,
+		ignored:   map[string]struct{}{},
+		changes:   []Change{},
+	}
+
+	for _, key := range opts.IgnoreKeys {
+		d.ignored[key] = struct{}{}
+	}
+
+	if err := d.diff(jsonpath.Path{}, left, right); err != nil {
+		return nil, err
+	}
+
+	return d.changes, nil
+}
+
+type differ struct {
+	coalescer coalescing.Coalescer
+	opts      *Options
+	ignored   map[string]struct{}
+	changes   []Change
+}
+
+func (d *differ) add(path jsonpath.Path, op Op, old, new any) {
+	d.changes = append(d.changes, Change{
+		Path: append(jsonpath.Path{}, path...),
+		Op:   op,
+		Old:  old,
+		New:  new,
+	})
+}
+
+func (d *differ) diff(path jsonpath.Path, left, right any) error {
+	if identical(left, right) {
+		return nil
+	}
+
+	if leftObj, ok := toObject(left); ok {
+		if rightObj, ok := toObject(right); ok {
+			return d.diffObjects(path, leftObj, rightObj)
+		}
+	}
+
+	if leftVec, ok := toVector(left); ok {
+		if rightVec, ok := toVector(right); ok {
+			switch d.opts.Vectors {
+			case SetVectors:
+				return d.diffSets(path, leftVec, rightVec)
+			case KeyedVectors:
+				return d.diffKeyedVectors(path, leftVec, rightVec)
+			default:
+				return d.diffOrderedVectors(path, leftVec, rightVec)
+			}
+		}
+	}
+
+	if !d.equal(left, right) {
+		d.add(path, OpReplace, left, right)
+	}
+
+	return nil
+}
+
+func (d *differ) equal(left, right any) bool {
+	equal, err := equality.Equal(d.coalescer, left, right)
+
+	return err == nil && equal
+}
+
+func (d *differ) diffObjects(path jsonpath.Path, left, right map[string]any) error {
+	keys := make([]string, 0, len(left)+len(right))
+	for key := range left {
+		keys = append(keys, key)
+	}
+
+	for key := range right {
+		if _, exists := left[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if _, ignored := d.ignored[key]; ignored {
+			continue
+		}
+
+		leftValue, inLeft := left[key]
+		rightValue, inRight := right[key]
+		keyPath := append(path, key)
+
+		switch {
+		case !inRight:
+			d.add(keyPath, OpRemove, leftValue, nil)
+		case !inLeft:
+			d.add(keyPath, OpAdd, nil, rightValue)
+		default:
+			if err := d.diff(keyPath, leftValue, rightValue); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *differ) diffOrderedVectors(path jsonpath.Path, left, right []any) error {
+	// skip over equal items at the start and end, so that inserting or removing
+	// a single item does not report all following items as replaced
+	prefix := 0
+	for prefix < len(left) && prefix < len(right) && d.equalDeep(left[prefix], right[prefix]) {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(left)-prefix && suffix < len(right)-prefix && d.equalDeep(left[len(left)-suffix-1], right[len(right)-suffix-1]) {
+		suffix++
+	}
+
+	left = left[prefix : len(left)-suffix]
+	right = right[prefix : len(right)-suffix]
+
+	for i := range right {
+		if i >= len(left) {
+			d.add(append(path, prefix+i), OpAdd, nil, right[i])
+			continue
+		}
+
+		if err := d.diff(append(path, prefix+i), left[i], right[i]); err != nil {
+			return err
+		}
+	}
+
+	// report removals from the end, so that applying the changes in order does
+	// not shift the indices of the remaining items
+	for i := len(left) - 1; i >= len(right); i-- {
+		d.add(append(path, prefix+i), OpRemove, left[i], nil)
+	}
+
+	return nil
+}
+
+func (d *differ) diffSets(path jsonpath.Path, left, right []any) error {
+	// every item can only be matched once, so that duplicates are reported
+	matched := make([]bool, len(right))
+
+	for i, leftItem := range left {
+		found := false
+
+		for j, rightItem := range right {
+			if !matched[j] && d.equalDeep(leftItem, rightItem) {
+				matched[j] = true
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			d.add(append(path, i), OpRemove, leftItem, nil)
+		}
+	}
+
+	for j, rightItem := range right {
+		if !matched[j] {
+			d.add(append(path, j), OpAdd, nil, rightItem)
+		}
+	}
+
+	return nil
+}
+
+// equalDeep returns true if there are no differences between both values,
+// taking the options into account.
+func (d *differ) equalDeep(left, right any) bool {
+	sub := differ{
+		coalescer: d.coalescer,
+		opts:      d.opts,
+		ignored:   d.ignored,
+	}
+
+	return sub.diff(jsonpath.Path{}, left, right) == nil && len(sub.changes) == 0
+}
+
+func (d *differ) diffKeyedVectors(path jsonpath.Path, left, right []any) error {
+	leftKeys, err := d.itemKeys(path, left)
+	if err != nil {
+		return err
+	}
+
+	rightKeys, err := d.itemKeys(path, right)
+	if err != nil {
+		return err
+	}
+
+	matched := make([]bool, len(left))
+
+	for j, rightKey := range rightKeys {
+		found := false
+
+		for i, leftKey := range leftKeys {
+			if !matched[i] && d.equal(leftKey, rightKey) {
+				matched[i] = true
+				found = true
+
+				if err := d.diff(append(path, j), left[i], right[j]); err != nil {
+					return err
+				}
+
+				break
+			}
+		}
+
+		if !found {
+			d.add(append(path, j), OpAdd, nil, right[j])
+		}
+	}
+
+	for i, leftItem := range left {
+		if !matched[i] {
+			d.add(append(path, i), OpRemove, leftItem, nil)
+		}
+	}
+
+	return nil
+}
+
+func (d *differ) itemKeys(path jsonpath.Path, items []any) ([]any, error) {
+	keys := make([]any, len(items))
+
+	for i, item := range items {
+		obj, ok := toObject(item)
+		if !ok {
+			return nil, fmt.Errorf("%s: item is %T, not an object", append(path, i), item)
+		}
+
+		key, exists := obj[d.opts.Key]
+		if !exists {
+			return nil, fmt.Errorf("%s: item has no %q key", append(path, i), d.opts.Key)
+		}
+
+		keys[i] = key
+	}
+
+	return keys, nil
+}
+
+func toObject(value any) (map[string]any, bool) {
+	if value == nil {
+		return nil, false
+	}
+
+	obj, err := typeChecker.ToObject(value)
+
+	return obj, err == nil
+}
+
+func toVector(value any) ([]any, bool) {
+	if value == nil {
+		return nil, false
+	}
+
+	vec, err := typeChecker.ToVector(value)
+
+	return vec, err == nil
+}
+
+// identical is a cheap check for values that have not been touched at all.
+// Since Rudi never modifies values in place, unchanged parts of a document
+// are still the very same maps and slices after a program ran.
+func identical(a, b any) bool {
+	switch asserted := a.(type) {
+	case map[string]any:
+		other, ok := b.(map[string]any)
+		return ok && len(asserted) == len(other) && reflect.ValueOf(asserted).Pointer() == reflect.ValueOf(other).Pointer()
+	case []any:
+		other, ok := b.([]any)
+		return ok && len(asserted) == len(other) && (len(asserted) == 0 || &asserted[0] == &other[0])
+	default:
+		return false
+	}
+}
@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package diff
+
+import (
+	"testing"
+
+	"go.xrstf.de/rudi/pkg/coalescing"
+	"go.xrstf.de/rudi/pkg/jsonpath"
+	"go.xrstf.de/rudi/pkg/native"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type container struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+func TestDiff(t *testing.T) {
+	testcases := []struct {
+		name      string
+		left      any
+		right     any
+		opts      *Options
+		coalescer coalescing.Coalescer
+		expected  []Change
+		invalid   bool
+	}{
+		{
+			name:     "equal scalars",
+			left:     int64(1),
+			right:    float64(1),
+			expected: []Change{},
+		},
+		{
+			name:  "incomparable scalars",
+			left:  int64(1),
+			right: "1",
+			expected: []Change{
+				{Path: jsonpath.Path{}, Op: OpReplace, Old: int64(1), New: "1"},
+			},
+		},
+		{
+			name:      "humane scalars",
+			left:      int64(1),
+			right:     "1",
+			coalescer: coalescing.NewHumane(),
+			expected:  []Change{},
+		},
+		{
+			name:  "object replaced by vector",
+			left:  map[string]any{},
+			right: []any{},
+			expected: []Change{
+				{Path: jsonpath.Path{}, Op: OpReplace, Old: map[string]any{}, New: []any{}},
+			},
+		},
+		{
+			name:  "ignored keys",
+			left:  map[string]any{"a": map[string]any{"status": 1, "b": 1}},
+			right: map[string]any{"a": map[string]any{"status": 2, "b": 2}, "status": 3},
+			opts:  &Options{IgnoreKeys: []string{"status"}},
+			expected: []Change{
+				{Path: jsonpath.Path{"a", "b"}, Op: OpReplace, Old: 1, New: 2},
+			},
+		},
+		{
+			name:  "item inserted into vector",
+			left:  []any{1, 2, 3},
+			right: []any{1, 4, 2, 3},
+			expected: []Change{
+				{Path: jsonpath.Path{1}, Op: OpAdd, New: 4},
+			},
+		},
+		{
+			name:  "items removed from vector",
+			left:  []any{1, 2, 3, 4},
+			right: []any{1, 4},
+			expected: []Change{
+				{Path: jsonpath.Path{2}, Op: OpRemove, Old: 3},
+				{Path: jsonpath.Path{1}, Op: OpRemove, Old: 2},
+			},
+		},
+		{
+			name:  "sets of objects",
+			left:  []any{map[string]any{"a": 1}, map[string]any{"b": 2}},
+			right: []any{map[string]any{"b": 2}, map[string]any{"a": 2}},
+			opts:  &Options{Vectors: SetVectors},
+			expected: []Change{
+				{Path: jsonpath.Path{0}, Op: OpRemove, Old: map[string]any{"a": 1}},
+				{Path: jsonpath.Path{1}, Op: OpAdd, New: map[string]any{"a": 2}},
+			},
+		},
+		{
+			name:     "sets with ignored keys",
+			left:     []any{map[string]any{"a": 1, "ts": 1}},
+			right:    []any{map[string]any{"a": 1, "ts": 2}},
+			opts:     &Options{Vectors: SetVectors, IgnoreKeys: []string{"ts"}},
+			expected: []Change{},
+		},
+		{
+			name:    "keyed vectors without key",
+			left:    []any{},
+			right:   []any{},
+			opts:    &Options{Vectors: KeyedVectors},
+			invalid: true,
+		},
+		{
+			name:    "keyed vectors with missing keys",
+			left:    []any{map[string]any{"name": "a"}},
+			right:   []any{map[string]any{"image": "b"}},
+			opts:    &Options{Vectors: KeyedVectors, Key: "name"},
+			invalid: true,
+		},
+		{
+			name:  "keyed native vectors",
+			left:  []container{{Name: "a", Image: "x"}, {Name: "b", Image: "y"}},
+			right: []container{{Name: "b", Image: "z"}, {Name: "a", Image: "x"}},
+			opts:  &Options{Vectors: KeyedVectors, Key: "name"},
+			expected: []Change{
+				{Path: jsonpath.Path{0, "image"}, Op: OpReplace, Old: "y", New: "z"},
+			},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			left, err := native.Wrap(testcase.left)
+			if err != nil {
+				t.Fatalf("Failed to wrap left side: %v", err)
+			}
+
+			right, err := native.Wrap(testcase.right)
+			if err != nil {
+				t.Fatalf("Failed to wrap right side: %v", err)
+			}
+
+			changes, err := Diff(testcase.coalescer, left, right, testcase.opts)
+			if err != nil {
+				if !testcase.invalid {
+					t.Fatalf("Failed to run: %v", err)
+				}
+
+				return
+			}
+
+			if testcase.invalid {
+				t.Fatalf("Should have errored, but got %v", changes)
+			}
+
+			if !cmp.Equal(testcase.expected, changes) {
+				t.Fatalf("Unexpected changes:\n%s", cmp.Diff(testcase.expected, changes))
+			}
+		})
+	}
+}
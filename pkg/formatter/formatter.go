@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+// Package formatter implements a pretty printer for Rudi source code. Unlike
+// the printers in the printer package, which work on the AST, the formatter
+// works on the source code itself and so is able to keep comments intact.
+package formatter
+
+import (
+	"errors"
+	"fmt"
+
+	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/lang/parser"
+)
+
+// Options control how the source code is formatted.
+type Options struct {
+	// Indent is the number of spaces used to indent nested expressions.
+	Indent int
+
+	// LineWidth is the maximum desired line width. Expressions that do not
+	// fit into a single line are broken up into multiple lines. Note that
+	// some expressions (like very long strings) can still exceed this width.
+	LineWidth int
+}
+
+// DefaultOptions returns the default formatting options.
+func DefaultOptions() Options {
+	return Options{
+		Indent:    2,
+		LineWidth: 80,
+	}
+}
+
+// Format takes the source code of a Rudi program and returns the formatted
+// code. Comments are retained, while commas in vectors and insignificant
+// whitespace are removed. An error is returned if the script cannot be parsed.
+func Format(name string, script []byte, opts Options) ([]byte, error) {
+	if opts.Indent <= 0 {
+		opts.Indent = DefaultOptions().Indent
+	}
+
+	if opts.LineWidth <= 0 {
+		opts.LineWidth = DefaultOptions().LineWidth
+	}
+
+	// Only valid programs can be formatted, this allows the rest of the
+	// formatter to be much simpler.
+	original, err := parseProgram(name, script)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := scan(string(script))
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := buildTree(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &layouter{opts: opts}
+	formatted := []byte(l.formatProgram(tree))
+
+	// As a safety net, ensure the formatting did not change the meaning of
+	// the program.
+	reformatted, err := parseProgram(name, formatted)
+	if err != nil {
+		return nil, fmt.Errorf("formatted program is invalid: %w", err)
+	}
+
+	if original.String() != reformatted.String() {
+		return nil, errors.New("formatted program is not equivalent to the original program")
+	}
+
+	return formatted, nil
+}
+
+func parseProgram(name string, script []byte) (*ast.Program, error) {
+	got, err := parser.Parse(name, script)
+	if err != nil {
+		return nil, err
+	}
+
+	program, ok := got.(ast.Program)
+	if !ok {
+		// this should never happen
+		return nil, fmt.Errorf("parsed input is not an ast.Program, but %T", got)
+	}
+
+	return &program, nil
+}
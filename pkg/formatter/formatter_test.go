@@ -0,0 +1,149 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	testcases := []struct {
+		input     string
+		output    string
+		lineWidth int
+		invalid   bool
+	}{
+		{
+			input:  `null`,
+			output: "null\n",
+		},
+		{
+			input:   `(`,
+			invalid: true,
+		},
+		{
+			input:  `(set!   .foo 1)  (set! .bar 2)`,
+			output: "(set! .foo 1)\n(set! .bar 2)\n",
+		},
+		{
+			input:  `[1, 2 ,3]`,
+			output: "[1 2 3]\n",
+		},
+		{
+			input:  `{ "a" 1  b  "two" }`,
+			output: "{\"a\" 1 b \"two\"}\n",
+		},
+		{
+			input:  `( map $foo.bar[ ( add 1.2)] [a  b] ( foo! ))`,
+			output: "(map $foo.bar[(add 1.2)] [a b] (foo!))\n",
+		},
+		{
+			input:  `(foo)[1].bar[ 2 ]`,
+			output: "(foo)[1].bar[2]\n",
+		},
+		{
+			input:  `.items[ * ].name ..image $x..[ 0 ]`,
+			output: ".items[*].name\n..image\n$x..[0]\n",
+		},
+		{
+			input:  `(foo .items[ 1 : -1 ] .items[? (eq? .name "app")])`,
+			output: "(foo .items[1:-1] .items[?(eq? .name \"app\")])\n",
+		},
+		{
+			input:  `.spec?.items[ 0 ]?.name $x[ 1 ]?`,
+			output: ".spec?.items[0]?.name\n$x[1]?\n",
+		},
+		{
+			input:  `"é\n" 1e3 -0.5`,
+			output: "\"é\\n\"\n1e3\n-0.5\n",
+		},
+		// comments
+		{
+			input:  "# header\n\n\n(foo) ; trailing\n# footer",
+			output: "# header\n\n(foo) ; trailing\n# footer\n",
+		},
+		{
+			input:  "#!/usr/bin/env -S rudi -s\n(foo)",
+			output: "#!/usr/bin/env -S rudi -s\n(foo)\n",
+		},
+		{
+			input:  "(foo # head comment\n  1 2)",
+			output: "(foo # head comment\n  1\n  2)\n",
+		},
+		{
+			input:  "(foo 1 # one\n)",
+			output: "(foo 1 # one\n)\n",
+		},
+		{
+			input:  "(do\n\n  # first step\n  (foo)\n\n  (bar) # second step\n  # done\n)",
+			output: "(do\n  # first step\n  (foo)\n\n  (bar) # second step\n  # done\n)\n",
+		},
+		{
+			input:  "[ # first\n 1 2\n # dangling\n]",
+			output: "[\n  # first\n  1\n  2\n  # dangling\n]\n",
+		},
+		{
+			input:  "{\n  # the key\n  a 1\n  b # the value\n  2\n}",
+			output: "{\n  # the key\n  a 1\n  # the value\n  b 2\n}\n",
+		},
+		// line breaking
+		{
+			input:     `(if (gt? .replicas 5) (error "too many replicas") (set! .replicas 5))`,
+			lineWidth: 40,
+			output:    "(if (gt? .replicas 5)\n  (error \"too many replicas\")\n  (set! .replicas 5))\n",
+		},
+		{
+			input:     `(set! .spec {replicas 3 template {name "app" image "nginx"}})`,
+			lineWidth: 40,
+			output:    "(set! .spec\n  {\n    replicas 3\n    template {name \"app\" image \"nginx\"}\n  })\n",
+		},
+		{
+			input:     `[1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16 17 18 19 20]`,
+			lineWidth: 20,
+			output:    "[\n  1 2 3 4 5 6 7 8 9\n  10 11 12 13 14 15\n  16 17 18 19 20\n]\n",
+		},
+		{
+			input:     `[(foo 1 2 3) (bar 4 5 6)][0].foo`,
+			lineWidth: 20,
+			output:    "[\n  (foo 1 2 3)\n  (bar 4 5 6)\n][0].foo\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.input, func(t *testing.T) {
+			opts := DefaultOptions()
+			if tc.lineWidth > 0 {
+				opts.LineWidth = tc.lineWidth
+			}
+
+			formatted, err := Format("test", []byte(tc.input), opts)
+			if err != nil {
+				if !tc.invalid {
+					t.Fatalf("Failed to format: %v", err)
+				}
+
+				return
+			}
+
+			if tc.invalid {
+				t.Fatalf("Should have failed, but got %q", string(formatted))
+			}
+
+			if string(formatted) != tc.output {
+				t.Fatalf("Expected\n%s\n\ngot\n\n%s", tc.output, string(formatted))
+			}
+
+			// formatting must be idempotent
+			reformatted, err := Format("test", formatted, opts)
+			if err != nil {
+				t.Fatalf("Failed to format formatted code: %v", err)
+			}
+
+			if string(reformatted) != string(formatted) {
+				t.Fatalf("Formatting is not idempotent, got\n%s", strings.TrimSpace(string(reformatted)))
+			}
+		})
+	}
+}
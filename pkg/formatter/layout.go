@@ -0,0 +1,382 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package formatter
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+type layouter struct {
+	opts Options
+}
+
+func width(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+func (l *layouter) indentation(level int) string {
+	return strings.Repeat(" ", level)
+}
+
+// flat renders a node on a single line. If the node contains comments (other
+// than its own leading/trailing comments, which are handled by the caller),
+// it cannot be rendered flat and false is returned.
+func (l *layouter) flat(n *node) (string, bool) {
+	if len(n.dangling) > 0 {
+		return "", false
+	}
+
+	for _, child := range n.children {
+		if len(child.leading) > 0 || child.trailing != "" {
+			return "", false
+		}
+	}
+
+	var out strings.Builder
+
+	switch n.kind {
+	case nodeLeaf:
+		out.WriteString(n.text)
+
+	case nodeTuple, nodeVector, nodeObject, nodeAccessor:
+		open, closer := delimiters(n.kind)
+		out.WriteString(open)
+
+		for i, child := range n.children {
+			rendered, ok := l.flat(child)
+			if !ok {
+				return "", false
+			}
+
+			// filters and slices are written without spaces, like "[?.enabled]" and "[1:3]"
+			if i > 0 && n.kind != nodeAccessor {
+				out.WriteString(" ")
+			}
+
+			out.WriteString(rendered)
+		}
+
+		out.WriteString(closer)
+	}
+
+	for _, step := range n.suffix {
+		rendered, ok := l.flat(step)
+		if !ok {
+			return "", false
+		}
+
+		out.WriteString(rendered)
+	}
+
+	return out.String(), true
+}
+
+func delimiters(kind nodeKind) (string, string) {
+	switch kind {
+	case nodeTuple:
+		return "(", ")"
+	case nodeObject:
+		return "{", "}"
+	default:
+		return "[", "]"
+	}
+}
+
+// format renders a node, beginning at column col. indent is the indentation
+// of the line the node starts on and is used to indent any further lines.
+func (l *layouter) format(n *node, indent int, col int) string {
+	if rendered, ok := l.flat(n); ok && (n.kind == nodeLeaf || col+width(rendered) <= l.opts.LineWidth) {
+		return rendered
+	}
+
+	var out string
+
+	switch n.kind {
+	case nodeLeaf:
+		out = n.text
+	case nodeTuple:
+		out = l.formatTuple(n, indent, col)
+	case nodeObject:
+		out = l.formatObject(n, indent)
+	default:
+		out = l.formatVector(n, indent)
+	}
+
+	return out + l.formatSuffix(n, indent, lastLineWidth(out, col))
+}
+
+func lastLineWidth(s string, col int) int {
+	idx := strings.LastIndex(s, "\n")
+	if idx < 0 {
+		return col + width(s)
+	}
+
+	return width(s[idx+1:])
+}
+
+func (l *layouter) formatSuffix(n *node, indent int, col int) string {
+	var out strings.Builder
+
+	for _, step := range n.suffix {
+		rendered := l.format(step, indent, col)
+		out.WriteString(rendered)
+		col = lastLineWidth(rendered, col)
+	}
+
+	return out.String()
+}
+
+// formatTuple renders tuples in the typical Lisp style, with the function
+// name (and, if short enough, its first argument) on the first line and all
+// remaining arguments on separate lines:
+//
+//	(if (gt? .replicas 5)
+//	  (error "too many replicas"))
+func (l *layouter) formatTuple(n *node, indent int, col int) string {
+	var out strings.Builder
+
+	childIndent := indent + l.opts.Indent
+	children := n.children
+	lastHasComment := false
+
+	out.WriteString("(")
+
+	head := children[0]
+	if len(head.leading) == 0 {
+		rendered := l.format(head, childIndent, col+1)
+		out.WriteString(rendered)
+		col = lastLineWidth(rendered, col+1)
+		children = children[1:]
+
+		if head.trailing != "" {
+			out.WriteString(" " + head.trailing)
+			lastHasComment = true
+		} else if len(children) > 0 && len(children[0].leading) == 0 && !children[0].blank && !strings.Contains(rendered, "\n") {
+			arg := children[0]
+
+			if rendered, ok := l.flat(arg); ok {
+				// leave room for the closing parenthesis if this is the last argument
+				end := col + 1 + width(rendered)
+				if len(children) == 1 && arg.trailing == "" && len(n.dangling) == 0 {
+					end++
+				}
+
+				if end <= l.opts.LineWidth {
+					out.WriteString(" " + rendered)
+					children = children[1:]
+
+					if arg.trailing != "" {
+						out.WriteString(" " + arg.trailing)
+						lastHasComment = true
+					}
+				}
+			}
+		}
+	}
+
+	if len(children) > 0 {
+		out.WriteString("\n")
+		out.WriteString(l.formatLines(children, childIndent, true))
+		lastHasComment = children[len(children)-1].trailing != ""
+	}
+
+	if len(n.dangling) > 0 {
+		out.WriteString("\n")
+		out.WriteString(l.formatComments(n.dangling, childIndent, false))
+		lastHasComment = true
+	}
+
+	if lastHasComment {
+		out.WriteString("\n" + l.indentation(indent))
+	}
+
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// formatVector renders vectors (and vector accessors) with one item per line,
+// unless all items are simple leafs, which are packed into as few lines as
+// possible.
+func (l *layouter) formatVector(n *node, indent int) string {
+	open, closer := delimiters(n.kind)
+	childIndent := indent + l.opts.Indent
+
+	var out strings.Builder
+
+	out.WriteString(open + "\n")
+
+	if packed, ok := l.packLeafs(n.children, childIndent); ok {
+		out.WriteString(packed)
+		out.WriteString("\n")
+	} else if len(n.children) > 0 {
+		out.WriteString(l.formatLines(n.children, childIndent, true))
+		out.WriteString("\n")
+	}
+
+	if len(n.dangling) > 0 {
+		out.WriteString(l.formatComments(n.dangling, childIndent, len(n.children) == 0))
+		out.WriteString("\n")
+	}
+
+	out.WriteString(l.indentation(indent) + closer)
+
+	return out.String()
+}
+
+// packLeafs renders vector items that are all simple leafs by putting as many
+// of them as possible onto each line.
+func (l *layouter) packLeafs(nodes []*node, indent int) (string, bool) {
+	if len(nodes) == 0 {
+		return "", false
+	}
+
+	for _, n := range nodes {
+		if n.kind != nodeLeaf || len(n.suffix) > 0 || len(n.leading) > 0 || n.trailing != "" || n.blank {
+			return "", false
+		}
+	}
+
+	prefix := l.indentation(indent)
+	lines := []string{}
+	line := prefix + nodes[0].text
+
+	for _, n := range nodes[1:] {
+		if width(line)+1+width(n.text) > l.opts.LineWidth {
+			lines = append(lines, line)
+			line = prefix + n.text
+		} else {
+			line += " " + n.text
+		}
+	}
+
+	lines = append(lines, line)
+
+	return strings.Join(lines, "\n"), true
+}
+
+// formatObject renders objects with one key-value pair per line.
+func (l *layouter) formatObject(n *node, indent int) string {
+	childIndent := indent + l.opts.Indent
+	prefix := l.indentation(childIndent)
+
+	var out strings.Builder
+
+	out.WriteString("{\n")
+
+	for i := 0; i+1 < len(n.children); i += 2 {
+		key := n.children[i]
+		value := n.children[i+1]
+
+		// Comments between key and value are moved above the pair, as there is
+		// no sensible way to keep them in place.
+		leading := append([]comment{}, key.leading...)
+		if key.trailing != "" {
+			leading = append(leading, comment{text: key.trailing})
+		}
+		leading = append(leading, value.leading...)
+
+		if i > 0 && key.blank && len(key.leading) == 0 {
+			out.WriteString("\n")
+		}
+
+		if len(leading) > 0 {
+			out.WriteString(l.formatComments(leading, childIndent, i == 0))
+			out.WriteString("\n")
+
+			if key.blank && len(key.leading) > 0 {
+				out.WriteString("\n")
+			}
+		}
+
+		renderedKey := l.format(key, childIndent, childIndent)
+
+		out.WriteString(prefix + renderedKey + " ")
+		out.WriteString(l.format(value, childIndent, lastLineWidth(renderedKey, childIndent)+1))
+
+		if value.trailing != "" {
+			out.WriteString(" " + value.trailing)
+		}
+
+		out.WriteString("\n")
+	}
+
+	if len(n.dangling) > 0 {
+		out.WriteString(l.formatComments(n.dangling, childIndent, len(n.children) == 0))
+		out.WriteString("\n")
+	}
+
+	out.WriteString(l.indentation(indent) + "}")
+
+	return out.String()
+}
+
+// formatLines renders each node on its own line(s), including their comments.
+// The result does not end with a newline.
+func (l *layouter) formatLines(nodes []*node, indent int, first bool) string {
+	prefix := l.indentation(indent)
+	lines := []string{}
+
+	for i, n := range nodes {
+		var out strings.Builder
+
+		if len(n.leading) > 0 {
+			out.WriteString(l.formatComments(n.leading, indent, first && i == 0))
+			out.WriteString("\n")
+		}
+
+		if n.blank && (!first || i > 0 || len(n.leading) > 0) {
+			out.WriteString("\n")
+		}
+
+		out.WriteString(prefix + l.format(n, indent, indent))
+
+		if n.trailing != "" {
+			out.WriteString(" " + n.trailing)
+		}
+
+		lines = append(lines, out.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatComments renders each comment on its own line, keeping empty lines
+// between them intact. The result does not end with a newline.
+func (l *layouter) formatComments(comments []comment, indent int, first bool) string {
+	prefix := l.indentation(indent)
+
+	var out strings.Builder
+
+	for i, c := range comments {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+
+		if c.blank && (!first || i > 0) {
+			out.WriteString("\n")
+		}
+
+		out.WriteString(prefix + c.text)
+	}
+
+	return out.String()
+}
+
+func (l *layouter) formatProgram(program *node) string {
+	var out strings.Builder
+
+	if len(program.children) > 0 {
+		out.WriteString(l.formatLines(program.children, 0, true))
+		out.WriteString("\n")
+	}
+
+	if len(program.dangling) > 0 {
+		out.WriteString(l.formatComments(program.dangling, 0, len(program.children) == 0))
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package formatter
+
+import (
+	"fmt"
+)
+
+type tokenKind int
+
+const (
+	tokenAtom tokenKind = iota
+	tokenString
+	tokenOpen
+	tokenClose
+	tokenComma
+	tokenComment
+)
+
+type token struct {
+	kind tokenKind
+	text string
+
+	// adjacent is true if the token directly follows the previous token,
+	// without any whitespace or comments in between.
+	adjacent bool
+
+	// newlines is the number of line breaks between the previous token and
+	// this one.
+	newlines int
+}
+
+// scan splits a Rudi script into tokens. Unlike the real parser, the scanner
+// retains comments and the information about whitespace between tokens. The
+// scanner is not validating the script, this is left to the actual parser.
+func scan(script string) ([]token, error) {
+	tokens := []token{}
+	newlines := 0
+	adjacent := false
+
+	for pos := 0; pos < len(script); {
+		c := script[pos]
+
+		switch {
+		case c == '\n':
+			newlines++
+			adjacent = false
+			pos++
+			continue
+
+		case c == ' ' || c == '\t' || c == '\r':
+			adjacent = false
+			pos++
+			continue
+		}
+
+		start := pos
+		kind := tokenAtom
+
+		switch {
+		case c == '#' || c == ';':
+			kind = tokenComment
+			for pos < len(script) && script[pos] != '\n' {
+				pos++
+			}
+
+		case c == '"':
+			kind = tokenString
+			pos++
+
+			for {
+				if pos >= len(script) {
+					return nil, fmt.Errorf("unterminated string starting at offset %d", start)
+				}
+
+				if script[pos] == '\\' {
+					pos += 2
+					continue
+				}
+
+				pos++
+
+				if script[pos-1] == '"' {
+					break
+				}
+			}
+
+		case c == '(' || c == '[' || c == '{':
+			kind = tokenOpen
+			pos++
+
+		case c == ')' || c == ']' || c == '}':
+			kind = tokenClose
+			pos++
+
+		case c == ',':
+			kind = tokenComma
+			pos++
+
+		default:
+			for pos < len(script) && !isAtomTerminator(script[pos]) {
+				pos++
+			}
+		}
+
+		tokens = append(tokens, token{
+			kind:     kind,
+			text:     script[start:pos],
+			adjacent: adjacent && kind != tokenComment,
+			newlines: newlines,
+		})
+
+		// comments always run until the end of the line, so nothing can be adjacent to them
+		adjacent = kind != tokenComment
+		newlines = 0
+	}
+
+	return tokens, nil
+}
+
+func isAtomTerminator(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '(', ')', '[', ']', '{', '}', '"', ',', '#', ';':
+		return true
+	default:
+		return false
+	}
+}
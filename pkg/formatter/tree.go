@@ -0,0 +1,261 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package formatter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type nodeKind int
+
+const (
+	nodeProgram nodeKind = iota
+	nodeLeaf
+	nodeTuple
+	nodeVector
+	nodeObject
+	// nodeAccessor is a vector accessor in a path expression, like "[1]".
+	nodeAccessor
+)
+
+// comment is a single line comment.
+type comment struct {
+	text string
+
+	// blank is true if the comment was preceded by an empty line.
+	blank bool
+}
+
+// node is a concrete syntax tree element. Unlike the AST, nodes keep comments
+// attached to them.
+type node struct {
+	kind nodeKind
+
+	// text is the verbatim source code for leafs (numbers, strings, symbols,
+	// identifiers etc.)
+	text string
+
+	// children are the elements of tuples, vectors, objects (keys and values
+	// alternating) and accessors.
+	children []*node
+
+	// suffix contains the steps of a path expression directly attached to
+	// this node. Object steps are leaf nodes (".foo"), vector steps are
+	// accessor nodes.
+	suffix []*node
+
+	// leading are comments on their own lines before this node.
+	leading []comment
+
+	// trailing is a comment on the same line, right after this node.
+	trailing string
+
+	// dangling are comments at the end of a container, after the last child.
+	dangling []comment
+
+	// blank is true if the node was preceded by an empty line.
+	blank bool
+}
+
+type treeBuilder struct {
+	tokens []token
+	pos    int
+}
+
+func buildTree(tokens []token) (*node, error) {
+	b := &treeBuilder{tokens: tokens}
+
+	program := &node{kind: nodeProgram}
+	if err := b.parseChildren(program, ""); err != nil {
+		return nil, err
+	}
+
+	return program, nil
+}
+
+func (b *treeBuilder) peek() *token {
+	if b.pos >= len(b.tokens) {
+		return nil
+	}
+
+	return &b.tokens[b.pos]
+}
+
+func closingDelimiter(open string) string {
+	switch open {
+	case "(":
+		return ")"
+	case "[":
+		return "]"
+	default:
+		return "}"
+	}
+}
+
+// parseChildren consumes tokens and adds them as children to parent, until
+// the closing delimiter is found (which is consumed as well). An empty closer
+// means to parse until the end of the input.
+func (b *treeBuilder) parseChildren(parent *node, closer string) error {
+	var (
+		pending []comment
+		last    *node
+	)
+
+	for {
+		tok := b.peek()
+		if tok == nil {
+			if closer != "" {
+				return fmt.Errorf("expected %q, but reached end of input", closer)
+			}
+
+			break
+		}
+
+		if tok.kind == tokenClose {
+			if tok.text != closer {
+				return fmt.Errorf("unexpected %q", tok.text)
+			}
+
+			b.pos++
+			break
+		}
+
+		if tok.kind == tokenComma {
+			b.pos++
+			continue
+		}
+
+		if tok.kind == tokenComment {
+			b.pos++
+			text := strings.TrimRight(tok.text, " \t\r")
+
+			if tok.newlines == 0 && last != nil && last.trailing == "" && len(pending) == 0 {
+				last.trailing = text
+			} else {
+				pending = append(pending, comment{text: text, blank: tok.newlines > 1})
+			}
+
+			continue
+		}
+
+		blank := tok.newlines > 1
+
+		child, err := b.parseNode()
+		if err != nil {
+			return err
+		}
+
+		child.leading = pending
+		child.blank = blank
+		pending = nil
+
+		parent.children = append(parent.children, child)
+		last = child
+	}
+
+	parent.dangling = pending
+
+	return nil
+}
+
+func (b *treeBuilder) parseNode() (*node, error) {
+	tok := b.peek()
+	b.pos++
+
+	var n *node
+
+	switch tok.kind {
+	case tokenAtom, tokenString:
+		n = &node{kind: nodeLeaf, text: tok.text}
+
+	case tokenOpen:
+		switch tok.text {
+		case "(":
+			n = &node{kind: nodeTuple}
+		case "[":
+			n = &node{kind: nodeVector}
+		default:
+			n = &node{kind: nodeObject}
+		}
+
+		if err := b.parseChildren(n, closingDelimiter(tok.text)); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("unexpected %q", tok.text)
+	}
+
+	return n, b.parseSuffix(n)
+}
+
+// parseSuffix consumes all path expression steps that directly follow a node.
+func (b *treeBuilder) parseSuffix(n *node) error {
+	for {
+		tok := b.peek()
+		if tok == nil || !tok.adjacent {
+			return nil
+		}
+
+		switch {
+		case tok.kind == tokenAtom && strings.HasPrefix(tok.text, "."):
+			b.pos++
+			n.suffix = append(n.suffix, &node{kind: nodeLeaf, text: tok.text})
+
+		// optional vector accessors like "[0]?" (maybe followed by more steps,
+		// like "[0]?.foo")
+		case tok.kind == tokenAtom && strings.HasPrefix(tok.text, "?") && followsAccessor(n):
+			b.pos++
+			n.suffix = append(n.suffix, &node{kind: nodeLeaf, text: tok.text})
+
+		case tok.kind == tokenOpen && tok.text == "[":
+			b.pos++
+
+			accessor := &node{kind: nodeAccessor}
+			if err := b.parseChildren(accessor, "]"); err != nil {
+				return err
+			}
+
+			if !validAccessor(accessor) {
+				return errors.New("vector accessor must contain exactly one expression, filter or slice")
+			}
+
+			n.suffix = append(n.suffix, accessor)
+
+		default:
+			return nil
+		}
+	}
+}
+
+func followsAccessor(n *node) bool {
+	return len(n.suffix) > 0 && n.suffix[len(n.suffix)-1].kind == nodeAccessor
+}
+
+// validAccessor checks that a vector accessor contains either a single
+// expression (this includes slices like "1:3" and "*"), a filter ("?" followed
+// by an expression) or a slice with whitespace around its colon.
+func validAccessor(accessor *node) bool {
+	children := accessor.children
+
+	switch {
+	case len(children) == 1:
+		return true
+	case len(children) == 2 && children[0].kind == nodeLeaf && children[0].text == "?":
+		return true
+	case len(children) > 3:
+		return false
+	}
+
+	colons := 0
+	for _, child := range children {
+		if child.kind == nodeLeaf && !strings.HasPrefix(child.text, `"`) {
+			colons += strings.Count(child.text, ":")
+		}
+	}
+
+	return colons == 1
+}
@@ -3,11 +3,6 @@
 
 package jsonpath
 
-import (
-	"errors"
-	"fmt"
-)
-
 type ObjectKeyDeleter interface {
 	DeleteObjectKey(name string) (any, error)
 }
@@ -17,121 +12,227 @@ type VectorItemDeleter interface {
 }
 
 func removeSliceItem(slice []any, index int) []any {
-	return append(slice[:index], slice[index+1:]...)
+	result := make([]any, 0, len(slice)-1)
+	result = append(result, slice[:index]...)
+
+	return append(result, slice[index+1:]...)
 }
 
-func Delete(dest any, path Path) (any, error) {
-	if len(path) == 0 {
-		return nil, nil
+// removeMatches removes the given indices or keys, as returned by
+// matchingSteps, from a copy of a vector or object.
+func removeMatches(dest any, matches []Step) (any, error) {
+	if slice, ok := dest.([]any); ok {
+		remove := map[int]struct{}{}
+		for _, match := range matches {
+			remove[match.(int)] = struct{}{}
+		}
+
+		result := []any{}
+		for i, item := range slice {
+			if _, ok := remove[i]; !ok {
+				result = append(result, item)
+			}
+		}
+
+		return result, nil
 	}
 
-	thisStep := path[0]
-	remainingSteps := path[1:]
+	owned, err := shallowCopy(dest)
+	if err != nil {
+		return nil, err
+	}
 
-	// we reached the level at which we want to remove the key
-	if len(remainingSteps) == 0 {
-		// [index]
-		if index, ok := toIntegerStep(thisStep); ok {
-			if slice, ok := dest.([]any); ok {
-				if index < 0 || index >= len(slice) {
-					return nil, fmt.Errorf("index %d out of bounds", index)
-				}
+	// remove from the back so that the remaining indices stay valid
+	for i := len(matches) - 1; i >= 0; i-- {
+		owned, err = removeMatch(owned, matches[i])
+		if err != nil {
+			return nil, err
+		}
+	}
 
-				return removeSliceItem(slice, index), nil
-			}
+	return owned, nil
+}
 
-			if deleter, ok := dest.(VectorItemDeleter); ok {
-				return deleter.DeleteVectorItem(index)
-			}
+// removeMatch removes a single index or key from a vector or object, which
+// must already be a copy.
+func removeMatch(dest any, match Step) (any, error) {
+	if index, ok := match.(int); ok {
+		if deleter, ok := dest.(VectorItemDeleter); ok {
+			return deleter.DeleteVectorItem(index)
+		}
+	}
 
-			return nil, fmt.Errorf("cannot delete index from %T", dest)
+	if key, ok := match.(string); ok {
+		if object, ok := dest.(map[string]any); ok {
+			delete(object, key)
+			return object, nil
 		}
 
-		// .key
-		if key, ok := toStringStep(thisStep); ok {
-			if object, ok := dest.(map[string]any); ok {
-				delete(object, key)
-				return object, nil
-			}
+		if deleter, ok := dest.(ObjectKeyDeleter); ok {
+			return deleter.DeleteObjectKey(key)
+		}
+	}
 
-			if deleter, ok := dest.(ObjectKeyDeleter); ok {
-				return deleter.DeleteObjectKey(key)
-			}
+	return nil, newTypeMismatchError(match, dest, nil)
+}
 
-			return nil, fmt.Errorf("cannot delete key from %T", dest)
-		}
+// Delete removes the value at the given path. For paths that match multiple
+// values, all matches are removed. Recursive descents only apply to existing
+// keys and indices, so for example "..name" removes the key "name" from all
+// objects in the document. If an optional step does not match or yields nil,
+// nothing is deleted. Like Update, Delete never modifies the given value and
+// only copies the vectors and objects along the path.
+func Delete(dest any, path Path) (any, error) {
+	result, _, err := DeleteWithPaths(dest, path)
+
+	return result, err
+}
 
-		return nil, fmt.Errorf("can only remove object keys or slice items, not %T", thisStep)
+// DeleteWithPaths works like Delete, but also returns the concrete paths of
+// all removed values (see UpdatePathFunc). Indices of items in the same vector
+// are returned from the last to the first, so that removing the paths one
+// after another removes the same items.
+func DeleteWithPaths(dest any, path Path) (any, []Path, error) {
+	removed := []Path{}
+
+	result, err := remove(dest, path, Path{}, &removed)
+	if err != nil {
+		return nil, nil, withPath(err, path)
 	}
 
-	// [index]...
-	if index, ok := toIntegerStep(thisStep); ok {
-		if slice, ok := dest.([]any); ok {
-			if index < 0 || index >= len(slice) {
-				return nil, fmt.Errorf("index %d out of bounds", index)
-			}
+	return result, removed, nil
+}
 
-			existingValue := slice[index]
+func remove(dest any, path Path, at Path, removed *[]Path) (any, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
 
-			updatedValue, err := Delete(existingValue, remainingSteps)
-			if err != nil {
-				return nil, err
-			}
+	thisStep, optional := unwrapOptional(path[0])
+	remainingSteps := path[1:]
+
+	// ..step...
+	if descent, ok := thisStep.(DescentStep); ok {
+		children, _, _ := matchingSteps(dest, WildcardStep{})
+
+		dest, err := updateChildren(dest, children, path, func(child Step, value any) (any, error) {
+			return remove(value, path, appendStep(at, child), removed)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if !stepMatches(dest, descent.Step) {
+			return dest, nil
+		}
 
-			slice[index] = updatedValue
+		return remove(dest, append(Path{descent.Step}, remainingSteps...), at, removed)
+	}
 
-			return slice, nil
+	// [*], [from:to], [?filter]...
+	if isMultiStep(thisStep) {
+		children, ok, err := matchingSteps(dest, thisStep)
+		if err != nil {
+			return nil, err
 		}
 
-		if writer, ok := dest.(VectorWriter); ok {
-			existingValue, err := writer.GetVectorItem(index)
-			if err != nil {
-				return nil, fmt.Errorf("cannot descend with [%d] into %T", index, dest)
+		if !ok {
+			if optional {
+				return dest, nil
 			}
 
-			updatedValue, err := Delete(existingValue, remainingSteps)
-			if err != nil {
-				return nil, err
+			return nil, newTypeMismatchError(thisStep, dest, remainingSteps)
+		}
+
+		if len(remainingSteps) == 0 {
+			_, isVector := vectorLength(dest)
+			for i := range children {
+				child := children[i]
+				if isVector {
+					child = children[len(children)-i-1]
+				}
+
+				*removed = append(*removed, appendStep(at, child))
 			}
 
-			return writer.SetVectorItem(index, updatedValue)
+			return removeMatches(dest, children)
 		}
 
-		return nil, fmt.Errorf("cannot descend with [%d] into %T", index, dest)
+		return updateChildren(dest, children, remainingSteps, func(child Step, value any) (any, error) {
+			return remove(value, remainingSteps, appendStep(at, child), removed)
+		})
 	}
 
-	// .key
-	if key, ok := toStringStep(thisStep); ok {
-		if object, ok := dest.(map[string]any); ok {
-			// getting the empty value for non-existing keys is fine
-			existingValue := object[key]
+	if optional {
+		if !stepMatches(dest, thisStep) {
+			return dest, nil
+		}
 
-			updatedValue, err := Delete(existingValue, remainingSteps)
-			if err != nil {
-				return nil, err
+		// like Get, do not descend into nulls after optional steps
+		if len(remainingSteps) > 0 {
+			if child, _ := getStep(dest, thisStep, remainingSteps); child == nil {
+				return dest, nil
 			}
+		}
+	}
 
-			object[key] = updatedValue
+	childPath := appendStep(at, concreteStep(dest, thisStep))
 
-			return object, nil
-		}
+	// we reached the level at which we want to remove the key
+	if len(remainingSteps) == 0 {
+		// [index]
+		if index, ok := toIntegerStep(thisStep); ok {
+			if slice, ok := dest.([]any); ok {
+				normalized, ok := normalizeIndex(index, len(slice))
+				if !ok {
+					return nil, newIndexOutOfBoundsError(index, len(slice), remainingSteps)
+				}
 
-		if writer, ok := dest.(ObjectWriter); ok {
-			existingValue, err := writer.GetObjectKey(key)
-			if err != nil {
-				return nil, fmt.Errorf("cannot descend with [%s] into %T", key, dest)
+				*removed = append(*removed, childPath)
+
+				return removeSliceItem(slice, normalized), nil
 			}
 
-			updatedValue, err := Delete(existingValue, remainingSteps)
-			if err != nil {
-				return nil, err
+			if _, ok := dest.(VectorItemDeleter); ok {
+				result, err := removeMatches(dest, []Step{index})
+				if err == nil {
+					*removed = append(*removed, childPath)
+				}
+
+				return result, err
 			}
 
-			return writer.SetObjectKey(key, updatedValue)
+			return nil, newTypeMismatchError(index, dest, remainingSteps)
+		}
+
+		// .key
+		if key, ok := toStringStep(thisStep); ok {
+			switch dest.(type) {
+			case map[string]any, ObjectKeyDeleter:
+				// removing non-existing keys is fine, but not a change
+				exists := stepMatches(dest, key)
+
+				result, err := removeMatches(dest, []Step{key})
+				if err == nil && exists {
+					*removed = append(*removed, childPath)
+				}
+
+				return result, err
+			default:
+				return nil, newTypeMismatchError(key, dest, remainingSteps)
+			}
 		}
 
-		return nil, fmt.Errorf("cannot descend with [%s] into %T", key, dest)
+		return nil, newTypeMismatchError(thisStep, dest, remainingSteps)
+	}
+
+	// [index]... and .key...
+	owned, err := shallowCopy(dest)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, errors.New("invalid path step: neither key nor index")
+	return updateChild(owned, thisStep, remainingSteps, false, func(child any) (any, error) {
+		return remove(child, remainingSteps, childPath, removed)
+	})
 }
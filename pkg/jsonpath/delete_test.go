@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"testing"
 
+	"go.xrstf.de/rudi/pkg/deepcopy"
+
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -170,9 +172,15 @@ func TestDelete(t *testing.T) {
 		{
 			name:    "out of bounds",
 			dest:    []any{"foo", map[string]any{"foo": "bar"}, "bar"},
-			path:    Path{-1},
+			path:    Path{-4},
 			invalid: true,
 		},
+		{
+			name:     "negative index",
+			dest:     []any{"foo", map[string]any{"foo": "bar"}, "bar"},
+			path:     Path{-1},
+			expected: []any{"foo", map[string]any{"foo": "bar"}},
+		},
 		{
 			name:    "out of bounds",
 			dest:    []any{"foo", map[string]any{"foo": "bar"}, "bar"},
@@ -182,7 +190,7 @@ func TestDelete(t *testing.T) {
 		{
 			name:    "out of bounds",
 			dest:    []any{"foo", map[string]any{"foo": "bar"}, "bar"},
-			path:    Path{-1, "list"},
+			path:    Path{-4, "list"},
 			invalid: true,
 		},
 		{
@@ -255,6 +263,101 @@ func TestDelete(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "wildcard empties vectors",
+			dest:     map[string]any{"list": []any{1, 2, 3}},
+			path:     Path{"list", WildcardStep{}},
+			expected: map[string]any{"list": []any{}},
+		},
+		{
+			name:     "wildcard empties objects",
+			dest:     map[string]any{"a": 1, "b": 2},
+			path:     Path{WildcardStep{}},
+			expected: map[string]any{},
+		},
+		{
+			name: "wildcard in the middle",
+			dest: []any{
+				map[string]any{"name": "a", "tmp": 1},
+				map[string]any{"name": "b"},
+			},
+			path: Path{WildcardStep{}, "tmp"},
+			expected: []any{
+				map[string]any{"name": "a"},
+				map[string]any{"name": "b"},
+			},
+		},
+		{
+			name:    "wildcard on scalar",
+			dest:    "foo",
+			path:    Path{WildcardStep{}},
+			invalid: true,
+		},
+		{
+			name:     "slice",
+			dest:     []any{1, 2, 3, 4},
+			path:     Path{SliceStep{From: intp(1), To: intp(3)}},
+			expected: []any{1, 4},
+		},
+		{
+			name: "filter",
+			dest: []any{
+				map[string]any{"name": "a"},
+				map[string]any{"name": "b"},
+				map[string]any{"name": "a"},
+			},
+			path: Path{hasValue("name", "a")},
+			expected: []any{
+				map[string]any{"name": "b"},
+			},
+		},
+		{
+			name: "descent removes key everywhere",
+			dest: map[string]any{
+				"tmp": 1,
+				"spec": []any{
+					map[string]any{"tmp": map[string]any{"tmp": 2}},
+					map[string]any{"name": "foo"},
+				},
+			},
+			path: Path{DescentStep{Step: "tmp"}},
+			expected: map[string]any{
+				"spec": []any{
+					map[string]any{},
+					map[string]any{"name": "foo"},
+				},
+			},
+		},
+		{
+			name:     "optional missing key is a no-op",
+			dest:     map[string]any{"foo": "bar"},
+			path:     Path{OptionalStep{Step: "missing"}, "deeper"},
+			expected: map[string]any{"foo": "bar"},
+		},
+		{
+			name:     "optional out of bounds index is a no-op",
+			dest:     []any{1, 2},
+			path:     Path{OptionalStep{Step: 5}},
+			expected: []any{1, 2},
+		},
+		{
+			name:     "optional existing key",
+			dest:     map[string]any{"foo": map[string]any{"bar": 1, "baz": 2}},
+			path:     Path{OptionalStep{Step: "foo"}, OptionalStep{Step: "bar"}},
+			expected: map[string]any{"foo": map[string]any{"baz": 2}},
+		},
+		{
+			name:     "optional null value is a no-op",
+			dest:     map[string]any{"foo": nil},
+			path:     Path{OptionalStep{Step: "foo"}, "bar"},
+			expected: map[string]any{"foo": nil},
+		},
+		{
+			name:    "non-optional out of bounds index",
+			dest:    []any{1, 2},
+			path:    Path{5},
+			invalid: true,
+		},
 	}
 
 	for _, tc := range testcases {
@@ -278,3 +381,103 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteDoesNotModifyDestination(t *testing.T) {
+	dest := map[string]any{
+		"items": []any{
+			map[string]any{"name": "a", "image": "x"},
+			map[string]any{"name": "b", "image": "y"},
+			map[string]any{"name": "c", "image": "z"},
+		},
+	}
+
+	testcases := []Path{
+		{"items", 1},
+		{"items", 0, "image"},
+		{"items", WildcardStep{}, "image"},
+		{"items", SliceStep{}},
+		{DescentStep{Step: "name"}},
+	}
+
+	for _, path := range testcases {
+		t.Run(path.String(), func(t *testing.T) {
+			expected := cloneValue(t, dest)
+
+			if _, err := Delete(dest, path); err != nil {
+				t.Fatalf("Failed to delete: %v", err)
+			}
+
+			if !cmp.Equal(expected, dest) {
+				t.Fatalf("Original value was modified: %v", dest)
+			}
+		})
+	}
+}
+
+func TestDeleteWithPaths(t *testing.T) {
+	dest := map[string]any{
+		"items": []any{
+			map[string]any{"name": "a", "image": "x"},
+			map[string]any{"name": "b"},
+			map[string]any{"name": "c", "image": "z"},
+		},
+	}
+
+	testcases := []struct {
+		path     Path
+		expected []Path
+	}{
+		{
+			path:     Path{"items", int64(-1)},
+			expected: []Path{{"items", 2}},
+		},
+		{
+			path:     Path{"items", 1, "image"},
+			expected: []Path{},
+		},
+		{
+			path:     Path{"items", WildcardStep{}, "image"},
+			expected: []Path{{"items", 0, "image"}, {"items", 2, "image"}},
+		},
+		{
+			// items are removed from the end, so the indices stay valid
+			path:     Path{"items", SliceStep{}},
+			expected: []Path{{"items", 2}, {"items", 1}, {"items", 0}},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.path.String(), func(t *testing.T) {
+			_, paths, err := DeleteWithPaths(dest, testcase.path)
+			if err != nil {
+				t.Fatalf("Failed to delete: %v", err)
+			}
+
+			if !cmp.Equal(testcase.expected, paths) {
+				t.Fatalf("Unexpected paths:\n%s", cmp.Diff(testcase.expected, paths))
+			}
+		})
+	}
+}
+
+func cloneValue(t testing.TB, value any) any {
+	cloned, err := deepcopy.Clone(value)
+	if err != nil {
+		t.Fatalf("Failed to clone value: %v", err)
+	}
+
+	return cloned
+}
+
+func BenchmarkDeleteLargeDocument(b *testing.B) {
+	dest := largeDocument(50000)
+	path := Path{"items", 25000, "spec", "labels"}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Delete(dest, path); err != nil {
+			b.Fatalf("Failed to delete value: %v", err)
+		}
+	}
+}
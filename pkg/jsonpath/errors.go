@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is matched (using errors.Is) by all errors that are caused by a
+// path step not matching anything, i.e. KeyNotFoundError and
+// IndexOutOfBoundsError. This allows to distinguish absent values from type
+// errors like trying to use a string as a vector index (TypeMismatchError).
+var ErrNotFound = errors.New("not found")
+
+// PathError contains the location of an error inside a path. Path is the full
+// path given to Get/Set/Delete and StepIndex is the index of the failing step
+// in it.
+type PathError struct {
+	Path      Path
+	StepIndex int
+
+	// remaining is the number of steps after the failing step, as known at the
+	// time the error is created. Since steps like wildcards are resolved into
+	// concrete steps, this is the only reliable way to compute the StepIndex
+	// once the error reaches the public functions.
+	remaining int
+}
+
+// location returns the path up to and including the failing step.
+func (e *PathError) location() string {
+	if e.Path == nil || e.StepIndex < 0 || e.StepIndex >= len(e.Path) {
+		return ""
+	}
+
+	return " at " + e.Path[:e.StepIndex+1].String()
+}
+
+func (e *PathError) pathError() *PathError {
+	return e
+}
+
+type locatedError interface {
+	error
+	pathError() *PathError
+}
+
+// KeyNotFoundError is returned when an object does not contain the requested
+// key (or the value was null).
+type KeyNotFoundError struct {
+	PathError
+	Key string
+}
+
+var _ error = &KeyNotFoundError{}
+
+func (e *KeyNotFoundError) Error() string {
+	return fmt.Sprintf("no such key %q%s", e.Key, e.location())
+}
+
+func (*KeyNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// IndexOutOfBoundsError is returned when a vector index is out of range (or the
+// value was null).
+type IndexOutOfBoundsError struct {
+	PathError
+	Index  int
+	Length int
+}
+
+var _ error = &IndexOutOfBoundsError{}
+
+func (e *IndexOutOfBoundsError) Error() string {
+	return fmt.Sprintf("index %d out of bounds (length %d)%s", e.Index, e.Length, e.location())
+}
+
+func (*IndexOutOfBoundsError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// TypeMismatchError is returned when a step cannot be applied to a value, like
+// using a string step on a vector or descending into a scalar value.
+type TypeMismatchError struct {
+	PathError
+	Step  Step
+	Value any
+}
+
+var _ error = &TypeMismatchError{}
+
+func (e *TypeMismatchError) Error() string {
+	step := stepString(e.Step)
+
+	switch e.Value.(type) {
+	case []any:
+		return fmt.Sprintf("cannot use %s as a vector index%s", step, e.location())
+	case map[string]any:
+		return fmt.Sprintf("cannot use %s as an object key%s", step, e.location())
+	default:
+		return fmt.Sprintf("cannot descend with %s into %T%s", step, e.Value, e.location())
+	}
+}
+
+func newKeyNotFoundError(key string, remaining Path) error {
+	return &KeyNotFoundError{
+		PathError: PathError{remaining: len(remaining)},
+		Key:       key,
+	}
+}
+
+func newIndexOutOfBoundsError(index int, length int, remaining Path) error {
+	return &IndexOutOfBoundsError{
+		PathError: PathError{remaining: len(remaining)},
+		Index:     index,
+		Length:    length,
+	}
+}
+
+func newTypeMismatchError(step Step, value any, remaining Path) error {
+	return &TypeMismatchError{
+		PathError: PathError{remaining: len(remaining)},
+		Step:      step,
+		Value:     value,
+	}
+}
+
+// withPath sets the full path and the failing step's index on path errors.
+func withPath(err error, path Path) error {
+	var located locatedError
+	if errors.As(err, &located) {
+		pe := located.pathError()
+		if pe.Path == nil {
+			pe.Path = path
+			pe.StepIndex = len(path) - 1 - pe.remaining
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package jsonpath
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	document := func() any {
+		return map[string]any{
+			"items": []any{
+				map[string]any{"name": "a"},
+				map[string]any{"name": "b", "tags": []any{"x"}},
+			},
+			"spec": map[string]any{
+				"name": "foo",
+				"null": nil,
+			},
+		}
+	}
+
+	testcases := []struct {
+		name      string
+		path      Path
+		operation func(value any, path Path) (any, error)
+		check     func(t *testing.T, err error)
+		stepIndex int
+		message   string
+	}{
+		{
+			name: "missing key",
+			path: Path{"spec", "missing", "deeper"},
+			check: func(t *testing.T, err error) {
+				var e *KeyNotFoundError
+				if !errors.As(err, &e) || e.Key != "missing" {
+					t.Fatalf("Expected KeyNotFoundError for key %q, got %#v", "missing", err)
+				}
+			},
+			stepIndex: 1,
+			message:   `no such key "missing" at .spec.missing`,
+		},
+		{
+			name: "null value",
+			path: Path{"spec", "null", "foo"},
+			check: func(t *testing.T, err error) {
+				var e *KeyNotFoundError
+				if !errors.As(err, &e) {
+					t.Fatalf("Expected KeyNotFoundError, got %#v", err)
+				}
+			},
+			stepIndex: 2,
+			message:   `no such key "foo" at .spec.null.foo`,
+		},
+		{
+			name: "index out of bounds",
+			path: Path{"items", 5, "name"},
+			check: func(t *testing.T, err error) {
+				var e *IndexOutOfBoundsError
+				if !errors.As(err, &e) || e.Index != 5 || e.Length != 2 {
+					t.Fatalf("Expected IndexOutOfBoundsError for index 5 and length 2, got %#v", err)
+				}
+			},
+			stepIndex: 1,
+			message:   `index 5 out of bounds (length 2) at .items[5]`,
+		},
+		{
+			name: "string step on vector",
+			path: Path{"items", "foo"},
+			check: func(t *testing.T, err error) {
+				var e *TypeMismatchError
+				if !errors.As(err, &e) || e.Step != "foo" {
+					t.Fatalf("Expected TypeMismatchError for step %q, got %#v", "foo", err)
+				}
+			},
+			stepIndex: 1,
+			message:   `cannot use .foo as a vector index at .items.foo`,
+		},
+		{
+			name: "descending into a scalar",
+			path: Path{"spec", "name", 0},
+			check: func(t *testing.T, err error) {
+				var e *TypeMismatchError
+				if !errors.As(err, &e) || e.Value != "foo" {
+					t.Fatalf("Expected TypeMismatchError for value %q, got %#v", "foo", err)
+				}
+			},
+			stepIndex: 2,
+			message:   `cannot descend with [0] into string at .spec.name[0]`,
+		},
+		{
+			name: "multi step on scalar",
+			path: Path{"spec", "name", WildcardStep{}},
+			check: func(t *testing.T, err error) {
+				var e *TypeMismatchError
+				if !errors.As(err, &e) {
+					t.Fatalf("Expected TypeMismatchError, got %#v", err)
+				}
+			},
+			stepIndex: 2,
+			message:   `cannot descend with [*] into string at .spec.name[*]`,
+		},
+		{
+			name:      "set into scalar",
+			path:      Path{"spec", "name", "foo"},
+			operation: func(value any, path Path) (any, error) { return Set(value, path, 1) },
+			check: func(t *testing.T, err error) {
+				var e *TypeMismatchError
+				if !errors.As(err, &e) {
+					t.Fatalf("Expected TypeMismatchError, got %#v", err)
+				}
+			},
+			stepIndex: 2,
+			message:   `cannot descend with .foo into string at .spec.name.foo`,
+		},
+		{
+			name:      "set out of bounds",
+			path:      Path{"items", 1, "tags", 3},
+			operation: func(value any, path Path) (any, error) { return Set(value, path, 1) },
+			check: func(t *testing.T, err error) {
+				var e *IndexOutOfBoundsError
+				if !errors.As(err, &e) || e.Length != 1 {
+					t.Fatalf("Expected IndexOutOfBoundsError for length 1, got %#v", err)
+				}
+			},
+			stepIndex: 3,
+			message:   `index 3 out of bounds (length 1) at .items[1].tags[3]`,
+		},
+		{
+			name:      "delete out of bounds",
+			path:      Path{"items", -3},
+			operation: Delete,
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, ErrNotFound) {
+					t.Fatalf("Expected ErrNotFound, got %#v", err)
+				}
+			},
+			stepIndex: 1,
+			message:   `index -3 out of bounds (length 2) at .items[-3]`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			operation := tc.operation
+			if operation == nil {
+				operation = Get
+			}
+
+			_, err := operation(document(), tc.path)
+			if err == nil {
+				t.Fatal("Should have returned an error.")
+			}
+
+			tc.check(t, err)
+
+			var located locatedError
+			if !errors.As(err, &located) {
+				t.Fatalf("Error does not contain a location: %#v", err)
+			}
+
+			if pe := located.pathError(); pe.StepIndex != tc.stepIndex {
+				t.Errorf("Expected step index %d, got %d.", tc.stepIndex, pe.StepIndex)
+			}
+
+			if err.Error() != tc.message {
+				t.Errorf("Expected error message %q, got %q.", tc.message, err.Error())
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@
 package jsonpath
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -15,71 +16,212 @@ type VectorReader interface {
 	GetVectorItem(index int) (any, error)
 }
 
+// ObjectKeyLister can be implemented by custom objects to support steps that
+// match multiple keys, like wildcards and filters.
+type ObjectKeyLister interface {
+	ObjectReader
+	ObjectKeys() []string
+}
+
+// VectorSizer can be implemented by custom vectors to support steps that match
+// multiple items, like wildcards, slices and filters.
+type VectorSizer interface {
+	VectorReader
+	VectorLength() int
+}
+
+// Get returns the value at the given path. If the path contains steps that can
+// match multiple values (wildcards, slices, filters or recursive descents),
+// a vector of all matches is returned instead. Values that do not match the
+// steps following such a step (e.g. because they lack a key) are skipped.
+// If an optional step does not match or yields nil, nil is returned for the
+// entire path.
 func Get(value any, path Path) (any, error) {
-	if len(path) == 0 {
-		return value, nil
+	if path.IsMulti() {
+		matches, err := getAll(value, path, false, []any{})
+		if err != nil {
+			return nil, withPath(err, path)
+		}
+
+		return matches, nil
 	}
 
-	for _, step := range path {
-		if valueAsSlice, ok := value.([]any); ok {
-			index, ok := toIntegerStep(step)
-			if !ok {
-				return nil, fmt.Errorf("cannot use %v as an array index", step)
+	for i, step := range path {
+		var err error
+
+		step, optional := unwrapOptional(step)
+
+		value, err = getStep(value, step, path[i+1:])
+		if err != nil {
+			if optional && errors.Is(err, ErrNotFound) {
+				return nil, nil
 			}
 
-			if index < 0 || index >= len(valueAsSlice) {
-				return nil, fmt.Errorf("index %d out of bounds", index)
+			return nil, withPath(err, path)
+		}
+
+		// safe navigation: do not descend into nulls after optional steps
+		if optional && value == nil {
+			return nil, nil
+		}
+	}
+
+	return value, nil
+}
+
+// getStep applies a single key or index step. remaining are the steps after
+// this one and only used to locate errors.
+func getStep(value any, step Step, remaining Path) (any, error) {
+	// null has neither keys nor indices
+	if value == nil {
+		if index, ok := toIntegerStep(step); ok {
+			return nil, newIndexOutOfBoundsError(index, 0, remaining)
+		}
+
+		if key, ok := toStringStep(step); ok {
+			return nil, newKeyNotFoundError(key, remaining)
+		}
+	}
+
+	if valueAsSlice, ok := value.([]any); ok {
+		index, ok := toIntegerStep(step)
+		if !ok {
+			return nil, newTypeMismatchError(step, value, remaining)
+		}
+
+		normalized, ok := normalizeIndex(index, len(valueAsSlice))
+		if !ok {
+			return nil, newIndexOutOfBoundsError(index, len(valueAsSlice), remaining)
+		}
+
+		return valueAsSlice[normalized], nil
+	}
+
+	if vectorReader, ok := value.(VectorReader); ok {
+		index, ok := toIntegerStep(step)
+		if ok {
+			item, err := vectorReader.GetVectorItem(index)
+			if err != nil {
+				if length, ok := vectorLength(value); ok && errors.Is(err, ErrNotFound) {
+					return nil, newIndexOutOfBoundsError(index, length, remaining)
+				}
+
+				return nil, fmt.Errorf("cannot descend with %v (%T) into %T: %w", step, step, value, err)
 			}
 
-			value = valueAsSlice[index]
-			continue
+			return item, nil
 		}
+	}
 
-		if vectorReader, ok := value.(VectorReader); ok {
-			index, ok := toIntegerStep(step)
-			if ok {
-				var err error
+	if valueAsObject, ok := value.(map[string]any); ok {
+		key, ok := toStringStep(step)
+		if !ok {
+			return nil, newTypeMismatchError(step, value, remaining)
+		}
+
+		item, exists := valueAsObject[key]
+		if !exists {
+			return nil, newKeyNotFoundError(key, remaining)
+		}
+
+		return item, nil
+	}
 
-				value, err = vectorReader.GetVectorItem(index)
-				if err != nil {
-					return nil, fmt.Errorf("cannot descend with %v (%T) into %T: %w", step, step, value, err)
+	if objectReader, ok := value.(ObjectReader); ok {
+		key, ok := toStringStep(step)
+		if ok {
+			item, err := objectReader.GetObjectKey(key)
+			if err != nil {
+				if errors.Is(err, ErrNotFound) {
+					return nil, newKeyNotFoundError(key, remaining)
 				}
 
-				continue
+				return nil, fmt.Errorf("cannot descend with %v (%T) into %T: %w", step, step, value, err)
 			}
+
+			return item, nil
+		}
+	}
+
+	return nil, newTypeMismatchError(step, value, remaining)
+}
+
+// getAll appends all values matching the path to matches. Once the first
+// multi-value step has been applied (projected is true), values that do not
+// fit the remaining steps are silently skipped.
+func getAll(value any, path Path, projected bool, matches []any) ([]any, error) {
+	if len(path) == 0 {
+		return append(matches, value), nil
+	}
+
+	thisStep, optional := unwrapOptional(path[0])
+	remainingSteps := path[1:]
+
+	if descent, ok := thisStep.(DescentStep); ok {
+		// apply the step to the current value first ...
+		matches, err := getAll(value, append(Path{descent.Step}, remainingSteps...), true, matches)
+		if err != nil {
+			return nil, err
 		}
 
-		if valueAsObject, ok := value.(map[string]any); ok {
-			key, ok := toStringStep(step)
-			if !ok {
-				return nil, fmt.Errorf("cannot use %v as an object key", step)
+		// ... and then to all of its descendants
+		children, _, _ := matchingSteps(value, WildcardStep{})
+		for _, child := range children {
+			childValue, err := getStep(value, child, remainingSteps)
+			if err != nil {
+				return nil, err
 			}
 
-			var exists bool
-			value, exists = valueAsObject[key]
-			if !exists {
-				return nil, fmt.Errorf("no such key: %q", key)
+			matches, err = getAll(childValue, path, true, matches)
+			if err != nil {
+				return nil, err
 			}
+		}
+
+		return matches, nil
+	}
 
-			continue
+	if isMultiStep(thisStep) {
+		children, ok, err := matchingSteps(value, thisStep)
+		if err != nil {
+			return nil, err
 		}
 
-		if objectReader, ok := value.(ObjectReader); ok {
-			key, ok := toStringStep(step)
-			if ok {
-				var err error
+		if !ok {
+			if projected || optional {
+				return matches, nil
+			}
+
+			return nil, newTypeMismatchError(thisStep, value, remainingSteps)
+		}
 
-				value, err = objectReader.GetObjectKey(key)
-				if err != nil {
-					return nil, fmt.Errorf("cannot descend with %v (%T) into %T: %w", step, step, value, err)
-				}
+		for _, child := range children {
+			childValue, err := getStep(value, child, remainingSteps)
+			if err != nil {
+				return nil, err
+			}
 
-				continue
+			matches, err = getAll(childValue, remainingSteps, true, matches)
+			if err != nil {
+				return nil, err
 			}
 		}
 
-		return nil, fmt.Errorf("cannot descend with %v (%T) into %T", step, step, value)
+		return matches, nil
 	}
 
-	return value, nil
+	childValue, err := getStep(value, thisStep, remainingSteps)
+	if err != nil {
+		if projected || (optional && errors.Is(err, ErrNotFound)) {
+			return matches, nil
+		}
+
+		return nil, err
+	}
+
+	if optional && childValue == nil && len(remainingSteps) > 0 {
+		return matches, nil
+	}
+
+	return getAll(childValue, remainingSteps, projected, matches)
 }
@@ -4,6 +4,7 @@
 package jsonpath
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -12,6 +13,22 @@ import (
 
 type unknownType struct{}
 
+func intp(i int) *int {
+	return &i
+}
+
+// hasValue returns a filter that matches objects with the given key/value.
+func hasValue(key string, value any) FilterStep {
+	return func(item any) (bool, error) {
+		object, ok := item.(map[string]any)
+		if !ok {
+			return false, nil
+		}
+
+		return object[key] == value, nil
+	}
+}
+
 type customObjGetter struct {
 	value any
 }
@@ -126,9 +143,19 @@ func TestGet(t *testing.T) {
 			path:     Path{2},
 			expected: "baz",
 		},
+		{
+			value:    []any{"foo", "bar", "baz"},
+			path:     Path{-1},
+			expected: "baz",
+		},
+		{
+			value:    []any{"foo", "bar", "baz"},
+			path:     Path{-3},
+			expected: "foo",
+		},
 		{
 			value:   []any{"foo", "bar", "baz"},
-			path:    Path{-1},
+			path:    Path{-4},
 			invalid: true,
 		},
 		{
@@ -203,6 +230,205 @@ func TestGet(t *testing.T) {
 			path:    Path{"objectstep"},
 			invalid: true,
 		},
+
+		/////////////////////////////////////////////////////
+		// multi-value steps
+
+		{
+			value:    []any{"foo", "bar", "baz"},
+			path:     Path{WildcardStep{}},
+			expected: []any{"foo", "bar", "baz"},
+		},
+		{
+			value:    []any{},
+			path:     Path{WildcardStep{}},
+			expected: []any{},
+		},
+		{
+			// object values are returned in key order
+			value:    map[string]any{"b": 2, "a": 1, "c": 3},
+			path:     Path{WildcardStep{}},
+			expected: []any{1, 2, 3},
+		},
+		{
+			value:   "foo",
+			path:    Path{WildcardStep{}},
+			invalid: true,
+		},
+		{
+			// items without the key are skipped
+			value: map[string]any{
+				"items": []any{
+					map[string]any{"name": "a"},
+					map[string]any{"other": "b"},
+					"not-an-object",
+					map[string]any{"name": "c"},
+				},
+			},
+			path:     Path{"items", WildcardStep{}, "name"},
+			expected: []any{"a", "c"},
+		},
+		{
+			value: map[string]any{
+				"items": []any{
+					[]any{1, 2},
+					[]any{3, 4},
+				},
+			},
+			path:     Path{"items", WildcardStep{}, WildcardStep{}},
+			expected: []any{1, 2, 3, 4},
+		},
+		{
+			// steps before the first multi-value step must still match
+			value:   map[string]any{"items": []any{}},
+			path:    Path{"missing", WildcardStep{}},
+			invalid: true,
+		},
+		{
+			value:    []any{0, 1, 2, 3, 4},
+			path:     Path{SliceStep{From: intp(1), To: intp(3)}},
+			expected: []any{1, 2},
+		},
+		{
+			value:    []any{0, 1, 2, 3, 4},
+			path:     Path{SliceStep{From: intp(-2)}},
+			expected: []any{3, 4},
+		},
+		{
+			value:    []any{0, 1, 2, 3, 4},
+			path:     Path{SliceStep{To: intp(-1)}},
+			expected: []any{0, 1, 2, 3},
+		},
+		{
+			value:    []any{0, 1, 2, 3, 4},
+			path:     Path{SliceStep{From: intp(3), To: intp(99)}},
+			expected: []any{3, 4},
+		},
+		{
+			value:    []any{0, 1, 2, 3, 4},
+			path:     Path{SliceStep{From: intp(3), To: intp(1)}},
+			expected: []any{},
+		},
+		{
+			value:   map[string]any{"foo": "bar"},
+			path:    Path{SliceStep{}},
+			invalid: true,
+		},
+		{
+			value: []any{
+				map[string]any{"name": "a", "image": "img-a"},
+				map[string]any{"name": "b", "image": "img-b"},
+				map[string]any{"name": "a", "image": "img-c"},
+			},
+			path:     Path{hasValue("name", "a"), "image"},
+			expected: []any{"img-a", "img-c"},
+		},
+		{
+			value: map[string]any{
+				"x": map[string]any{"enabled": true},
+				"y": map[string]any{"enabled": false},
+			},
+			path:     Path{hasValue("enabled", true)},
+			expected: []any{map[string]any{"enabled": true}},
+		},
+		{
+			value: []any{1, 2},
+			path: Path{FilterStep(func(_ any) (bool, error) {
+				return false, errors.New("filter failed")
+			})},
+			invalid: true,
+		},
+		{
+			value: map[string]any{
+				"image": "root",
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{"image": "a"},
+						map[string]any{"image": "b", "sidecar": map[string]any{"image": "c"}},
+					},
+				},
+			},
+			path:     Path{DescentStep{Step: "image"}},
+			expected: []any{"root", "a", "b", "c"},
+		},
+		{
+			value: map[string]any{
+				"a": []any{[]any{1, 2}, 3},
+			},
+			path:     Path{DescentStep{Step: 0}},
+			expected: []any{[]any{1, 2}, 1},
+		},
+		{
+			value:    map[string]any{"foo": "bar"},
+			path:     Path{DescentStep{Step: "missing"}},
+			expected: []any{},
+		},
+
+		// optional steps
+
+		{
+			value:    map[string]any{"foo": "bar"},
+			path:     Path{OptionalStep{Step: "foo"}},
+			expected: "bar",
+		},
+		{
+			value:    map[string]any{"foo": "bar"},
+			path:     Path{OptionalStep{Step: "missing"}},
+			expected: nil,
+		},
+		{
+			value:    map[string]any{"foo": "bar"},
+			path:     Path{OptionalStep{Step: "missing"}, "deeper"},
+			expected: nil,
+		},
+		{
+			value:    map[string]any{"foo": nil},
+			path:     Path{"foo", OptionalStep{Step: "bar"}},
+			expected: nil,
+		},
+		{
+			value:    map[string]any{"foo": nil},
+			path:     Path{OptionalStep{Step: "foo"}, "bar"},
+			expected: nil,
+		},
+		{
+			value:   map[string]any{"foo": nil},
+			path:    Path{"foo", "bar"},
+			invalid: true,
+		},
+		{
+			value:   map[string]any{"foo": map[string]any{}},
+			path:    Path{OptionalStep{Step: "foo"}, "missing"},
+			invalid: true,
+		},
+		{
+			value:    []any{1, 2},
+			path:     Path{OptionalStep{Step: 5}},
+			expected: nil,
+		},
+		{
+			value:   []any{1, 2},
+			path:    Path{OptionalStep{Step: "foo"}},
+			invalid: true,
+		},
+		{
+			value:   "a string",
+			path:    Path{OptionalStep{Step: "foo"}},
+			invalid: true,
+		},
+		{
+			value: []any{
+				map[string]any{"meta": map[string]any{"name": "a"}},
+				map[string]any{"meta": nil},
+			},
+			path:     Path{WildcardStep{}, "meta", OptionalStep{Step: "name"}},
+			expected: []any{"a"},
+		},
+		{
+			value:    map[string]any{"foo": "bar"},
+			path:     Path{OptionalStep{Step: "items"}, WildcardStep{}},
+			expected: []any{},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -226,3 +452,50 @@ func TestGet(t *testing.T) {
 		})
 	}
 }
+
+func TestGetNotFound(t *testing.T) {
+	testcases := []struct {
+		value    any
+		path     Path
+		notFound bool
+	}{
+		{
+			value:    map[string]any{"foo": "bar"},
+			path:     Path{"missing"},
+			notFound: true,
+		},
+		{
+			value:    []any{1, 2},
+			path:     Path{2},
+			notFound: true,
+		},
+		{
+			value:    map[string]any{"foo": nil},
+			path:     Path{"foo", "bar"},
+			notFound: true,
+		},
+		{
+			value:    []any{1, 2},
+			path:     Path{"foo"},
+			notFound: false,
+		},
+		{
+			value:    map[string]any{"foo": "bar"},
+			path:     Path{"foo", 0},
+			notFound: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run("", func(t *testing.T) {
+			_, err := Get(tc.value, tc.path)
+			if err == nil {
+				t.Fatal("Should have returned an error.")
+			}
+
+			if errors.Is(err, ErrNotFound) != tc.notFound {
+				t.Fatalf("Expected errors.Is(err, ErrNotFound) to be %v, but got %v (error: %v)", tc.notFound, !tc.notFound, err)
+			}
+		})
+	}
+}
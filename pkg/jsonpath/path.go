@@ -3,24 +3,198 @@
 
 package jsonpath
 
-import "go.xrstf.de/rudi/pkg/lang/ast"
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.xrstf.de/rudi/pkg/lang/ast"
+)
 
 type Path []Step
 type Step any
 
+// String returns the path in Rudi syntax, like ".foo[1].bar".
+func (p Path) String() string {
+	result := ""
+	for _, step := range p {
+		result += stepString(step)
+	}
+
+	return result
+}
+
+func stepString(s Step) string {
+	if key, ok := toStringStep(s); ok {
+		if ast.PathIdentifierPattern.MatchString(key) {
+			return "." + key
+		}
+
+		return fmt.Sprintf("[%q]", key)
+	}
+
+	if index, ok := toIntegerStep(s); ok {
+		return fmt.Sprintf("[%d]", index)
+	}
+
+	if stringer, ok := s.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+
+	return fmt.Sprintf("[%v]", s)
+}
+
+// WildcardStep matches all items of a vector or all values of an object.
+type WildcardStep struct{}
+
+func (WildcardStep) String() string {
+	return "[*]"
+}
+
+// SliceStep matches a range of vector items. Nil bounds mean the beginning
+// or end of the vector, negative bounds count from the end.
+type SliceStep struct {
+	From *int
+	To   *int
+}
+
+func (s SliceStep) String() string {
+	result := "["
+	if s.From != nil {
+		result += fmt.Sprintf("%d", *s.From)
+	}
+
+	result += ":"
+
+	if s.To != nil {
+		result += fmt.Sprintf("%d", *s.To)
+	}
+
+	return result + "]"
+}
+
+// FilterStep matches all vector items and object values for which the
+// function returns true.
+type FilterStep func(value any) (bool, error)
+
+func (FilterStep) String() string {
+	return "[?filter]"
+}
+
+// DescentStep applies its step to the current value and all of its
+// descendants.
+type DescentStep struct {
+	Step Step
+}
+
+func (d DescentStep) String() string {
+	inner := stepString(d.Step)
+	if strings.HasPrefix(inner, ".") {
+		return "." + inner
+	}
+
+	return ".." + inner
+}
+
+// OptionalStep wraps a step that is allowed to not match. If it does not match,
+// Get returns nil instead of an error and Delete does nothing. Update ignores
+// the optional flag and creates missing keys as usual.
+type OptionalStep struct {
+	Step Step
+}
+
+func (o OptionalStep) String() string {
+	return stepString(o.Step) + "?"
+}
+
+// unwrapOptional returns the step wrapped by an OptionalStep and true, or the
+// step itself and false for all other steps.
+func unwrapOptional(s Step) (Step, bool) {
+	if optional, ok := s.(OptionalStep); ok {
+		return optional.Step, true
+	}
+
+	return s, false
+}
+
 func FromEvaluatedPath(evaledPath ast.EvaluatedPathExpression) Path {
 	p := Path{}
 	for _, step := range evaledPath.Steps {
-		if i := step.IntegerValue; i != nil {
-			p = append(p, *i)
-		} else if s := step.StringValue; s != nil {
-			p = append(p, *s)
+		if converted := fromEvaluatedStep(step); converted != nil {
+			p = append(p, converted)
 		}
 	}
 
 	return p
 }
 
+func fromEvaluatedStep(step ast.EvaluatedPathStep) Step {
+	if step.Optional {
+		inner := step
+		inner.Optional = false
+
+		return OptionalStep{Step: fromEvaluatedStep(inner)}
+	}
+
+	if step.Descent {
+		inner := step
+		inner.Descent = false
+
+		return DescentStep{Step: fromEvaluatedStep(inner)}
+	}
+
+	switch {
+	case step.IntegerValue != nil:
+		return *step.IntegerValue
+	case step.StringValue != nil:
+		return *step.StringValue
+	case step.Wildcard:
+		return WildcardStep{}
+	case step.Slice != nil:
+		return SliceStep{
+			From: toIntPointer(step.Slice.From),
+			To:   toIntPointer(step.Slice.To),
+		}
+	case step.Filter != nil:
+		return FilterStep(step.Filter.Matches)
+	default:
+		return nil
+	}
+}
+
+func toIntPointer(i *int64) *int {
+	if i == nil {
+		return nil
+	}
+
+	converted := int(*i)
+
+	return &converted
+}
+
+// IsMulti returns true if the path contains steps that can match more than
+// one value. Get returns a vector of all matches for such paths.
+func (p Path) IsMulti() bool {
+	for _, step := range p {
+		if isMultiStep(step) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isMultiStep(s Step) bool {
+	s, _ = unwrapOptional(s)
+
+	switch s.(type) {
+	case WildcardStep, SliceStep, FilterStep, DescentStep, selectorListStep, steppedSliceStep:
+		return true
+	default:
+		return false
+	}
+}
+
 func toIntegerStep(s Step) (int, bool) {
 	switch asserted := s.(type) {
 	case int:
@@ -42,3 +216,214 @@ func toStringStep(s Step) (string, bool) {
 		return "", false
 	}
 }
+
+// normalizeIndex turns negative indices into positive ones, counting from the
+// end of the vector. The second return value is false if the index is out of
+// bounds.
+func normalizeIndex(index int, length int) (int, bool) {
+	if index < 0 {
+		index += length
+	}
+
+	return index, index >= 0 && index < length
+}
+
+// matchingSteps resolves a step that can match multiple values into the
+// concrete indices (for vectors) or keys (for objects) it matches. The second
+// return value is false if the step cannot be applied to the value at all.
+// Object keys are returned in sorted order.
+func matchingSteps(value any, step Step) ([]Step, bool, error) {
+	if list, ok := step.(selectorListStep); ok {
+		return list.matchingSteps(value)
+	}
+
+	if length, ok := vectorLength(value); ok {
+		var indices []int
+
+		switch s := step.(type) {
+		case WildcardStep:
+			indices = sliceRange(length, nil, nil)
+		case SliceStep:
+			indices = sliceRange(length, s.From, s.To)
+		case steppedSliceStep:
+			indices = s.indices(length)
+		case FilterStep:
+			for i := 0; i < length; i++ {
+				item, err := getStep(value, i, nil)
+				if err != nil {
+					return nil, false, err
+				}
+
+				matches, err := s(item)
+				if err != nil {
+					return nil, false, err
+				}
+
+				if matches {
+					indices = append(indices, i)
+				}
+			}
+		default:
+			return nil, false, nil
+		}
+
+		steps := make([]Step, len(indices))
+		for i, index := range indices {
+			steps[i] = index
+		}
+
+		return steps, true, nil
+	}
+
+	if keys, ok := objectKeys(value); ok {
+		steps := []Step{}
+
+		switch s := step.(type) {
+		case WildcardStep:
+			for _, key := range keys {
+				steps = append(steps, key)
+			}
+		case FilterStep:
+			for _, key := range keys {
+				item, err := getStep(value, key, nil)
+				if err != nil {
+					return nil, false, err
+				}
+
+				matches, err := s(item)
+				if err != nil {
+					return nil, false, err
+				}
+
+				if matches {
+					steps = append(steps, key)
+				}
+			}
+		default:
+			return nil, false, nil
+		}
+
+		return steps, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// vectorLength returns the number of items in a vector. The second return
+// value is false if the value is not a vector.
+func vectorLength(value any) (int, bool) {
+	switch asserted := value.(type) {
+	case []any:
+		return len(asserted), true
+	case VectorSizer:
+		return asserted.VectorLength(), true
+	default:
+		return 0, false
+	}
+}
+
+// objectKeys returns the sorted keys of an object. The second return value is
+// false if the value is not an object.
+func objectKeys(value any) ([]string, bool) {
+	var keys []string
+
+	switch asserted := value.(type) {
+	case map[string]any:
+		keys = make([]string, 0, len(asserted))
+		for key := range asserted {
+			keys = append(keys, key)
+		}
+	case ObjectKeyLister:
+		keys = append([]string{}, asserted.ObjectKeys()...)
+	default:
+		return nil, false
+	}
+
+	sort.Strings(keys)
+
+	return keys, true
+}
+
+// sliceRange returns the indices within [from, to), with negative bounds
+// counting from the end and out of range bounds being clamped.
+func sliceRange(length int, from *int, to *int) []int {
+	clamp := func(bound *int, fallback int) int {
+		if bound == nil {
+			return fallback
+		}
+
+		b := *bound
+		if b < 0 {
+			b += length
+		}
+
+		switch {
+		case b < 0:
+			return 0
+		case b > length:
+			return length
+		default:
+			return b
+		}
+	}
+
+	start := clamp(from, 0)
+	end := clamp(to, length)
+
+	indices := []int{}
+	for i := start; i < end; i++ {
+		indices = append(indices, i)
+	}
+
+	return indices
+}
+
+// stepMatches returns true if the step can be applied to the value without
+// creating new keys, i.e. the key or index exists. Descents are never
+// nested, so step is never a DescentStep.
+func stepMatches(value any, step Step) bool {
+	switch step.(type) {
+	case WildcardStep, FilterStep:
+		if _, ok := vectorLength(value); ok {
+			return true
+		}
+
+		_, ok := objectKeys(value)
+		return ok
+	case SliceStep, steppedSliceStep:
+		_, ok := vectorLength(value)
+		return ok
+	case selectorListStep:
+		_, isVector := vectorLength(value)
+		_, isObject := objectKeys(value)
+		return isVector || isObject
+	}
+
+	if index, ok := toIntegerStep(step); ok {
+		if slice, ok := value.([]any); ok {
+			_, inBounds := normalizeIndex(index, len(slice))
+			return inBounds
+		}
+
+		if reader, ok := value.(VectorReader); ok {
+			_, err := reader.GetVectorItem(index)
+			return err == nil
+		}
+
+		return false
+	}
+
+	if key, ok := toStringStep(step); ok {
+		if object, ok := value.(map[string]any); ok {
+			_, exists := object[key]
+			return exists
+		}
+
+		if reader, ok := value.(ObjectReader); ok {
+			_, err := reader.GetObjectKey(key)
+			return err == nil
+		}
+	}
+
+	return false
+}
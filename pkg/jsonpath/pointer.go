@@ -0,0 +1,213 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pointerIndexPattern is the array-index rule from RFC 6901.
+var pointerIndexPattern = regexp.MustCompile(`^(0|[1-9][0-9]*)$`)
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// parsePointerTokens splits a JSON Pointer (RFC 6901) into its unescaped
+// reference tokens.
+func parsePointerTokens(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must be empty or start with a slash", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		unescaped, err := unescapePointerToken(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON pointer %q: %w", pointer, err)
+		}
+
+		tokens[i] = unescaped
+	}
+
+	return tokens, nil
+}
+
+func unescapePointerToken(token string) (string, error) {
+	if !strings.Contains(token, "~") {
+		return token, nil
+	}
+
+	var result strings.Builder
+
+	for i := 0; i < len(token); i++ {
+		if token[i] != '~' {
+			result.WriteByte(token[i])
+			continue
+		}
+
+		if i+1 >= len(token) {
+			return "", errors.New("incomplete escape sequence at end of token")
+		}
+
+		switch token[i+1] {
+		case '0':
+			result.WriteByte('~')
+		case '1':
+			result.WriteByte('/')
+		default:
+			return "", fmt.Errorf("invalid escape sequence ~%c", token[i+1])
+		}
+
+		i++
+	}
+
+	return result.String(), nil
+}
+
+// FromPointer converts a JSON Pointer (RFC 6901) like "/spec/containers/0" into
+// a Path. Since pointers do not distinguish between object keys and vector
+// indices, all tokens that look like indices are turned into integer steps. Use
+// ResolvePointer to decide based on an actual document instead.
+func FromPointer(pointer string) (Path, error) {
+	tokens, err := parsePointerTokens(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	path := Path{}
+	for _, token := range tokens {
+		if pointerIndexPattern.MatchString(token) {
+			if index, err := strconv.Atoi(token); err == nil {
+				path = append(path, index)
+				continue
+			}
+		}
+
+		path = append(path, token)
+	}
+
+	return path, nil
+}
+
+// ResolvePointer converts a JSON Pointer (RFC 6901) into a Path, using the
+// given value to decide whether a token refers to an object key or a vector
+// index. Tokens that point to values that do not exist yet are treated as
+// object keys. The "-" token refers to the (nonexistent) item after the last
+// vector item and is turned into an index equal to the vector's length.
+func ResolvePointer(value any, pointer string) (Path, error) {
+	tokens, err := parsePointerTokens(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	path := Path{}
+	for i, token := range tokens {
+		var step Step = token
+
+		switch asserted := value.(type) {
+		case []any:
+			if token == "-" {
+				path = append(path, len(asserted))
+				value = nil
+				continue
+			}
+
+			if !pointerIndexPattern.MatchString(token) {
+				remaining := Path{}
+				for _, t := range tokens[i+1:] {
+					remaining = append(remaining, t)
+				}
+
+				full := append(append(Path{}, path...), token)
+				full = append(full, remaining...)
+
+				return nil, withPath(newTypeMismatchError(token, value, remaining), full)
+			}
+
+			index, err := strconv.Atoi(token)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q: %w", token, err)
+			}
+
+			step = index
+
+			if index < len(asserted) {
+				value = asserted[index]
+			} else {
+				value = nil
+			}
+
+		case map[string]any:
+			value = asserted[token]
+
+		default:
+			value = nil
+		}
+
+		path = append(path, step)
+	}
+
+	return path, nil
+}
+
+// SetPointer sets the value at the given JSON Pointer (RFC 6901), like Set
+// does for paths. If the last token is "-" or an index equal to the length of
+// a vector, the value is appended to the vector, like the "add" operation in
+// JSON Patch (RFC 6902) does.
+func SetPointer(dest any, pointer string, newValue any) (any, error) {
+	path, err := ResolvePointer(dest, pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(path) > 0 {
+		parentPath := path[:len(path)-1]
+
+		if index, ok := toIntegerStep(path[len(path)-1]); ok {
+			if parent, err := Get(dest, parentPath); err == nil {
+				if vector, ok := parent.([]any); ok && index == len(vector) {
+					return Set(dest, parentPath, append(vector[:len(vector):len(vector)], newValue))
+				}
+			}
+		}
+	}
+
+	return Set(dest, path, newValue)
+}
+
+// ToPointer converts the path into a JSON Pointer (RFC 6901). This is only
+// possible for paths that consist solely of object keys and non-negative
+// vector indices.
+func (p Path) ToPointer() (string, error) {
+	var result strings.Builder
+
+	for _, step := range p {
+		step, _ = unwrapOptional(step)
+
+		if index, ok := toIntegerStep(step); ok {
+			if index < 0 {
+				return "", fmt.Errorf("cannot convert negative index %d to JSON pointer", index)
+			}
+
+			result.WriteString("/" + strconv.Itoa(index))
+			continue
+		}
+
+		if key, ok := toStringStep(step); ok {
+			result.WriteString("/" + pointerEscaper.Replace(key))
+			continue
+		}
+
+		return "", fmt.Errorf("cannot convert %s to JSON pointer", stepString(step))
+	}
+
+	return result.String(), nil
+}
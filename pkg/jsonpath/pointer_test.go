@@ -0,0 +1,246 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFromPointer(t *testing.T) {
+	testcases := []struct {
+		pointer  string
+		expected Path
+		invalid  bool
+	}{
+		{
+			pointer:  "",
+			expected: Path{},
+		},
+		{
+			pointer:  "/",
+			expected: Path{""},
+		},
+		{
+			pointer:  "/spec/containers/0/image",
+			expected: Path{"spec", "containers", 0, "image"},
+		},
+		{
+			pointer:  "/a~1b/m~0n/01/-",
+			expected: Path{"a/b", "m~n", "01", "-"},
+		},
+		{
+			pointer: "spec",
+			invalid: true,
+		},
+		{
+			pointer: "/foo~2",
+			invalid: true,
+		},
+		{
+			pointer: "/foo~",
+			invalid: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.pointer, func(t *testing.T) {
+			result, err := FromPointer(tc.pointer)
+			if err != nil {
+				if !tc.invalid {
+					t.Fatalf("Failed to parse: %v", err)
+				}
+
+				return
+			}
+
+			if tc.invalid {
+				t.Fatalf("Should not have been able to parse pointer, but got: %v", result)
+			}
+
+			if !cmp.Equal(tc.expected, result) {
+				t.Fatalf("Expected %v, but got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestResolvePointer(t *testing.T) {
+	document := map[string]any{
+		"labels": map[string]any{"0": "zero"},
+		"items":  []any{"a", map[string]any{"1": "one"}},
+	}
+
+	testcases := []struct {
+		pointer  string
+		expected Path
+		invalid  bool
+	}{
+		{
+			pointer:  "/labels/0",
+			expected: Path{"labels", "0"},
+		},
+		{
+			pointer:  "/items/1/1",
+			expected: Path{"items", 1, "1"},
+		},
+		{
+			pointer:  "/items/5/foo",
+			expected: Path{"items", 5, "foo"},
+		},
+		{
+			pointer:  "/missing/0",
+			expected: Path{"missing", "0"},
+		},
+		{
+			pointer:  "/items/-",
+			expected: Path{"items", 2},
+		},
+		{
+			pointer:  "/labels/-",
+			expected: Path{"labels", "-"},
+		},
+		{
+			pointer: "/items/foo",
+			invalid: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.pointer, func(t *testing.T) {
+			result, err := ResolvePointer(document, tc.pointer)
+			if err != nil {
+				if !tc.invalid {
+					t.Fatalf("Failed to resolve: %v", err)
+				}
+
+				return
+			}
+
+			if tc.invalid {
+				t.Fatalf("Should not have been able to resolve pointer, but got: %v", result)
+			}
+
+			if !cmp.Equal(tc.expected, result) {
+				t.Fatalf("Expected %v, but got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestSetPointer(t *testing.T) {
+	testcases := []struct {
+		pointer  string
+		expected any
+		invalid  bool
+	}{
+		{
+			pointer:  "",
+			expected: "new",
+		},
+		{
+			pointer:  "/items/1",
+			expected: map[string]any{"items": []any{"a", "new", "c"}, "labels": map[string]any{"0": "zero"}},
+		},
+		{
+			pointer:  "/items/-",
+			expected: map[string]any{"items": []any{"a", "b", "c", "new"}, "labels": map[string]any{"0": "zero"}},
+		},
+		{
+			pointer:  "/items/3",
+			expected: map[string]any{"items": []any{"a", "b", "c", "new"}, "labels": map[string]any{"0": "zero"}},
+		},
+		{
+			pointer:  "/labels/-",
+			expected: map[string]any{"items": []any{"a", "b", "c"}, "labels": map[string]any{"0": "zero", "-": "new"}},
+		},
+		{
+			pointer: "/items/4",
+			invalid: true,
+		},
+		{
+			pointer: "/items/-/foo",
+			invalid: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.pointer, func(t *testing.T) {
+			// leave spare capacity to detect appends that modify the original
+			items := append(make([]any, 0, 4), "a", "b", "c")
+			document := map[string]any{
+				"labels": map[string]any{"0": "zero"},
+				"items":  items,
+			}
+
+			result, err := SetPointer(document, tc.pointer, "new")
+			if err != nil {
+				if !tc.invalid {
+					t.Fatalf("Failed to set: %v", err)
+				}
+
+				return
+			}
+
+			if tc.invalid {
+				t.Fatalf("Should not have been able to set pointer, but got: %v", result)
+			}
+
+			if !cmp.Equal(tc.expected, result) {
+				t.Fatalf("Expected %v, but got %v", tc.expected, result)
+			}
+
+			if items[:4][3] != nil {
+				t.Fatalf("Original vector was modified: %v", items[:4])
+			}
+		})
+	}
+}
+
+func TestToPointer(t *testing.T) {
+	testcases := []struct {
+		path     Path
+		expected string
+		invalid  bool
+	}{
+		{
+			path:     Path{},
+			expected: "",
+		},
+		{
+			path:     Path{"spec", 0, OptionalStep{Step: "a/b~c"}},
+			expected: "/spec/0/a~1b~0c",
+		},
+		{
+			path:    Path{"items", -1},
+			invalid: true,
+		},
+		{
+			path:    Path{"items", WildcardStep{}},
+			invalid: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.expected, func(t *testing.T) {
+			result, err := tc.path.ToPointer()
+			if err != nil {
+				if !tc.invalid {
+					t.Fatalf("Failed to convert: %v", err)
+				}
+
+				return
+			}
+
+			if tc.invalid {
+				t.Fatalf("Should not have been able to convert path, but got: %q", result)
+			}
+
+			if result != tc.expected {
+				t.Fatalf("Expected %q, but got %q", tc.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,1261 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package jsonpath
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Query evaluates a JSONPath query (RFC 9535) like "$.spec.containers[*].image"
+// against the value and returns all matching values. Like in the RFC, queries
+// that do not match anything simply return an empty vector.
+func Query(value any, query string) ([]any, error) {
+	root := value
+
+	path, err := parseQuery(query, &root)
+	if err != nil {
+		return nil, err
+	}
+
+	return getAll(value, path, true, []any{})
+}
+
+// FromJSONPath converts a JSONPath query (RFC 9535) into a Path. Filter
+// expressions are supported, but must not reference the root value ($); use
+// Query for those. Selector lists like "[1,2]" and slices with a step other
+// than 1 cannot be represented as a Path and return an error.
+func FromJSONPath(query string) (Path, error) {
+	path, err := parseQuery(query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, step := range path {
+		if descent, ok := step.(DescentStep); ok {
+			step = descent.Step
+		}
+
+		switch step.(type) {
+		case selectorListStep:
+			return nil, fmt.Errorf("invalid JSONPath %q: selector lists cannot be converted to a path", query)
+		case steppedSliceStep:
+			return nil, fmt.Errorf("invalid JSONPath %q: slices with a step other than 1 cannot be converted to a path", query)
+		}
+	}
+
+	return path, nil
+}
+
+// selectorListStep is a list of selectors like "[0,2]" or "['a','b']". Its
+// matches are the matches of all selectors, in order. Selector lists can only
+// be used in queries, as they have no equivalent in Rudi path expressions.
+type selectorListStep []Step
+
+func (l selectorListStep) String() string {
+	selectors := make([]string, len(l))
+	for i, selector := range l {
+		if key, ok := toStringStep(selector); ok {
+			selectors[i] = quoteJSONPathString(key)
+		} else {
+			selectors[i] = strings.TrimSuffix(strings.TrimPrefix(stepString(selector), "["), "]")
+		}
+	}
+
+	return "[" + strings.Join(selectors, ",") + "]"
+}
+
+// matchingSteps returns the matches of all selectors. Names only match
+// on objects and indices only on vectors.
+func (l selectorListStep) matchingSteps(value any) ([]Step, bool, error) {
+	_, isVector := vectorLength(value)
+	_, isObject := objectKeys(value)
+
+	if !isVector && !isObject {
+		return nil, false, nil
+	}
+
+	steps := []Step{}
+
+	for _, selector := range l {
+		if isMultiStep(selector) {
+			matched, _, err := matchingSteps(value, selector)
+			if err != nil {
+				return nil, false, err
+			}
+
+			steps = append(steps, matched...)
+			continue
+		}
+
+		if stepMatches(value, selector) {
+			steps = append(steps, selector)
+		}
+	}
+
+	return steps, true, nil
+}
+
+// steppedSliceStep is a slice with a step other than 1, like "[::2]" or
+// "[::-1]". Like selector lists, these can only be used in queries.
+type steppedSliceStep struct {
+	From *int
+	To   *int
+	Step int
+}
+
+func (s steppedSliceStep) String() string {
+	result := "["
+	if s.From != nil {
+		result += fmt.Sprintf("%d", *s.From)
+	}
+
+	result += ":"
+
+	if s.To != nil {
+		result += fmt.Sprintf("%d", *s.To)
+	}
+
+	return result + fmt.Sprintf(":%d]", s.Step)
+}
+
+// indices returns the selected indices as defined in RFC 9535, section
+// 2.3.4.2.2. Negative steps select items in reverse order.
+func (s steppedSliceStep) indices(length int) []int {
+	indices := []int{}
+
+	if s.Step == 0 {
+		return indices
+	}
+
+	bound := func(value *int, fallback int) int {
+		b := fallback
+		if value != nil {
+			b = *value
+		}
+
+		if b < 0 {
+			b += length
+		}
+
+		return b
+	}
+
+	clamp := func(value, lower, upper int) int {
+		if value < lower {
+			return lower
+		}
+
+		if value > upper {
+			return upper
+		}
+
+		return value
+	}
+
+	if s.Step > 0 {
+		lower := clamp(bound(s.From, 0), 0, length)
+		upper := clamp(bound(s.To, length), 0, length)
+
+		for i := lower; i < upper; i += s.Step {
+			indices = append(indices, i)
+		}
+	} else {
+		upper := clamp(bound(s.From, length-1), -1, length-1)
+		lower := clamp(bound(s.To, -length-1), -1, length-1)
+
+		for i := upper; lower < i; i += s.Step {
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
+
+// ToJSONPath converts the path into a JSONPath query (RFC 9535). Filter steps
+// cannot be converted, as they are Go functions.
+func (p Path) ToJSONPath() (string, error) {
+	var result strings.Builder
+
+	result.WriteString("$")
+
+	for _, step := range p {
+		step, _ = unwrapOptional(step)
+
+		converted, err := jsonPathSegment(step)
+		if err != nil {
+			return "", err
+		}
+
+		result.WriteString(converted)
+	}
+
+	return result.String(), nil
+}
+
+var memberNamePattern = regexp.MustCompile(`^[\p{L}_][\p{L}\p{N}_]*$`)
+
+func jsonPathSegment(step Step) (string, error) {
+	if key, ok := toStringStep(step); ok {
+		if memberNamePattern.MatchString(key) {
+			return "." + key, nil
+		}
+
+		return "[" + quoteJSONPathString(key) + "]", nil
+	}
+
+	if index, ok := toIntegerStep(step); ok {
+		return fmt.Sprintf("[%d]", index), nil
+	}
+
+	switch asserted := step.(type) {
+	case WildcardStep, SliceStep:
+		return stepString(asserted), nil
+
+	case DescentStep:
+		inner, err := jsonPathSegment(asserted.Step)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(inner, ".") {
+			return "." + inner, nil
+		}
+
+		return ".." + inner, nil
+	}
+
+	return "", fmt.Errorf("cannot convert %s to JSONPath", stepString(step))
+}
+
+func quoteJSONPathString(s string) string {
+	var result strings.Builder
+
+	result.WriteByte('\'')
+
+	for _, r := range s {
+		switch r {
+		case '\'':
+			result.WriteString(`\'`)
+		case '\\':
+			result.WriteString(`\\`)
+		case '\b':
+			result.WriteString(`\b`)
+		case '\f':
+			result.WriteString(`\f`)
+		case '\n':
+			result.WriteString(`\n`)
+		case '\r':
+			result.WriteString(`\r`)
+		case '\t':
+			result.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				result.WriteString(fmt.Sprintf(`\u%04x`, r))
+			} else {
+				result.WriteRune(r)
+			}
+		}
+	}
+
+	result.WriteByte('\'')
+
+	return result.String()
+}
+
+// queryParser is a recursive descent parser for RFC 9535 queries. root is nil
+// when references to the root value are not allowed in filters.
+type queryParser struct {
+	input string
+	pos   int
+	root  *any
+}
+
+func parseQuery(query string, root *any) (Path, error) {
+	p := &queryParser{input: query, root: root}
+
+	if !p.consume("$") {
+		return nil, p.errorf("query must start with $")
+	}
+
+	path, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", p.input[p.pos:])
+	}
+
+	return path, nil
+}
+
+func (p *queryParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid JSONPath %q at position %d: %s", p.input, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *queryParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *queryParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+
+	return p.input[p.pos]
+}
+
+func (p *queryParser) consume(s string) bool {
+	if strings.HasPrefix(p.input[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+
+	return false
+}
+
+func (p *queryParser) skipBlank() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *queryParser) parseSegments() (Path, error) {
+	path := Path{}
+
+	for {
+		// blanks are allowed between segments, but not after the last one
+		start := p.pos
+		p.skipBlank()
+
+		switch {
+		case p.consume(".."):
+			step, err := p.parseDescendantSelector()
+			if err != nil {
+				return nil, err
+			}
+
+			path = append(path, DescentStep{Step: step})
+
+		case p.consume("."):
+			if p.consume("*") {
+				path = append(path, WildcardStep{})
+				continue
+			}
+
+			name, ok := p.parseMemberName()
+			if !ok {
+				return nil, p.errorf("expected member name or * after .")
+			}
+
+			path = append(path, name)
+
+		case p.peek() == '[':
+			step, err := p.parseBracketedSelection()
+			if err != nil {
+				return nil, err
+			}
+
+			path = append(path, step)
+
+		default:
+			p.pos = start
+			return path, nil
+		}
+	}
+}
+
+func (p *queryParser) parseDescendantSelector() (Step, error) {
+	if p.consume("*") {
+		return WildcardStep{}, nil
+	}
+
+	if p.peek() == '[' {
+		return p.parseBracketedSelection()
+	}
+
+	name, ok := p.parseMemberName()
+	if !ok {
+		return nil, p.errorf("expected member name, * or [ after ..")
+	}
+
+	return name, nil
+}
+
+func (p *queryParser) parseMemberName() (string, bool) {
+	start := p.pos
+
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+
+		isFirst := r == '_' || r >= 0x80 || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		isDigit := r >= '0' && r <= '9'
+
+		if !isFirst && (!isDigit || p.pos == start) {
+			break
+		}
+
+		p.pos += size
+	}
+
+	return p.input[start:p.pos], p.pos > start
+}
+
+func (p *queryParser) parseBracketedSelection() (Step, error) {
+	p.consume("[")
+	p.skipBlank()
+
+	step, err := p.parseSelector()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipBlank()
+
+	selectors := selectorListStep{step}
+
+	for p.consume(",") {
+		p.skipBlank()
+
+		step, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+
+		selectors = append(selectors, step)
+
+		p.skipBlank()
+	}
+
+	if !p.consume("]") {
+		return nil, p.errorf("expected ]")
+	}
+
+	if len(selectors) == 1 {
+		return step, nil
+	}
+
+	return selectors, nil
+}
+
+func (p *queryParser) parseSelector() (Step, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		return p.parseStringLiteral()
+
+	case c == '*':
+		p.pos++
+		return WildcardStep{}, nil
+
+	case c == '?':
+		p.pos++
+		p.skipBlank()
+
+		expr, err := p.parseLogicalOr()
+		if err != nil {
+			return nil, err
+		}
+
+		return FilterStep(expr), nil
+
+	default:
+		return p.parseIndexOrSlice()
+	}
+}
+
+func (p *queryParser) parseIndexOrSlice() (Step, error) {
+	start, hasStart, err := p.parseOptionalInteger()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipBlank()
+
+	if !p.consume(":") {
+		if !hasStart {
+			return nil, p.errorf("expected selector")
+		}
+
+		return start, nil
+	}
+
+	p.skipBlank()
+
+	end, hasEnd, err := p.parseOptionalInteger()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipBlank()
+
+	slice := SliceStep{}
+	if hasStart {
+		slice.From = &start
+	}
+
+	if hasEnd {
+		slice.To = &end
+	}
+
+	if p.consume(":") {
+		p.skipBlank()
+
+		step, hasStep, err := p.parseOptionalInteger()
+		if err != nil {
+			return nil, err
+		}
+
+		if hasStep && step != 1 {
+			return steppedSliceStep{From: slice.From, To: slice.To, Step: step}, nil
+		}
+	}
+
+	return slice, nil
+}
+
+func (p *queryParser) parseOptionalInteger() (int, bool, error) {
+	start := p.pos
+
+	p.consume("-")
+
+	digits := p.pos
+	for !p.eof() && p.peek() >= '0' && p.peek() <= '9' {
+		p.pos++
+	}
+
+	if p.pos == digits {
+		p.pos = start
+		return 0, false, nil
+	}
+
+	literal := p.input[start:p.pos]
+	if (p.pos-digits > 1 && p.input[digits] == '0') || literal == "-0" {
+		return 0, false, p.errorf("invalid integer %q", literal)
+	}
+
+	value, err := strconv.Atoi(literal)
+	if err != nil {
+		return 0, false, p.errorf("invalid integer %q", literal)
+	}
+
+	return value, true, nil
+}
+
+func (p *queryParser) parseStringLiteral() (string, error) {
+	quote := p.input[p.pos]
+	p.pos++
+
+	var result strings.Builder
+
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+
+		c := p.input[p.pos]
+		p.pos++
+
+		switch {
+		case c == quote:
+			return result.String(), nil
+
+		case c == '\\':
+			if p.eof() {
+				return "", p.errorf("unterminated string")
+			}
+
+			escaped := p.input[p.pos]
+			p.pos++
+
+			switch escaped {
+			case 'b':
+				result.WriteByte('\b')
+			case 'f':
+				result.WriteByte('\f')
+			case 'n':
+				result.WriteByte('\n')
+			case 'r':
+				result.WriteByte('\r')
+			case 't':
+				result.WriteByte('\t')
+			case '/', '\\', '\'', '"':
+				if (escaped == '\'' || escaped == '"') && escaped != quote {
+					return "", p.errorf("invalid escape sequence \\%c", escaped)
+				}
+
+				result.WriteByte(escaped)
+			case 'u':
+				r, err := p.parseUnicodeEscape()
+				if err != nil {
+					return "", err
+				}
+
+				result.WriteRune(r)
+			default:
+				return "", p.errorf("invalid escape sequence \\%c", escaped)
+			}
+
+		case c < 0x20:
+			return "", p.errorf("control characters must be escaped in strings")
+
+		default:
+			result.WriteByte(c)
+		}
+	}
+}
+
+func (p *queryParser) parseUnicodeEscape() (rune, error) {
+	readHex := func() (rune, error) {
+		if p.pos+4 > len(p.input) {
+			return 0, p.errorf("incomplete unicode escape sequence")
+		}
+
+		value, err := strconv.ParseUint(p.input[p.pos:p.pos+4], 16, 32)
+		if err != nil {
+			return 0, p.errorf("invalid unicode escape sequence")
+		}
+
+		p.pos += 4
+
+		return rune(value), nil
+	}
+
+	r, err := readHex()
+	if err != nil {
+		return 0, err
+	}
+
+	if utf16.IsSurrogate(r) {
+		if !p.consume(`\u`) {
+			return 0, p.errorf("incomplete surrogate pair")
+		}
+
+		low, err := readHex()
+		if err != nil {
+			return 0, err
+		}
+
+		r = utf16.DecodeRune(r, low)
+		if r == utf8.RuneError {
+			return 0, p.errorf("invalid surrogate pair")
+		}
+	}
+
+	return r, nil
+}
+
+// filter expressions
+
+type logicalFunc func(current any) (bool, error)
+
+// valueFunc returns the value of a comparable and false if it is "Nothing"
+// (e.g. a query that did not match).
+type valueFunc func(current any) (any, bool, error)
+
+type nodesFunc func(current any) ([]any, error)
+
+// operand is a literal, query or function call inside a filter. Depending on
+// its type, some of the functions are nil.
+type operand struct {
+	value    valueFunc
+	logical  logicalFunc
+	nodes    nodesFunc
+	singular bool
+}
+
+func (p *queryParser) parseLogicalOr() (logicalFunc, error) {
+	left, err := p.parseLogicalAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipBlank()
+
+		if !p.consume("||") {
+			return left, nil
+		}
+
+		p.skipBlank()
+
+		right, err := p.parseLogicalAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orFunc(left, right)
+	}
+}
+
+func orFunc(left, right logicalFunc) logicalFunc {
+	return func(current any) (bool, error) {
+		matches, err := left(current)
+		if err != nil || matches {
+			return matches, err
+		}
+
+		return right(current)
+	}
+}
+
+func (p *queryParser) parseLogicalAnd() (logicalFunc, error) {
+	left, err := p.parseBasicExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipBlank()
+
+		if !p.consume("&&") {
+			return left, nil
+		}
+
+		p.skipBlank()
+
+		right, err := p.parseBasicExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andFunc(left, right)
+	}
+}
+
+func andFunc(left, right logicalFunc) logicalFunc {
+	return func(current any) (bool, error) {
+		matches, err := left(current)
+		if err != nil || !matches {
+			return false, err
+		}
+
+		return right(current)
+	}
+}
+
+func notFunc(expr logicalFunc) logicalFunc {
+	return func(current any) (bool, error) {
+		matches, err := expr(current)
+		return !matches, err
+	}
+}
+
+func (p *queryParser) parseBasicExpression() (logicalFunc, error) {
+	negated := p.consume("!")
+	if negated {
+		p.skipBlank()
+	}
+
+	if p.consume("(") {
+		p.skipBlank()
+
+		expr, err := p.parseLogicalOr()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipBlank()
+
+		if !p.consume(")") {
+			return nil, p.errorf("expected )")
+		}
+
+		if negated {
+			return notFunc(expr), nil
+		}
+
+		return expr, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	// comparisons cannot be negated without parentheses
+	if !negated {
+		start := p.pos
+		p.skipBlank()
+
+		if op := p.parseComparisonOperator(); op != "" {
+			p.skipBlank()
+
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+
+			return p.comparison(left, op, right)
+		}
+
+		p.pos = start
+	}
+
+	if left.logical == nil {
+		return nil, p.errorf("expected query or logical function as test expression")
+	}
+
+	if negated {
+		return notFunc(left.logical), nil
+	}
+
+	return left.logical, nil
+}
+
+func (p *queryParser) parseComparisonOperator() string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			return op
+		}
+	}
+
+	return ""
+}
+
+func (p *queryParser) comparison(left operand, op string, right operand) (logicalFunc, error) {
+	for _, o := range []operand{left, right} {
+		if o.value == nil {
+			return nil, p.errorf("logical functions cannot be compared")
+		}
+
+		if o.nodes != nil && !o.singular {
+			return nil, p.errorf("only singular queries can be compared")
+		}
+	}
+
+	return func(current any) (bool, error) {
+		a, aExists, err := left.value(current)
+		if err != nil {
+			return false, err
+		}
+
+		b, bExists, err := right.value(current)
+		if err != nil {
+			return false, err
+		}
+
+		switch op {
+		case "==":
+			return compareEqual(a, aExists, b, bExists), nil
+		case "!=":
+			return !compareEqual(a, aExists, b, bExists), nil
+		case "<":
+			return compareLess(a, aExists, b, bExists), nil
+		case ">":
+			return compareLess(b, bExists, a, aExists), nil
+		case "<=":
+			return compareLess(a, aExists, b, bExists) || compareEqual(a, aExists, b, bExists), nil
+		default: // >=
+			return compareLess(b, bExists, a, aExists) || compareEqual(a, aExists, b, bExists), nil
+		}
+	}, nil
+}
+
+func (p *queryParser) parseOperand() (operand, error) {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		return p.parseFilterQuery()
+
+	case c == '\'' || c == '"':
+		s, err := p.parseStringLiteral()
+		if err != nil {
+			return operand{}, err
+		}
+
+		return literalOperand(s), nil
+
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumberLiteral()
+
+	case p.consume("true"):
+		return literalOperand(true), nil
+
+	case p.consume("false"):
+		return literalOperand(false), nil
+
+	case p.consume("null"):
+		return literalOperand(nil), nil
+
+	case c >= 'a' && c <= 'z':
+		return p.parseFunctionCall()
+
+	default:
+		return operand{}, p.errorf("expected filter expression")
+	}
+}
+
+func literalOperand(value any) operand {
+	return operand{
+		value: func(any) (any, bool, error) {
+			return value, true, nil
+		},
+	}
+}
+
+var numberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?`)
+
+func (p *queryParser) parseNumberLiteral() (operand, error) {
+	literal := numberPattern.FindString(p.input[p.pos:])
+	if literal == "" {
+		return operand{}, p.errorf("invalid number")
+	}
+
+	p.pos += len(literal)
+
+	if i, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return literalOperand(i), nil
+	}
+
+	f, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return operand{}, p.errorf("invalid number %q", literal)
+	}
+
+	return literalOperand(f), nil
+}
+
+func (p *queryParser) parseFilterQuery() (operand, error) {
+	relative := p.input[p.pos] == '@'
+	p.pos++
+
+	if !relative && p.root == nil {
+		return operand{}, p.errorf("references to the root value are only supported when querying")
+	}
+
+	path, err := p.parseSegments()
+	if err != nil {
+		return operand{}, err
+	}
+
+	root := p.root
+
+	nodes := func(current any) ([]any, error) {
+		if !relative {
+			current = *root
+		}
+
+		return getAll(current, path, true, []any{})
+	}
+
+	return operand{
+		nodes:    nodes,
+		singular: isSingularPath(path),
+		value: func(current any) (any, bool, error) {
+			matches, err := nodes(current)
+			if err != nil || len(matches) != 1 {
+				return nil, false, err
+			}
+
+			return matches[0], true, nil
+		},
+		logical: func(current any) (bool, error) {
+			matches, err := nodes(current)
+			return len(matches) > 0, err
+		},
+	}, nil
+}
+
+func isSingularPath(path Path) bool {
+	for _, step := range path {
+		_, isIndex := toIntegerStep(step)
+		_, isKey := toStringStep(step)
+
+		if !isIndex && !isKey {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *queryParser) parseFunctionCall() (operand, error) {
+	start := p.pos
+	for !p.eof() && (p.peek() >= 'a' && p.peek() <= 'z' || p.peek() == '_' || p.peek() >= '0' && p.peek() <= '9') {
+		p.pos++
+	}
+
+	name := p.input[start:p.pos]
+
+	if !p.consume("(") {
+		return operand{}, p.errorf("expected ( after function name %q", name)
+	}
+
+	args := []operand{}
+
+	for {
+		p.skipBlank()
+
+		if p.consume(")") {
+			break
+		}
+
+		if len(args) > 0 {
+			if !p.consume(",") {
+				return operand{}, p.errorf("expected , or )")
+			}
+
+			p.skipBlank()
+		}
+
+		// full logical expressions as arguments are not supported
+		arg, err := p.parseOperand()
+		if err != nil {
+			return operand{}, err
+		}
+
+		args = append(args, arg)
+	}
+
+	return p.buildFunction(name, args)
+}
+
+func (p *queryParser) buildFunction(name string, args []operand) (operand, error) {
+	expectArgs := func(n int) error {
+		if len(args) != n {
+			return p.errorf("%s() requires %d argument(s), got %d", name, n, len(args))
+		}
+
+		return nil
+	}
+
+	switch name {
+	case "length":
+		if err := expectArgs(1); err != nil {
+			return operand{}, err
+		}
+
+		arg, err := p.requireValue(name, args[0])
+		if err != nil {
+			return operand{}, err
+		}
+
+		return operand{
+			value: func(current any) (any, bool, error) {
+				value, exists, err := arg(current)
+				if err != nil || !exists {
+					return nil, false, err
+				}
+
+				switch asserted := value.(type) {
+				case string:
+					return int64(utf8.RuneCountInString(asserted)), true, nil
+				case []any:
+					return int64(len(asserted)), true, nil
+				case map[string]any:
+					return int64(len(asserted)), true, nil
+				default:
+					return nil, false, nil
+				}
+			},
+		}, nil
+
+	case "count":
+		if err := expectArgs(1); err != nil {
+			return operand{}, err
+		}
+
+		if args[0].nodes == nil {
+			return operand{}, p.errorf("count() requires a query argument")
+		}
+
+		nodes := args[0].nodes
+
+		return operand{
+			value: func(current any) (any, bool, error) {
+				matches, err := nodes(current)
+				if err != nil {
+					return nil, false, err
+				}
+
+				return int64(len(matches)), true, nil
+			},
+		}, nil
+
+	case "value":
+		if err := expectArgs(1); err != nil {
+			return operand{}, err
+		}
+
+		if args[0].nodes == nil {
+			return operand{}, p.errorf("value() requires a query argument")
+		}
+
+		nodes := args[0].nodes
+
+		return operand{
+			value: func(current any) (any, bool, error) {
+				matches, err := nodes(current)
+				if err != nil || len(matches) != 1 {
+					return nil, false, err
+				}
+
+				return matches[0], true, nil
+			},
+		}, nil
+
+	case "match", "search":
+		if err := expectArgs(2); err != nil {
+			return operand{}, err
+		}
+
+		subject, err := p.requireValue(name, args[0])
+		if err != nil {
+			return operand{}, err
+		}
+
+		pattern, err := p.requireValue(name, args[1])
+		if err != nil {
+			return operand{}, err
+		}
+
+		anchored := name == "match"
+
+		return operand{
+			logical: func(current any) (bool, error) {
+				s, sExists, err := subject(current)
+				if err != nil || !sExists {
+					return false, err
+				}
+
+				re, reExists, err := pattern(current)
+				if err != nil || !reExists {
+					return false, err
+				}
+
+				return regexMatches(s, re, anchored), nil
+			},
+		}, nil
+
+	default:
+		return operand{}, p.errorf("unknown function %s()", name)
+	}
+}
+
+func (p *queryParser) requireValue(function string, arg operand) (valueFunc, error) {
+	if arg.value == nil || (arg.nodes != nil && !arg.singular) {
+		return nil, p.errorf("%s() requires a value or singular query as argument", function)
+	}
+
+	return arg.value, nil
+}
+
+func regexMatches(subject any, pattern any, anchored bool) bool {
+	s, ok := subject.(string)
+	if !ok {
+		return false
+	}
+
+	expr, ok := pattern.(string)
+	if !ok {
+		return false
+	}
+
+	if anchored {
+		expr = "^(?:" + expr + ")$"
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return false
+	}
+
+	return re.MatchString(s)
+}
+
+func toFloat(value any) (float64, bool) {
+	switch asserted := value.(type) {
+	case int:
+		return float64(asserted), true
+	case int32:
+		return float64(asserted), true
+	case int64:
+		return float64(asserted), true
+	case float32:
+		return float64(asserted), true
+	case float64:
+		return asserted, true
+	default:
+		return 0, false
+	}
+}
+
+func compareEqual(a any, aExists bool, b any, bExists bool) bool {
+	if !aExists || !bExists {
+		return aExists == bExists
+	}
+
+	return valuesEqual(a, b)
+}
+
+func valuesEqual(a, b any) bool {
+	if af, ok := toFloat(a); ok {
+		bf, ok := toFloat(b)
+		return ok && af == bf
+	}
+
+	switch asserted := a.(type) {
+	case []any:
+		other, ok := b.([]any)
+		if !ok || len(asserted) != len(other) {
+			return false
+		}
+
+		for i := range asserted {
+			if !valuesEqual(asserted[i], other[i]) {
+				return false
+			}
+		}
+
+		return true
+
+	case map[string]any:
+		other, ok := b.(map[string]any)
+		if !ok || len(asserted) != len(other) {
+			return false
+		}
+
+		for key, value := range asserted {
+			otherValue, exists := other[key]
+			if !exists || !valuesEqual(value, otherValue) {
+				return false
+			}
+		}
+
+		return true
+
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+func compareLess(a any, aExists bool, b any, bExists bool) bool {
+	if !aExists || !bExists {
+		return false
+	}
+
+	if af, ok := toFloat(a); ok {
+		bf, ok := toFloat(b)
+		return ok && af < bf
+	}
+
+	as, ok := a.(string)
+	if !ok {
+		return false
+	}
+
+	bs, ok := b.(string)
+
+	return ok && as < bs
+}
@@ -0,0 +1,304 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// queryDocument is the example from RFC 9535, section 1.5.
+func queryDocument() any {
+	return map[string]any{
+		"store": map[string]any{
+			"book": []any{
+				map[string]any{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+				map[string]any{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+				map[string]any{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+				map[string]any{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": int64(22)},
+			},
+			"bicycle": map[string]any{"color": "red", "price": int64(399)},
+		},
+		"limit": int64(10),
+	}
+}
+
+func TestQuery(t *testing.T) {
+	testcases := []struct {
+		query    string
+		expected []any
+		invalid  bool
+	}{
+		{
+			query:    `$`,
+			expected: []any{queryDocument()},
+		},
+		{
+			query:    `$.store.book[*].author`,
+			expected: []any{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"},
+		},
+		{
+			query:    `$..author`,
+			expected: []any{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"},
+		},
+		{
+			query:    `$.store.bicycle.*`,
+			expected: []any{"red", int64(399)},
+		},
+		{
+			query:    `$['store']["bicycle"]['color']`,
+			expected: []any{"red"},
+		},
+		{
+			query:    `$..book[2].author`,
+			expected: []any{"Herman Melville"},
+		},
+		{
+			query:    `$..book[-1].title`,
+			expected: []any{"The Lord of the Rings"},
+		},
+		{
+			query:    `$..book[:2].title`,
+			expected: []any{"Sayings of the Century", "Sword of Honour"},
+		},
+		{
+			query:    `$.store.book[ 1 : 3 : 1 ].price`,
+			expected: []any{12.99, 8.99},
+		},
+		{
+			query:    `$..book[?@.isbn].title`,
+			expected: []any{"Moby Dick", "The Lord of the Rings"},
+		},
+		{
+			query:    `$..book[?!@.isbn].title`,
+			expected: []any{"Sayings of the Century", "Sword of Honour"},
+		},
+		{
+			query:    `$..book[?@.price<10].title`,
+			expected: []any{"Sayings of the Century", "Moby Dick"},
+		},
+		{
+			query:    `$..book[?@.price > $.limit && @.category == 'fiction'].title`,
+			expected: []any{"Sword of Honour", "The Lord of the Rings"},
+		},
+		{
+			query:    `$.store.book[?(@.price == 22 || @.author == "Nigel Rees")].title`,
+			expected: []any{"Sayings of the Century", "The Lord of the Rings"},
+		},
+		{
+			query:    `$.store.book[?match(@.author, 'J.*')].title`,
+			expected: []any{"The Lord of the Rings"},
+		},
+		{
+			query:    `$.store.book[?search(@.title, 'of')].title`,
+			expected: []any{"Sayings of the Century", "Sword of Honour", "The Lord of the Rings"},
+		},
+		{
+			query:    `$.store.book[?length(@.title) <= 9].title`,
+			expected: []any{"Moby Dick"},
+		},
+		{
+			query:    `$.store[?count(@.*) == 2].color`,
+			expected: []any{"red"},
+		},
+		{
+			query:    `$.store.book[?@.missing == @.alsoMissing].title`,
+			expected: []any{"Sayings of the Century", "Sword of Honour", "Moby Dick", "The Lord of the Rings"},
+		},
+		{
+			query:    `$.missing.deeper`,
+			expected: []any{},
+		},
+		{
+			query:    `$.store.book.foo`,
+			expected: []any{},
+		},
+		{
+			query:   `store`,
+			invalid: true,
+		},
+		{
+			query:    `$.store.book[0,1].title`,
+			expected: []any{"Sayings of the Century", "Sword of Honour"},
+		},
+		{
+			query:    `$.store.bicycle['price', 'color', 'missing']`,
+			expected: []any{int64(399), "red"},
+		},
+		{
+			// selectors are applied in order and can match the same value twice
+			query:    `$.store.book[-1, 0:2, 3, 'title'].price`,
+			expected: []any{int64(22), 8.95, 12.99, int64(22)},
+		},
+		{
+			query:    `$.store.book[?@.price < 9, ?@.price > 20].title`,
+			expected: []any{"Sayings of the Century", "Moby Dick", "The Lord of the Rings"},
+		},
+		{
+			query:    `$..book[0,1].author`,
+			expected: []any{"Nigel Rees", "Evelyn Waugh"},
+		},
+		{
+			query:    `$.store.book[::2].title`,
+			expected: []any{"Sayings of the Century", "Moby Dick"},
+		},
+		{
+			query:    `$.store.book[::-1].author`,
+			expected: []any{"J. R. R. Tolkien", "Herman Melville", "Evelyn Waugh", "Nigel Rees"},
+		},
+		{
+			query:    `$.store.book[2:0:-1].author`,
+			expected: []any{"Herman Melville", "Evelyn Waugh"},
+		},
+		{
+			query:    `$.store.book[-1:-10:-2].author`,
+			expected: []any{"J. R. R. Tolkien", "Evelyn Waugh"},
+		},
+		{
+			query:    `$.store.book[::0]`,
+			expected: []any{},
+		},
+		{
+			query:    `$.store.bicycle[::2]`,
+			expected: []any{},
+		},
+		{
+			query:    `$ .store .book [0] ..author`,
+			expected: []any{"Nigel Rees"},
+		},
+		{
+			query:    `$..book[?@ .price > 20].title`,
+			expected: []any{"The Lord of the Rings"},
+		},
+		{
+			query:   `$.store `,
+			invalid: true,
+		},
+		{
+			query:   `$.store.book[0,]`,
+			invalid: true,
+		},
+		{
+			query:   `$.store.book[?@.* == 1]`,
+			invalid: true,
+		},
+		{
+			query:   `$.store.book[?@.price == ]`,
+			invalid: true,
+		},
+		{
+			query:   `$.store.book[?unknown(@)]`,
+			invalid: true,
+		},
+		{
+			query:   `$.store.book[01]`,
+			invalid: true,
+		},
+		{
+			query:   `$.store.book['unterminated]`,
+			invalid: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.query, func(t *testing.T) {
+			result, err := Query(queryDocument(), tc.query)
+			if err != nil {
+				if !tc.invalid {
+					t.Fatalf("Failed to run: %v", err)
+				}
+
+				return
+			}
+
+			if tc.invalid {
+				t.Fatalf("Should not have been able to run query, but got: %v", result)
+			}
+
+			if !cmp.Equal(tc.expected, result) {
+				t.Fatalf("Expected %v, but got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestJSONPathConversion(t *testing.T) {
+	testcases := []struct {
+		query    string
+		path     Path
+		expected string
+	}{
+		{
+			query:    `$`,
+			path:     Path{},
+			expected: `$`,
+		},
+		{
+			query:    `$.spec['containers'][0].image`,
+			path:     Path{"spec", "containers", 0, "image"},
+			expected: `$.spec.containers[0].image`,
+		},
+		{
+			query:    `$['a b']["it's"]`,
+			path:     Path{"a b", "it's"},
+			expected: `$['a b']['it\'s']`,
+		},
+		{
+			query:    `$.items[*]..name..[0]..*`,
+			path:     Path{"items", WildcardStep{}, DescentStep{Step: "name"}, DescentStep{Step: 0}, DescentStep{Step: WildcardStep{}}},
+			expected: `$.items[*]..name..[0]..[*]`,
+		},
+		{
+			query:    `$.items[1:-1][:2][3:]`,
+			path:     Path{"items", SliceStep{From: intp(1), To: intp(-1)}, SliceStep{To: intp(2)}, SliceStep{From: intp(3)}},
+			expected: `$.items[1:-1][:2][3:]`,
+		},
+		{
+			query:    `$["é\t"]`,
+			path:     Path{"é\t"},
+			expected: `$['é\t']`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.query, func(t *testing.T) {
+			path, err := FromJSONPath(tc.query)
+			if err != nil {
+				t.Fatalf("Failed to parse: %v", err)
+			}
+
+			if !cmp.Equal(tc.path, path) {
+				t.Fatalf("Expected %v, but got %v", tc.path, path)
+			}
+
+			converted, err := path.ToJSONPath()
+			if err != nil {
+				t.Fatalf("Failed to convert: %v", err)
+			}
+
+			if converted != tc.expected {
+				t.Fatalf("Expected %q, but got %q", tc.expected, converted)
+			}
+		})
+	}
+
+	if _, err := FromJSONPath(`$.items[?@.x == $.y]`); err == nil {
+		t.Error("Root references in filters should not be allowed when converting to a Path.")
+	}
+
+	for _, query := range []string{`$.items[0,1]`, `$..['a','b']`, `$.items[::2]`, `$.items[::-1].name`} {
+		if _, err := FromJSONPath(query); err == nil {
+			t.Errorf("%s should not be convertible to a Path.", query)
+		}
+	}
+
+	if _, err := FromJSONPath(`$.items[?@.tags[0,1]]`); err != nil {
+		t.Errorf("Selector lists inside filters should be allowed: %v", err)
+	}
+
+	if _, err := (Path{hasValue("a", "b")}).ToJSONPath(); err == nil {
+		t.Error("Filter steps should not be convertible to JSONPath.")
+	}
+}
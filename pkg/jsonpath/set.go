@@ -6,6 +6,8 @@ package jsonpath
 import (
 	"errors"
 	"fmt"
+
+	"go.xrstf.de/rudi/pkg/deepcopy"
 )
 
 type ObjectWriter interface {
@@ -18,29 +20,184 @@ type VectorWriter interface {
 	SetVectorItem(index int, value any) (any, error)
 }
 
+// ShallowCopier can be implemented by custom objects and vectors to take part
+// in copy-on-write updates. ShallowCopy must return a copy that can be modified
+// using the writer/deleter interfaces without affecting the original, while
+// child values can be shared between both. Custom types that do not implement
+// this interface (or deepcopy.Copier) are modified in-place.
+type ShallowCopier interface {
+	ShallowCopy() (any, error)
+}
+
+// Set replaces the value at the given path, see Update.
 func Set(dest any, path Path, newValue any) (any, error) {
-	if len(path) == 0 {
+	return Update(dest, path, func(_ any) (any, error) {
 		return newValue, nil
+	})
+}
+
+// UpdateFunc receives the current value at a path and returns its replacement.
+type UpdateFunc func(value any) (any, error)
+
+// Update replaces the value at the given path with the result of fn. For
+// paths that match multiple values, fn is called for every match. Recursive
+// descents only apply to existing keys and indices and update the innermost
+// matches first. Optional steps are treated like regular steps.
+//
+// The given value is never modified. Instead, all vectors and objects along
+// the path are copied and the updated copy is returned, while all other
+// values are shared between the original and the result. This makes updates
+// cost proportional to the size of the modified path, not the entire value.
+func Update(dest any, path Path, fn UpdateFunc) (any, error) {
+	return UpdateWithPaths(dest, path, func(_ Path, value any) (any, error) {
+		return fn(value)
+	})
+}
+
+// UpdatePathFunc is like UpdateFunc, but also receives the concrete path of
+// the value, i.e. the keys and indices that all wildcards, slices, filters and
+// descents resolved to, with negative indices counting from the end replaced
+// by the actual index.
+type UpdatePathFunc func(path Path, value any) (any, error)
+
+// UpdateWithPaths works like Update, but also passes the concrete path of
+// every match to fn. This allows to record exactly which values were changed.
+func UpdateWithPaths(dest any, path Path, fn UpdatePathFunc) (any, error) {
+	result, err := update(dest, path, Path{}, fn)
+	if err != nil {
+		return nil, withPath(err, path)
+	}
+
+	return result, nil
+}
+
+func update(dest any, path Path, at Path, fn UpdatePathFunc) (any, error) {
+	if len(path) == 0 {
+		return fn(at, dest)
 	}
 
-	thisStep := path[0]
+	thisStep, _ := unwrapOptional(path[0])
 	remainingSteps := path[1:]
 
-	// [index]...
-	if index, ok := toIntegerStep(thisStep); ok {
-		if slice, ok := dest.([]any); ok {
-			if index < 0 || index >= len(slice) {
-				return nil, fmt.Errorf("index %d out of bounds", index)
+	// ..step...
+	if descent, ok := thisStep.(DescentStep); ok {
+		children, _, _ := matchingSteps(dest, WildcardStep{})
+
+		dest, err := updateChildren(dest, children, path, func(child Step, value any) (any, error) {
+			return update(value, path, appendStep(at, child), fn)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if !stepMatches(dest, descent.Step) {
+			return dest, nil
+		}
+
+		return update(dest, append(Path{descent.Step}, remainingSteps...), at, fn)
+	}
+
+	// [*], [from:to], [?filter]...
+	if isMultiStep(thisStep) {
+		children, ok, err := matchingSteps(dest, thisStep)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			return nil, newTypeMismatchError(thisStep, dest, remainingSteps)
+		}
+
+		return updateChildren(dest, children, remainingSteps, func(child Step, value any) (any, error) {
+			return update(value, remainingSteps, appendStep(at, child), fn)
+		})
+	}
+
+	// [index]... and .key...
+	childPath := appendStep(at, concreteStep(dest, thisStep))
+
+	owned, err := shallowCopy(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return updateChild(owned, thisStep, remainingSteps, true, func(child any) (any, error) {
+		return update(child, remainingSteps, childPath, fn)
+	})
+}
+
+// updateChildren copies the vector or object once and then replaces each of
+// the given children (as returned by matchingSteps) with the result of fn.
+func updateChildren(dest any, children []Step, remaining Path, fn func(child Step, value any) (any, error)) (any, error) {
+	if len(children) == 0 {
+		return dest, nil
+	}
+
+	owned, err := shallowCopy(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, child := range children {
+		child := child
+
+		owned, err = updateChild(owned, child, remaining, false, func(value any) (any, error) {
+			return fn(child, value)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return owned, nil
+}
+
+// appendStep returns a new path with the step appended, without modifying the
+// given path.
+func appendStep(path Path, step Step) Path {
+	return append(path[:len(path):len(path)], step)
+}
+
+// concreteStep returns the index or key that the step refers to in the value,
+// with negative indices replaced by the actual index.
+func concreteStep(value any, step Step) Step {
+	if index, ok := toIntegerStep(step); ok {
+		if length, ok := vectorLength(value); ok {
+			if normalized, ok := normalizeIndex(index, length); ok {
+				return normalized
 			}
+		}
 
-			existingValue := slice[index]
+		return index
+	}
+
+	if key, ok := toStringStep(step); ok {
+		return key
+	}
 
-			updatedValue, err := Set(existingValue, remainingSteps, newValue)
+	return step
+}
+
+// updateChild replaces the value at the given key or index with the result of
+// fn. dest must already be a copy, as it is modified in-place (only custom
+// writers may return a different value). Non-existing object keys are passed
+// to fn as nil. If create is true, nils are turned into objects when a key is
+// set.
+func updateChild(dest any, step Step, remaining Path, create bool, fn UpdateFunc) (any, error) {
+	// [index]
+	if index, ok := toIntegerStep(step); ok {
+		if slice, ok := dest.([]any); ok {
+			normalized, ok := normalizeIndex(index, len(slice))
+			if !ok {
+				return nil, newIndexOutOfBoundsError(index, len(slice), remaining)
+			}
+
+			updatedValue, err := fn(slice[normalized])
 			if err != nil {
 				return nil, err
 			}
 
-			slice[index] = updatedValue
+			slice[normalized] = updatedValue
 
 			return slice, nil
 		}
@@ -48,10 +205,14 @@ func Set(dest any, path Path, newValue any) (any, error) {
 		if writer, ok := dest.(VectorWriter); ok {
 			existingValue, err := writer.GetVectorItem(index)
 			if err != nil {
-				return nil, fmt.Errorf("cannot descend with [%d] into %T", index, dest)
+				if length, ok := vectorLength(dest); ok && errors.Is(err, ErrNotFound) {
+					return nil, newIndexOutOfBoundsError(index, length, remaining)
+				}
+
+				return nil, fmt.Errorf("cannot descend with [%d] into %T: %w", index, dest, err)
 			}
 
-			updatedValue, err := Set(existingValue, remainingSteps, newValue)
+			updatedValue, err := fn(existingValue)
 			if err != nil {
 				return nil, err
 			}
@@ -59,16 +220,14 @@ func Set(dest any, path Path, newValue any) (any, error) {
 			return writer.SetVectorItem(index, updatedValue)
 		}
 
-		return nil, fmt.Errorf("cannot descend with [%d] into %T", index, dest)
+		return nil, newTypeMismatchError(index, dest, remaining)
 	}
 
 	// .key
-	if key, ok := toStringStep(thisStep); ok {
+	if key, ok := toStringStep(step); ok {
 		if object, ok := dest.(map[string]any); ok {
 			// getting the empty value for non-existing keys is fine
-			existingValue := object[key]
-
-			updatedValue, err := Set(existingValue, remainingSteps, newValue)
+			updatedValue, err := fn(object[key])
 			if err != nil {
 				return nil, err
 			}
@@ -79,12 +238,13 @@ func Set(dest any, path Path, newValue any) (any, error) {
 		}
 
 		if writer, ok := dest.(ObjectWriter); ok {
+			// like for maps, non-existing keys can be created
 			existingValue, err := writer.GetObjectKey(key)
-			if err != nil {
-				return nil, fmt.Errorf("cannot descend with [%s] into %T", key, dest)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return nil, fmt.Errorf("cannot descend with [%s] into %T: %w", key, dest, err)
 			}
 
-			updatedValue, err := Set(existingValue, remainingSteps, newValue)
+			updatedValue, err := fn(existingValue)
 			if err != nil {
 				return nil, err
 			}
@@ -93,8 +253,8 @@ func Set(dest any, path Path, newValue any) (any, error) {
 		}
 
 		// nulls can be turned into objects
-		if dest == nil {
-			updatedValue, err := Set(nil, remainingSteps, newValue)
+		if dest == nil && create {
+			updatedValue, err := fn(nil)
 			if err != nil {
 				return nil, err
 			}
@@ -104,8 +264,34 @@ func Set(dest any, path Path, newValue any) (any, error) {
 			}, nil
 		}
 
-		return nil, fmt.Errorf("cannot descend with [%s] into %T", key, dest)
+		return nil, newTypeMismatchError(key, dest, remaining)
 	}
 
 	return nil, errors.New("invalid path step: neither key nor index")
 }
+
+// shallowCopy returns a copy of a vector or object that can be modified
+// without affecting the original. All other values are returned as-is.
+func shallowCopy(value any) (any, error) {
+	switch asserted := value.(type) {
+	case []any:
+		return append(make([]any, 0, len(asserted)), asserted...), nil
+
+	case map[string]any:
+		result := make(map[string]any, len(asserted))
+		for key, val := range asserted {
+			result[key] = val
+		}
+
+		return result, nil
+
+	case ShallowCopier:
+		return asserted.ShallowCopy()
+
+	case deepcopy.Copier:
+		return asserted.DeepCopy()
+
+	default:
+		return value, nil
+	}
+}
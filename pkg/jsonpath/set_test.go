@@ -156,10 +156,17 @@ func TestSet(t *testing.T) {
 		{
 			name:     "handle out of bounds",
 			dest:     []any{1, 2, 3},
-			path:     Path{-1},
+			path:     Path{-4},
 			newValue: "new-value",
 			invalid:  true,
 		},
+		{
+			name:     "negative indices count from the end",
+			dest:     []any{1, 2, 3},
+			path:     Path{-1},
+			newValue: "new-value",
+			expected: []any{1, 2, "new-value"},
+		},
 		{
 			name:     "handle out of bounds",
 			dest:     []any{1, 2, 3},
@@ -274,6 +281,90 @@ func TestSet(t *testing.T) {
 			newValue: "new-value",
 			invalid:  true,
 		},
+		{
+			name: "wildcard sets all items",
+			dest: map[string]any{
+				"items": []any{
+					map[string]any{"name": "a"},
+					map[string]any{"name": "b", "enabled": false},
+				},
+			},
+			path:     Path{"items", WildcardStep{}, "enabled"},
+			newValue: true,
+			expected: map[string]any{
+				"items": []any{
+					map[string]any{"name": "a", "enabled": true},
+					map[string]any{"name": "b", "enabled": true},
+				},
+			},
+		},
+		{
+			name:     "wildcard on object",
+			dest:     map[string]any{"a": 1, "b": 2},
+			path:     Path{WildcardStep{}},
+			newValue: 0,
+			expected: map[string]any{"a": 0, "b": 0},
+		},
+		{
+			name:     "wildcard on scalar",
+			dest:     "foo",
+			path:     Path{WildcardStep{}},
+			newValue: 0,
+			invalid:  true,
+		},
+		{
+			name:     "wildcard with mismatching items",
+			dest:     []any{map[string]any{}, "foo"},
+			path:     Path{WildcardStep{}, "name"},
+			newValue: "x",
+			invalid:  true,
+		},
+		{
+			name:     "slice",
+			dest:     []any{1, 2, 3, 4},
+			path:     Path{SliceStep{From: intp(1), To: intp(-1)}},
+			newValue: 0,
+			expected: []any{1, 0, 0, 4},
+		},
+		{
+			name: "filter",
+			dest: []any{
+				map[string]any{"name": "a"},
+				map[string]any{"name": "b"},
+			},
+			path:     Path{hasValue("name", "b"), "enabled"},
+			newValue: true,
+			expected: []any{
+				map[string]any{"name": "a"},
+				map[string]any{"name": "b", "enabled": true},
+			},
+		},
+		{
+			name: "descent only updates existing keys",
+			dest: map[string]any{
+				"image": "old",
+				"spec": []any{
+					map[string]any{"image": "old"},
+					map[string]any{"name": "foo"},
+				},
+			},
+			path:     Path{DescentStep{Step: "image"}},
+			newValue: "new",
+			expected: map[string]any{
+				"image": "new",
+				"spec": []any{
+					map[string]any{"image": "new"},
+					map[string]any{"name": "foo"},
+				},
+			},
+		},
+		{
+			name:     "optional steps create missing keys",
+			dest:     map[string]any{},
+			path:     Path{OptionalStep{Step: "foo"}, OptionalStep{Step: "bar"}},
+			newValue: 1,
+			expected: map[string]any{"foo": map[string]any{"bar": 1}},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -297,3 +388,145 @@ func TestSet(t *testing.T) {
 		})
 	}
 }
+
+func TestSetDoesNotModifyDestination(t *testing.T) {
+	dest := map[string]any{
+		"spec": map[string]any{
+			"items": []any{
+				map[string]any{"name": "a"},
+				map[string]any{"name": "b"},
+			},
+		},
+		"status": map[string]any{"ready": true},
+	}
+
+	result, err := Set(dest, Path{"spec", "items", 1, "name"}, "new")
+	if err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	expected := map[string]any{
+		"spec": map[string]any{
+			"items": []any{
+				map[string]any{"name": "a"},
+				map[string]any{"name": "new"},
+			},
+		},
+		"status": map[string]any{"ready": true},
+	}
+
+	if !cmp.Equal(expected, result) {
+		t.Fatalf("Expected %v, but got %v", expected, result)
+	}
+
+	if name := dest["spec"].(map[string]any)["items"].([]any)[1].(map[string]any)["name"]; name != "b" {
+		t.Fatalf("Original value was modified: %v", dest)
+	}
+
+	// values outside of the path are shared
+	original := dest["status"].(map[string]any)
+	original["shared"] = true
+
+	if _, ok := result.(map[string]any)["status"].(map[string]any)["shared"]; !ok {
+		t.Fatal("Values outside of the path should be shared with the original.")
+	}
+
+	untouched := dest["spec"].(map[string]any)["items"].([]any)[0].(map[string]any)
+	untouched["shared"] = true
+
+	if _, ok := result.(map[string]any)["spec"].(map[string]any)["items"].([]any)[0].(map[string]any)["shared"]; !ok {
+		t.Fatal("Vector items outside of the path should be shared with the original.")
+	}
+}
+
+func TestUpdateWithPaths(t *testing.T) {
+	dest := map[string]any{
+		"items": []any{
+			map[string]any{"x": 1},
+			map[string]any{"x": 2},
+			map[string]any{"x": 1},
+		},
+	}
+
+	isOne := FilterStep(func(value any) (bool, error) {
+		return value.(map[string]any)["x"] == 1, nil
+	})
+
+	testcases := []struct {
+		path     Path
+		expected []Path
+	}{
+		{
+			path:     Path{"items", int64(1), "x"},
+			expected: []Path{{"items", 1, "x"}},
+		},
+		{
+			path:     Path{"items", int64(-1), "x"},
+			expected: []Path{{"items", 2, "x"}},
+		},
+		{
+			path:     Path{"items", WildcardStep{}, "y"},
+			expected: []Path{{"items", 0, "y"}, {"items", 1, "y"}, {"items", 2, "y"}},
+		},
+		{
+			path:     Path{"items", isOne, "x"},
+			expected: []Path{{"items", 0, "x"}, {"items", 2, "x"}},
+		},
+		{
+			path:     Path{DescentStep{Step: "x"}},
+			expected: []Path{{"items", 0, "x"}, {"items", 1, "x"}, {"items", 2, "x"}},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.path.String(), func(t *testing.T) {
+			paths := []Path{}
+
+			_, err := UpdateWithPaths(dest, testcase.path, func(path Path, value any) (any, error) {
+				paths = append(paths, path)
+				return 3, nil
+			})
+			if err != nil {
+				t.Fatalf("Failed to update value: %v", err)
+			}
+
+			if !cmp.Equal(testcase.expected, paths) {
+				t.Fatalf("Unexpected paths:\n%s", cmp.Diff(testcase.expected, paths))
+			}
+		})
+	}
+}
+
+// largeDocument returns an object with the given number of items, each with a
+// small nested object, resulting in roughly 100 bytes of JSON per item.
+func largeDocument(items int) map[string]any {
+	list := make([]any, items)
+	for i := range list {
+		list[i] = map[string]any{
+			"name": fmt.Sprintf("item-%d", i),
+			"spec": map[string]any{
+				"image":    "example.com/image:latest",
+				"replicas": i,
+				"labels":   map[string]any{"app": "rudi"},
+			},
+		}
+	}
+
+	return map[string]any{
+		"items": list,
+		"meta":  map[string]any{"count": items},
+	}
+}
+
+func BenchmarkSetLargeDocument(b *testing.B) {
+	dest := largeDocument(50000)
+	path := Path{"items", 25000, "spec", "replicas"}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Set(dest, path, i); err != nil {
+			b.Fatalf("Failed to set value: %v", err)
+		}
+	}
+}
@@ -17,6 +17,7 @@ var (
 	VariableNamePattern   = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 	PathIdentifierPattern = VariableNamePattern
 	IdentifierNamePattern = regexp.MustCompile(`^[a-zA-Z_+/*_%?-][a-zA-Z0-9_+/*_%?!-]*$`)
+	NamespacePattern      = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
 )
 
 type Expression interface {
@@ -275,18 +276,31 @@ func (Variable) ExpressionName() string {
 }
 
 type Identifier struct {
-	Name string
-	Bang bool
+	// Namespace is the optional prefix of a namespaced identifier like
+	// "set/contains?", in which case the Name would be "contains?".
+	Namespace string
+	Name      string
+	Bang      bool
 }
 
 var _ Expression = Identifier{}
 
 func (i Identifier) Equal(other Identifier) bool {
-	return i.Name == other.Name
+	return i.Namespace == other.Namespace && i.Name == other.Name
+}
+
+// FullName returns the name including the namespace (if any), but without the
+// bang modifier. This is the name functions are registered under.
+func (i Identifier) FullName() string {
+	if i.Namespace == "" {
+		return i.Name
+	}
+
+	return i.Namespace + "/" + i.Name
 }
 
 func (i Identifier) String() string {
-	result := i.Name
+	result := i.FullName()
 	if i.Bang {
 		result += "!"
 	}
@@ -425,20 +439,148 @@ func (e PathExpression) IsIdentity() bool {
 func (e PathExpression) String() string {
 	result := ""
 	for _, step := range e.Steps {
-		if ident, ok := step.(Identifier); ok {
-			result += "." + ident.String()
-		} else {
-			result += "[" + step.String() + "]"
-		}
+		result += pathStepString(step)
 	}
 
 	return result
 }
 
+func pathStepString(step Expression) string {
+	switch asserted := step.(type) {
+	case Identifier:
+		return "." + asserted.String()
+	case DescentStep:
+		return descentString(pathStepString(asserted.Step))
+	case OptionalStep:
+		return pathStepString(asserted.Step) + "?"
+	default:
+		return "[" + step.String() + "]"
+	}
+}
+
 func (PathExpression) ExpressionName() string {
 	return "PathExpression"
 }
 
+// IsMulti returns true if the path expression contains steps that can match
+// more than one value (wildcards, slices, filters and recursive descents).
+func (e PathExpression) IsMulti() bool {
+	for _, step := range e.Steps {
+		if optional, ok := step.(OptionalStep); ok {
+			step = optional.Step
+		}
+
+		switch step.(type) {
+		case WildcardStep, SliceStep, FilterStep, DescentStep:
+			return true
+		}
+	}
+
+	return false
+}
+
+// WildcardStep is the "[*]" step in a path expression, matching all items in
+// a vector or all values in an object.
+type WildcardStep struct{}
+
+var _ Expression = WildcardStep{}
+
+func (WildcardStep) String() string {
+	return "*"
+}
+
+func (WildcardStep) ExpressionName() string {
+	return "WildcardStep"
+}
+
+// SliceStep is a "[from:to]" step in a path expression, matching a range of
+// items in a vector. Both bounds are optional and can be negative to count
+// from the end of the vector.
+type SliceStep struct {
+	From Expression
+	To   Expression
+}
+
+var _ Expression = SliceStep{}
+
+func (s SliceStep) String() string {
+	result := ""
+	if s.From != nil {
+		result += s.From.String()
+	}
+
+	result += ":"
+
+	if s.To != nil {
+		result += s.To.String()
+	}
+
+	return result
+}
+
+func (SliceStep) ExpressionName() string {
+	return "SliceStep"
+}
+
+// FilterStep is a "[?(predicate)]" step in a path expression, matching all
+// items in a vector or values in an object for which the predicate is true.
+// The predicate is evaluated with each item as the document.
+type FilterStep struct {
+	Predicate Expression
+}
+
+var _ Expression = FilterStep{}
+
+func (f FilterStep) String() string {
+	return "?" + f.Predicate.String()
+}
+
+func (FilterStep) ExpressionName() string {
+	return "FilterStep"
+}
+
+// DescentStep is a recursive descent like "..name" in a path expression,
+// applying its step to the current value and all of its descendants.
+type DescentStep struct {
+	Step Expression
+}
+
+var _ Expression = DescentStep{}
+
+func (d DescentStep) String() string {
+	return descentString(pathStepString(d.Step))
+}
+
+// descentString turns ".foo" into "..foo" and "[0]" into "..[0]".
+func descentString(step string) string {
+	if strings.HasPrefix(step, ".") {
+		return "." + step
+	}
+
+	return ".." + step
+}
+
+func (DescentStep) ExpressionName() string {
+	return "DescentStep"
+}
+
+// OptionalStep wraps a step followed by a "?" in a path expression, like
+// ".spec?". If the step does not match (missing key, index out of range), the
+// entire path evaluates to null instead of returning an error.
+type OptionalStep struct {
+	Step Expression
+}
+
+var _ Expression = OptionalStep{}
+
+func (o OptionalStep) String() string {
+	return pathStepString(o)
+}
+
+func (OptionalStep) ExpressionName() string {
+	return "OptionalStep"
+}
+
 type EvaluatedPathExpression struct {
 	Steps []EvaluatedPathStep
 }
@@ -452,6 +594,17 @@ func (e EvaluatedPathExpression) IsIdentity() bool {
 	return len(e.Steps) == 0
 }
 
+// IsMulti returns true if any of the steps can match more than one value.
+func (e EvaluatedPathExpression) IsMulti() bool {
+	for _, step := range e.Steps {
+		if step.IsMulti() {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (e EvaluatedPathExpression) String() string {
 	result := ""
 	for _, step := range e.Steps {
@@ -468,9 +621,51 @@ func (EvaluatedPathExpression) ExpressionName() string {
 type EvaluatedPathStep struct {
 	StringValue  *string
 	IntegerValue *int64
+	Wildcard     bool
+	Slice        *EvaluatedSlice
+	Filter       *EvaluatedFilter
+	// Descent is set for recursive descents and applies the step to the
+	// current value and all of its descendants.
+	Descent bool
+	// Optional is set for steps followed by a "?"; if they do not match,
+	// the path evaluates to null.
+	Optional bool
+}
+
+// EvaluatedSlice is the evaluated form of a SliceStep, with nil bounds
+// meaning the beginning/end of the vector.
+type EvaluatedSlice struct {
+	From *int64
+	To   *int64
+}
+
+// EvaluatedFilter is the evaluated form of a FilterStep. Since the predicate
+// has to be evaluated for every item, Matches holds a function that does so.
+type EvaluatedFilter struct {
+	Predicate Expression
+	Matches   func(value any) (bool, error)
+}
+
+// IsMulti returns true if the step can match more than one value.
+func (a EvaluatedPathStep) IsMulti() bool {
+	return a.Descent || a.Wildcard || a.Slice != nil || a.Filter != nil
 }
 
 func (a EvaluatedPathStep) String() string {
+	if a.Optional {
+		inner := a
+		inner.Optional = false
+
+		return inner.String() + "?"
+	}
+
+	if a.Descent {
+		inner := a
+		inner.Descent = false
+
+		return descentString(inner.String())
+	}
+
 	switch {
 	case a.StringValue != nil:
 		if PathIdentifierPattern.MatchString(*a.StringValue) {
@@ -480,6 +675,23 @@ func (a EvaluatedPathStep) String() string {
 		}
 	case a.IntegerValue != nil:
 		return fmt.Sprintf("[%d]", *a.IntegerValue)
+	case a.Wildcard:
+		return "[*]"
+	case a.Slice != nil:
+		result := "["
+		if a.Slice.From != nil {
+			result += fmt.Sprintf("%d", *a.Slice.From)
+		}
+
+		result += ":"
+
+		if a.Slice.To != nil {
+			result += fmt.Sprintf("%d", *a.Slice.To)
+		}
+
+		return result + "]"
+	case a.Filter != nil:
+		return fmt.Sprintf("[?%s]", a.Filter.Predicate.String())
 	default:
 		return "<unknown PathStep>"
 	}
@@ -493,10 +705,24 @@ func (a EvaluatedPathStep) ExpressionName() string {
 		name = "String"
 	case a.IntegerValue != nil:
 		name = "Number"
+	case a.Wildcard:
+		name = "Wildcard"
+	case a.Slice != nil:
+		name = "Slice"
+	case a.Filter != nil:
+		name = "Filter"
 	default:
 		name = "?"
 	}
 
+	if a.Descent {
+		name = "Descent(" + name + ")"
+	}
+
+	if a.Optional {
+		name = "Optional(" + name + ")"
+	}
+
 	return "PathStep(" + name + ")"
 }
 
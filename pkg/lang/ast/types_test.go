@@ -172,6 +172,18 @@ func TestStringers(t *testing.T) {
 			expr:     Statement{Expression: Bool(true)},
 			expected: `true`,
 		},
+		{
+			expr:     Identifier{Name: "foo", Bang: true},
+			expected: `foo!`,
+		},
+		{
+			expr:     Identifier{Namespace: "set", Name: "contains?"},
+			expected: `set/contains?`,
+		},
+		{
+			expr:     Identifier{Namespace: "set", Name: "insert", Bang: true},
+			expected: `set/insert!`,
+		},
 		{
 			expr:     Symbol{},
 			expected: `<invalid Symbol>`,
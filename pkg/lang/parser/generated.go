@@ -633,16 +633,16 @@ var g = &grammar{
 									label: "acc",
 									expr: &ruleRefExpr{
 										pos:  position{line: 175, col: 9, offset: 4145},
-										name: "ObjectAccessor",
+										name: "DescentAccessor",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 175, col: 24, offset: 4160},
+									pos:   position{line: 175, col: 25, offset: 4161},
 									label: "expr",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 175, col: 29, offset: 4165},
+										pos: position{line: 175, col: 30, offset: 4166},
 										expr: &ruleRefExpr{
-											pos:  position{line: 175, col: 29, offset: 4165},
+											pos:  position{line: 175, col: 30, offset: 4166},
 											name: "AnyQualifiedPathExpression",
 										},
 									},
@@ -651,26 +651,54 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 186, col: 5, offset: 4421},
+						pos: position{line: 186, col: 5, offset: 4424},
 						run: (*parser).callonSymbol17,
 						expr: &seqExpr{
-							pos: position{line: 186, col: 5, offset: 4421},
+							pos: position{line: 186, col: 5, offset: 4424},
+							exprs: []any{
+								&labeledExpr{
+									pos:   position{line: 186, col: 5, offset: 4424},
+									label: "acc",
+									expr: &ruleRefExpr{
+										pos:  position{line: 186, col: 9, offset: 4428},
+										name: "ObjectAccessor",
+									},
+								},
+								&labeledExpr{
+									pos:   position{line: 186, col: 24, offset: 4443},
+									label: "expr",
+									expr: &zeroOrOneExpr{
+										pos: position{line: 186, col: 29, offset: 4448},
+										expr: &ruleRefExpr{
+											pos:  position{line: 186, col: 29, offset: 4448},
+											name: "AnyQualifiedPathExpression",
+										},
+									},
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 197, col: 5, offset: 4704},
+						run: (*parser).callonSymbol24,
+						expr: &seqExpr{
+							pos: position{line: 197, col: 5, offset: 4704},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 186, col: 5, offset: 4421},
+									pos:   position{line: 197, col: 5, offset: 4704},
 									label: "val",
 									expr: &ruleRefExpr{
-										pos:  position{line: 186, col: 9, offset: 4425},
+										pos:  position{line: 197, col: 9, offset: 4708},
 										name: "Variable",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 186, col: 18, offset: 4434},
+									pos:   position{line: 197, col: 18, offset: 4717},
 									label: "expr",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 186, col: 23, offset: 4439},
+										pos: position{line: 197, col: 23, offset: 4722},
 										expr: &ruleRefExpr{
-											pos:  position{line: 186, col: 23, offset: 4439},
+											pos:  position{line: 197, col: 23, offset: 4722},
 											name: "AnyQualifiedPathExpression",
 										},
 									},
@@ -679,10 +707,10 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 199, col: 5, offset: 4732},
-						run: (*parser).callonSymbol24,
+						pos: position{line: 210, col: 5, offset: 5015},
+						run: (*parser).callonSymbol31,
 						expr: &litMatcher{
-							pos:        position{line: 199, col: 5, offset: 4732},
+							pos:        position{line: 210, col: 5, offset: 5015},
 							val:        ".",
 							ignoreCase: false,
 							want:       "\".\"",
@@ -693,17 +721,17 @@ var g = &grammar{
 		},
 		{
 			name: "AnyQualifiedPathExpression",
-			pos:  position{line: 206, col: 1, offset: 4870},
+			pos:  position{line: 217, col: 1, offset: 5153},
 			expr: &actionExpr{
-				pos: position{line: 206, col: 31, offset: 4900},
+				pos: position{line: 217, col: 31, offset: 5183},
 				run: (*parser).callonAnyQualifiedPathExpression1,
 				expr: &labeledExpr{
-					pos:   position{line: 206, col: 31, offset: 4900},
+					pos:   position{line: 217, col: 31, offset: 5183},
 					label: "pathExpr",
 					expr: &oneOrMoreExpr{
-						pos: position{line: 206, col: 40, offset: 4909},
+						pos: position{line: 217, col: 40, offset: 5192},
 						expr: &ruleRefExpr{
-							pos:  position{line: 206, col: 41, offset: 4910},
+							pos:  position{line: 217, col: 41, offset: 5193},
 							name: "Accessor",
 						},
 					},
@@ -712,28 +740,28 @@ var g = &grammar{
 		},
 		{
 			name: "ObjectQualifiedPathExpression",
-			pos:  position{line: 218, col: 1, offset: 5160},
+			pos:  position{line: 229, col: 1, offset: 5443},
 			expr: &actionExpr{
-				pos: position{line: 218, col: 34, offset: 5193},
+				pos: position{line: 229, col: 34, offset: 5476},
 				run: (*parser).callonObjectQualifiedPathExpression1,
 				expr: &seqExpr{
-					pos: position{line: 218, col: 34, offset: 5193},
+					pos: position{line: 229, col: 34, offset: 5476},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 218, col: 34, offset: 5193},
+							pos:   position{line: 229, col: 34, offset: 5476},
 							label: "begin",
 							expr: &ruleRefExpr{
-								pos:  position{line: 218, col: 40, offset: 5199},
+								pos:  position{line: 229, col: 40, offset: 5482},
 								name: "ObjectAccessor",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 218, col: 55, offset: 5214},
+							pos:   position{line: 229, col: 55, offset: 5497},
 							label: "pathExpr",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 218, col: 64, offset: 5223},
+								pos: position{line: 229, col: 64, offset: 5506},
 								expr: &ruleRefExpr{
-									pos:  position{line: 218, col: 65, offset: 5224},
+									pos:  position{line: 229, col: 65, offset: 5507},
 									name: "Accessor",
 								},
 							},
@@ -744,28 +772,28 @@ var g = &grammar{
 		},
 		{
 			name: "VectorQualifiedPathExpression",
-			pos:  position{line: 233, col: 1, offset: 5533},
+			pos:  position{line: 244, col: 1, offset: 5816},
 			expr: &actionExpr{
-				pos: position{line: 233, col: 34, offset: 5566},
+				pos: position{line: 244, col: 34, offset: 5849},
 				run: (*parser).callonVectorQualifiedPathExpression1,
 				expr: &seqExpr{
-					pos: position{line: 233, col: 34, offset: 5566},
+					pos: position{line: 244, col: 34, offset: 5849},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 233, col: 34, offset: 5566},
+							pos:   position{line: 244, col: 34, offset: 5849},
 							label: "begin",
 							expr: &ruleRefExpr{
-								pos:  position{line: 233, col: 40, offset: 5572},
+								pos:  position{line: 244, col: 40, offset: 5855},
 								name: "VectorAccessor",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 233, col: 55, offset: 5587},
+							pos:   position{line: 244, col: 55, offset: 5870},
 							label: "pathExpr",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 233, col: 64, offset: 5596},
+								pos: position{line: 244, col: 64, offset: 5879},
 								expr: &ruleRefExpr{
-									pos:  position{line: 233, col: 65, offset: 5597},
+									pos:  position{line: 244, col: 65, offset: 5880},
 									name: "Accessor",
 								},
 							},
@@ -776,84 +804,332 @@ var g = &grammar{
 		},
 		{
 			name: "Accessor",
-			pos:  position{line: 246, col: 1, offset: 5855},
+			pos:  position{line: 257, col: 1, offset: 6138},
 			expr: &choiceExpr{
-				pos: position{line: 246, col: 13, offset: 5867},
+				pos: position{line: 257, col: 13, offset: 6150},
 				alternatives: []any{
 					&ruleRefExpr{
-						pos:  position{line: 246, col: 13, offset: 5867},
+						pos:  position{line: 257, col: 13, offset: 6150},
+						name: "DescentAccessor",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 257, col: 31, offset: 6168},
 						name: "ObjectAccessor",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 246, col: 30, offset: 5884},
+						pos:  position{line: 257, col: 48, offset: 6185},
 						name: "VectorAccessor",
 					},
 				},
 			},
 		},
+		{
+			name: "DescentAccessor",
+			pos:  position{line: 260, col: 1, offset: 6286},
+			expr: &actionExpr{
+				pos: position{line: 260, col: 20, offset: 6305},
+				run: (*parser).callonDescentAccessor1,
+				expr: &seqExpr{
+					pos: position{line: 260, col: 20, offset: 6305},
+					exprs: []any{
+						&litMatcher{
+							pos:        position{line: 260, col: 20, offset: 6305},
+							val:        "..",
+							ignoreCase: false,
+							want:       "\"..\"",
+						},
+						&labeledExpr{
+							pos:   position{line: 260, col: 25, offset: 6310},
+							label: "step",
+							expr: &choiceExpr{
+								pos: position{line: 260, col: 31, offset: 6316},
+								alternatives: []any{
+									&ruleRefExpr{
+										pos:  position{line: 260, col: 31, offset: 6316},
+										name: "PathIdentifier",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 260, col: 48, offset: 6333},
+										name: "VectorStep",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "ObjectAccessor",
-			pos:  position{line: 248, col: 1, offset: 5900},
+			pos:  position{line: 265, col: 1, offset: 6511},
 			expr: &actionExpr{
-				pos: position{line: 248, col: 19, offset: 5918},
+				pos: position{line: 265, col: 19, offset: 6529},
 				run: (*parser).callonObjectAccessor1,
 				expr: &seqExpr{
-					pos: position{line: 248, col: 19, offset: 5918},
+					pos: position{line: 265, col: 19, offset: 6529},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 248, col: 19, offset: 5918},
+							pos:        position{line: 265, col: 19, offset: 6529},
 							val:        ".",
 							ignoreCase: false,
 							want:       "\".\"",
 						},
 						&labeledExpr{
-							pos:   position{line: 248, col: 23, offset: 5922},
+							pos:   position{line: 265, col: 23, offset: 6533},
 							label: "val",
 							expr: &ruleRefExpr{
-								pos:  position{line: 248, col: 27, offset: 5926},
+								pos:  position{line: 265, col: 27, offset: 6537},
 								name: "PathIdentifier",
 							},
 						},
+						&labeledExpr{
+							pos:   position{line: 265, col: 42, offset: 6552},
+							label: "optional",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 265, col: 51, offset: 6561},
+								expr: &litMatcher{
+									pos:        position{line: 265, col: 51, offset: 6561},
+									val:        "?",
+									ignoreCase: false,
+									want:       "\"?\"",
+								},
+							},
+						},
 					},
 				},
 			},
 		},
 		{
 			name: "VectorAccessor",
-			pos:  position{line: 252, col: 1, offset: 5965},
+			pos:  position{line: 269, col: 1, offset: 6631},
 			expr: &actionExpr{
-				pos: position{line: 252, col: 19, offset: 5983},
+				pos: position{line: 269, col: 19, offset: 6649},
 				run: (*parser).callonVectorAccessor1,
 				expr: &seqExpr{
-					pos: position{line: 252, col: 19, offset: 5983},
+					pos: position{line: 269, col: 19, offset: 6649},
 					exprs: []any{
-						&litMatcher{
-							pos:        position{line: 252, col: 19, offset: 5983},
-							val:        "[",
-							ignoreCase: false,
-							want:       "\"[\"",
-						},
-						&ruleRefExpr{
-							pos:  position{line: 252, col: 23, offset: 5987},
-							name: "__",
-						},
 						&labeledExpr{
-							pos:   position{line: 252, col: 26, offset: 5990},
-							label: "expr",
+							pos:   position{line: 269, col: 19, offset: 6649},
+							label: "step",
 							expr: &ruleRefExpr{
-								pos:  position{line: 252, col: 31, offset: 5995},
-								name: "ScalarExpression",
+								pos:  position{line: 269, col: 24, offset: 6654},
+								name: "VectorStep",
 							},
 						},
-						&ruleRefExpr{
-							pos:  position{line: 252, col: 48, offset: 6012},
-							name: "__",
+						&labeledExpr{
+							pos:   position{line: 269, col: 35, offset: 6665},
+							label: "optional",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 269, col: 44, offset: 6674},
+								expr: &litMatcher{
+									pos:        position{line: 269, col: 44, offset: 6674},
+									val:        "?",
+									ignoreCase: false,
+									want:       "\"?\"",
+								},
+							},
 						},
-						&litMatcher{
-							pos:        position{line: 252, col: 51, offset: 6015},
-							val:        "]",
-							ignoreCase: false,
-							want:       "\"]\"",
+					},
+				},
+			},
+		},
+		{
+			name: "VectorStep",
+			pos:  position{line: 273, col: 1, offset: 6745},
+			expr: &choiceExpr{
+				pos: position{line: 273, col: 15, offset: 6759},
+				alternatives: []any{
+					&actionExpr{
+						pos: position{line: 273, col: 15, offset: 6759},
+						run: (*parser).callonVectorStep2,
+						expr: &seqExpr{
+							pos: position{line: 273, col: 15, offset: 6759},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 273, col: 15, offset: 6759},
+									val:        "[",
+									ignoreCase: false,
+									want:       "\"[\"",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 273, col: 19, offset: 6763},
+									name: "__",
+								},
+								&litMatcher{
+									pos:        position{line: 273, col: 22, offset: 6766},
+									val:        "*",
+									ignoreCase: false,
+									want:       "\"*\"",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 273, col: 26, offset: 6770},
+									name: "__",
+								},
+								&litMatcher{
+									pos:        position{line: 273, col: 29, offset: 6773},
+									val:        "]",
+									ignoreCase: false,
+									want:       "\"]\"",
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 275, col: 5, offset: 6817},
+						run: (*parser).callonVectorStep9,
+						expr: &seqExpr{
+							pos: position{line: 275, col: 5, offset: 6817},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 275, col: 5, offset: 6817},
+									val:        "[",
+									ignoreCase: false,
+									want:       "\"[\"",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 275, col: 9, offset: 6821},
+									name: "__",
+								},
+								&litMatcher{
+									pos:        position{line: 275, col: 12, offset: 6824},
+									val:        "?",
+									ignoreCase: false,
+									want:       "\"?\"",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 275, col: 16, offset: 6828},
+									name: "__",
+								},
+								&labeledExpr{
+									pos:   position{line: 275, col: 19, offset: 6831},
+									label: "expr",
+									expr: &choiceExpr{
+										pos: position{line: 275, col: 25, offset: 6837},
+										alternatives: []any{
+											&ruleRefExpr{
+												pos:  position{line: 275, col: 25, offset: 6837},
+												name: "Tuple",
+											},
+											&ruleRefExpr{
+												pos:  position{line: 275, col: 33, offset: 6845},
+												name: "Symbol",
+											},
+										},
+									},
+								},
+								&ruleRefExpr{
+									pos:  position{line: 275, col: 41, offset: 6853},
+									name: "__",
+								},
+								&litMatcher{
+									pos:        position{line: 275, col: 44, offset: 6856},
+									val:        "]",
+									ignoreCase: false,
+									want:       "\"]\"",
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 277, col: 5, offset: 6930},
+						run: (*parser).callonVectorStep21,
+						expr: &seqExpr{
+							pos: position{line: 277, col: 5, offset: 6930},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 277, col: 5, offset: 6930},
+									val:        "[",
+									ignoreCase: false,
+									want:       "\"[\"",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 277, col: 9, offset: 6934},
+									name: "__",
+								},
+								&labeledExpr{
+									pos:   position{line: 277, col: 12, offset: 6937},
+									label: "from",
+									expr: &zeroOrOneExpr{
+										pos: position{line: 277, col: 17, offset: 6942},
+										expr: &ruleRefExpr{
+											pos:  position{line: 277, col: 17, offset: 6942},
+											name: "ScalarExpression",
+										},
+									},
+								},
+								&ruleRefExpr{
+									pos:  position{line: 277, col: 35, offset: 6960},
+									name: "__",
+								},
+								&litMatcher{
+									pos:        position{line: 277, col: 38, offset: 6963},
+									val:        ":",
+									ignoreCase: false,
+									want:       "\":\"",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 277, col: 42, offset: 6967},
+									name: "__",
+								},
+								&labeledExpr{
+									pos:   position{line: 277, col: 45, offset: 6970},
+									label: "to",
+									expr: &zeroOrOneExpr{
+										pos: position{line: 277, col: 48, offset: 6973},
+										expr: &ruleRefExpr{
+											pos:  position{line: 277, col: 48, offset: 6973},
+											name: "ScalarExpression",
+										},
+									},
+								},
+								&ruleRefExpr{
+									pos:  position{line: 277, col: 66, offset: 6991},
+									name: "__",
+								},
+								&litMatcher{
+									pos:        position{line: 277, col: 69, offset: 6994},
+									val:        "]",
+									ignoreCase: false,
+									want:       "\"]\"",
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 289, col: 5, offset: 7182},
+						run: (*parser).callonVectorStep36,
+						expr: &seqExpr{
+							pos: position{line: 289, col: 5, offset: 7182},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 289, col: 5, offset: 7182},
+									val:        "[",
+									ignoreCase: false,
+									want:       "\"[\"",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 289, col: 9, offset: 7186},
+									name: "__",
+								},
+								&labeledExpr{
+									pos:   position{line: 289, col: 12, offset: 7189},
+									label: "expr",
+									expr: &ruleRefExpr{
+										pos:  position{line: 289, col: 17, offset: 7194},
+										name: "ScalarExpression",
+									},
+								},
+								&ruleRefExpr{
+									pos:  position{line: 289, col: 34, offset: 7211},
+									name: "__",
+								},
+								&litMatcher{
+									pos:        position{line: 289, col: 37, offset: 7214},
+									val:        "]",
+									ignoreCase: false,
+									want:       "\"]\"",
+								},
+							},
 						},
 					},
 				},
@@ -861,15 +1137,15 @@ var g = &grammar{
 		},
 		{
 			name: "PathIdentifier",
-			pos:  position{line: 257, col: 1, offset: 6141},
+			pos:  position{line: 294, col: 1, offset: 7340},
 			expr: &actionExpr{
-				pos: position{line: 257, col: 19, offset: 6159},
+				pos: position{line: 294, col: 19, offset: 7358},
 				run: (*parser).callonPathIdentifier1,
 				expr: &seqExpr{
-					pos: position{line: 257, col: 19, offset: 6159},
+					pos: position{line: 294, col: 19, offset: 7358},
 					exprs: []any{
 						&charClassMatcher{
-							pos:        position{line: 257, col: 19, offset: 6159},
+							pos:        position{line: 294, col: 19, offset: 7358},
 							val:        "[a-zA-Z_]",
 							chars:      []rune{'_'},
 							ranges:     []rune{'a', 'z', 'A', 'Z'},
@@ -877,9 +1153,9 @@ var g = &grammar{
 							inverted:   false,
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 257, col: 28, offset: 6168},
+							pos: position{line: 294, col: 28, offset: 7367},
 							expr: &charClassMatcher{
-								pos:        position{line: 257, col: 28, offset: 6168},
+								pos:        position{line: 294, col: 28, offset: 7367},
 								val:        "[a-zA-Z0-9_]",
 								chars:      []rune{'_'},
 								ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
@@ -893,24 +1169,24 @@ var g = &grammar{
 		},
 		{
 			name: "Variable",
-			pos:  position{line: 264, col: 1, offset: 6316},
+			pos:  position{line: 301, col: 1, offset: 7515},
 			expr: &actionExpr{
-				pos: position{line: 264, col: 13, offset: 6328},
+				pos: position{line: 301, col: 13, offset: 7527},
 				run: (*parser).callonVariable1,
 				expr: &seqExpr{
-					pos: position{line: 264, col: 13, offset: 6328},
+					pos: position{line: 301, col: 13, offset: 7527},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 264, col: 13, offset: 6328},
+							pos:        position{line: 301, col: 13, offset: 7527},
 							val:        "$",
 							ignoreCase: false,
 							want:       "\"$\"",
 						},
 						&labeledExpr{
-							pos:   position{line: 264, col: 17, offset: 6332},
+							pos:   position{line: 301, col: 17, offset: 7531},
 							label: "name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 264, col: 22, offset: 6337},
+								pos:  position{line: 301, col: 22, offset: 7536},
 								name: "VariableName",
 							},
 						},
@@ -920,15 +1196,15 @@ var g = &grammar{
 		},
 		{
 			name: "VariableName",
-			pos:  position{line: 269, col: 1, offset: 6493},
+			pos:  position{line: 306, col: 1, offset: 7692},
 			expr: &actionExpr{
-				pos: position{line: 269, col: 17, offset: 6509},
+				pos: position{line: 306, col: 17, offset: 7708},
 				run: (*parser).callonVariableName1,
 				expr: &seqExpr{
-					pos: position{line: 269, col: 17, offset: 6509},
+					pos: position{line: 306, col: 17, offset: 7708},
 					exprs: []any{
 						&charClassMatcher{
-							pos:        position{line: 269, col: 17, offset: 6509},
+							pos:        position{line: 306, col: 17, offset: 7708},
 							val:        "[a-zA-Z_]",
 							chars:      []rune{'_'},
 							ranges:     []rune{'a', 'z', 'A', 'Z'},
@@ -936,9 +1212,9 @@ var g = &grammar{
 							inverted:   false,
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 269, col: 26, offset: 6518},
+							pos: position{line: 306, col: 26, offset: 7717},
 							expr: &charClassMatcher{
-								pos:        position{line: 269, col: 26, offset: 6518},
+								pos:        position{line: 306, col: 26, offset: 7717},
 								val:        "[a-zA-Z0-9_]",
 								chars:      []rune{'_'},
 								ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
@@ -952,15 +1228,99 @@ var g = &grammar{
 		},
 		{
 			name: "Identifier",
-			pos:  position{line: 274, col: 1, offset: 6664},
+			pos:  position{line: 310, col: 1, offset: 7766},
+			expr: &choiceExpr{
+				pos: position{line: 310, col: 15, offset: 7780},
+				alternatives: []any{
+					&ruleRefExpr{
+						pos:  position{line: 310, col: 15, offset: 7780},
+						name: "NamespacedIdentifier",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 310, col: 38, offset: 7803},
+						name: "IdentifierName",
+					},
+				},
+			},
+		},
+		{
+			name: "NamespacedIdentifier",
+			pos:  position{line: 314, col: 1, offset: 8005},
+			expr: &actionExpr{
+				pos: position{line: 314, col: 25, offset: 8029},
+				run: (*parser).callonNamespacedIdentifier1,
+				expr: &seqExpr{
+					pos: position{line: 314, col: 25, offset: 8029},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 314, col: 25, offset: 8029},
+							label: "namespace",
+							expr: &ruleRefExpr{
+								pos:  position{line: 314, col: 35, offset: 8039},
+								name: "Namespace",
+							},
+						},
+						&litMatcher{
+							pos:        position{line: 314, col: 45, offset: 8049},
+							val:        "/",
+							ignoreCase: false,
+							want:       "\"/\"",
+						},
+						&labeledExpr{
+							pos:   position{line: 314, col: 49, offset: 8053},
+							label: "name",
+							expr: &ruleRefExpr{
+								pos:  position{line: 314, col: 54, offset: 8058},
+								name: "IdentifierName",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Namespace",
+			pos:  position{line: 322, col: 1, offset: 8266},
+			expr: &actionExpr{
+				pos: position{line: 322, col: 14, offset: 8279},
+				run: (*parser).callonNamespace1,
+				expr: &seqExpr{
+					pos: position{line: 322, col: 14, offset: 8279},
+					exprs: []any{
+						&charClassMatcher{
+							pos:        position{line: 322, col: 14, offset: 8279},
+							val:        "[a-zA-Z_]",
+							chars:      []rune{'_'},
+							ranges:     []rune{'a', 'z', 'A', 'Z'},
+							ignoreCase: false,
+							inverted:   false,
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 322, col: 23, offset: 8288},
+							expr: &charClassMatcher{
+								pos:        position{line: 322, col: 23, offset: 8288},
+								val:        "[a-zA-Z0-9_-]",
+								chars:      []rune{'_', '-'},
+								ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "IdentifierName",
+			pos:  position{line: 327, col: 1, offset: 8435},
 			expr: &actionExpr{
-				pos: position{line: 274, col: 15, offset: 6678},
-				run: (*parser).callonIdentifier1,
+				pos: position{line: 327, col: 19, offset: 8453},
+				run: (*parser).callonIdentifierName1,
 				expr: &seqExpr{
-					pos: position{line: 274, col: 15, offset: 6678},
+					pos: position{line: 327, col: 19, offset: 8453},
 					exprs: []any{
 						&charClassMatcher{
-							pos:        position{line: 274, col: 15, offset: 6678},
+							pos:        position{line: 327, col: 19, offset: 8453},
 							val:        "[a-zA-Z_+/*_%?-]",
 							chars:      []rune{'_', '+', '/', '*', '_', '%', '?', '-'},
 							ranges:     []rune{'a', 'z', 'A', 'Z'},
@@ -968,9 +1328,9 @@ var g = &grammar{
 							inverted:   false,
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 274, col: 31, offset: 6694},
+							pos: position{line: 327, col: 35, offset: 8469},
 							expr: &charClassMatcher{
-								pos:        position{line: 274, col: 31, offset: 6694},
+								pos:        position{line: 327, col: 35, offset: 8469},
 								val:        "[a-zA-Z0-9_+/*_%?!-]",
 								chars:      []rune{'_', '+', '/', '*', '_', '%', '?', '!', '-'},
 								ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
@@ -984,25 +1344,25 @@ var g = &grammar{
 		},
 		{
 			name: "Bool",
-			pos:  position{line: 288, col: 1, offset: 6999},
+			pos:  position{line: 341, col: 1, offset: 8774},
 			expr: &choiceExpr{
-				pos: position{line: 288, col: 9, offset: 7007},
+				pos: position{line: 341, col: 9, offset: 8782},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 288, col: 9, offset: 7007},
+						pos: position{line: 341, col: 9, offset: 8782},
 						run: (*parser).callonBool2,
 						expr: &litMatcher{
-							pos:        position{line: 288, col: 9, offset: 7007},
+							pos:        position{line: 341, col: 9, offset: 8782},
 							val:        "true",
 							ignoreCase: false,
 							want:       "\"true\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 288, col: 49, offset: 7047},
+						pos: position{line: 341, col: 49, offset: 8822},
 						run: (*parser).callonBool4,
 						expr: &litMatcher{
-							pos:        position{line: 288, col: 49, offset: 7047},
+							pos:        position{line: 341, col: 49, offset: 8822},
 							val:        "false",
 							ignoreCase: false,
 							want:       "\"false\"",
@@ -1013,12 +1373,12 @@ var g = &grammar{
 		},
 		{
 			name: "Null",
-			pos:  position{line: 290, col: 1, offset: 7088},
+			pos:  position{line: 343, col: 1, offset: 8863},
 			expr: &actionExpr{
-				pos: position{line: 290, col: 9, offset: 7096},
+				pos: position{line: 343, col: 9, offset: 8871},
 				run: (*parser).callonNull1,
 				expr: &litMatcher{
-					pos:        position{line: 290, col: 9, offset: 7096},
+					pos:        position{line: 343, col: 9, offset: 8871},
 					val:        "null",
 					ignoreCase: false,
 					want:       "\"null\"",
@@ -1027,52 +1387,52 @@ var g = &grammar{
 		},
 		{
 			name: "Number",
-			pos:  position{line: 295, col: 1, offset: 7220},
+			pos:  position{line: 348, col: 1, offset: 8995},
 			expr: &choiceExpr{
-				pos: position{line: 295, col: 11, offset: 7230},
+				pos: position{line: 348, col: 11, offset: 9005},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 295, col: 11, offset: 7230},
+						pos: position{line: 348, col: 11, offset: 9005},
 						run: (*parser).callonNumber2,
 						expr: &seqExpr{
-							pos: position{line: 295, col: 11, offset: 7230},
+							pos: position{line: 348, col: 11, offset: 9005},
 							exprs: []any{
 								&zeroOrOneExpr{
-									pos: position{line: 295, col: 11, offset: 7230},
+									pos: position{line: 348, col: 11, offset: 9005},
 									expr: &litMatcher{
-										pos:        position{line: 295, col: 11, offset: 7230},
+										pos:        position{line: 348, col: 11, offset: 9005},
 										val:        "-",
 										ignoreCase: false,
 										want:       "\"-\"",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 295, col: 16, offset: 7235},
+									pos:  position{line: 348, col: 16, offset: 9010},
 									name: "Integer",
 								},
 								&choiceExpr{
-									pos: position{line: 295, col: 25, offset: 7244},
+									pos: position{line: 348, col: 25, offset: 9019},
 									alternatives: []any{
 										&seqExpr{
-											pos: position{line: 295, col: 27, offset: 7246},
+											pos: position{line: 348, col: 27, offset: 9021},
 											exprs: []any{
 												&litMatcher{
-													pos:        position{line: 295, col: 27, offset: 7246},
+													pos:        position{line: 348, col: 27, offset: 9021},
 													val:        ".",
 													ignoreCase: false,
 													want:       "\".\"",
 												},
 												&oneOrMoreExpr{
-													pos: position{line: 295, col: 31, offset: 7250},
+													pos: position{line: 348, col: 31, offset: 9025},
 													expr: &ruleRefExpr{
-														pos:  position{line: 295, col: 31, offset: 7250},
+														pos:  position{line: 348, col: 31, offset: 9025},
 														name: "DecimalDigit",
 													},
 												},
 											},
 										},
 										&ruleRefExpr{
-											pos:  position{line: 295, col: 49, offset: 7268},
+											pos:  position{line: 348, col: 49, offset: 9043},
 											name: "Exponent",
 										},
 									},
@@ -1081,13 +1441,13 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 304, col: 5, offset: 7538},
+						pos: position{line: 357, col: 5, offset: 9313},
 						run: (*parser).callonNumber13,
 						expr: &labeledExpr{
-							pos:   position{line: 304, col: 5, offset: 7538},
+							pos:   position{line: 357, col: 5, offset: 9313},
 							label: "i",
 							expr: &ruleRefExpr{
-								pos:  position{line: 304, col: 7, offset: 7540},
+								pos:  position{line: 357, col: 7, offset: 9315},
 								name: "Integer",
 							},
 						},
@@ -1097,43 +1457,43 @@ var g = &grammar{
 		},
 		{
 			name: "Integer",
-			pos:  position{line: 308, col: 1, offset: 7589},
+			pos:  position{line: 361, col: 1, offset: 9364},
 			expr: &choiceExpr{
-				pos: position{line: 308, col: 12, offset: 7600},
+				pos: position{line: 361, col: 12, offset: 9375},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 308, col: 12, offset: 7600},
+						pos: position{line: 361, col: 12, offset: 9375},
 						run: (*parser).callonInteger2,
 						expr: &litMatcher{
-							pos:        position{line: 308, col: 12, offset: 7600},
+							pos:        position{line: 361, col: 12, offset: 9375},
 							val:        "0",
 							ignoreCase: false,
 							want:       "\"0\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 310, col: 5, offset: 7634},
+						pos: position{line: 363, col: 5, offset: 9409},
 						run: (*parser).callonInteger4,
 						expr: &seqExpr{
-							pos: position{line: 310, col: 5, offset: 7634},
+							pos: position{line: 363, col: 5, offset: 9409},
 							exprs: []any{
 								&zeroOrOneExpr{
-									pos: position{line: 310, col: 5, offset: 7634},
+									pos: position{line: 363, col: 5, offset: 9409},
 									expr: &litMatcher{
-										pos:        position{line: 310, col: 5, offset: 7634},
+										pos:        position{line: 363, col: 5, offset: 9409},
 										val:        "-",
 										ignoreCase: false,
 										want:       "\"-\"",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 310, col: 10, offset: 7639},
+									pos:  position{line: 363, col: 10, offset: 9414},
 									name: "NonZeroDecimalDigit",
 								},
 								&zeroOrMoreExpr{
-									pos: position{line: 310, col: 30, offset: 7659},
+									pos: position{line: 363, col: 30, offset: 9434},
 									expr: &ruleRefExpr{
-										pos:  position{line: 310, col: 30, offset: 7659},
+										pos:  position{line: 363, col: 30, offset: 9434},
 										name: "DecimalDigit",
 									},
 								},
@@ -1145,9 +1505,9 @@ var g = &grammar{
 		},
 		{
 			name: "DecimalDigit",
-			pos:  position{line: 319, col: 1, offset: 7827},
+			pos:  position{line: 372, col: 1, offset: 9602},
 			expr: &charClassMatcher{
-				pos:        position{line: 319, col: 17, offset: 7843},
+				pos:        position{line: 372, col: 17, offset: 9618},
 				val:        "[0-9]",
 				ranges:     []rune{'0', '9'},
 				ignoreCase: false,
@@ -1156,9 +1516,9 @@ var g = &grammar{
 		},
 		{
 			name: "NonZeroDecimalDigit",
-			pos:  position{line: 321, col: 1, offset: 7850},
+			pos:  position{line: 374, col: 1, offset: 9625},
 			expr: &charClassMatcher{
-				pos:        position{line: 321, col: 24, offset: 7873},
+				pos:        position{line: 374, col: 24, offset: 9648},
 				val:        "[1-9]",
 				ranges:     []rune{'1', '9'},
 				ignoreCase: false,
@@ -1167,20 +1527,20 @@ var g = &grammar{
 		},
 		{
 			name: "Exponent",
-			pos:  position{line: 323, col: 1, offset: 7880},
+			pos:  position{line: 376, col: 1, offset: 9655},
 			expr: &seqExpr{
-				pos: position{line: 323, col: 13, offset: 7892},
+				pos: position{line: 376, col: 13, offset: 9667},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 323, col: 13, offset: 7892},
+						pos:        position{line: 376, col: 13, offset: 9667},
 						val:        "e",
 						ignoreCase: true,
 						want:       "\"e\"i",
 					},
 					&zeroOrOneExpr{
-						pos: position{line: 323, col: 18, offset: 7897},
+						pos: position{line: 376, col: 18, offset: 9672},
 						expr: &charClassMatcher{
-							pos:        position{line: 323, col: 18, offset: 7897},
+							pos:        position{line: 376, col: 18, offset: 9672},
 							val:        "[+-]",
 							chars:      []rune{'+', '-'},
 							ignoreCase: false,
@@ -1188,9 +1548,9 @@ var g = &grammar{
 						},
 					},
 					&oneOrMoreExpr{
-						pos: position{line: 323, col: 24, offset: 7903},
+						pos: position{line: 376, col: 24, offset: 9678},
 						expr: &ruleRefExpr{
-							pos:  position{line: 323, col: 24, offset: 7903},
+							pos:  position{line: 376, col: 24, offset: 9678},
 							name: "DecimalDigit",
 						},
 					},
@@ -1199,50 +1559,50 @@ var g = &grammar{
 		},
 		{
 			name: "String",
-			pos:  position{line: 328, col: 1, offset: 7989},
+			pos:  position{line: 381, col: 1, offset: 9764},
 			expr: &actionExpr{
-				pos: position{line: 328, col: 11, offset: 7999},
+				pos: position{line: 381, col: 11, offset: 9774},
 				run: (*parser).callonString1,
 				expr: &seqExpr{
-					pos: position{line: 328, col: 11, offset: 7999},
+					pos: position{line: 381, col: 11, offset: 9774},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 328, col: 11, offset: 7999},
+							pos:        position{line: 381, col: 11, offset: 9774},
 							val:        "\"",
 							ignoreCase: false,
 							want:       "\"\\\"\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 328, col: 15, offset: 8003},
+							pos: position{line: 381, col: 15, offset: 9778},
 							expr: &choiceExpr{
-								pos: position{line: 328, col: 17, offset: 8005},
+								pos: position{line: 381, col: 17, offset: 9780},
 								alternatives: []any{
 									&seqExpr{
-										pos: position{line: 328, col: 17, offset: 8005},
+										pos: position{line: 381, col: 17, offset: 9780},
 										exprs: []any{
 											&notExpr{
-												pos: position{line: 328, col: 17, offset: 8005},
+												pos: position{line: 381, col: 17, offset: 9780},
 												expr: &ruleRefExpr{
-													pos:  position{line: 328, col: 18, offset: 8006},
+													pos:  position{line: 381, col: 18, offset: 9781},
 													name: "EscapedChar",
 												},
 											},
 											&anyMatcher{
-												line: 328, col: 30, offset: 8018,
+												line: 381, col: 30, offset: 9793,
 											},
 										},
 									},
 									&seqExpr{
-										pos: position{line: 328, col: 34, offset: 8022},
+										pos: position{line: 381, col: 34, offset: 9797},
 										exprs: []any{
 											&litMatcher{
-												pos:        position{line: 328, col: 34, offset: 8022},
+												pos:        position{line: 381, col: 34, offset: 9797},
 												val:        "\\",
 												ignoreCase: false,
 												want:       "\"\\\\\"",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 328, col: 39, offset: 8027},
+												pos:  position{line: 381, col: 39, offset: 9802},
 												name: "EscapeSequence",
 											},
 										},
@@ -1251,7 +1611,7 @@ var g = &grammar{
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 328, col: 57, offset: 8045},
+							pos:        position{line: 381, col: 57, offset: 9820},
 							val:        "\"",
 							ignoreCase: false,
 							want:       "\"\\\"\"",
@@ -1262,9 +1622,9 @@ var g = &grammar{
 		},
 		{
 			name: "EscapedChar",
-			pos:  position{line: 339, col: 1, offset: 8255},
+			pos:  position{line: 392, col: 1, offset: 10030},
 			expr: &charClassMatcher{
-				pos:        position{line: 339, col: 16, offset: 8270},
+				pos:        position{line: 392, col: 16, offset: 10045},
 				val:        "[\\x00-\\x1f\"\\\\]",
 				chars:      []rune{'"', '\\'},
 				ranges:     []rune{'\x00', '\x1f'},
@@ -1274,16 +1634,16 @@ var g = &grammar{
 		},
 		{
 			name: "EscapeSequence",
-			pos:  position{line: 341, col: 1, offset: 8286},
+			pos:  position{line: 394, col: 1, offset: 10061},
 			expr: &choiceExpr{
-				pos: position{line: 341, col: 19, offset: 8304},
+				pos: position{line: 394, col: 19, offset: 10079},
 				alternatives: []any{
 					&ruleRefExpr{
-						pos:  position{line: 341, col: 19, offset: 8304},
+						pos:  position{line: 394, col: 19, offset: 10079},
 						name: "SingleCharEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 341, col: 38, offset: 8323},
+						pos:  position{line: 394, col: 38, offset: 10098},
 						name: "UnicodeEscape",
 					},
 				},
@@ -1291,9 +1651,9 @@ var g = &grammar{
 		},
 		{
 			name: "SingleCharEscape",
-			pos:  position{line: 343, col: 1, offset: 8338},
+			pos:  position{line: 396, col: 1, offset: 10113},
 			expr: &charClassMatcher{
-				pos:        position{line: 343, col: 21, offset: 8358},
+				pos:        position{line: 396, col: 21, offset: 10133},
 				val:        "[\"\\\\/bfnrt]",
 				chars:      []rune{'"', '\\', '/', 'b', 'f', 'n', 'r', 't'},
 				ignoreCase: false,
@@ -1302,30 +1662,30 @@ var g = &grammar{
 		},
 		{
 			name: "UnicodeEscape",
-			pos:  position{line: 345, col: 1, offset: 8371},
+			pos:  position{line: 398, col: 1, offset: 10146},
 			expr: &seqExpr{
-				pos: position{line: 345, col: 18, offset: 8388},
+				pos: position{line: 398, col: 18, offset: 10163},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 345, col: 18, offset: 8388},
+						pos:        position{line: 398, col: 18, offset: 10163},
 						val:        "u",
 						ignoreCase: false,
 						want:       "\"u\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 345, col: 22, offset: 8392},
+						pos:  position{line: 398, col: 22, offset: 10167},
 						name: "HexDigit",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 345, col: 31, offset: 8401},
+						pos:  position{line: 398, col: 31, offset: 10176},
 						name: "HexDigit",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 345, col: 40, offset: 8410},
+						pos:  position{line: 398, col: 40, offset: 10185},
 						name: "HexDigit",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 345, col: 49, offset: 8419},
+						pos:  position{line: 398, col: 49, offset: 10194},
 						name: "HexDigit",
 					},
 				},
@@ -1333,9 +1693,9 @@ var g = &grammar{
 		},
 		{
 			name: "HexDigit",
-			pos:  position{line: 347, col: 1, offset: 8429},
+			pos:  position{line: 400, col: 1, offset: 10204},
 			expr: &charClassMatcher{
-				pos:        position{line: 347, col: 13, offset: 8441},
+				pos:        position{line: 400, col: 13, offset: 10216},
 				val:        "[0-9a-f]i",
 				ranges:     []rune{'0', '9', 'a', 'f'},
 				ignoreCase: true,
@@ -1344,21 +1704,21 @@ var g = &grammar{
 		},
 		{
 			name: "SingleLineComment",
-			pos:  position{line: 352, col: 1, offset: 8524},
+			pos:  position{line: 405, col: 1, offset: 10299},
 			expr: &seqExpr{
-				pos: position{line: 352, col: 21, offset: 8546},
+				pos: position{line: 405, col: 21, offset: 10321},
 				exprs: []any{
 					&choiceExpr{
-						pos: position{line: 352, col: 23, offset: 8548},
+						pos: position{line: 405, col: 23, offset: 10323},
 						alternatives: []any{
 							&litMatcher{
-								pos:        position{line: 352, col: 23, offset: 8548},
+								pos:        position{line: 405, col: 23, offset: 10323},
 								val:        "#",
 								ignoreCase: false,
 								want:       "\"#\"",
 							},
 							&litMatcher{
-								pos:        position{line: 352, col: 29, offset: 8554},
+								pos:        position{line: 405, col: 29, offset: 10329},
 								val:        ";",
 								ignoreCase: false,
 								want:       "\";\"",
@@ -1366,19 +1726,19 @@ var g = &grammar{
 						},
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 352, col: 35, offset: 8560},
+						pos: position{line: 405, col: 35, offset: 10335},
 						expr: &seqExpr{
-							pos: position{line: 352, col: 37, offset: 8562},
+							pos: position{line: 405, col: 37, offset: 10337},
 							exprs: []any{
 								&notExpr{
-									pos: position{line: 352, col: 37, offset: 8562},
+									pos: position{line: 405, col: 37, offset: 10337},
 									expr: &ruleRefExpr{
-										pos:  position{line: 352, col: 38, offset: 8563},
+										pos:  position{line: 405, col: 38, offset: 10338},
 										name: "EOL",
 									},
 								},
 								&anyMatcher{
-									line: 352, col: 42, offset: 8567,
+									line: 405, col: 42, offset: 10342,
 								},
 							},
 						},
@@ -1388,22 +1748,22 @@ var g = &grammar{
 		},
 		{
 			name: "___",
-			pos:  position{line: 357, col: 1, offset: 8641},
+			pos:  position{line: 410, col: 1, offset: 10416},
 			expr: &oneOrMoreExpr{
-				pos: position{line: 357, col: 8, offset: 8648},
+				pos: position{line: 410, col: 8, offset: 10423},
 				expr: &choiceExpr{
-					pos: position{line: 357, col: 10, offset: 8650},
+					pos: position{line: 410, col: 10, offset: 10425},
 					alternatives: []any{
 						&ruleRefExpr{
-							pos:  position{line: 357, col: 10, offset: 8650},
+							pos:  position{line: 410, col: 10, offset: 10425},
 							name: "Whitespace",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 357, col: 23, offset: 8663},
+							pos:  position{line: 410, col: 23, offset: 10438},
 							name: "EOL",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 357, col: 29, offset: 8669},
+							pos:  position{line: 410, col: 29, offset: 10444},
 							name: "SingleLineComment",
 						},
 					},
@@ -1412,22 +1772,22 @@ var g = &grammar{
 		},
 		{
 			name: "__",
-			pos:  position{line: 358, col: 1, offset: 8690},
+			pos:  position{line: 411, col: 1, offset: 10465},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 358, col: 7, offset: 8696},
+				pos: position{line: 411, col: 7, offset: 10471},
 				expr: &choiceExpr{
-					pos: position{line: 358, col: 9, offset: 8698},
+					pos: position{line: 411, col: 9, offset: 10473},
 					alternatives: []any{
 						&ruleRefExpr{
-							pos:  position{line: 358, col: 9, offset: 8698},
+							pos:  position{line: 411, col: 9, offset: 10473},
 							name: "Whitespace",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 358, col: 22, offset: 8711},
+							pos:  position{line: 411, col: 22, offset: 10486},
 							name: "EOL",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 358, col: 28, offset: 8717},
+							pos:  position{line: 411, col: 28, offset: 10492},
 							name: "SingleLineComment",
 						},
 					},
@@ -1436,20 +1796,20 @@ var g = &grammar{
 		},
 		{
 			name: "_",
-			pos:  position{line: 359, col: 1, offset: 8738},
+			pos:  position{line: 412, col: 1, offset: 10513},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 359, col: 6, offset: 8743},
+				pos: position{line: 412, col: 6, offset: 10518},
 				expr: &ruleRefExpr{
-					pos:  position{line: 359, col: 6, offset: 8743},
+					pos:  position{line: 412, col: 6, offset: 10518},
 					name: "Whitespace",
 				},
 			},
 		},
 		{
 			name: "Whitespace",
-			pos:  position{line: 361, col: 1, offset: 8756},
+			pos:  position{line: 414, col: 1, offset: 10531},
 			expr: &charClassMatcher{
-				pos:        position{line: 361, col: 15, offset: 8770},
+				pos:        position{line: 414, col: 15, offset: 10545},
 				val:        "[ \\t\\r]",
 				chars:      []rune{' ', '\t', '\r'},
 				ignoreCase: false,
@@ -1458,9 +1818,9 @@ var g = &grammar{
 		},
 		{
 			name: "EOL",
-			pos:  position{line: 362, col: 1, offset: 8778},
+			pos:  position{line: 415, col: 1, offset: 10553},
 			expr: &litMatcher{
-				pos:        position{line: 362, col: 8, offset: 8785},
+				pos:        position{line: 415, col: 8, offset: 10560},
 				val:        "\n",
 				ignoreCase: false,
 				want:       "\"\\n\"",
@@ -1468,39 +1828,39 @@ var g = &grammar{
 		},
 		{
 			name: "EOS",
-			pos:  position{line: 363, col: 1, offset: 8790},
+			pos:  position{line: 416, col: 1, offset: 10565},
 			expr: &choiceExpr{
-				pos: position{line: 363, col: 8, offset: 8797},
+				pos: position{line: 416, col: 8, offset: 10572},
 				alternatives: []any{
 					&seqExpr{
-						pos: position{line: 363, col: 8, offset: 8797},
+						pos: position{line: 416, col: 8, offset: 10572},
 						exprs: []any{
 							&ruleRefExpr{
-								pos:  position{line: 363, col: 8, offset: 8797},
+								pos:  position{line: 416, col: 8, offset: 10572},
 								name: "_",
 							},
 							&zeroOrOneExpr{
-								pos: position{line: 363, col: 10, offset: 8799},
+								pos: position{line: 416, col: 10, offset: 10574},
 								expr: &ruleRefExpr{
-									pos:  position{line: 363, col: 10, offset: 8799},
+									pos:  position{line: 416, col: 10, offset: 10574},
 									name: "SingleLineComment",
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 363, col: 29, offset: 8818},
+								pos:  position{line: 416, col: 29, offset: 10593},
 								name: "EOL",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 363, col: 35, offset: 8824},
+						pos: position{line: 416, col: 35, offset: 10599},
 						exprs: []any{
 							&ruleRefExpr{
-								pos:  position{line: 363, col: 35, offset: 8824},
+								pos:  position{line: 416, col: 35, offset: 10599},
 								name: "__",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 363, col: 38, offset: 8827},
+								pos:  position{line: 416, col: 38, offset: 10602},
 								name: "EOF",
 							},
 						},
@@ -1510,11 +1870,11 @@ var g = &grammar{
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 365, col: 1, offset: 8832},
+			pos:  position{line: 418, col: 1, offset: 10607},
 			expr: &notExpr{
-				pos: position{line: 365, col: 8, offset: 8839},
+				pos: position{line: 418, col: 8, offset: 10614},
 				expr: &anyMatcher{
-					line: 365, col: 9, offset: 8840,
+					line: 418, col: 9, offset: 10615,
 				},
 			},
 		},
@@ -1742,14 +2102,14 @@ func (p *parser) callonSymbol2() (any, error) {
 }
 
 func (c *current) onSymbol10(acc, expr any) (any, error) {
-	objAcc := acc.(ast.Expression)
+	descAcc := acc.(ast.Expression)
 
 	pathExpr := ast.PathExpression{}
 	if expr != nil {
 		pathExpr = expr.(ast.PathExpression)
 	}
 
-	pathExpr.Prepend(objAcc)
+	pathExpr.Prepend(descAcc)
 
 	return ast.Symbol{PathExpression: &pathExpr}, nil
 }
@@ -1760,7 +2120,26 @@ func (p *parser) callonSymbol10() (any, error) {
 	return p.cur.onSymbol10(stack["acc"], stack["expr"])
 }
 
-func (c *current) onSymbol17(val, expr any) (any, error) {
+func (c *current) onSymbol17(acc, expr any) (any, error) {
+	objAcc := acc.(ast.Expression)
+
+	pathExpr := ast.PathExpression{}
+	if expr != nil {
+		pathExpr = expr.(ast.PathExpression)
+	}
+
+	pathExpr.Prepend(objAcc)
+
+	return ast.Symbol{PathExpression: &pathExpr}, nil
+}
+
+func (p *parser) callonSymbol17() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSymbol17(stack["acc"], stack["expr"])
+}
+
+func (c *current) onSymbol24(val, expr any) (any, error) {
 	variable := val.(ast.Variable)
 
 	var pathExpr *ast.PathExpression
@@ -1775,22 +2154,22 @@ func (c *current) onSymbol17(val, expr any) (any, error) {
 	}, nil
 }
 
-func (p *parser) callonSymbol17() (any, error) {
+func (p *parser) callonSymbol24() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onSymbol17(stack["val"], stack["expr"])
+	return p.cur.onSymbol24(stack["val"], stack["expr"])
 }
 
-func (c *current) onSymbol24() (any, error) {
+func (c *current) onSymbol31() (any, error) {
 	return ast.Symbol{
 		PathExpression: &ast.PathExpression{},
 	}, nil
 }
 
-func (p *parser) callonSymbol24() (any, error) {
+func (p *parser) callonSymbol31() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onSymbol24()
+	return p.cur.onSymbol31()
 }
 
 func (c *current) onAnyQualifiedPathExpression1(pathExpr any) (any, error) {
@@ -1849,24 +2228,84 @@ func (p *parser) callonVectorQualifiedPathExpression1() (any, error) {
 	return p.cur.onVectorQualifiedPathExpression1(stack["begin"], stack["pathExpr"])
 }
 
-func (c *current) onObjectAccessor1(val any) (any, error) {
-	return val, nil
+func (c *current) onDescentAccessor1(step any) (any, error) {
+	return ast.DescentStep{Step: step.(ast.Expression)}, nil
+}
+
+func (p *parser) callonDescentAccessor1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDescentAccessor1(stack["step"])
+}
+
+func (c *current) onObjectAccessor1(val, optional any) (any, error) {
+	return makeOptional(val.(ast.Expression), optional), nil
 }
 
 func (p *parser) callonObjectAccessor1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onObjectAccessor1(stack["val"])
+	return p.cur.onObjectAccessor1(stack["val"], stack["optional"])
 }
 
-func (c *current) onVectorAccessor1(expr any) (any, error) {
-	return expr, nil
+func (c *current) onVectorAccessor1(step, optional any) (any, error) {
+	return makeOptional(step.(ast.Expression), optional), nil
 }
 
 func (p *parser) callonVectorAccessor1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onVectorAccessor1(stack["expr"])
+	return p.cur.onVectorAccessor1(stack["step"], stack["optional"])
+}
+
+func (c *current) onVectorStep2() (any, error) {
+	return ast.WildcardStep{}, nil
+}
+
+func (p *parser) callonVectorStep2() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onVectorStep2()
+}
+
+func (c *current) onVectorStep9(expr any) (any, error) {
+	return ast.FilterStep{Predicate: expr.(ast.Expression)}, nil
+}
+
+func (p *parser) callonVectorStep9() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onVectorStep9(stack["expr"])
+}
+
+func (c *current) onVectorStep21(from, to any) (any, error) {
+	slice := ast.SliceStep{}
+
+	if from != nil {
+		slice.From = from.(ast.Expression)
+	}
+
+	if to != nil {
+		slice.To = to.(ast.Expression)
+	}
+
+	return slice, nil
+}
+
+func (p *parser) callonVectorStep21() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onVectorStep21(stack["from"], stack["to"])
+}
+
+func (c *current) onVectorStep36(expr any) (any, error) {
+	return expr, nil
+}
+
+func (p *parser) callonVectorStep36() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onVectorStep36(stack["expr"])
 }
 
 func (c *current) onPathIdentifier1() (any, error) {
@@ -1899,7 +2338,30 @@ func (p *parser) callonVariableName1() (any, error) {
 	return p.cur.onVariableName1()
 }
 
-func (c *current) onIdentifier1() (any, error) {
+func (c *current) onNamespacedIdentifier1(namespace, name any) (any, error) {
+	ident := name.(ast.Identifier)
+	ident.Namespace = namespace.(string)
+
+	return ident, nil
+}
+
+func (p *parser) callonNamespacedIdentifier1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onNamespacedIdentifier1(stack["namespace"], stack["name"])
+}
+
+func (c *current) onNamespace1() (any, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonNamespace1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onNamespace1()
+}
+
+func (c *current) onIdentifierName1() (any, error) {
 	name := string(c.text)
 	bang := false
 	if strings.HasSuffix(name, "!") {
@@ -1910,10 +2372,10 @@ func (c *current) onIdentifier1() (any, error) {
 	return ast.Identifier{Name: name, Bang: bang}, nil
 }
 
-func (p *parser) callonIdentifier1() (any, error) {
+func (p *parser) callonIdentifierName1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onIdentifier1()
+	return p.cur.onIdentifierName1()
 }
 
 func (c *current) onBool2() (any, error) {
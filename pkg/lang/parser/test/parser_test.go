@@ -50,6 +50,20 @@ func TestParseProgram(t *testing.T) {
 			input:    `(func+ -foo bar!)`,
 			expected: `(tuple (identifier func+) (identifier -foo) (identifier bar (bang)))`,
 		},
+		{
+			input:    `(set/contains? my-mod/update! _/x)`,
+			expected: `(tuple (identifier contains? (namespace set)) (identifier update (namespace my-mod) (bang)) (identifier x (namespace _)))`,
+		},
+		{
+			// only the first slash separates the namespace
+			input:    `(a/b/c)`,
+			expected: `(tuple (identifier b/c (namespace a)))`,
+		},
+		{
+			// identifiers that do not form a valid namespace are left as they are
+			input:    `(/ foo/ +/bar -a/b)`,
+			expected: `(tuple (identifier /) (identifier foo/) (identifier +/bar) (identifier -a/b))`,
+		},
 		{
 			input:    `(1)`,
 			expected: `(tuple (number (int64 1)))`,
@@ -70,6 +84,11 @@ func TestParseProgram(t *testing.T) {
 			input:   `("fo\")`,
 			invalid: true,
 		},
+		{
+			// shebang lines are regular comments
+			input:    "#!/usr/bin/env -S rudi -s\n(add)",
+			expected: `(tuple (identifier add))`,
+		},
 		{
 			input:    `(null true false)`,
 			expected: `(tuple (null) (bool true) (bool false))`,
@@ -174,6 +193,74 @@ func TestParseProgram(t *testing.T) {
 			input:   `(+ .bar[0].[1])`,
 			invalid: true,
 		},
+		{
+			input:    `(+ .bar[*].foo)`,
+			expected: `(tuple (identifier +) (symbol (path [(identifier bar) (wildcard) (identifier foo)])))`,
+		},
+		{
+			input:    `(+ .[ * ])`,
+			expected: `(tuple (identifier +) (symbol (path [(wildcard)])))`,
+		},
+		{
+			input:    `(+ .bar[1:3])`,
+			expected: `(tuple (identifier +) (symbol (path [(identifier bar) (slice (from (number (int64 1))) (to (number (int64 3))))])))`,
+		},
+		{
+			input:    `(+ .bar[:-1][$i:])`,
+			expected: `(tuple (identifier +) (symbol (path [(identifier bar) (slice (to (number (int64 -1)))) (slice (from (symbol (var i))))])))`,
+		},
+		{
+			input:    `(+ .bar[:])`,
+			expected: `(tuple (identifier +) (symbol (path [(identifier bar) (slice)])))`,
+		},
+		{
+			input:    `(+ .bar[-1])`,
+			expected: `(tuple (identifier +) (symbol (path [(identifier bar) (number (int64 -1))])))`,
+		},
+		{
+			input:    `(+ .bar[?(eq? .name "app")])`,
+			expected: `(tuple (identifier +) (symbol (path [(identifier bar) (filter (tuple (identifier eq?) (symbol (path [(identifier name)])) (string "app")))])))`,
+		},
+		{
+			input:    `(+ .bar[?.enabled].name)`,
+			expected: `(tuple (identifier +) (symbol (path [(identifier bar) (filter (symbol (path [(identifier enabled)]))) (identifier name)])))`,
+		},
+		{
+			input:   `(+ .bar[?"foo"])`,
+			invalid: true,
+		},
+		{
+			input:    `(+ ..foo)`,
+			expected: `(tuple (identifier +) (symbol (path [(descent (identifier foo))])))`,
+		},
+		{
+			input:    `(+ .bar..foo[0]..[1])`,
+			expected: `(tuple (identifier +) (symbol (path [(identifier bar) (descent (identifier foo)) (number (int64 0)) (descent (number (int64 1)))])))`,
+		},
+		{
+			input:    `(+ $var..foo)`,
+			expected: `(tuple (identifier +) (symbol (var var) (path [(descent (identifier foo))])))`,
+		},
+		{
+			input:   `(+ ...foo)`,
+			invalid: true,
+		},
+		{
+			input:    `(+ .foo?.bar[0]?)`,
+			expected: `(tuple (identifier +) (symbol (path [(optional (identifier foo)) (identifier bar) (optional (number (int64 0)))])))`,
+		},
+		{
+			input:    `(+ $var[*]?["a b"]?)`,
+			expected: `(tuple (identifier +) (symbol (var var) (path [(optional (wildcard)) (optional (string "a b"))])))`,
+		},
+		{
+			input:   `(+ .?)`,
+			invalid: true,
+		},
+		{
+			input:   `(+ .foo??)`,
+			invalid: true,
+		},
 
 		/////////////////////////////////////////////////////
 		// dot (document)
@@ -3,9 +3,21 @@
 
 package parser
 
+import "go.xrstf.de/rudi/pkg/lang/ast"
+
 func toAnySlice(v any) []any {
 	if v == nil {
 		return nil
 	}
 	return v.([]any)
 }
+
+// makeOptional wraps the step in an ast.OptionalStep if the optional marker
+// ("?") was matched.
+func makeOptional(step ast.Expression, marker any) ast.Expression {
+	if marker == nil {
+		return step
+	}
+
+	return ast.OptionalStep{Step: step}
+}
@@ -0,0 +1,493 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+// Package native makes arbitrary Go values (structs, typed maps and slices,
+// pointers) usable as Rudi values. Wrapped values can be read and written
+// using path expressions, are understood by all coalescers and can be cloned
+// using deepcopy.Clone, so that there is no need to round-trip them through
+// JSON before running a Rudi program.
+package native
+
+import (
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+
+	"go.xrstf.de/rudi/pkg/jsonpath"
+)
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Wrap returns values that Rudi can handle natively (nil, bools, numbers,
+// strings, []any and map[string]any) and values that already implement the
+// jsonpath reader interfaces as-is. Other scalars are converted to their Rudi
+// equivalent, for example uint8 to int64. Structs, maps with string keys,
+// slices and arrays are wrapped in an Object or Vector. Pointers are followed.
+// Updates using the jsonpath package never modify the wrapped value, but
+// return a wrapped copy instead, just like for native Rudi values.
+//
+// Like encoding/json, types implementing encoding.TextMarshaler are turned
+// into strings and byte slices into base64-encoded strings.
+func Wrap(value any) (any, error) {
+	switch value.(type) {
+	case nil, bool, int, int32, int64, float32, float64, string, []any, map[string]any:
+		return value, nil
+	case jsonpath.ObjectReader, jsonpath.VectorReader:
+		return value, nil
+	}
+
+	return wrapValue(reflect.ValueOf(value))
+}
+
+// Unwrap returns the Go value wrapped in an Object or Vector. All other
+// values are returned as-is.
+func Unwrap(value any) any {
+	switch asserted := value.(type) {
+	case Object:
+		return asserted.Unwrap()
+	case Vector:
+		return asserted.Unwrap()
+	default:
+		return value
+	}
+}
+
+func wrapValue(v reflect.Value) (any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	if v.CanInterface() {
+		switch asserted := v.Interface().(type) {
+		case []any:
+			if asserted == nil {
+				return nil, nil
+			}
+
+			return asserted, nil
+
+		case map[string]any:
+			if asserted == nil {
+				return nil, nil
+			}
+
+			return asserted, nil
+
+		case jsonpath.ObjectReader, jsonpath.VectorReader:
+			return asserted, nil
+		}
+
+		if text, ok, err := marshalText(v); ok {
+			return text, err
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		return wrapValue(v.Elem())
+
+	case reflect.Bool:
+		return v.Bool(), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int64(v.Uint()), nil
+
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+
+	case reflect.String:
+		return v.String(), nil
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+
+		return Vector{value: v}, nil
+
+	case reflect.Array:
+		return Vector{value: addressable(v)}, nil
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("cannot wrap %s: only maps with string keys are supported", v.Type())
+		}
+
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		return Object{value: v}, nil
+
+	case reflect.Struct:
+		return Object{value: addressable(v)}, nil
+
+	default:
+		return nil, fmt.Errorf("cannot wrap %s", v.Type())
+	}
+}
+
+// marshalText turns values implementing encoding.TextMarshaler into strings.
+// The second return value is false if the value does not implement it.
+func marshalText(v reflect.Value) (string, bool, error) {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return "", false, nil
+	}
+
+	if !v.Type().Implements(textMarshalerType) {
+		if !v.CanAddr() || !v.Addr().Type().Implements(textMarshalerType) {
+			return "", false, nil
+		}
+
+		v = v.Addr()
+	}
+
+	text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return "", true, err
+	}
+
+	return string(text), true, nil
+}
+
+// addressable returns v if it is addressable or an addressable copy
+// otherwise. Structs and arrays need to be addressable for their fields and
+// items to be settable.
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+
+	return copyValue(v)
+}
+
+// copyValue returns an addressable shallow copy of v.
+func copyValue(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+
+	return c
+}
+
+// convert turns a Rudi value into a Go value of the given type, for example
+// to set a struct field. Objects and vectors are converted into structs, maps,
+// slices or arrays as needed.
+func convert(value any, t reflect.Type) (reflect.Value, error) {
+	// keep wrapped values if possible, convert them like a map or slice otherwise
+	switch asserted := value.(type) {
+	case Object:
+		if v, ok := assignWrapped(asserted.value, t); ok {
+			return v, nil
+		}
+
+		converted, err := asserted.toMap()
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		value = converted
+
+	case Vector:
+		if v, ok := assignWrapped(asserted.value, t); ok {
+			return v, nil
+		}
+
+		converted, err := asserted.toSlice()
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		value = converted
+	}
+
+	if value == nil {
+		return reflect.Zero(t), nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(t) {
+		return v, nil
+	}
+
+	if s, ok := value.(string); ok && reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		ptr := reflect.New(t)
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return reflect.Value{}, err
+		}
+
+		return ptr.Elem(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem, err := convert(value, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+
+		return ptr, nil
+
+	case reflect.Bool:
+		if b, ok := value.(bool); ok {
+			return reflect.ValueOf(b).Convert(t), nil
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, ok := toInt64(value); ok {
+			result := reflect.New(t).Elem()
+			if result.OverflowInt(i) {
+				return reflect.Value{}, fmt.Errorf("%d overflows %s", i, t)
+			}
+
+			result.SetInt(i)
+
+			return result, nil
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if i, ok := toInt64(value); ok {
+			result := reflect.New(t).Elem()
+			if i < 0 || result.OverflowUint(uint64(i)) {
+				return reflect.Value{}, fmt.Errorf("%d overflows %s", i, t)
+			}
+
+			result.SetUint(uint64(i))
+
+			return result, nil
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if f, ok := toFloat64(value); ok {
+			result := reflect.New(t).Elem()
+			result.SetFloat(f)
+
+			return result, nil
+		}
+
+	case reflect.String:
+		if s, ok := value.(string); ok {
+			return reflect.ValueOf(s).Convert(t), nil
+		}
+
+	case reflect.Slice:
+		if s, ok := value.(string); ok && t.Elem().Kind() == reflect.Uint8 {
+			decoded, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("cannot decode %q: %w", s, err)
+			}
+
+			return reflect.ValueOf(decoded).Convert(t), nil
+		}
+
+		if items, ok := value.([]any); ok {
+			result := reflect.MakeSlice(t, len(items), len(items))
+			if err := convertItems(items, result); err != nil {
+				return reflect.Value{}, err
+			}
+
+			return result, nil
+		}
+
+	case reflect.Array:
+		if items, ok := value.([]any); ok {
+			if len(items) != t.Len() {
+				return reflect.Value{}, fmt.Errorf("cannot convert vector of length %d into %s", len(items), t)
+			}
+
+			result := reflect.New(t).Elem()
+			if err := convertItems(items, result); err != nil {
+				return reflect.Value{}, err
+			}
+
+			return result, nil
+		}
+
+	case reflect.Map:
+		if object, ok := value.(map[string]any); ok && t.Key().Kind() == reflect.String {
+			result := reflect.MakeMapWithSize(t, len(object))
+			for key, item := range object {
+				converted, err := convert(item, t.Elem())
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("key %q: %w", key, err)
+				}
+
+				result.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), converted)
+			}
+
+			return result, nil
+		}
+
+	case reflect.Struct:
+		if object, ok := value.(map[string]any); ok {
+			result := Object{value: reflect.New(t).Elem()}
+			for key, item := range object {
+				if _, err := result.SetObjectKey(key, item); err != nil {
+					return reflect.Value{}, err
+				}
+			}
+
+			return result.value, nil
+		}
+
+	case reflect.Interface:
+		v := reflect.ValueOf(Unwrap(value))
+		if v.Type().Implements(t) {
+			return v, nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("cannot convert %T into %s", value, t)
+}
+
+// assignWrapped returns the wrapped value (or a pointer to it) if it can be
+// assigned to the given type.
+func assignWrapped(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	if v.Type().AssignableTo(t) {
+		return v, true
+	}
+
+	if t.Kind() == reflect.Pointer && v.CanAddr() && v.Addr().Type().AssignableTo(t) {
+		return v.Addr(), true
+	}
+
+	return reflect.Value{}, false
+}
+
+func convertItems(items []any, dest reflect.Value) error {
+	for i, item := range items {
+		converted, err := convert(item, dest.Type().Elem())
+		if err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+
+		dest.Index(i).Set(converted)
+	}
+
+	return nil
+}
+
+func toInt64(value any) (int64, bool) {
+	switch asserted := value.(type) {
+	case int:
+		return int64(asserted), true
+	case int32:
+		return int64(asserted), true
+	case int64:
+		return asserted, true
+	case float32:
+		return int64(asserted), float32(int64(asserted)) == asserted
+	case float64:
+		return int64(asserted), float64(int64(asserted)) == asserted
+	default:
+		return 0, false
+	}
+}
+
+func toFloat64(value any) (float64, bool) {
+	switch asserted := value.(type) {
+	case int:
+		return float64(asserted), true
+	case int32:
+		return float64(asserted), true
+	case int64:
+		return float64(asserted), true
+	case float32:
+		return float64(asserted), true
+	case float64:
+		return asserted, true
+	default:
+		return 0, false
+	}
+}
+
+// deepCopyValue returns a deep copy of v. Unexported struct fields are copied
+// shallowly.
+func deepCopyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopyValue(v.Elem()))
+
+		return c
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopyValue(v.Elem()))
+
+		return c
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopyValue(v.Index(i)))
+		}
+
+		return c
+
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopyValue(v.Index(i)))
+		}
+
+		return c
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopyValue(iter.Value()))
+		}
+
+		return c
+
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+
+		for i := 0; i < v.NumField(); i++ {
+			if field := c.Field(i); field.CanSet() {
+				field.Set(deepCopyValue(v.Field(i)))
+			}
+		}
+
+		return c
+
+	default:
+		return v
+	}
+}
@@ -0,0 +1,347 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package native
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"go.xrstf.de/rudi/pkg/coalescing"
+	"go.xrstf.de/rudi/pkg/deepcopy"
+	"go.xrstf.de/rudi/pkg/jsonpath"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Metadata struct {
+	Name    string            `json:"name"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Created time.Time         `json:"created"`
+}
+
+type item struct {
+	ID    uint8   `json:"id"`
+	Score float32 `json:"score"`
+}
+
+type document struct {
+	Metadata `json:",inline"`
+
+	Kind     string   `json:"kind"`
+	Items    []item   `json:"items"`
+	Pair     [2]int   `json:"pair"`
+	Data     []byte   `json:"data"`
+	Extra    any      `json:"extra"`
+	Optional *string  `json:"optional"`
+	Ignored  string   `json:"-"`
+	Tags     []string `json:"tags,omitempty"`
+	internal string
+}
+
+func makeDocument() *document {
+	return &document{
+		Metadata: Metadata{
+			Name:    "test",
+			Labels:  map[string]string{"app": "rudi"},
+			Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		Kind:     "Example",
+		Items:    []item{{ID: 1, Score: 0.5}, {ID: 2, Score: 1}},
+		Pair:     [2]int{3, 4},
+		Data:     []byte("hello"),
+		Extra:    map[string]any{"foo": []any{"bar"}},
+		Ignored:  "ignored",
+		internal: "internal",
+	}
+}
+
+func TestWrap(t *testing.T) {
+	testcases := []struct {
+		value    any
+		expected any
+	}{
+		{value: nil, expected: nil},
+		{value: "foo", expected: "foo"},
+		{value: uint16(3), expected: int64(3)},
+		{value: float32(1.5), expected: float32(1.5)},
+		{value: []any{1}, expected: []any{1}},
+		{value: []string(nil), expected: nil},
+		{value: map[string]int(nil), expected: nil},
+		{value: (*document)(nil), expected: nil},
+		{value: []byte("foo"), expected: "Zm9v"},
+	}
+
+	for _, tc := range testcases {
+		wrapped, err := Wrap(tc.value)
+		if err != nil {
+			t.Errorf("Failed to wrap %#v: %v", tc.value, err)
+			continue
+		}
+
+		if !cmp.Equal(tc.expected, wrapped) {
+			t.Errorf("Expected %#v to be wrapped as %#v, got %#v", tc.value, tc.expected, wrapped)
+		}
+	}
+
+	if _, err := Wrap(map[int]string{}); err == nil {
+		t.Error("Should not have been able to wrap a map with non-string keys.")
+	}
+
+	if _, err := Wrap(make(chan int)); err == nil {
+		t.Error("Should not have been able to wrap a channel.")
+	}
+}
+
+func TestGet(t *testing.T) {
+	wrapped, err := Wrap(makeDocument())
+	if err != nil {
+		t.Fatalf("Failed to wrap document: %v", err)
+	}
+
+	testcases := []struct {
+		path     jsonpath.Path
+		expected any
+	}{
+		{path: jsonpath.Path{"kind"}, expected: "Example"},
+		{path: jsonpath.Path{"name"}, expected: "test"},
+		{path: jsonpath.Path{"labels", "app"}, expected: "rudi"},
+		{path: jsonpath.Path{"created"}, expected: "2024-01-02T03:04:05Z"},
+		{path: jsonpath.Path{"items", 1, "id"}, expected: int64(2)},
+		{path: jsonpath.Path{"items", -2, "score"}, expected: float64(0.5)},
+		{path: jsonpath.Path{"pair", 1}, expected: int64(4)},
+		{path: jsonpath.Path{"data"}, expected: "aGVsbG8="},
+		{path: jsonpath.Path{"extra", "foo", 0}, expected: "bar"},
+		{path: jsonpath.Path{"optional"}, expected: nil},
+		{path: jsonpath.Path{"tags"}, expected: nil},
+		{path: jsonpath.Path{"items", jsonpath.WildcardStep{}, "id"}, expected: []any{int64(1), int64(2)}},
+		{path: jsonpath.Path{"pair", jsonpath.SliceStep{}}, expected: []any{int64(3), int64(4)}},
+		{path: jsonpath.Path{jsonpath.DescentStep{Step: "app"}}, expected: []any{"rudi"}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.path.String(), func(t *testing.T) {
+			value, err := jsonpath.Get(wrapped, tc.path)
+			if err != nil {
+				t.Fatalf("Failed to get value: %v", err)
+			}
+
+			if !cmp.Equal(tc.expected, value) {
+				t.Fatalf("Expected %#v, got %#v", tc.expected, value)
+			}
+		})
+	}
+
+	for _, path := range []jsonpath.Path{{"Ignored"}, {"internal"}, {"unknown"}, {"items", 2}} {
+		if _, err := jsonpath.Get(wrapped, path); !errors.Is(err, jsonpath.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for %s, got %v", path, err)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	doc := makeDocument()
+
+	wrapped, err := Wrap(doc)
+	if err != nil {
+		t.Fatalf("Failed to wrap document: %v", err)
+	}
+
+	changes := []struct {
+		path  jsonpath.Path
+		value any
+	}{
+		{path: jsonpath.Path{"kind"}, value: "Changed"},
+		{path: jsonpath.Path{"labels", "tier"}, value: "backend"},
+		{path: jsonpath.Path{"created"}, value: "2000-01-01T00:00:00Z"},
+		{path: jsonpath.Path{"items", 0, "id"}, value: int64(7)},
+		{path: jsonpath.Path{"items", 1}, value: map[string]any{"id": int64(8), "score": int64(2)}},
+		{path: jsonpath.Path{"pair", 0}, value: float64(9)},
+		{path: jsonpath.Path{"optional"}, value: "set"},
+		{path: jsonpath.Path{"tags"}, value: []any{"a", "b"}},
+		{path: jsonpath.Path{"data"}, value: "Ynll"},
+	}
+
+	updated := wrapped
+	for _, change := range changes {
+		if updated, err = jsonpath.Set(updated, change.path, change.value); err != nil {
+			t.Fatalf("Failed to set %s: %v", change.path, err)
+		}
+	}
+
+	optional := "set"
+
+	expected := makeDocument()
+	expected.Kind = "Changed"
+	expected.Labels["tier"] = "backend"
+	expected.Created = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	expected.Items = []item{{ID: 7, Score: 0.5}, {ID: 8, Score: 2}}
+	expected.Pair[0] = 9
+	expected.Optional = &optional
+	expected.Tags = []string{"a", "b"}
+	expected.Data = []byte("bye")
+
+	result, ok := Unwrap(updated).(document)
+	if !ok {
+		t.Fatalf("Expected updated value to be a document, got %T", Unwrap(updated))
+	}
+
+	if !cmp.Equal(*expected, result, cmp.AllowUnexported(document{})) {
+		t.Fatalf("Document does not match expectation:\n%s", cmp.Diff(*expected, result, cmp.AllowUnexported(document{})))
+	}
+
+	// the original must not have been modified
+	if !cmp.Equal(makeDocument(), doc, cmp.AllowUnexported(document{})) {
+		t.Fatalf("Original document was modified:\n%s", cmp.Diff(makeDocument(), doc, cmp.AllowUnexported(document{})))
+	}
+
+	invalid := []struct {
+		path  jsonpath.Path
+		value any
+	}{
+		{path: jsonpath.Path{"kind"}, value: int64(1)},
+		{path: jsonpath.Path{"items", 0, "id"}, value: int64(256)},
+		{path: jsonpath.Path{"items", 0, "id"}, value: 1.5},
+		{path: jsonpath.Path{"pair"}, value: []any{1}},
+		{path: jsonpath.Path{"unknown"}, value: "foo"},
+		{path: jsonpath.Path{"items", 0}, value: map[string]any{"unknown": 1}},
+	}
+
+	for _, tc := range invalid {
+		if _, err := jsonpath.Set(wrapped, tc.path, tc.value); err == nil {
+			t.Errorf("Should not have been able to set %s to %#v.", tc.path, tc.value)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	doc := makeDocument()
+
+	wrapped, err := Wrap(doc)
+	if err != nil {
+		t.Fatalf("Failed to wrap document: %v", err)
+	}
+
+	updated := wrapped
+	for _, path := range []jsonpath.Path{{"labels", "app"}, {"items", 0}, {"kind"}} {
+		if updated, err = jsonpath.Delete(updated, path); err != nil {
+			t.Fatalf("Failed to delete %s: %v", path, err)
+		}
+	}
+
+	result := Unwrap(updated).(document)
+	if len(result.Labels) != 0 || len(result.Items) != 1 || result.Items[0].ID != 2 || result.Kind != "" {
+		t.Fatalf("Document was not updated as expected: %#v", result)
+	}
+
+	if !cmp.Equal(makeDocument(), doc, cmp.AllowUnexported(document{})) {
+		t.Fatalf("Original document was modified:\n%s", cmp.Diff(makeDocument(), doc, cmp.AllowUnexported(document{})))
+	}
+
+	if _, err := jsonpath.Delete(wrapped, jsonpath.Path{"pair", 0}); err == nil {
+		t.Fatal("Should not have been able to delete from an array.")
+	}
+}
+
+func TestDeepCopy(t *testing.T) {
+	doc := makeDocument()
+
+	wrapped, err := Wrap(doc)
+	if err != nil {
+		t.Fatalf("Failed to wrap document: %v", err)
+	}
+
+	cloned, err := deepcopy.Clone(wrapped)
+	if err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+
+	if _, err := jsonpath.Set(cloned, jsonpath.Path{"labels", "app"}, "changed"); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	if _, err := jsonpath.Set(cloned, jsonpath.Path{"items", 0, "id"}, int64(9)); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	if doc.Labels["app"] != "rudi" || doc.Items[0].ID != 1 {
+		t.Fatalf("Original document was modified: %#v", doc)
+	}
+
+	if clonedDoc := Unwrap(cloned).(document); clonedDoc.internal != "internal" {
+		t.Fatalf("Unexported fields were not copied: %#v", clonedDoc)
+	}
+}
+
+func TestCoalescing(t *testing.T) {
+	wrapped, err := Wrap(makeDocument())
+	if err != nil {
+		t.Fatalf("Failed to wrap document: %v", err)
+	}
+
+	items, err := jsonpath.Get(wrapped, jsonpath.Path{"items"})
+	if err != nil {
+		t.Fatalf("Failed to get items: %v", err)
+	}
+
+	for _, coalescer := range []coalescing.Coalescer{coalescing.NewPedantic(), coalescing.NewStrict(), coalescing.NewHumane()} {
+		object, err := coalescer.ToObject(wrapped)
+		if err != nil {
+			t.Fatalf("Failed to coalesce document into object: %v", err)
+		}
+
+		keys := []string{}
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		// "Ignored" and "internal" are skipped, "tags" is omitted because it is empty
+		expected := []string{"created", "data", "extra", "items", "kind", "labels", "name", "optional", "pair"}
+		if !cmp.Equal(expected, keys) {
+			t.Errorf("Expected keys %v, got %v", expected, keys)
+		}
+
+		vector, err := coalescer.ToVector(items)
+		if err != nil {
+			t.Fatalf("Failed to coalesce items into vector: %v", err)
+		}
+
+		if len(vector) != 2 {
+			t.Errorf("Expected 2 items, got %v", vector)
+		}
+	}
+
+	empty, err := Wrap([]string{})
+	if err != nil {
+		t.Fatalf("Failed to wrap empty slice: %v", err)
+	}
+
+	if isNull, err := coalescing.NewHumane().ToNull(empty); err != nil || !isNull {
+		t.Errorf("Expected empty slice to be coalesced to null, got %v (error %v).", isNull, err)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	wrapped, err := Wrap(makeDocument())
+	if err != nil {
+		t.Fatalf("Failed to wrap document: %v", err)
+	}
+
+	encoded, err := json.Marshal(map[string]any{"doc": wrapped})
+	if err != nil {
+		t.Fatalf("Failed to encode document: %v", err)
+	}
+
+	expected, err := json.Marshal(map[string]any{"doc": makeDocument()})
+	if err != nil {
+		t.Fatalf("Failed to encode document: %v", err)
+	}
+
+	if string(encoded) != string(expected) {
+		t.Fatalf("Expected %s, got %s", expected, encoded)
+	}
+}
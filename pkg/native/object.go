@@ -0,0 +1,326 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package native
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"go.xrstf.de/rudi/pkg/coalescing"
+	"go.xrstf.de/rudi/pkg/jsonpath"
+)
+
+// Object wraps a Go struct or a map with string keys. Struct fields are named
+// according to their json tags, with the same rules as in encoding/json.
+// Reading a field returns its wrapped value, writing a field converts the new
+// value into the field's type.
+type Object struct {
+	value reflect.Value
+}
+
+var (
+	_ jsonpath.ObjectKeyLister             = Object{}
+	_ jsonpath.ObjectWriter                = Object{}
+	_ jsonpath.ObjectKeyDeleter            = Object{}
+	_ jsonpath.ShallowCopier               = Object{}
+	_ coalescing.CustomNullCoalescer       = Object{}
+	_ coalescing.CustomBoolCoalescer       = Object{}
+	_ coalescing.CustomObjectCoalescer     = Object{}
+	_ json.Marshaler                       = Object{}
+	_ interface{ DeepCopy() (any, error) } = Object{}
+)
+
+// Unwrap returns the wrapped Go value.
+func (o Object) Unwrap() any {
+	return o.value.Interface()
+}
+
+func (o Object) isStruct() bool {
+	return o.value.Kind() == reflect.Struct
+}
+
+func (o Object) GetObjectKey(name string) (any, error) {
+	if o.isStruct() {
+		field, ok := lookupField(o.value.Type(), name)
+		if !ok {
+			return nil, fmt.Errorf("no such key %q: %w", name, jsonpath.ErrNotFound)
+		}
+
+		value, ok := fieldByIndex(o.value, field.index, false)
+		if !ok {
+			// a nil embedded pointer
+			return nil, nil
+		}
+
+		return wrapValue(value)
+	}
+
+	value := o.value.MapIndex(o.mapKey(name))
+	if !value.IsValid() {
+		return nil, fmt.Errorf("no such key %q: %w", name, jsonpath.ErrNotFound)
+	}
+
+	return wrapValue(value)
+}
+
+func (o Object) SetObjectKey(name string, value any) (any, error) {
+	if o.isStruct() {
+		field, ok := lookupField(o.value.Type(), name)
+		if !ok {
+			return nil, fmt.Errorf("%s has no field %q", o.value.Type(), name)
+		}
+
+		dest, _ := fieldByIndex(o.value, field.index, true)
+		if !dest.CanSet() {
+			return nil, fmt.Errorf("cannot set field %q of %s", name, o.value.Type())
+		}
+
+		converted, err := convert(value, dest.Type())
+		if err != nil {
+			return nil, fmt.Errorf("cannot set field %q: %w", name, err)
+		}
+
+		dest.Set(converted)
+
+		return o, nil
+	}
+
+	converted, err := convert(value, o.value.Type().Elem())
+	if err != nil {
+		return nil, fmt.Errorf("cannot set key %q: %w", name, err)
+	}
+
+	o.value.SetMapIndex(o.mapKey(name), converted)
+
+	return o, nil
+}
+
+// DeleteObjectKey removes a key from a map or resets a struct field to its
+// zero value.
+func (o Object) DeleteObjectKey(name string) (any, error) {
+	if o.isStruct() {
+		field, ok := lookupField(o.value.Type(), name)
+		if !ok {
+			return o, nil
+		}
+
+		dest, ok := fieldByIndex(o.value, field.index, false)
+		if ok && dest.CanSet() {
+			dest.Set(reflect.Zero(dest.Type()))
+		}
+
+		return o, nil
+	}
+
+	o.value.SetMapIndex(o.mapKey(name), reflect.Value{})
+
+	return o, nil
+}
+
+// ObjectKeys returns all map keys or struct field names. Like in encoding/json,
+// fields with the omitempty option are skipped if they hold their zero value.
+func (o Object) ObjectKeys() []string {
+	if !o.isStruct() {
+		keys := make([]string, 0, o.value.Len())
+		for _, key := range o.value.MapKeys() {
+			keys = append(keys, key.String())
+		}
+
+		return keys
+	}
+
+	keys := []string{}
+	for _, field := range structFields(o.value.Type()) {
+		value, ok := fieldByIndex(o.value, field.index, false)
+		if field.omitEmpty && (!ok || value.IsZero()) {
+			continue
+		}
+
+		keys = append(keys, field.name)
+	}
+
+	return keys
+}
+
+func (o Object) mapKey(name string) reflect.Value {
+	return reflect.ValueOf(name).Convert(o.value.Type().Key())
+}
+
+// toMap returns a shallow map of all wrapped values.
+func (o Object) toMap() (map[string]any, error) {
+	result := map[string]any{}
+	for _, key := range o.ObjectKeys() {
+		value, err := o.GetObjectKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+func (o Object) CoalesceToNull(c coalescing.Coalescer) (bool, error) {
+	object, err := o.toMap()
+	if err != nil {
+		return false, err
+	}
+
+	return c.ToNull(object)
+}
+
+func (o Object) CoalesceToBool(c coalescing.Coalescer) (bool, error) {
+	object, err := o.toMap()
+	if err != nil {
+		return false, err
+	}
+
+	return c.ToBool(object)
+}
+
+func (o Object) CoalesceToObject(_ coalescing.Coalescer) (map[string]any, error) {
+	return o.toMap()
+}
+
+func (o Object) ShallowCopy() (any, error) {
+	if o.isStruct() {
+		return Object{value: copyValue(o.value)}, nil
+	}
+
+	result := reflect.MakeMapWithSize(o.value.Type(), o.value.Len())
+	iter := o.value.MapRange()
+	for iter.Next() {
+		result.SetMapIndex(iter.Key(), iter.Value())
+	}
+
+	return Object{value: result}, nil
+}
+
+func (o Object) DeepCopy() (any, error) {
+	return Object{value: addressable(deepCopyValue(o.value))}, nil
+}
+
+func (o Object) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.value.Interface())
+}
+
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+var structFieldCache sync.Map
+
+// structFields returns the fields of a struct type like encoding/json sees
+// them, including fields promoted from embedded structs. If multiple fields
+// share the same name, the least nested one wins.
+func structFields(t reflect.Type) []structField {
+	if cached, ok := structFieldCache.Load(t); ok {
+		return cached.([]structField)
+	}
+
+	candidates := []structField{}
+
+	var collect func(t reflect.Type, index []int)
+	collect = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+
+			tag := field.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+
+			name, options, _ := strings.Cut(tag, ",")
+			fieldIndex := append(append([]int{}, index...), i)
+
+			if field.Anonymous && name == "" {
+				fieldType := field.Type
+				if fieldType.Kind() == reflect.Pointer {
+					fieldType = fieldType.Elem()
+				}
+
+				if fieldType.Kind() == reflect.Struct {
+					collect(fieldType, fieldIndex)
+					continue
+				}
+			}
+
+			if !field.IsExported() {
+				continue
+			}
+
+			if name == "" {
+				name = field.Name
+			}
+
+			candidates = append(candidates, structField{
+				name:      name,
+				index:     fieldIndex,
+				omitEmpty: strings.Contains(","+options+",", ",omitempty,"),
+			})
+		}
+	}
+
+	collect(t, nil)
+
+	depths := map[string]int{}
+	for _, field := range candidates {
+		if depth, ok := depths[field.name]; !ok || len(field.index) < depth {
+			depths[field.name] = len(field.index)
+		}
+	}
+
+	fields := []structField{}
+	for _, field := range candidates {
+		if depths[field.name] == len(field.index) {
+			fields = append(fields, field)
+
+			// on ties, the first field wins
+			depths[field.name] = -1
+		}
+	}
+
+	structFieldCache.Store(t, fields)
+
+	return fields
+}
+
+func lookupField(t reflect.Type, name string) (structField, bool) {
+	for _, field := range structFields(t) {
+		if field.name == name {
+			return field, true
+		}
+	}
+
+	return structField{}, false
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but handles nil pointers to
+// embedded structs by either allocating them (if alloc is true) or returning
+// false.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v, true
+}
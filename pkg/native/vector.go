@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package native
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"go.xrstf.de/rudi/pkg/coalescing"
+	"go.xrstf.de/rudi/pkg/jsonpath"
+)
+
+// Vector wraps a Go slice or array. Reading an item returns its wrapped value,
+// writing an item converts the new value into the item type. Like for native
+// vectors, negative indices count from the end.
+type Vector struct {
+	value reflect.Value
+}
+
+var (
+	_ jsonpath.VectorSizer                 = Vector{}
+	_ jsonpath.VectorWriter                = Vector{}
+	_ jsonpath.VectorItemDeleter           = Vector{}
+	_ jsonpath.ShallowCopier               = Vector{}
+	_ coalescing.CustomNullCoalescer       = Vector{}
+	_ coalescing.CustomBoolCoalescer       = Vector{}
+	_ coalescing.CustomVectorCoalescer     = Vector{}
+	_ json.Marshaler                       = Vector{}
+	_ interface{ DeepCopy() (any, error) } = Vector{}
+)
+
+// Unwrap returns the wrapped Go value.
+func (v Vector) Unwrap() any {
+	return v.value.Interface()
+}
+
+func (v Vector) index(index int) (int, error) {
+	length := v.value.Len()
+	if index < 0 {
+		index += length
+	}
+
+	if index < 0 || index >= length {
+		return 0, fmt.Errorf("index %d out of bounds: %w", index, jsonpath.ErrNotFound)
+	}
+
+	return index, nil
+}
+
+func (v Vector) GetVectorItem(index int) (any, error) {
+	index, err := v.index(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapValue(v.value.Index(index))
+}
+
+func (v Vector) SetVectorItem(index int, value any) (any, error) {
+	index, err := v.index(index)
+	if err != nil {
+		return nil, err
+	}
+
+	converted, err := convert(value, v.value.Type().Elem())
+	if err != nil {
+		return nil, fmt.Errorf("cannot set item %d: %w", index, err)
+	}
+
+	v.value.Index(index).Set(converted)
+
+	return v, nil
+}
+
+// DeleteVectorItem removes an item from a slice. Since arrays have a fixed
+// length, items cannot be removed from them.
+func (v Vector) DeleteVectorItem(index int) (any, error) {
+	if v.value.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("cannot remove items from %s", v.value.Type())
+	}
+
+	index, err := v.index(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return Vector{value: reflect.AppendSlice(v.value.Slice(0, index), v.value.Slice(index+1, v.value.Len()))}, nil
+}
+
+func (v Vector) VectorLength() int {
+	return v.value.Len()
+}
+
+// toSlice returns a shallow vector of all wrapped items.
+func (v Vector) toSlice() ([]any, error) {
+	result := make([]any, v.value.Len())
+	for i := range result {
+		item, err := wrapValue(v.value.Index(i))
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = item
+	}
+
+	return result, nil
+}
+
+func (v Vector) CoalesceToNull(c coalescing.Coalescer) (bool, error) {
+	vector, err := v.toSlice()
+	if err != nil {
+		return false, err
+	}
+
+	return c.ToNull(vector)
+}
+
+func (v Vector) CoalesceToBool(c coalescing.Coalescer) (bool, error) {
+	vector, err := v.toSlice()
+	if err != nil {
+		return false, err
+	}
+
+	return c.ToBool(vector)
+}
+
+func (v Vector) CoalesceToVector(_ coalescing.Coalescer) ([]any, error) {
+	return v.toSlice()
+}
+
+func (v Vector) ShallowCopy() (any, error) {
+	if v.value.Kind() == reflect.Array {
+		return Vector{value: copyValue(v.value)}, nil
+	}
+
+	result := reflect.MakeSlice(v.value.Type(), v.value.Len(), v.value.Len())
+	reflect.Copy(result, v.value)
+
+	return Vector{value: result}, nil
+}
+
+func (v Vector) DeepCopy() (any, error) {
+	return Vector{value: addressable(deepCopyValue(v.value))}, nil
+}
+
+func (v Vector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.value.Interface())
+}
@@ -0,0 +1,481 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+// Package optimizer implements an optimization pass over Rudi programs. It
+// evaluates calls to pure functions with only literal arguments ahead of time
+// (constant folding), removes unreachable branches from `if` and `case` and
+// flattens nested `do` calls.
+package optimizer
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"sort"
+
+	"go.xrstf.de/rudi/pkg/builtin/core"
+	"go.xrstf.de/rudi/pkg/coalescing"
+	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/runtime/interpreter"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+)
+
+// Since the optimizer cannot know which coalescer a program will be run with
+// (programs can also switch coalescers using functions like `humanely`),
+// expressions are only folded if they evaluate to the same result with all
+// built-in coalescers.
+var coalescers = []coalescing.Coalescer{
+	coalescing.NewPedantic(),
+	coalescing.NewStrict(),
+	coalescing.NewHumane(),
+}
+
+type optimizer struct {
+	funcs types.Functions
+}
+
+// Optimize returns an optimized copy of the given program; the program itself
+// is not modified. funcs must be the same functions the program is later run
+// with, as only calls to functions that are marked as pure (see
+// types.PureFunction) are folded, while all other function calls are left
+// intact. Errors are never folded, so that they still happen when the program
+// is run.
+func Optimize(program *ast.Program, funcs types.Functions) *ast.Program {
+	o := &optimizer{
+		funcs: funcs,
+	}
+
+	result := &ast.Program{
+		Statements: []ast.Statement{},
+	}
+
+	for _, stmt := range program.Statements {
+		optimized := o.optimize(stmt.Expression)
+
+		// Programs and `do` both evaluate their expressions in the same scope,
+		// so a top-level `do` can be turned into separate statements.
+		if args, ok := o.doArguments(optimized); ok {
+			for _, arg := range args {
+				result.Statements = append(result.Statements, ast.Statement{Expression: arg})
+			}
+
+			continue
+		}
+
+		result.Statements = append(result.Statements, ast.Statement{Expression: optimized})
+	}
+
+	return result
+}
+
+func (o *optimizer) optimize(expr ast.Expression) ast.Expression {
+	switch asserted := expr.(type) {
+	case ast.Symbol:
+		return ast.Symbol{
+			Variable:       asserted.Variable,
+			PathExpression: o.optimizePath(asserted.PathExpression),
+		}
+
+	case ast.VectorNode:
+		return ast.VectorNode{
+			Expressions:    o.optimizeAll(asserted.Expressions),
+			PathExpression: o.optimizePath(asserted.PathExpression),
+		}
+
+	case ast.ObjectNode:
+		data := make([]ast.KeyValuePair, len(asserted.Data))
+		for i, pair := range asserted.Data {
+			data[i] = ast.KeyValuePair{
+				Key:   o.optimize(pair.Key),
+				Value: o.optimize(pair.Value),
+			}
+		}
+
+		return ast.ObjectNode{
+			Data:           data,
+			PathExpression: o.optimizePath(asserted.PathExpression),
+		}
+
+	case ast.Tuple:
+		return o.optimizeTuple(asserted)
+
+	default:
+		return expr
+	}
+}
+
+func (o *optimizer) optimizeAll(exprs []ast.Expression) []ast.Expression {
+	result := make([]ast.Expression, len(exprs))
+	for i, expr := range exprs {
+		result[i] = o.optimize(expr)
+	}
+
+	return result
+}
+
+func (o *optimizer) optimizePath(path *ast.PathExpression) *ast.PathExpression {
+	if path == nil {
+		return nil
+	}
+
+	return &ast.PathExpression{
+		Steps: o.optimizeAll(path.Steps),
+	}
+}
+
+func (o *optimizer) optimizeTuple(tup ast.Tuple) ast.Expression {
+	optimized := ast.Tuple{
+		Expressions:    o.optimizeAll(tup.Expressions),
+		PathExpression: o.optimizePath(tup.PathExpression),
+	}
+
+	if len(optimized.Expressions) == 0 {
+		return optimized
+	}
+
+	identifier, ok := optimized.Expressions[0].(ast.Identifier)
+	if !ok || identifier.Bang {
+		return optimized
+	}
+
+	function, ok := o.funcs.Get(identifier.FullName())
+	if !ok {
+		return optimized
+	}
+
+	if folded, ok := o.fold(optimized, function); ok {
+		return folded
+	}
+
+	// Removing branches would also remove the path expression, so this is only
+	// done for tuples without one.
+	if optimized.PathExpression != nil {
+		return optimized
+	}
+
+	args := optimized.Expressions[1:]
+
+	switch function {
+	case core.Functions["if"]:
+		return o.optimizeIf(optimized, args)
+	case core.Functions["case"]:
+		return o.optimizeCase(optimized, args)
+	case core.Functions["do"]:
+		return o.optimizeDo(optimized, args)
+	}
+
+	return optimized
+}
+
+// fold evaluates the tuple if it is a call to a pure function and all of its
+// arguments are literals.
+func (o *optimizer) fold(tup ast.Tuple, function types.Function) (ast.Expression, bool) {
+	pure, ok := function.(types.PureFunction)
+	if !ok || !pure.IsPure() {
+		return nil, false
+	}
+
+	for _, arg := range tup.Expressions[1:] {
+		if !isLiteral(arg) {
+			return nil, false
+		}
+	}
+
+	if tup.PathExpression != nil {
+		for _, step := range tup.PathExpression.Steps {
+			if _, ok := step.(ast.Identifier); !ok && !isLiteral(step) {
+				return nil, false
+			}
+		}
+	}
+
+	value, ok := o.evaluate(tup)
+	if !ok {
+		return nil, false
+	}
+
+	return toLiteral(value)
+}
+
+// evaluate evaluates the expression with all built-in coalescers and only
+// succeeds if all of them lead to the same result.
+func (o *optimizer) evaluate(expr ast.Expression) (any, bool) {
+	var result any
+
+	for i, coalescer := range coalescers {
+		value, err := o.evaluateWith(expr, coalescer)
+		if err != nil {
+			return nil, false
+		}
+
+		if i > 0 && !reflect.DeepEqual(result, value) {
+			return nil, false
+		}
+
+		result = value
+	}
+
+	return result, true
+}
+
+func (o *optimizer) evaluateWith(expr ast.Expression, coalescer coalescing.Coalescer) (any, error) {
+	doc, err := types.NewDocument(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	runtime := interpreter.New()
+
+	ctx, err := types.NewContext(runtime, context.Background(), doc, nil, o.funcs, coalescer)
+	if err != nil {
+		return nil, err
+	}
+
+	return runtime.EvalExpression(ctx, expr)
+}
+
+// condition determines the boolean value of a literal condition.
+func (o *optimizer) condition(expr ast.Expression) (bool, bool) {
+	if !isLiteral(expr) {
+		return false, false
+	}
+
+	value, ok := o.evaluate(expr)
+	if !ok {
+		return false, false
+	}
+
+	var result bool
+
+	for i, coalescer := range coalescers {
+		b, err := coalescer.ToBool(value)
+		if err != nil {
+			return false, false
+		}
+
+		if i > 0 && b != result {
+			return false, false
+		}
+
+		result = b
+	}
+
+	return result, true
+}
+
+// optimizeIf handles (if COND YES) and (if COND YES NO).
+func (o *optimizer) optimizeIf(tup ast.Tuple, args []ast.Expression) ast.Expression {
+	if len(args) != 2 && len(args) != 3 {
+		return tup
+	}
+
+	test, ok := o.condition(args[0])
+	if !ok {
+		return tup
+	}
+
+	if test {
+		return args[1]
+	}
+
+	if len(args) == 3 {
+		return args[2]
+	}
+
+	return ast.Null{}
+}
+
+// optimizeCase removes all test/value pairs whose test is known to be false
+// and stops at the first pair whose test is known to be true.
+func (o *optimizer) optimizeCase(tup ast.Tuple, args []ast.Expression) ast.Expression {
+	if len(args) == 0 || len(args)%2 != 0 {
+		return tup
+	}
+
+	remaining := []ast.Expression{}
+
+	for i := 0; i < len(args); i += 2 {
+		testExpr := args[i]
+		valueExpr := args[i+1]
+
+		test, ok := o.condition(testExpr)
+		if !ok {
+			remaining = append(remaining, testExpr, valueExpr)
+			continue
+		}
+
+		if !test {
+			continue
+		}
+
+		// all previous tests were false, so this is the result
+		if len(remaining) == 0 {
+			return valueExpr
+		}
+
+		// everything after this pair is unreachable
+		remaining = append(remaining, testExpr, valueExpr)
+		break
+	}
+
+	// none of the tests can ever be true
+	if len(remaining) == 0 {
+		return ast.Null{}
+	}
+
+	return ast.Tuple{
+		Expressions: append([]ast.Expression{tup.Expressions[0]}, remaining...),
+	}
+}
+
+// optimizeDo inlines the arguments of nested `do` calls, as they are all
+// evaluated in the same scope anyway.
+func (o *optimizer) optimizeDo(tup ast.Tuple, args []ast.Expression) ast.Expression {
+	flattened := []ast.Expression{}
+
+	for _, arg := range args {
+		if nested, ok := o.doArguments(arg); ok {
+			flattened = append(flattened, nested...)
+		} else {
+			flattened = append(flattened, arg)
+		}
+	}
+
+	if len(flattened) == 1 {
+		return flattened[0]
+	}
+
+	return ast.Tuple{
+		Expressions: append([]ast.Expression{tup.Expressions[0]}, flattened...),
+	}
+}
+
+// doArguments returns the arguments if the expression is a `do` call
+// without a path expression.
+func (o *optimizer) doArguments(expr ast.Expression) ([]ast.Expression, bool) {
+	tup, ok := expr.(ast.Tuple)
+	if !ok || tup.PathExpression != nil || len(tup.Expressions) < 2 {
+		return nil, false
+	}
+
+	identifier, ok := tup.Expressions[0].(ast.Identifier)
+	if !ok || identifier.Bang {
+		return nil, false
+	}
+
+	function, ok := o.funcs.Get(identifier.FullName())
+	if !ok || function != core.Functions["do"] {
+		return nil, false
+	}
+
+	return tup.Expressions[1:], true
+}
+
+// isLiteral returns true if the expression does not depend on any context,
+// like variables, the document or function calls.
+func isLiteral(expr ast.Expression) bool {
+	switch asserted := expr.(type) {
+	case ast.Null, ast.Bool, ast.String, ast.Number:
+		return true
+
+	case ast.VectorNode:
+		if asserted.PathExpression != nil {
+			return false
+		}
+
+		for _, item := range asserted.Expressions {
+			if !isLiteral(item) {
+				return false
+			}
+		}
+
+		return true
+
+	case ast.ObjectNode:
+		if asserted.PathExpression != nil {
+			return false
+		}
+
+		for _, pair := range asserted.Data {
+			switch key := pair.Key.(type) {
+			case ast.Identifier:
+				if key.Bang {
+					return false
+				}
+			case ast.String:
+				// valid key
+			default:
+				return false
+			}
+
+			if !isLiteral(pair.Value) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// toLiteral turns an evaluated value back into an expression. Not all values
+// can be represented as literals in Rudi code, in which case false is returned.
+func toLiteral(value any) (ast.Expression, bool) {
+	switch asserted := value.(type) {
+	case nil:
+		return ast.Null{}, true
+	case bool:
+		return ast.Bool(asserted), true
+	case string:
+		return ast.String(asserted), true
+	case int:
+		return ast.Number{Value: int64(asserted)}, true
+	case int32:
+		return ast.Number{Value: int64(asserted)}, true
+	case int64:
+		return ast.Number{Value: asserted}, true
+	case float32:
+		return toLiteral(float64(asserted))
+	case float64:
+		if math.IsInf(asserted, 0) || math.IsNaN(asserted) {
+			return nil, false
+		}
+
+		return ast.Number{Value: asserted}, true
+
+	case []any:
+		exprs := make([]ast.Expression, len(asserted))
+		for i, item := range asserted {
+			expr, ok := toLiteral(item)
+			if !ok {
+				return nil, false
+			}
+
+			exprs[i] = expr
+		}
+
+		return ast.VectorNode{Expressions: exprs}, true
+
+	case map[string]any:
+		keys := make([]string, 0, len(asserted))
+		for key := range asserted {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		data := make([]ast.KeyValuePair, len(keys))
+		for i, key := range keys {
+			expr, ok := toLiteral(asserted[key])
+			if !ok {
+				return nil, false
+			}
+
+			data[i] = ast.KeyValuePair{Key: ast.String(key), Value: expr}
+		}
+
+		return ast.ObjectNode{Data: data}, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,221 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package optimizer
+
+import (
+	"strings"
+	"testing"
+
+	"go.xrstf.de/rudi/pkg/builtin"
+	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/lang/parser"
+	"go.xrstf.de/rudi/pkg/printer"
+	"go.xrstf.de/rudi/pkg/runtime/functions"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+)
+
+func TestOptimize(t *testing.T) {
+	counter := 0
+
+	funcs := builtin.SafeFunctions.DeepCopy().Add(types.Functions{
+		// not marked as pure
+		"count": functions.NewBuilder(func(n int64) (any, error) {
+			counter++
+			return n, nil
+		}).Build(),
+		// returns a value that cannot be a literal
+		"chan": functions.NewBuilder(func() (any, error) {
+			return make(chan int), nil
+		}).Pure().Build(),
+	})
+
+	testcases := []struct {
+		input    string
+		expected string
+	}{
+		// constant folding
+		{
+			input:    `(+ 1 2)`,
+			expected: `3`,
+		},
+		{
+			input:    `(+ 1 (* 2 3) (- 10 4))`,
+			expected: `13`,
+		},
+		{
+			input:    `(/ 3 2)`,
+			expected: `1.5`,
+		},
+		{
+			input:    `(+ 1.5 1.5)`,
+			expected: `3.0`,
+		},
+		{
+			input:    `(concat "" "a" "b")`,
+			expected: `"ab"`,
+		},
+		{
+			input:    `(split "," "a,b")`,
+			expected: `["a" "b"]`,
+		},
+		{
+			input:    `(len {foo "bar"})`,
+			expected: `1`,
+		},
+		{
+			input:    `(from-json "{\"b\": 1, \"a\": [true]}")`,
+			expected: `{a [true] b 1.0}`,
+		},
+		{
+			input:    `(to-upper "foo").bar`,
+			expected: `(to-upper "foo").bar`,
+		},
+		{
+			input:    `(concat "" "a" "b")[0]`,
+			expected: `(concat "" "a" "b")[0]`,
+		},
+		{
+			input:    `[(+ 1 2) .foo[(+ 0 1)] {a (to-upper "b")}]`,
+			expected: `[3 .foo[1] {a "B"}]`,
+		},
+		{
+			input:    `(set! .foo (+ 1 2))`,
+			expected: `(set! .foo 3)`,
+		},
+		{
+			input:    `(add! .foo (+ 1 2))`,
+			expected: `(add! .foo 3)`,
+		},
+		// non-literal arguments
+		{
+			input:    `(+ 1 .foo)`,
+			expected: `(+ 1 .foo)`,
+		},
+		{
+			input:    `(+ 1 $foo)`,
+			expected: `(+ 1 $foo)`,
+		},
+		// impure functions
+		{
+			input:    `(count (+ 1 2))`,
+			expected: `(count 3)`,
+		},
+		{
+			input:    `(chan)`,
+			expected: `(chan)`,
+		},
+		// errors are not folded
+		{
+			input:    `(+ 1 "2")`,
+			expected: `(+ 1 "2")`,
+		},
+		{
+			input:    `(/ 1 0)`,
+			expected: `(/ 1 0)`,
+		},
+		// results that depend on the coalescer are not folded
+		{
+			input:    `(eq? 1 "1")`,
+			expected: `(eq? 1 "1")`,
+		},
+		{
+			input:    `(humanely (+ 1 "2"))`,
+			expected: `(humanely (+ 1 "2"))`,
+		},
+		// if
+		{
+			input:    `(if true .yes .no)`,
+			expected: `.yes`,
+		},
+		{
+			input:    `(if (eq? 1 2) .yes .no)`,
+			expected: `.no`,
+		},
+		{
+			input:    `(if false .yes)`,
+			expected: `null`,
+		},
+		{
+			input:    `(if .test .yes .no)`,
+			expected: `(if .test .yes .no)`,
+		},
+		{
+			input:    `(if "" .yes .no)`,
+			expected: `(if "" .yes .no)`,
+		},
+		{
+			input:    `(if true .yes .no).foo`,
+			expected: `(if true .yes .no).foo`,
+		},
+		// case
+		{
+			input:    `(case false 1 .test 2 true 3 .other 4)`,
+			expected: `(case .test 2 true 3)`,
+		},
+		{
+			input:    `(case false 1 (eq? 1 1) .value .test 3)`,
+			expected: `.value`,
+		},
+		{
+			input:    `(case false 1 false 2)`,
+			expected: `null`,
+		},
+		{
+			input:    `(case false 1 .test)`,
+			expected: `(case false 1 .test)`,
+		},
+		// do
+		{
+			input:    `(do (set! $a 1) (do (set! $b 2) (do $c)) $d)`,
+			expected: `(set! $a 1) (set! $b 2) $c $d`,
+		},
+		{
+			input:    `(if (do .a (do .b .c)) .d)`,
+			expected: `(if (do .a .b .c) .d)`,
+		},
+		{
+			input:    `(if (do .a) .b)`,
+			expected: `(if .a .b)`,
+		},
+		{
+			input:    `(if .x (do .a (do .b).c))`,
+			expected: `(if .x (do .a (do .b).c))`,
+		},
+		{
+			input:    `(do! .a (do .b))`,
+			expected: `(do! .a .b)`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := parser.Parse("test", []byte(tc.input))
+			if err != nil {
+				t.Fatalf("Failed to parse %s: %v", tc.input, err)
+			}
+
+			program := got.(ast.Program)
+			original := program.String()
+
+			optimized := Optimize(&program, funcs)
+
+			if program.String() != original {
+				t.Fatalf("Optimize() modified the original program.")
+			}
+
+			var buf strings.Builder
+			if err := printer.NewRudiPrinter(&buf).Program(optimized); err != nil {
+				t.Fatalf("Failed to print optimized program: %v", err)
+			}
+
+			if output := buf.String(); output != tc.expected {
+				t.Fatalf("Expected\n\n%s\n\ngot\n\n%s", tc.expected, output)
+			}
+		})
+	}
+
+	if counter > 0 {
+		t.Fatalf("Impure function was evaluated %d times.", counter)
+	}
+}
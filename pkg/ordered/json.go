@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package ordered
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DecodeJSON reads a single JSON value from the decoder. Objects are decoded
+// into Objects, all other values like encoding/json would decode them into
+// an empty interface.
+func DecodeJSON(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeValue(decoder, token)
+}
+
+func decodeValue(decoder *json.Decoder, token json.Token) (any, error) {
+	switch token {
+	case json.Delim('{'):
+		return decodeObject(decoder)
+	case json.Delim('['):
+		return decodeVector(decoder)
+	default:
+		return token, nil
+	}
+}
+
+func decodeObject(decoder *json.Decoder) (any, error) {
+	result := NewObject()
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		if token == json.Delim('}') {
+			return result, nil
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected object key, got %v", token)
+		}
+
+		token, err = decoder.Token()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		value, err := decodeValue(decoder, token)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Set(key, value)
+	}
+}
+
+func decodeVector(decoder *json.Decoder) (any, error) {
+	result := []any{}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		if token == json.Delim(']') {
+			return result, nil
+		}
+
+		value, err := decodeValue(decoder, token)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, value)
+	}
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
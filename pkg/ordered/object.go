@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+// Package ordered provides an object that remembers the order of its keys.
+// It can be used in place of map[string]any wherever Rudi expects an object,
+// for example as (part of) a document, so that configuration files keep their
+// key order when they are processed by Rudi.
+package ordered
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"go.xrstf.de/rudi/pkg/coalescing"
+	"go.xrstf.de/rudi/pkg/deepcopy"
+	"go.xrstf.de/rudi/pkg/jsonpath"
+)
+
+// Object is a JSON object that keeps its keys in insertion order. Like all
+// values in Rudi, objects must not be modified once they have been handed to
+// Rudi; Set and Delete are only meant to be used while building an object.
+type Object struct {
+	keys   []string
+	values map[string]any
+}
+
+var (
+	_ jsonpath.ObjectKeyLister         = Object{}
+	_ jsonpath.ObjectWriter            = Object{}
+	_ jsonpath.ObjectKeyDeleter        = Object{}
+	_ jsonpath.ShallowCopier           = Object{}
+	_ deepcopy.Copier                  = Object{}
+	_ coalescing.CustomNullCoalescer   = Object{}
+	_ coalescing.CustomBoolCoalescer   = Object{}
+	_ coalescing.CustomObjectCoalescer = Object{}
+	_ json.Marshaler                   = Object{}
+)
+
+// NewObject returns an empty object.
+func NewObject() Object {
+	return Object{
+		keys:   []string{},
+		values: map[string]any{},
+	}
+}
+
+// Keys returns all keys in order. The returned slice must not be modified.
+func (o Object) Keys() []string {
+	return o.keys
+}
+
+// Len returns the number of keys.
+func (o Object) Len() int {
+	return len(o.keys)
+}
+
+// Get returns the value for the given key.
+func (o Object) Get(key string) (any, bool) {
+	value, ok := o.values[key]
+	return value, ok
+}
+
+// Set replaces the value of an existing key or appends a new key.
+func (o *Object) Set(key string, value any) {
+	if o.values == nil {
+		o.values = map[string]any{}
+	}
+
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+
+	o.values[key] = value
+}
+
+// Delete removes a key, if it exists.
+func (o *Object) Delete(key string) {
+	if _, exists := o.values[key]; !exists {
+		return
+	}
+
+	delete(o.values, key)
+
+	keys := make([]string, 0, len(o.keys)-1)
+	for _, k := range o.keys {
+		if k != key {
+			keys = append(keys, k)
+		}
+	}
+
+	o.keys = keys
+}
+
+// ToMap returns the object as a regular, unordered map.
+func (o Object) ToMap() map[string]any {
+	result := make(map[string]any, len(o.values))
+	for key, value := range o.values {
+		result[key] = value
+	}
+
+	return result
+}
+
+func (o Object) GetObjectKey(name string) (any, error) {
+	value, ok := o.values[name]
+	if !ok {
+		return nil, fmt.Errorf("no such key %q: %w", name, jsonpath.ErrNotFound)
+	}
+
+	return value, nil
+}
+
+func (o Object) SetObjectKey(name string, value any) (any, error) {
+	o.Set(name, value)
+	return o, nil
+}
+
+func (o Object) DeleteObjectKey(name string) (any, error) {
+	o.Delete(name)
+	return o, nil
+}
+
+func (o Object) ObjectKeys() []string {
+	return o.keys
+}
+
+func (o Object) CoalesceToNull(c coalescing.Coalescer) (bool, error) {
+	return c.ToNull(o.ToMap())
+}
+
+func (o Object) CoalesceToBool(c coalescing.Coalescer) (bool, error) {
+	return c.ToBool(o.ToMap())
+}
+
+func (o Object) CoalesceToObject(_ coalescing.Coalescer) (map[string]any, error) {
+	return o.ToMap(), nil
+}
+
+func (o Object) ShallowCopy() (any, error) {
+	return Object{
+		keys:   append([]string{}, o.keys...),
+		values: o.ToMap(),
+	}, nil
+}
+
+func (o Object) DeepCopy() (any, error) {
+	result := Object{
+		keys:   append([]string{}, o.keys...),
+		values: make(map[string]any, len(o.values)),
+	}
+
+	for key, value := range o.values {
+		cloned, err := deepcopy.Clone(value)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+
+		result.values[key] = cloned
+	}
+
+	return result, nil
+}
+
+// MarshalJSON encodes the object, keeping the order of its keys.
+func (o Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, key := range o.keys {
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		encodedValue, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		buf.Write(encodedValue)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
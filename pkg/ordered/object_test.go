@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package ordered
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.xrstf.de/rudi/pkg/coalescing"
+	"go.xrstf.de/rudi/pkg/deepcopy"
+	"go.xrstf.de/rudi/pkg/equality"
+	"go.xrstf.de/rudi/pkg/jsonpath"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testDocument = `{"zeta": 1, "alpha": {"y": [true, {"b": null, "a": "x"}], "x": 2.5}, "mu": "text"}`
+
+func decodeTestDocument(t *testing.T) any {
+	t.Helper()
+
+	decoded, err := DecodeJSON(json.NewDecoder(strings.NewReader(testDocument)))
+	if err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+
+	return decoded
+}
+
+func encode(t *testing.T, value any) string {
+	t.Helper()
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("Failed to encode JSON: %v", err)
+	}
+
+	return string(encoded)
+}
+
+func TestDecodeJSON(t *testing.T) {
+	decoded := decodeTestDocument(t)
+
+	expected := `{"zeta":1,"alpha":{"y":[true,{"b":null,"a":"x"}],"x":2.5},"mu":"text"}`
+	if encoded := encode(t, decoded); encoded != expected {
+		t.Fatalf("Expected %s, got %s", expected, encoded)
+	}
+
+	for _, invalid := range []string{``, `{`, `{"a": }`, `[1, 2`, `{"a" 1}`} {
+		if _, err := DecodeJSON(json.NewDecoder(strings.NewReader(invalid))); err == nil {
+			t.Errorf("Should not have been able to decode %q.", invalid)
+		}
+	}
+}
+
+func TestObject(t *testing.T) {
+	obj := Object{}
+	obj.Set("b", 1)
+	obj.Set("a", 2)
+	obj.Set("c", 3)
+	obj.Set("b", 4)
+	obj.Delete("a")
+	obj.Delete("unknown")
+
+	if !cmp.Equal([]string{"b", "c"}, obj.Keys()) {
+		t.Fatalf("Expected keys [b c], got %v", obj.Keys())
+	}
+
+	if value, ok := obj.Get("b"); !ok || value != 4 {
+		t.Fatalf("Expected b=4, got %v", value)
+	}
+
+	if obj.Len() != 2 {
+		t.Fatalf("Expected 2 keys, got %d", obj.Len())
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	decoded := decodeTestDocument(t)
+
+	updated, err := jsonpath.Set(decoded, jsonpath.Path{"alpha", "y", 1, "c"}, "new")
+	if err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	updated, err = jsonpath.Set(updated, jsonpath.Path{"zeta"}, 2)
+	if err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	updated, err = jsonpath.Delete(updated, jsonpath.Path{"alpha", "x"})
+	if err != nil {
+		t.Fatalf("Failed to delete value: %v", err)
+	}
+
+	expected := `{"zeta":2,"alpha":{"y":[true,{"b":null,"a":"x","c":"new"}]},"mu":"text"}`
+	if encoded := encode(t, updated); encoded != expected {
+		t.Fatalf("Expected %s, got %s", expected, encoded)
+	}
+
+	// the original must not have been modified
+	if encoded := encode(t, decoded); encoded != encode(t, decodeTestDocument(t)) {
+		t.Fatalf("Original document was modified: %s", encoded)
+	}
+
+	value, err := jsonpath.Get(decoded, jsonpath.Path{"alpha", "y", 1, "a"})
+	if err != nil {
+		t.Fatalf("Failed to get value: %v", err)
+	}
+
+	if value != "x" {
+		t.Fatalf("Expected \"x\", got %v", value)
+	}
+
+	if _, err := jsonpath.Get(decoded, jsonpath.Path{"unknown"}); err == nil {
+		t.Fatal("Should not have been able to get a non-existing key.")
+	}
+}
+
+func TestDeepCopy(t *testing.T) {
+	decoded := decodeTestDocument(t)
+
+	cloned, err := deepcopy.Clone(decoded)
+	if err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+
+	alpha, _ := cloned.(Object).Get("alpha")
+	alphaObj := alpha.(Object)
+	alphaObj.Set("x", "changed")
+
+	if encoded := encode(t, decoded); encoded != encode(t, decodeTestDocument(t)) {
+		t.Fatalf("Original document was modified: %s", encoded)
+	}
+}
+
+func TestCoalescing(t *testing.T) {
+	decoded := decodeTestDocument(t)
+
+	unordered := map[string]any{}
+	if err := json.Unmarshal([]byte(testDocument), &unordered); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+
+	for _, coalescer := range []coalescing.Coalescer{coalescing.NewPedantic(), coalescing.NewStrict(), coalescing.NewHumane()} {
+		object, err := coalescer.ToObject(decoded)
+		if err != nil {
+			t.Fatalf("Failed to coalesce into object: %v", err)
+		}
+
+		if len(object) != 3 {
+			t.Errorf("Expected 3 keys, got %v", object)
+		}
+
+		equal, err := equality.Equal(coalescer, decoded, unordered)
+		if err != nil {
+			t.Fatalf("Failed to compare: %v", err)
+		}
+
+		if !equal {
+			t.Errorf("Expected ordered and unordered objects to be equal.")
+		}
+	}
+
+	if isNull, err := coalescing.NewHumane().ToNull(NewObject()); err != nil || !isNull {
+		t.Errorf("Expected empty object to be coalesced to null, got %v (error %v).", isNull, err)
+	}
+}
@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+// Package patch computes the differences between two documents in the form
+// of a JSON Patch (RFC 6902) or a JSON Merge Patch (RFC 7386). This is useful
+// to find out what a Rudi program changed, e.g. to respond to a Kubernetes
+// mutating webhook.
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.xrstf.de/rudi/pkg/coalescing"
+	"go.xrstf.de/rudi/pkg/diff"
+	"go.xrstf.de/rudi/pkg/jsonpath"
+	"go.xrstf.de/rudi/pkg/native"
+)
+
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// Operation is a single operation in a JSON Patch.
+type Operation struct {
+	Op    string
+	Path  string
+	Value any
+}
+
+func (o Operation) MarshalJSON() ([]byte, error) {
+	if o.Op == OpRemove {
+		return json.Marshal(map[string]any{
+			"op":   o.Op,
+			"path": o.Path,
+		})
+	}
+
+	return json.Marshal(map[string]any{
+		"op":    o.Op,
+		"path":  o.Path,
+		"value": o.Value,
+	})
+}
+
+// Values are compared the same way equality.Equal would with a pedantic
+// coalescer, so that for example 1 and 1.0 do not result in a change.
+var pedantic = coalescing.NewPedantic()
+
+// JSONPatch returns the operations that turn original into updated. Both
+// values can be anything a Rudi document can hold. Vector items are compared
+// by position, after skipping over equal items at the start and end, so
+// inserting an item in the middle of a vector results in a single add
+// operation, while changing the order of items results in replace operations.
+func JSONPatch(original, updated any) ([]Operation, error) {
+	changes, err := diff.Diff(pedantic, original, updated, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ops := make([]Operation, 0, len(changes))
+
+	for _, change := range changes {
+		pointer, err := change.Path.ToPointer()
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %s: %w", change.Path, err)
+		}
+
+		ops = append(ops, Operation{
+			Op:    string(change.Op),
+			Path:  pointer,
+			Value: native.Unwrap(change.New),
+		})
+	}
+
+	return ops, nil
+}
+
+// MergePatch returns a JSON Merge Patch that turns original into updated.
+// Since merge patches use null to remove keys, setting a key to null cannot
+// be expressed and will result in the key being removed when the patch is
+// applied. Likewise, vectors are always replaced in their entirety.
+func MergePatch(original, updated any) (any, error) {
+	// a merge patch can only describe changes to an object
+	if _, err := pedantic.ToObject(original); original == nil || err != nil {
+		return native.Unwrap(updated), nil
+	}
+
+	changes, err := diff.Diff(pedantic, original, updated, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]any{}
+
+	for _, change := range changes {
+		path, value := change.Path, change.New
+		if change.Op == diff.OpRemove {
+			value = nil
+		}
+
+		// cut the path off at the first vector and replace the entire vector
+		for i, step := range path {
+			if _, isKey := step.(string); !isKey {
+				path = path[:i]
+
+				value, err = jsonpath.Get(updated, path)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", path, err)
+				}
+
+				break
+			}
+		}
+
+		// updated is not an object
+		if len(path) == 0 {
+			return native.Unwrap(updated), nil
+		}
+
+		setKey(result, path, native.Unwrap(value))
+	}
+
+	return result, nil
+}
+
+// setKey sets the value in the nested objects of the patch, creating them as
+// needed. All steps in path must be object keys.
+func setKey(patch map[string]any, path jsonpath.Path, value any) {
+	for _, step := range path[:len(path)-1] {
+		key := step.(string)
+
+		sub, ok := patch[key].(map[string]any)
+		if !ok {
+			sub = map[string]any{}
+			patch[key] = sub
+		}
+
+		patch = sub
+	}
+
+	patch[path[len(path)-1].(string)] = value
+}
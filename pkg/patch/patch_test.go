@@ -0,0 +1,224 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package patch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.xrstf.de/rudi/pkg/native"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testStruct struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+func TestJSONPatch(t *testing.T) {
+	shared := map[string]any{"deep": []any{1, 2, 3}}
+
+	testcases := []struct {
+		name     string
+		original any
+		updated  any
+		expected []Operation
+	}{
+		{
+			name:     "identical scalars",
+			original: "foo",
+			updated:  "foo",
+			expected: []Operation{},
+		},
+		{
+			name:     "replace root",
+			original: "foo",
+			updated:  int64(42),
+			expected: []Operation{{Op: OpReplace, Path: "", Value: int64(42)}},
+		},
+		{
+			name:     "replace object with vector",
+			original: map[string]any{"a": 1},
+			updated:  []any{1},
+			expected: []Operation{{Op: OpReplace, Path: "", Value: []any{1}}},
+		},
+		{
+			name:     "shared subtree",
+			original: map[string]any{"shared": shared, "b": 1},
+			updated:  map[string]any{"shared": shared, "b": 2},
+			expected: []Operation{{Op: OpReplace, Path: "/b", Value: 2}},
+		},
+		{
+			name:     "object changes",
+			original: map[string]any{"keep": 1, "remove": 2, "change": map[string]any{"x": "y"}},
+			updated:  map[string]any{"keep": 1, "add": nil, "change": map[string]any{"x": "z"}},
+			expected: []Operation{
+				{Op: OpAdd, Path: "/add", Value: nil},
+				{Op: OpReplace, Path: "/change/x", Value: "z"},
+				{Op: OpRemove, Path: "/remove"},
+			},
+		},
+		{
+			name:     "escaped keys",
+			original: map[string]any{},
+			updated:  map[string]any{"a/b~c": true},
+			expected: []Operation{{Op: OpAdd, Path: "/a~1b~0c", Value: true}},
+		},
+		{
+			name:     "append to vector",
+			original: []any{1, 2},
+			updated:  []any{1, 2, 3, 4},
+			expected: []Operation{
+				{Op: OpAdd, Path: "/2", Value: 3},
+				{Op: OpAdd, Path: "/3", Value: 4},
+			},
+		},
+		{
+			name:     "remove from vector",
+			original: []any{1, 2, 3, 4},
+			updated:  []any{1, 4},
+			expected: []Operation{
+				{Op: OpRemove, Path: "/2"},
+				{Op: OpRemove, Path: "/1"},
+			},
+		},
+		{
+			name:     "insert into vector",
+			original: []any{1, 2, 3},
+			updated:  []any{1, 4, 2, 3},
+			expected: []Operation{{Op: OpAdd, Path: "/1", Value: 4}},
+		},
+		{
+			name:     "change within vector",
+			original: []any{1, map[string]any{"a": "b"}, 3},
+			updated:  []any{1, map[string]any{"a": "c"}, 3},
+			expected: []Operation{{Op: OpReplace, Path: "/1/a", Value: "c"}},
+		},
+		{
+			name:     "native values",
+			original: testStruct{Name: "foo"},
+			updated:  testStruct{Name: "bar", Labels: map[string]string{"a": "b"}},
+			expected: []Operation{
+				{Op: OpAdd, Path: "/labels", Value: map[string]string{"a": "b"}},
+				{Op: OpReplace, Path: "/name", Value: "bar"},
+			},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			original, updated := wrap(t, testcase.original), wrap(t, testcase.updated)
+
+			ops, err := JSONPatch(original, updated)
+			if err != nil {
+				t.Fatalf("Failed to create patch: %v", err)
+			}
+
+			if !cmp.Equal(testcase.expected, ops) {
+				t.Fatalf("Unexpected patch:\n%s", cmp.Diff(testcase.expected, ops))
+			}
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	testcases := []struct {
+		name     string
+		original any
+		updated  any
+		expected any
+	}{
+		{
+			name:     "identical objects",
+			original: map[string]any{"a": 1},
+			updated:  map[string]any{"a": 1},
+			expected: map[string]any{},
+		},
+		{
+			name:     "replace root",
+			original: map[string]any{"a": 1},
+			updated:  []any{1},
+			expected: []any{1},
+		},
+		{
+			name:     "object changes",
+			original: map[string]any{"keep": 1, "remove": 2, "nested": map[string]any{"x": "y", "same": true}, "list": []any{1, 2}},
+			updated:  map[string]any{"keep": 1, "add": "new", "nested": map[string]any{"x": "z", "same": true}, "list": []any{1, 3}},
+			expected: map[string]any{
+				"add":    "new",
+				"remove": nil,
+				"nested": map[string]any{"x": "z"},
+				"list":   []any{1, 3},
+			},
+		},
+		{
+			name:     "equal scalars",
+			original: int64(1),
+			updated:  int64(1),
+			expected: int64(1),
+		},
+		{
+			name:     "change within vector",
+			original: map[string]any{"nested": map[string]any{"list": []any{map[string]any{"a": 1}, 2}}},
+			updated:  map[string]any{"nested": map[string]any{"list": []any{map[string]any{"a": 2}, 2}}},
+			expected: map[string]any{"nested": map[string]any{"list": []any{map[string]any{"a": 2}, 2}}},
+		},
+		{
+			name:     "unchanged nested object",
+			original: map[string]any{"nested": map[string]any{"x": "y"}, "a": 1},
+			updated:  map[string]any{"nested": map[string]any{"x": "y"}, "a": 2},
+			expected: map[string]any{"a": 2},
+		},
+		{
+			name:     "native values",
+			original: testStruct{Name: "foo", Labels: map[string]string{"a": "b"}},
+			updated:  testStruct{Name: "bar"},
+			expected: map[string]any{"name": "bar", "labels": nil},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			original, updated := wrap(t, testcase.original), wrap(t, testcase.updated)
+
+			patch, err := MergePatch(original, updated)
+			if err != nil {
+				t.Fatalf("Failed to create patch: %v", err)
+			}
+
+			if !cmp.Equal(testcase.expected, patch) {
+				t.Fatalf("Unexpected patch:\n%s", cmp.Diff(testcase.expected, patch))
+			}
+		})
+	}
+}
+
+func TestOperationMarshalJSON(t *testing.T) {
+	ops := []Operation{
+		{Op: OpAdd, Path: "/a", Value: nil},
+		{Op: OpRemove, Path: "/b", Value: "ignored"},
+	}
+
+	encoded, err := json.Marshal(ops)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+
+	expected := `[{"op":"add","path":"/a","value":null},{"op":"remove","path":"/b"}]`
+	if string(encoded) != expected {
+		t.Fatalf("Expected %s, got %s", expected, string(encoded))
+	}
+}
+
+func wrap(t *testing.T, value any) any {
+	t.Helper()
+
+	wrapped, err := native.Wrap(value)
+	if err != nil {
+		t.Fatalf("Failed to wrap %T: %v", value, err)
+	}
+
+	return wrapped
+}
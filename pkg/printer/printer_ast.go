@@ -41,12 +41,17 @@ func (p *astPrinter) String(str string) error {
 }
 
 func (p *astPrinter) Identifier(ident *ast.Identifier) error {
+	var namespace string
+	if ident.Namespace != "" {
+		namespace = fmt.Sprintf(" (namespace %s)", ident.Namespace)
+	}
+
 	var bang string
 	if ident.Bang {
 		bang = " (bang)"
 	}
 
-	return p.write(fmt.Sprintf("(identifier %s%s)", ident.Name, bang))
+	return p.write(fmt.Sprintf("(identifier %s%s%s)", ident.Name, namespace, bang))
 }
 
 func (p *astPrinter) Vector(vec []any) error {
@@ -238,7 +243,7 @@ func (p *astPrinter) writePathExpression(path *ast.PathExpression) error {
 	}
 
 	for i, step := range path.Steps {
-		if err := printAny(step, p); err != nil {
+		if err := p.writePathStep(step); err != nil {
 			return err
 		}
 
@@ -252,6 +257,83 @@ func (p *astPrinter) writePathExpression(path *ast.PathExpression) error {
 	return p.write("])")
 }
 
+func (p *astPrinter) writePathStep(step ast.Expression) error {
+	switch asserted := step.(type) {
+	case ast.DescentStep:
+		if err := p.write("(descent "); err != nil {
+			return err
+		}
+
+		if err := p.writePathStep(asserted.Step); err != nil {
+			return err
+		}
+
+		return p.write(")")
+
+	case ast.OptionalStep:
+		if err := p.write("(optional "); err != nil {
+			return err
+		}
+
+		if err := p.writePathStep(asserted.Step); err != nil {
+			return err
+		}
+
+		return p.write(")")
+
+	case ast.WildcardStep:
+		return p.write("(wildcard)")
+
+	case ast.SliceStep:
+		if err := p.write("(slice"); err != nil {
+			return err
+		}
+
+		if asserted.From != nil {
+			if err := p.write(" (from "); err != nil {
+				return err
+			}
+
+			if err := printAny(asserted.From, p); err != nil {
+				return err
+			}
+
+			if err := p.write(")"); err != nil {
+				return err
+			}
+		}
+
+		if asserted.To != nil {
+			if err := p.write(" (to "); err != nil {
+				return err
+			}
+
+			if err := printAny(asserted.To, p); err != nil {
+				return err
+			}
+
+			if err := p.write(")"); err != nil {
+				return err
+			}
+		}
+
+		return p.write(")")
+
+	case ast.FilterStep:
+		if err := p.write("(filter "); err != nil {
+			return err
+		}
+
+		if err := printAny(asserted.Predicate, p); err != nil {
+			return err
+		}
+
+		return p.write(")")
+	}
+
+	return printAny(step, p)
+}
+
 func (p *astPrinter) writeOptionalPathExpression(path *ast.PathExpression) error {
 	if path == nil {
 		return nil
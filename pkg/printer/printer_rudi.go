@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 
 	"go.xrstf.de/rudi/pkg/lang/ast"
 )
@@ -34,7 +35,26 @@ func (p *rudiPrinter) Bool(b bool) error {
 }
 
 func (p *rudiPrinter) Number(value any) error {
-	return p.write(fmt.Sprintf("%v", value))
+	var f float64
+
+	switch asserted := value.(type) {
+	case float32:
+		f = float64(asserted)
+	case float64:
+		f = asserted
+	default:
+		return p.write(fmt.Sprintf("%v", value))
+	}
+
+	// Floats must always contain a decimal point, otherwise they would be
+	// parsed as integers; exponents are not used because Rudi does not allow
+	// to combine them with decimal places.
+	formatted := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.Contains(formatted, ".") {
+		formatted += ".0"
+	}
+
+	return p.write(formatted)
 }
 
 func (p *rudiPrinter) String(str string) error {
@@ -42,7 +62,7 @@ func (p *rudiPrinter) String(str string) error {
 }
 
 func (p *rudiPrinter) Identifier(ident *ast.Identifier) error {
-	name := ident.Name
+	name := ident.FullName()
 	if ident.Bang {
 		name += "!"
 	}
@@ -245,45 +265,106 @@ func (p *rudiPrinter) printPathExpression(path *ast.PathExpression) error {
 	}
 
 	for _, step := range path.Steps {
-		if p.isVectorStep(step) {
-			if err := p.write("["); err != nil {
-				return err
-			}
+		if err := p.printPathStep(step); err != nil {
+			return err
+		}
+	}
 
-			if err := printAny(step, p); err != nil {
-				return err
-			}
+	return nil
+}
 
-			if err := p.write("]"); err != nil {
+func (p *rudiPrinter) printPathStep(step ast.Expression) error {
+	switch asserted := step.(type) {
+	case ast.DescentStep:
+		// identifier steps bring their own dot
+		prefix := "."
+		if p.isVectorStep(asserted.Step) {
+			prefix = ".."
+		}
+
+		if err := p.write(prefix); err != nil {
+			return err
+		}
+
+		return p.printPathStep(asserted.Step)
+
+	case ast.OptionalStep:
+		if err := p.printPathStep(asserted.Step); err != nil {
+			return err
+		}
+
+		return p.write("?")
+
+	case ast.WildcardStep:
+		return p.write("[*]")
+
+	case ast.SliceStep:
+		if err := p.write("["); err != nil {
+			return err
+		}
+
+		if asserted.From != nil {
+			if err := printAny(asserted.From, p); err != nil {
 				return err
 			}
-		} else {
-			if err := p.write("."); err != nil {
+		}
+
+		if err := p.write(":"); err != nil {
+			return err
+		}
+
+		if asserted.To != nil {
+			if err := printAny(asserted.To, p); err != nil {
 				return err
 			}
+		}
 
-			switch asserted := step.(type) {
-			case ast.Identifier:
-				if err := p.write(asserted.Name); err != nil {
-					return err
-				}
-			case ast.String:
-				if err := p.write(string(asserted)); err != nil {
-					return err
-				}
-			default:
-				panic("Should not reach this point: isVectorStop is out of sync.")
-			}
+		return p.write("]")
+
+	case ast.FilterStep:
+		if err := p.write("[?"); err != nil {
+			return err
+		}
+
+		if err := printAny(asserted.Predicate, p); err != nil {
+			return err
 		}
+
+		return p.write("]")
 	}
 
-	return nil
+	if p.isVectorStep(step) {
+		if err := p.write("["); err != nil {
+			return err
+		}
+
+		if err := printAny(step, p); err != nil {
+			return err
+		}
+
+		return p.write("]")
+	}
+
+	if err := p.write("."); err != nil {
+		return err
+	}
+
+	switch asserted := step.(type) {
+	case ast.Identifier:
+		return p.write(asserted.Name)
+	case ast.String:
+		return p.write(string(asserted))
+	default:
+		panic("Should not reach this point: isVectorStop is out of sync.")
+	}
 }
 
 func (p *rudiPrinter) isVectorStep(step ast.Expression) bool {
 	switch asserted := step.(type) {
-	case ast.Identifier:
+	case ast.Identifier, ast.DescentStep:
 		return false
+	case ast.OptionalStep:
+		return p.isVectorStep(asserted.Step)
 	case ast.String:
 		return !ast.PathIdentifierPattern.MatchString(string(asserted))
 	default:
@@ -56,6 +56,34 @@ func TestRudiRenderer(t *testing.T) {
 			input:  `.["fo\"o"]`,
 			output: `.["fo\"o"]`,
 		},
+		{
+			input:  `.items[ * ].name`,
+			output: `.items[*].name`,
+		},
+		{
+			input:  `.items[1:3][:-1][ $i : ]`,
+			output: `.items[1:3][:-1][$i:]`,
+		},
+		{
+			input:  `.items[? (eq? .name "app")].image`,
+			output: `.items[?(eq? .name "app")].image`,
+		},
+		{
+			input:  `..image`,
+			output: `..image`,
+		},
+		{
+			input:  `$foo..["a b"]..[0]`,
+			output: `$foo..["a b"]..[0]`,
+		},
+		{
+			input:  `.spec?.items[0]?["a b"]?`,
+			output: `.spec?.items[0]?["a b"]?`,
+		},
+		{
+			input:  `.[0]?.foo`,
+			output: `.[0]?.foo`,
+		},
 		{
 			// series of 3 statements
 			input:  `1 (foo) [true]`,
@@ -129,6 +157,10 @@ func TestRudiRenderer(t *testing.T) {
 			input:  `( map $foo.bar[ ( add 1.2)] [a  b] ( foo! ))`,
 			output: `(map $foo.bar[(add 1.2)] [a b] (foo!))`,
 		},
+		{
+			input:  `[2.0 1e3 -0.5 1e-7]`,
+			output: `[2.0 1000.0 -0.5 0.0000001]`,
+		},
 	}
 
 	for _, tc := range testcases {
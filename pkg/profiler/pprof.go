@@ -0,0 +1,216 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package profiler
+
+import (
+	"compress/gzip"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"go.xrstf.de/rudi/pkg/runtime/types"
+)
+
+// Field numbers of the pprof profile.proto messages, see
+// https://github.com/google/pprof/blob/main/proto/profile.proto.
+const (
+	profileSampleType    = 1
+	profileSample        = 2
+	profileLocation      = 4
+	profileFunction      = 5
+	profileStringTable   = 6
+	profileTimeNanos     = 9
+	profileDurationNanos = 10
+	profilePeriodType    = 11
+	profilePeriod        = 12
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+
+	functionID   = 1
+	functionName = 2
+)
+
+// WritePprof writes the profile in the gzip-compressed protobuf format
+// understood by `go tool pprof`. Every sample is a distinct stack of
+// statements and function calls, with the number of calls and the time spent
+// in the innermost function (self time) as its values.
+func (p *Profiler) WritePprof(w io.Writer) error {
+	strs := newStringTable()
+
+	// sort the samples to produce stable output
+	keys := make([]string, 0, len(p.samples))
+	for key := range p.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Each function and each statement becomes one pprof function with a
+	// single location.
+	functionIDs := map[string]uint64{}
+	functionNames := []string{}
+
+	buf := &protobuf{}
+
+	valueType := func(typ, unit string) func(*protobuf) {
+		return func(b *protobuf) {
+			b.int64(valueTypeType, strs.index(typ))
+			b.int64(valueTypeUnit, strs.index(unit))
+		}
+	}
+
+	buf.message(profileSampleType, valueType("calls", "count"))
+	buf.message(profileSampleType, valueType("time", "nanoseconds"))
+
+	for _, key := range keys {
+		s := p.samples[key]
+
+		// pprof expects the innermost frame first
+		locations := make([]uint64, len(s.stack))
+		for i, f := range s.stack {
+			name := f.name
+			if f.kind == types.StatementEvent {
+				name = "statement " + shorten(strings.Join(strings.Fields(name), " "))
+			}
+
+			id, exists := functionIDs[name]
+			if !exists {
+				functionNames = append(functionNames, name)
+				id = uint64(len(functionNames))
+				functionIDs[name] = id
+			}
+
+			locations[len(s.stack)-1-i] = id
+		}
+
+		buf.message(profileSample, func(b *protobuf) {
+			b.packedUint64(sampleLocationID, locations)
+			b.packedUint64(sampleValue, []uint64{uint64(s.calls), uint64(s.self.Nanoseconds())})
+		})
+	}
+
+	for i, name := range functionNames {
+		id := uint64(i + 1)
+
+		buf.message(profileLocation, func(b *protobuf) {
+			b.uint64(locationID, id)
+			b.message(locationLine, func(b *protobuf) {
+				b.uint64(lineFunctionID, id)
+			})
+		})
+
+		buf.message(profileFunction, func(b *protobuf) {
+			b.uint64(functionID, id)
+			b.int64(functionName, strs.index(name))
+		})
+	}
+
+	if !p.started.IsZero() {
+		buf.int64(profileTimeNanos, p.started.UnixNano())
+		buf.int64(profileDurationNanos, time.Since(p.started).Nanoseconds())
+	}
+
+	buf.message(profilePeriodType, valueType("time", "nanoseconds"))
+	buf.int64(profilePeriod, 1)
+
+	// the string table must be written last, as all other fields add to it
+	for _, s := range strs.strings {
+		buf.string(profileStringTable, s)
+	}
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(buf.data); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+type stringTable struct {
+	strings []string
+	indices map[string]int64
+}
+
+func newStringTable() *stringTable {
+	// the first string must always be the empty string
+	return &stringTable{
+		strings: []string{""},
+		indices: map[string]int64{"": 0},
+	}
+}
+
+func (t *stringTable) index(s string) int64 {
+	idx, exists := t.indices[s]
+	if !exists {
+		idx = int64(len(t.strings))
+		t.strings = append(t.strings, s)
+		t.indices[s] = idx
+	}
+
+	return idx
+}
+
+// protobuf is a minimal protobuf encoder, just enough to encode pprof
+// profiles without depending on a protobuf library.
+type protobuf struct {
+	data []byte
+}
+
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func (b *protobuf) varint(x uint64) {
+	for x >= 0x80 {
+		b.data = append(b.data, byte(x)|0x80)
+		x >>= 7
+	}
+
+	b.data = append(b.data, byte(x))
+}
+
+func (b *protobuf) key(field int, wireType int) {
+	b.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (b *protobuf) uint64(field int, x uint64) {
+	b.key(field, wireVarint)
+	b.varint(x)
+}
+
+func (b *protobuf) int64(field int, x int64) {
+	b.uint64(field, uint64(x))
+}
+
+func (b *protobuf) string(field int, s string) {
+	b.key(field, wireBytes)
+	b.varint(uint64(len(s)))
+	b.data = append(b.data, s...)
+}
+
+func (b *protobuf) packedUint64(field int, xs []uint64) {
+	packed := &protobuf{}
+	for _, x := range xs {
+		packed.varint(x)
+	}
+
+	b.string(field, string(packed.data))
+}
+
+func (b *protobuf) message(field int, encode func(*protobuf)) {
+	nested := &protobuf{}
+	encode(nested)
+
+	b.string(field, string(nested.data))
+}
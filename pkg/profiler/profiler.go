@@ -0,0 +1,243 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+// Package profiler implements a tracer that aggregates how often functions
+// and statements are evaluated and how much time is spent in them. Profiles
+// can be printed as a text table or exported in the pprof format.
+package profiler
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+)
+
+const maxStatementLength = 60
+
+// Stats are the aggregated numbers for a single function or statement.
+type Stats struct {
+	// Name is either the function name or the statement's Rudi code.
+	Name  string
+	Calls int
+	// Cumulative is the total time spent in the function/statement, including
+	// the time spent in any functions called by it. Recursive calls are not
+	// counted twice.
+	Cumulative time.Duration
+	// Self is the time spent in the function/statement itself, excluding
+	// the time spent in other functions.
+	Self time.Duration
+}
+
+type frame struct {
+	kind types.TraceEventKind
+	name string
+	// children is the time spent in nested function calls and statements
+	children time.Duration
+}
+
+type sample struct {
+	// stack contains the names of all frames, from the outermost statement
+	// to the function that was called
+	stack []frame
+	calls int64
+	self  time.Duration
+}
+
+// Profiler is a types.Tracer that aggregates call counts and durations for
+// every function and statement. A profiler can be used for many evaluations
+// to aggregate their numbers, but not for concurrent evaluations.
+type Profiler struct {
+	stack      []frame
+	functions  map[string]*Stats
+	statements map[string]*Stats
+	samples    map[string]*sample
+	started    time.Time
+}
+
+var _ types.Tracer = &Profiler{}
+
+func New() *Profiler {
+	p := &Profiler{}
+	p.Reset()
+
+	return p
+}
+
+// Reset discards all aggregated numbers.
+func (p *Profiler) Reset() {
+	p.stack = []frame{}
+	p.functions = map[string]*Stats{}
+	p.statements = map[string]*Stats{}
+	p.samples = map[string]*sample{}
+	p.started = time.Time{}
+}
+
+func (p *Profiler) Enter(_ types.Context, event types.TraceEvent) error {
+	name, ok := frameName(event)
+	if !ok {
+		return nil
+	}
+
+	if p.started.IsZero() {
+		p.started = time.Now()
+	}
+
+	p.stack = append(p.stack, frame{
+		kind: event.Kind,
+		name: name,
+	})
+
+	return nil
+}
+
+func (p *Profiler) Exit(_ types.Context, event types.TraceEvent) {
+	if _, ok := frameName(event); !ok {
+		return
+	}
+
+	current := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+
+	self := event.Duration - current.children
+	if len(p.stack) > 0 {
+		p.stack[len(p.stack)-1].children += event.Duration
+	}
+
+	stats := p.functions
+	if current.kind == types.StatementEvent {
+		stats = p.statements
+	}
+
+	entry, exists := stats[current.name]
+	if !exists {
+		entry = &Stats{Name: current.name}
+		stats[current.name] = entry
+	}
+
+	entry.Calls++
+	entry.Self += self
+
+	if !p.onStack(current) {
+		entry.Cumulative += event.Duration
+	}
+
+	p.record(current, self)
+}
+
+// onStack returns true if the frame is already on the stack, i.e. for
+// recursive calls.
+func (p *Profiler) onStack(f frame) bool {
+	for _, other := range p.stack {
+		if other.kind == f.kind && other.name == f.name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Profiler) record(current frame, self time.Duration) {
+	stack := make([]frame, len(p.stack), len(p.stack)+1)
+	copy(stack, p.stack)
+	stack = append(stack, current)
+
+	names := make([]string, len(stack))
+	for i, f := range stack {
+		names[i] = fmt.Sprintf("%d:%s", f.kind, f.name)
+	}
+
+	key := strings.Join(names, "\x00")
+
+	s, exists := p.samples[key]
+	if !exists {
+		s = &sample{stack: stack}
+		p.samples[key] = s
+	}
+
+	s.calls++
+	s.self += self
+}
+
+func frameName(event types.TraceEvent) (string, bool) {
+	switch event.Kind {
+	case types.CallEvent:
+		if ident, ok := event.Expression.(ast.Identifier); ok {
+			return ident.FullName(), true
+		}
+
+		return event.Expression.String(), true
+
+	case types.StatementEvent:
+		return event.Expression.String(), true
+	}
+
+	return "", false
+}
+
+// Functions returns the stats for all called functions, sorted by the time
+// spent in them (descending).
+func (p *Profiler) Functions() []Stats {
+	return sortedStats(p.functions)
+}
+
+// Statements returns the stats for all evaluated statements, sorted by the
+// time spent in them (descending). Statements are identified by their code,
+// so identical statements in different programs are aggregated together.
+func (p *Profiler) Statements() []Stats {
+	return sortedStats(p.statements)
+}
+
+func sortedStats(stats map[string]*Stats) []Stats {
+	result := make([]Stats, 0, len(stats))
+	for _, s := range stats {
+		result = append(result, *s)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Cumulative != result[j].Cumulative {
+			return result[i].Cumulative > result[j].Cumulative
+		}
+
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// WriteText writes the aggregated stats as human readable tables.
+func (p *Profiler) WriteText(w io.Writer) error {
+	if err := writeTable(w, "function", p.Functions()); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+
+	return writeTable(w, "statement", p.Statements())
+}
+
+func writeTable(w io.Writer, title string, stats []Stats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintf(tw, "calls\tcumulative\tself\t\t%s\n", title)
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%d\t%v\t%v\t\t%s\n", s.Calls, s.Cumulative, s.Self, shorten(s.Name))
+	}
+
+	return tw.Flush()
+}
+
+func shorten(s string) string {
+	if runes := []rune(s); len(runes) > maxStatementLength {
+		return string(runes[:maxStatementLength-1]) + "…"
+	}
+
+	return s
+}
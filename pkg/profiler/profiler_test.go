@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package profiler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"go.xrstf.de/rudi/pkg/builtin"
+	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/lang/parser"
+	"go.xrstf.de/rudi/pkg/runtime/interpreter"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+)
+
+func runProfiled(t *testing.T, profiler *Profiler, script string) {
+	got, err := parser.Parse("test", []byte(script))
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", script, err)
+	}
+
+	program := got.(ast.Program)
+
+	funcs := builtin.SafeFunctions.DeepCopy().Add(builtin.UnsafeFunctions)
+	runtime := interpreter.New()
+
+	ctx, err := types.NewContext(runtime, context.Background(), types.Document{}, nil, funcs, nil)
+	if err != nil {
+		t.Fatalf("Failed to create context: %v", err)
+	}
+
+	if _, err := runtime.EvalProgram(ctx.WithTracer(profiler), &program); err != nil {
+		t.Fatalf("Failed to run program: %v", err)
+	}
+}
+
+func findStats(stats []Stats, name string) Stats {
+	for _, s := range stats {
+		if s.Name == name {
+			return s
+		}
+	}
+
+	return Stats{}
+}
+
+func TestProfiler(t *testing.T) {
+	profiler := New()
+
+	// run twice to ensure numbers are aggregated
+	for i := 0; i < 2; i++ {
+		runProfiled(t, profiler, `(map [1 2 3] [v] (add $v 1)) (add 1 2)`)
+	}
+
+	functions := profiler.Functions()
+	if len(functions) != 2 {
+		t.Fatalf("Expected 2 functions, got %+v", functions)
+	}
+
+	add := findStats(functions, "add")
+	if add.Calls != 8 {
+		t.Errorf("Expected add to be called 8 times, got %d", add.Calls)
+	}
+
+	mapStats := findStats(functions, "map")
+	if mapStats.Calls != 2 {
+		t.Errorf("Expected map to be called 2 times, got %d", mapStats.Calls)
+	}
+
+	statements := profiler.Statements()
+	if len(statements) != 2 {
+		t.Fatalf("Expected 2 statements, got %+v", statements)
+	}
+
+	for _, s := range append(functions, statements...) {
+		if s.Self > s.Cumulative {
+			t.Errorf("%s: self time %v is larger than cumulative time %v", s.Name, s.Self, s.Cumulative)
+		}
+	}
+
+	// the map statement includes the time spent in map and add
+	stmt := findStats(statements, "(map [1 2 3] [v] (add $v 1))")
+	if stmt.Calls != 2 || stmt.Cumulative < mapStats.Cumulative {
+		t.Errorf("Unexpected stats for map statement: %+v", stmt)
+	}
+
+	if mapStats.Self+add.Cumulative < mapStats.Cumulative {
+		t.Errorf("Time spent in map (%v) is not explained by its self time (%v) and add (%v)", mapStats.Cumulative, mapStats.Self, add.Cumulative)
+	}
+
+	var text strings.Builder
+	if err := profiler.WriteText(&text); err != nil {
+		t.Fatalf("Failed to write text: %v", err)
+	}
+
+	if !strings.Contains(text.String(), "(add 1 2)") {
+		t.Errorf("Text output does not contain statement:\n%s", text.String())
+	}
+}
+
+func TestProfilerRecursion(t *testing.T) {
+	profiler := New()
+	runProfiled(t, profiler, `(func! countdown [n] (if (gt? $n 0) (countdown (sub $n 1)) "done")) (countdown 3)`)
+
+	countdown := findStats(profiler.Functions(), "countdown")
+	if countdown.Calls != 4 {
+		t.Fatalf("Expected countdown to be called 4 times, got %d", countdown.Calls)
+	}
+
+	// recursive calls must not be counted multiple times
+	stmt := findStats(profiler.Statements(), "(countdown 3)")
+	if countdown.Cumulative > stmt.Cumulative {
+		t.Fatalf("Cumulative time of countdown (%v) exceeds time of the statement (%v)", countdown.Cumulative, stmt.Cumulative)
+	}
+}
+
+func TestWritePprof(t *testing.T) {
+	profiler := New()
+	runProfiled(t, profiler, `(add 1 (len "foo"))`)
+
+	var buf bytes.Buffer
+	if err := profiler.WritePprof(&buf); err != nil {
+		t.Fatalf("Failed to write profile: %v", err)
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("Profile is not gzip-compressed: %v", err)
+	}
+
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("Failed to decompress profile: %v", err)
+	}
+
+	for _, s := range []string{"add", "len", `statement (add 1 (len "foo"))`, "nanoseconds"} {
+		if !bytes.Contains(data, []byte(s)) {
+			t.Errorf("Profile does not contain %q.", s)
+		}
+	}
+}
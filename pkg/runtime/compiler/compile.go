@@ -0,0 +1,473 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package compiler
+
+import (
+	"errors"
+	"fmt"
+
+	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/ordered"
+	"go.xrstf.de/rudi/pkg/runtime/functions"
+	"go.xrstf.de/rudi/pkg/runtime/pathexpr"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+)
+
+// pathFunc applies a compiled path expression to a value.
+type pathFunc func(ctx types.Context, value any) (any, error)
+
+// compile turns an expression into an evaluator. If register is true, all
+// compiled container nodes are remembered, so that evaluating the same nodes
+// again using EvalExpression() can re-use the compiled code.
+func (c *compiler) compile(expr ast.Expression, register bool) functions.Evaluator {
+	var compiled functions.Evaluator
+
+	switch asserted := expr.(type) {
+	case ast.Null:
+		compiled = constant(nil)
+	case ast.Bool:
+		compiled = constant(bool(asserted))
+	case ast.String:
+		compiled = constant(string(asserted))
+	case ast.Number:
+		compiled = constant(asserted.Value)
+	case ast.Shim:
+		compiled = constant(asserted.Value)
+	case ast.ObjectNode:
+		compiled = c.compileObject(asserted, register)
+	case ast.VectorNode:
+		compiled = c.compileVector(asserted, register)
+	case ast.Symbol:
+		compiled = traced(types.TraceEvent{Kind: types.SymbolEvent, Expression: asserted}, c.compileSymbol(asserted, register))
+	case ast.Tuple:
+		compiled = traced(types.TraceEvent{Kind: types.TupleEvent, Expression: asserted}, c.compileTuple(asserted, register))
+	default:
+		// identifiers are not valid expressions, let the interpreter
+		// generate the appropriate error
+		compiled = func(ctx types.Context) (any, error) {
+			return c.interpreter.EvalExpression(ctx, expr)
+		}
+	}
+
+	if register {
+		if key, ok := keyOf(expr); ok {
+			c.nodes.Store(key, compiled)
+		}
+	}
+
+	return compiled
+}
+
+// traced wraps an evaluator so that it informs the context's tracer (if any)
+// in the same way the interpreter does.
+func traced(event types.TraceEvent, eval functions.Evaluator) functions.Evaluator {
+	return func(ctx types.Context) (any, error) {
+		if ctx.Tracer() == nil {
+			return eval(ctx)
+		}
+
+		return types.Trace(ctx, event, func() (any, error) {
+			return eval(ctx)
+		})
+	}
+}
+
+func constant(value any) functions.Evaluator {
+	return func(types.Context) (any, error) {
+		return value, nil
+	}
+}
+
+// literal returns the value of expressions that do not depend on the context
+// in any way.
+func literal(expr ast.Expression) (any, bool) {
+	switch asserted := expr.(type) {
+	case ast.Null:
+		return nil, true
+	case ast.Bool:
+		return bool(asserted), true
+	case ast.String:
+		return string(asserted), true
+	case ast.Number:
+		return asserted.Value, true
+
+	case ast.VectorNode:
+		if asserted.PathExpression != nil {
+			return nil, false
+		}
+
+		result := make([]any, len(asserted.Expressions))
+		for i, item := range asserted.Expressions {
+			value, ok := literal(item)
+			if !ok {
+				return nil, false
+			}
+
+			result[i] = value
+		}
+
+		return result, true
+
+	case ast.ObjectNode:
+		if asserted.PathExpression != nil {
+			return nil, false
+		}
+
+		result := map[string]any{}
+		for _, pair := range asserted.Data {
+			var key string
+
+			switch k := pair.Key.(type) {
+			case ast.Identifier:
+				if k.Bang {
+					return nil, false
+				}
+				key = k.FullName()
+			case ast.String:
+				key = string(k)
+			default:
+				return nil, false
+			}
+
+			value, ok := literal(pair.Value)
+			if !ok {
+				return nil, false
+			}
+
+			result[key] = value
+		}
+
+		return result, true
+	}
+
+	return nil, false
+}
+
+// precomputed returns an evaluator that returns the given value. The value is
+// shared between all evaluations, which is safe because set!, delete! and all
+// other modifications copy the values they change instead of modifying them in
+// place. Precomputed objects are plain maps, so when ordered objects are
+// enabled, the regular evaluator is used instead.
+func precomputed(value any, path pathFunc, regular functions.Evaluator) functions.Evaluator {
+	return func(ctx types.Context) (any, error) {
+		if ctx.OrderedObjects() {
+			return regular(ctx)
+		}
+
+		return path(ctx, value)
+	}
+}
+
+func (c *compiler) compileVector(vec ast.VectorNode, register bool) functions.Evaluator {
+	items := make([]functions.Evaluator, len(vec.Expressions))
+	for i, expr := range vec.Expressions {
+		items[i] = c.compile(expr, register)
+	}
+
+	path := c.compilePath(vec.PathExpression, register)
+
+	evaluator := func(ctx types.Context) (any, error) {
+		result := make([]any, len(items))
+
+		for i, item := range items {
+			data, err := item(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to eval expression %s: %w", vec.Expressions[i].String(), err)
+			}
+
+			result[i] = data
+		}
+
+		return path(ctx, result)
+	}
+
+	if value, ok := literal(vec.Pathless().(ast.VectorNode)); ok {
+		return precomputed(value, path, evaluator)
+	}
+
+	return evaluator
+}
+
+type compiledPair struct {
+	// key is nil if the key is a static identifier
+	key      functions.Evaluator
+	keyName  string
+	keyError error
+	value    functions.Evaluator
+}
+
+func (c *compiler) compileObject(obj ast.ObjectNode, register bool) functions.Evaluator {
+	pairs := make([]compiledPair, len(obj.Data))
+	for i, pair := range obj.Data {
+		compiled := compiledPair{
+			value: c.compile(pair.Value, register),
+		}
+
+		// as a convenience feature, we allow unquoted object keys, which are parsed as bare identifiers
+		if ident, ok := pair.Key.(ast.Identifier); ok {
+			if ident.Bang {
+				compiled.keyError = errors.New("cannot use bang modifier in object keys")
+			}
+
+			compiled.keyName = ident.FullName()
+		} else {
+			compiled.key = c.compile(pair.Key, register)
+		}
+
+		pairs[i] = compiled
+	}
+
+	path := c.compilePath(obj.PathExpression, register)
+
+	evaluator := func(ctx types.Context) (any, error) {
+		var (
+			result        = map[string]any{}
+			orderedResult *ordered.Object
+		)
+
+		if ctx.OrderedObjects() {
+			object := ordered.NewObject()
+			orderedResult = &object
+		}
+
+		for i, pair := range pairs {
+			if pair.keyError != nil {
+				return nil, pair.keyError
+			}
+
+			keyString := pair.keyName
+
+			if pair.key != nil {
+				key, err := pair.key(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to evaluate object key %s: %w", obj.Data[i].Key.String(), err)
+				}
+
+				keyString, err = ctx.Coalesce().ToString(key)
+				if err != nil {
+					return nil, fmt.Errorf("object key: %w", err)
+				}
+			}
+
+			value, err := pair.value(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate object value %s: %w", obj.Data[i].Value.String(), err)
+			}
+
+			if orderedResult != nil {
+				orderedResult.Set(keyString, value)
+			} else {
+				result[keyString] = value
+			}
+		}
+
+		if orderedResult != nil {
+			return path(ctx, *orderedResult)
+		}
+
+		return path(ctx, result)
+	}
+
+	if value, ok := literal(obj.Pathless().(ast.ObjectNode)); ok {
+		return precomputed(value, path, evaluator)
+	}
+
+	return evaluator
+}
+
+func (c *compiler) compileSymbol(sym ast.Symbol, register bool) functions.Evaluator {
+	// sanity check
+	if sym.Variable == nil && sym.PathExpression == nil {
+		return func(types.Context) (any, error) {
+			return nil, errors.New("invalid symbol")
+		}
+	}
+
+	// . always returns the root document
+	if sym.IsDot() {
+		return func(ctx types.Context) (any, error) {
+			return ctx.GetDocument().Data(), nil
+		}
+	}
+
+	path := c.compilePath(sym.PathExpression, register)
+
+	var root functions.Evaluator
+
+	if sym.Variable != nil {
+		varName := string(*sym.Variable)
+
+		root = func(ctx types.Context) (any, error) {
+			value, ok := ctx.GetVariable(varName)
+			if !ok {
+				return nil, fmt.Errorf("unknown variable %s", varName)
+			}
+
+			return value, nil
+		}
+	} else {
+		root = func(ctx types.Context) (any, error) {
+			return ctx.GetDocument().Data(), nil
+		}
+	}
+
+	return func(ctx types.Context) (any, error) {
+		rootValue, err := root(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		deeper, err := path(ctx, rootValue)
+		if err != nil {
+			return nil, fmt.Errorf("cannot evaluate %s: %w", sym.String(), err)
+		}
+
+		return deeper, nil
+	}
+}
+
+func (c *compiler) compileTuple(tup ast.Tuple, register bool) functions.Evaluator {
+	call := c.compileTupleCall(tup, register)
+	path := c.compilePath(tup.PathExpression, register)
+
+	return func(ctx types.Context) (any, error) {
+		// Function calls are the only place where we check if the Go context has been cancelled.
+		// This error should not be caught and swallowed by any other function, like `try` or `default`.
+		if err := ctx.GoContext().Err(); err != nil {
+			return nil, err
+		}
+
+		result, err := call(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return path(ctx, result)
+	}
+}
+
+func (c *compiler) compileTupleCall(tup ast.Tuple, register bool) functions.Evaluator {
+	if len(tup.Expressions) == 0 {
+		return func(types.Context) (any, error) {
+			return nil, errors.New("invalid tuple: tuple cannot be empty")
+		}
+	}
+
+	identifier, ok := tup.Expressions[0].(ast.Identifier)
+	if !ok {
+		return func(types.Context) (any, error) {
+			return nil, errors.New("invalid tuple: first expression must be an identifier")
+		}
+	}
+
+	args := tup.Expressions[1:]
+	evaluators := make([]functions.Evaluator, len(args))
+	for i, arg := range args {
+		evaluators[i] = c.compile(arg, register)
+	}
+
+	return c.compileCall(identifier, args, evaluators)
+}
+
+func (c *compiler) compileCall(fun ast.Identifier, args []ast.Expression, evaluators []functions.Evaluator) functions.Evaluator {
+	// Updating the document or variables is rare enough to not warrant
+	// duplicating the logic here.
+	if fun.Bang {
+		return func(ctx types.Context) (any, error) {
+			return c.interpreter.CallFunction(ctx, fun, args)
+		}
+	}
+
+	event := types.TraceEvent{Kind: types.CallEvent, Expression: fun, Arguments: args}
+	funcName := fun.FullName()
+
+	// functions not known at compile time (e.g. functions defined using
+	// `func!`) have to be looked up during evaluation
+	function, ok := c.funcs.Get(funcName)
+	if !ok {
+		return traced(event, func(ctx types.Context) (any, error) {
+			function, ok := ctx.GetFunction(funcName)
+			if !ok {
+				return nil, fmt.Errorf("unknown function %s", funcName)
+			}
+
+			result, err := function.Evaluate(ctx, args)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", funcName, err)
+			}
+
+			return result, nil
+		})
+	}
+
+	call := func(ctx types.Context, args []ast.Expression, _ []functions.Evaluator) (any, error) {
+		return function.Evaluate(ctx, args)
+	}
+
+	if compiled, ok := function.(functions.CompiledFunction); ok {
+		call = compiled.Compile(len(args))
+	}
+
+	return traced(event, func(ctx types.Context) (any, error) {
+		result, err := call(ctx, args, evaluators)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", funcName, err)
+		}
+
+		return result, nil
+	})
+}
+
+// compilePath returns a function that behaves like pathexpr.Apply(). Paths
+// consisting only of constant steps are pre-evaluated.
+func (c *compiler) compilePath(path *ast.PathExpression, register bool) pathFunc {
+	if path == nil {
+		return func(_ types.Context, value any) (any, error) {
+			return value, nil
+		}
+	}
+
+	if evaluated, ok := constantPath(path); ok {
+		return func(_ types.Context, value any) (any, error) {
+			return pathexpr.Traverse(value, evaluated)
+		}
+	}
+
+	// compile the steps, so they are available to pathexpr.Eval()
+	for _, step := range path.Steps {
+		if _, ok := step.(ast.Identifier); !ok {
+			c.compile(step, register)
+		}
+	}
+
+	return func(ctx types.Context, value any) (any, error) {
+		return pathexpr.Apply(ctx, value, path)
+	}
+}
+
+func constantPath(path *ast.PathExpression) (ast.EvaluatedPathExpression, bool) {
+	result := ast.EvaluatedPathExpression{
+		Steps: make([]ast.EvaluatedPathStep, len(path.Steps)),
+	}
+
+	for i, step := range path.Steps {
+		switch asserted := step.(type) {
+		case ast.Identifier:
+			result.Steps[i] = ast.EvaluatedPathStep{StringValue: &asserted.Name}
+		case ast.String:
+			s := string(asserted)
+			result.Steps[i] = ast.EvaluatedPathStep{StringValue: &s}
+		case ast.Number:
+			i64, ok := asserted.Value.(int64)
+			if !ok {
+				return result, false
+			}
+			result.Steps[i] = ast.EvaluatedPathStep{IntegerValue: &i64}
+		default:
+			return result, false
+		}
+	}
+
+	return result, true
+}
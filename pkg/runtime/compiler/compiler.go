@@ -0,0 +1,205 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+// Package compiler implements a Rudi runtime that compiles programs into a
+// tree of Go closures before evaluating them. Compiling a program happens
+// once, on its first evaluation, so that repeated evaluations of the same
+// program do not have to walk and inspect the AST anymore.
+//
+// While compiling, function lookups are resolved, constant path expressions
+// are pre-evaluated and literal vectors/objects are pre-computed. The result
+// of evaluating a program is identical to the interpreter.
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/runtime/functions"
+	"go.xrstf.de/rudi/pkg/runtime/interpreter"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+)
+
+type compiler struct {
+	// funcs are the functions that are resolved at compile time.
+	funcs types.Functions
+
+	// interpreter is used for function calls with bang modifiers and for
+	// the trivial expressions.
+	interpreter types.Runtime
+
+	// programs maps *ast.Program to []functions.Evaluator.
+	programs sync.Map
+
+	// nodes maps nodeKeys to functions.Evaluator; it contains all compiled
+	// container nodes, so that functions which evaluate their argument
+	// expressions themselves still use the compiled code.
+	nodes sync.Map
+}
+
+var _ types.Runtime = &compiler{}
+
+// New returns a new compiling runtime. The given functions are resolved when
+// compiling a program, so all contexts used with this runtime must be created
+// using the same set of functions. Functions not contained in funcs (like
+// functions defined in Rudi code) are looked up during evaluation.
+//
+// Compiled programs are cached for the lifetime of the runtime, so a runtime
+// should be reused for evaluating the same programs many times, but not for
+// evaluating an ever-growing number of different programs.
+func New(funcs types.Functions) types.Runtime {
+	return &compiler{
+		funcs:       funcs,
+		interpreter: interpreter.New(),
+	}
+}
+
+func (c *compiler) EvalProgram(ctx types.Context, p *ast.Program) (any, error) {
+	if p == nil {
+		return nil, errors.New("program is nil")
+	}
+
+	if len(p.Statements) == 0 {
+		return nil, nil
+	}
+
+	statements := c.compileProgram(p)
+	scope := ctx.NewScope()
+
+	var (
+		result any
+		err    error
+	)
+
+	for i, stmt := range statements {
+		result, err = stmt(scope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to eval statement %s: %w", p.Statements[i].String(), err)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *compiler) compileProgram(p *ast.Program) []functions.Evaluator {
+	if compiled, ok := c.programs.Load(p); ok {
+		return compiled.([]functions.Evaluator)
+	}
+
+	statements := make([]functions.Evaluator, len(p.Statements))
+	for i, stmt := range p.Statements {
+		statements[i] = traced(types.TraceEvent{Kind: types.StatementEvent, Expression: stmt}, c.compile(stmt.Expression, true))
+	}
+
+	compiled, _ := c.programs.LoadOrStore(p, statements)
+
+	return compiled.([]functions.Evaluator)
+}
+
+func (c *compiler) EvalStatement(ctx types.Context, stmt ast.Statement) (any, error) {
+	if ctx.Tracer() != nil {
+		return types.Trace(ctx, types.TraceEvent{Kind: types.StatementEvent, Expression: stmt}, func() (any, error) {
+			return c.EvalExpression(ctx, stmt.Expression)
+		})
+	}
+
+	return c.EvalExpression(ctx, stmt.Expression)
+}
+
+func (c *compiler) EvalExpression(ctx types.Context, expr ast.Expression) (any, error) {
+	if key, ok := keyOf(expr); ok {
+		if compiled, ok := c.nodes.Load(key); ok {
+			return compiled.(functions.Evaluator)(ctx)
+		}
+	}
+
+	// Expressions that are not part of a compiled program (e.g. those constructed
+	// at runtime by functions) are compiled on the fly, but not cached.
+	return c.compile(expr, false)(ctx)
+}
+
+func (c *compiler) EvalSymbol(ctx types.Context, sym ast.Symbol) (any, error) {
+	return c.EvalExpression(ctx, sym)
+}
+
+func (c *compiler) EvalVectorNode(ctx types.Context, vec ast.VectorNode) (any, error) {
+	return c.EvalExpression(ctx, vec)
+}
+
+func (c *compiler) EvalObjectNode(ctx types.Context, obj ast.ObjectNode) (any, error) {
+	return c.EvalExpression(ctx, obj)
+}
+
+func (c *compiler) EvalTuple(ctx types.Context, tup ast.Tuple) (any, error) {
+	return c.EvalExpression(ctx, tup)
+}
+
+func (c *compiler) EvalNull(ctx types.Context, n ast.Null) (any, error) {
+	return c.interpreter.EvalNull(ctx, n)
+}
+
+func (c *compiler) EvalBool(ctx types.Context, b ast.Bool) (any, error) {
+	return c.interpreter.EvalBool(ctx, b)
+}
+
+func (c *compiler) EvalNumber(ctx types.Context, n ast.Number) (any, error) {
+	return c.interpreter.EvalNumber(ctx, n)
+}
+
+func (c *compiler) EvalString(ctx types.Context, str ast.String) (any, error) {
+	return c.interpreter.EvalString(ctx, str)
+}
+
+func (c *compiler) EvalIdentifier(ctx types.Context, ident ast.Identifier) (any, error) {
+	return c.interpreter.EvalIdentifier(ctx, ident)
+}
+
+func (c *compiler) CallFunction(ctx types.Context, fun ast.Identifier, args []ast.Expression) (any, error) {
+	return c.interpreter.CallFunction(ctx, fun, args)
+}
+
+type nodeKind int
+
+const (
+	kindTuple nodeKind = iota
+	kindVector
+	kindObject
+	kindSymbol
+)
+
+// nodeKey identifies a container node in a program. Since AST nodes are
+// passed around by value, the address of their first element is used to
+// identify them. The length and path expression are part of the key, so that
+// the shortened or pathless copies created by some functions are not mistaken
+// for the original node.
+type nodeKey struct {
+	kind   nodeKind
+	items  any
+	length int
+	path   *ast.PathExpression
+}
+
+func keyOf(expr ast.Expression) (nodeKey, bool) {
+	switch asserted := expr.(type) {
+	case ast.Tuple:
+		if len(asserted.Expressions) > 0 {
+			return nodeKey{kind: kindTuple, items: &asserted.Expressions[0], length: len(asserted.Expressions), path: asserted.PathExpression}, true
+		}
+	case ast.VectorNode:
+		if len(asserted.Expressions) > 0 {
+			return nodeKey{kind: kindVector, items: &asserted.Expressions[0], length: len(asserted.Expressions), path: asserted.PathExpression}, true
+		}
+	case ast.ObjectNode:
+		if len(asserted.Data) > 0 {
+			return nodeKey{kind: kindObject, items: &asserted.Data[0], length: len(asserted.Data), path: asserted.PathExpression}, true
+		}
+	case ast.Symbol:
+		if asserted.Variable != nil || asserted.PathExpression != nil {
+			return nodeKey{kind: kindSymbol, items: asserted.Variable, path: asserted.PathExpression}, true
+		}
+	}
+
+	return nodeKey{}, false
+}
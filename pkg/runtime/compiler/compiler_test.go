@@ -0,0 +1,426 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package compiler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.xrstf.de/rudi/pkg/builtin"
+	"go.xrstf.de/rudi/pkg/deepcopy"
+	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/lang/parser"
+	"go.xrstf.de/rudi/pkg/runtime/interpreter"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func parse(t testing.TB, script string) *ast.Program {
+	got, err := parser.Parse("test", []byte(script))
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", script, err)
+	}
+
+	program, ok := got.(ast.Program)
+	if !ok {
+		t.Fatalf("Parsed result is not a ast.Program, but %T", got)
+	}
+
+	return &program
+}
+
+func newContext(t testing.TB, runtime types.Runtime, funcs types.Functions, data any) types.Context {
+	doc, err := types.NewDocument(deepcopy.MustClone(data))
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+
+	ctx, err := types.NewContext(runtime, context.Background(), doc, types.Variables{"myvar": int64(42)}, funcs, nil)
+	if err != nil {
+		t.Fatalf("Failed to create context: %v", err)
+	}
+
+	return ctx
+}
+
+type outcome struct {
+	Result   any
+	Document any
+	Error    string
+}
+
+func run(t testing.TB, runtime types.Runtime, funcs types.Functions, program *ast.Program, data any) outcome {
+	ctx := newContext(t, runtime, funcs, data)
+
+	result, err := runtime.EvalProgram(ctx, program)
+	if err != nil {
+		return outcome{Error: err.Error()}
+	}
+
+	return outcome{
+		Result:   result,
+		Document: ctx.GetDocument().Data(),
+	}
+}
+
+func testDocument() any {
+	return map[string]any{
+		"name":  "rudi",
+		"items": []any{int64(1), int64(2), int64(3)},
+		"spec": map[string]any{
+			"replicas": int64(3),
+			"labels":   map[string]any{"app": "test"},
+		},
+	}
+}
+
+func TestCompilerMatchesInterpreter(t *testing.T) {
+	funcs := builtin.SafeFunctions.DeepCopy().Add(builtin.UnsafeFunctions)
+
+	testcases := []string{
+		`null`,
+		`true`,
+		`"foo"`,
+		`42`,
+		`1.5`,
+		`.`,
+		`.name`,
+		`.items[1]`,
+		`.spec.labels.app`,
+		`.spec["labels"]`,
+		`.items[(add 1 1)]`,
+		`.items[1.5]`,
+		`.missing`,
+		`$myvar`,
+		`$unknown`,
+		`[]`,
+		`{}`,
+		`[1 2 [3 4]]`,
+		`[1 2 [3 4]][2][0]`,
+		`{foo "bar" "baz" [1 2]}`,
+		`{foo "bar"}.foo`,
+		`{foo .name}`,
+		`{(concat "" "a" "b") 1}`,
+		`{foo! 1}`,
+		`[.name $myvar]`,
+		`(add 1 2)`,
+		`(add 1 "2")`,
+		`(unknown 1)`,
+		`(if true "yes" "no")`,
+		`(if (eq? .name "rudi") .spec.replicas 0)`,
+		`(set! .spec.replicas 5) .spec`,
+		`(set! $foo [1 2]) (append! $foo 3) $foo`,
+		`(delete! .spec.labels)`,
+		`(map .items to-string)`,
+		`(map .items [i v] (add $i $v))`,
+		`(range .spec.labels [k v] (set! $last $k)) $last`,
+		`(filter .items [v] (gt? $v 1))`,
+		`(default .missing "fallback")`,
+		`(try (add "a" 1) "caught")`,
+		`(do (set! $x 1) (set! $x (add $x 1)) $x)`,
+		`(to-string 1)`,
+		`(humanely (add "1" 2))`,
+		`(func! inc [n] (add $n 1)) (inc (inc 1))`,
+		`(len (concat "," "a" "b"))`,
+		`(append [1 2] 3)`,
+		`(keys .spec)`,
+		`(add 1 2).foo`,
+		`(set! .items[0] {a 1}) .items`,
+		`[1 2 3][5]`,
+		`.items[*]`,
+		`.items[-1]`,
+		`.items[1:]`,
+		`.items[?(gt? . 1)]`,
+		`.spec[*]`,
+		`..app`,
+		`(set! .items[*] 0) .items`,
+		`(append! .spec.labels[*] "x")`,
+		`(delete! .items[:-1])`,
+		`.missing?.foo`,
+		`.items[9]?`,
+		`.spec?[(add 0 0)]?`,
+		`(has? .spec.missing)`,
+		`(default .missing 5)`,
+	}
+
+	for _, script := range testcases {
+		t.Run(script, func(t *testing.T) {
+			program := parse(t, script)
+
+			expected := run(t, interpreter.New(), funcs, program, testDocument())
+
+			// evaluate multiple times, to ensure the compiled program is
+			// not affected by previous evaluations
+			compiler := New(funcs)
+			for i := 0; i < 3; i++ {
+				actual := run(t, compiler, funcs, program, testDocument())
+
+				if !cmp.Equal(expected, actual) {
+					t.Fatalf("Compiled program returned a different outcome (run %d):\n\n%s", i+1, cmp.Diff(expected, actual))
+				}
+			}
+		})
+	}
+}
+
+func TestCompilerDoesNotLeakLiterals(t *testing.T) {
+	funcs := builtin.SafeFunctions.DeepCopy()
+	compiler := New(funcs)
+
+	testcases := []struct {
+		script   string
+		expected any
+	}{
+		{
+			script:   `(set! $v [1 2]) (set! $v[0] 3) $v`,
+			expected: []any{int64(3), int64(2)},
+		},
+		{
+			script:   `(set! $v [1 [2]]) (append! $v[1] 3) $v`,
+			expected: []any{int64(1), []any{int64(2), int64(3)}},
+		},
+		{
+			script:   `(set! $v {a {b 1}}) (set! $v.a.c 2) (delete! $v.a.b) $v`,
+			expected: map[string]any{"a": map[string]any{"c": int64(2)}},
+		},
+		{
+			script:   `(set! . {a [1]}) (set! .a[0] 2) .`,
+			expected: map[string]any{"a": []any{int64(2)}},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.script, func(t *testing.T) {
+			program := parse(t, testcase.script)
+
+			// literals are shared between evaluations, so modifying them
+			// in the first run must not affect the second one
+			for i := 0; i < 2; i++ {
+				outcome := run(t, compiler, funcs, program, nil)
+				if outcome.Error != "" {
+					t.Fatalf("Failed to run program: %s", outcome.Error)
+				}
+
+				if !cmp.Equal(testcase.expected, outcome.Result) {
+					t.Fatalf("Expected %v, got %v", testcase.expected, outcome.Result)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderedObjects(t *testing.T) {
+	funcs := builtin.SafeFunctions.DeepCopy()
+
+	testcases := map[string]string{
+		`{z 1 a 2}`:                        `{"z":1,"a":2}`,
+		`{z 1 a [{y 2 b 3}] m (+ 1 2)}`:    `{"z":1,"a":[{"y":2,"b":3}],"m":3}`,
+		`(set! $o {z 1 a 2}) (set $o.b 3)`: `{"z":1,"a":2,"b":3}`,
+		`{z 1 a {y 2 b 3}}.a`:              `{"y":2,"b":3}`,
+	}
+
+	runtimes := map[string]types.Runtime{
+		"interpreter": interpreter.New(),
+		"compiler":    New(funcs),
+	}
+
+	for script, expected := range testcases {
+		program := parse(t, script)
+
+		for name, runtime := range runtimes {
+			ctx := newContext(t, runtime, funcs, nil).WithOrderedObjects(true)
+
+			result, err := runtime.EvalProgram(ctx, program)
+			if err != nil {
+				t.Fatalf("%s: failed to run %s: %v", name, script, err)
+			}
+
+			encoded, err := json.Marshal(result)
+			if err != nil {
+				t.Fatalf("%s: failed to encode result: %v", name, err)
+			}
+
+			if string(encoded) != expected {
+				t.Errorf("%s: expected %s to return %s, got %s", name, script, expected, encoded)
+			}
+		}
+	}
+}
+
+type recordingTracer struct {
+	events []string
+	abort  string
+}
+
+func (r *recordingTracer) Enter(_ types.Context, event types.TraceEvent) error {
+	r.events = append(r.events, fmt.Sprintf("enter %s %s", event.Kind, event.Expression))
+
+	if event.Expression.String() == r.abort {
+		return errors.New("aborted")
+	}
+
+	return nil
+}
+
+func (r *recordingTracer) Exit(_ types.Context, event types.TraceEvent) {
+	r.events = append(r.events, fmt.Sprintf("exit %s %s = %v (%v)", event.Kind, event.Expression, event.Result, event.Error))
+}
+
+func trace(t testing.TB, runtime types.Runtime, funcs types.Functions, program *ast.Program, abort string) ([]string, error) {
+	tracer := &recordingTracer{abort: abort}
+	ctx := newContext(t, runtime, funcs, testDocument()).WithTracer(tracer)
+
+	_, err := runtime.EvalProgram(ctx, program)
+
+	return tracer.events, err
+}
+
+func TestCompilerEmitsSameTraceEvents(t *testing.T) {
+	funcs := builtin.SafeFunctions.DeepCopy()
+
+	testcases := []struct {
+		script string
+		abort  string
+	}{
+		{script: `(add 1 .spec.replicas)`},
+		{script: `(set! $x (map .items to-string)) $x[0]`},
+		{script: `(if (eq? .name "rudi") (len .items) $unknown)`},
+		{script: `(try (add "a" 1) "caught").foo`},
+		{script: `(add 1 (len .name))`, abort: `.name`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.script, func(t *testing.T) {
+			program := parse(t, tc.script)
+
+			expected, expectedErr := trace(t, interpreter.New(), funcs, program, tc.abort)
+			if len(expected) == 0 {
+				t.Fatal("Interpreter did not emit any trace events.")
+			}
+
+			actual, actualErr := trace(t, New(funcs), funcs, program, tc.abort)
+
+			if !cmp.Equal(expected, actual) {
+				t.Fatalf("Compiled program emitted different events:\n\n%s", cmp.Diff(expected, actual))
+			}
+
+			if fmt.Sprint(expectedErr) != fmt.Sprint(actualErr) {
+				t.Fatalf("Expected error %v, got %v", expectedErr, actualErr)
+			}
+
+			if tc.abort != "" && expectedErr == nil {
+				t.Fatal("Expected tracer to abort the evaluation.")
+			}
+		})
+	}
+}
+
+var benchmarkScripts = map[string]string{
+	"arithmetic": `(add 1 (mult 2 3) (sub 10 4))`,
+	"paths":      `[.spec.replicas .spec.labels.app .items[2] .name]`,
+	"literals":   `(len [1 2 3 {foo "bar" baz [4 5 6]}])`,
+	"loop":       `(map .items [v] (if (gt? $v 1) (mult $v 2) $v))`,
+	"objects":    `(set! .spec.replicas (add .spec.replicas 1)) {name .name replicas .spec.replicas}`,
+}
+
+func benchmarkRuntime(b *testing.B, newRuntime func(types.Functions) types.Runtime) {
+	funcs := builtin.SafeFunctions.DeepCopy()
+
+	for name, script := range benchmarkScripts {
+		b.Run(name, func(b *testing.B) {
+			program := parse(b, script)
+			runtime := newRuntime(funcs)
+			ctx := newContext(b, runtime, funcs, testDocument())
+
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := runtime.EvalProgram(ctx, program); err != nil {
+					b.Fatal(fmt.Errorf("failed to run program: %w", err))
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkInterpreter(b *testing.B) {
+	benchmarkRuntime(b, func(types.Functions) types.Runtime {
+		return interpreter.New()
+	})
+}
+
+func BenchmarkCompiler(b *testing.B) {
+	benchmarkRuntime(b, New)
+}
+
+func BenchmarkLargeLiteral(b *testing.B) {
+	items := make([]string, 1000)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{name "item-%d" labels {app "rudi"} ports [80 443]}`, i)
+	}
+
+	funcs := builtin.SafeFunctions.DeepCopy()
+	program := parse(b, fmt.Sprintf(`(len [%s])`, strings.Join(items, " ")))
+
+	runtime := New(funcs)
+	ctx := newContext(b, runtime, funcs, nil)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := runtime.EvalProgram(ctx, program); err != nil {
+			b.Fatal(fmt.Errorf("failed to run program: %w", err))
+		}
+	}
+}
+
+// largeDocument returns a document with roughly 5 MB of JSON.
+func largeDocument() any {
+	items := make([]any, 50000)
+	for i := range items {
+		items[i] = map[string]any{
+			"name": fmt.Sprintf("item-%d", i),
+			"spec": map[string]any{
+				"image":    "example.com/image:latest",
+				"replicas": int64(i),
+				"labels":   map[string]any{"app": "rudi"},
+			},
+		}
+	}
+
+	return map[string]any{
+		"items": items,
+		"spec":  map[string]any{"replicas": int64(1)},
+	}
+}
+
+func BenchmarkLargeDocument(b *testing.B) {
+	funcs := builtin.SafeFunctions.DeepCopy()
+	program := parse(b, `(set! .spec.replicas 2) (set! .items[100].spec.labels.tier "backend") (delete! .items[200].spec.image)`)
+
+	runtimes := map[string]func(types.Functions) types.Runtime{
+		"interpreter": func(types.Functions) types.Runtime { return interpreter.New() },
+		"compiler":    New,
+	}
+
+	for name, newRuntime := range runtimes {
+		b.Run(name, func(b *testing.B) {
+			runtime := newRuntime(funcs)
+			ctx := newContext(b, runtime, funcs, largeDocument())
+
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := runtime.EvalProgram(ctx, program); err != nil {
+					b.Fatal(fmt.Errorf("failed to run program: %w", err))
+				}
+			}
+		})
+	}
+}
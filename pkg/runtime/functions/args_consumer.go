@@ -73,130 +73,51 @@ func newConsumerFunc(t reflect.Type) (consumer argsConsumer, argsConsumed int) {
 	return nil, 0
 }
 
-func boolConsumer(ctx types.Context, args []cachedExpression) (asserted []any, remaining []cachedExpression, err error) {
+// coalescingConsumer evaluates the first argument and coalesces it into the
+// desired type. If coalescing fails, the argument is not consumed.
+func coalescingConsumer(ctx types.Context, args []cachedExpression, kind coalesceKind) (asserted []any, remaining []cachedExpression, err error) {
 	if len(args) == 0 {
 		return nil, nil, nil
 	}
 
-	evaluated, err := args[0].Eval(ctx)
+	coalesced, ok, err := args[0].Coalesce(ctx, kind)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	coalesced, err := ctx.Coalesce().ToBool(evaluated)
-	if err != nil {
+	if !ok {
 		return nil, args, nil
 	}
 
 	return []any{coalesced}, args[1:], nil
 }
 
-func intConsumer(ctx types.Context, args []cachedExpression) (asserted []any, remaining []cachedExpression, err error) {
-	if len(args) == 0 {
-		return nil, nil, nil
-	}
-
-	evaluated, err := args[0].Eval(ctx)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	coalesced, err := ctx.Coalesce().ToInt64(evaluated)
-	if err != nil {
-		return nil, args, nil
-	}
+func boolConsumer(ctx types.Context, args []cachedExpression) (asserted []any, remaining []cachedExpression, err error) {
+	return coalescingConsumer(ctx, args, coalesceToBool)
+}
 
-	return []any{coalesced}, args[1:], nil
+func intConsumer(ctx types.Context, args []cachedExpression) (asserted []any, remaining []cachedExpression, err error) {
+	return coalescingConsumer(ctx, args, coalesceToInt64)
 }
 
 func floatConsumer(ctx types.Context, args []cachedExpression) (asserted []any, remaining []cachedExpression, err error) {
-	if len(args) == 0 {
-		return nil, nil, nil
-	}
-
-	evaluated, err := args[0].Eval(ctx)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	coalesced, err := ctx.Coalesce().ToFloat64(evaluated)
-	if err != nil {
-		return nil, args, nil
-	}
-
-	return []any{coalesced}, args[1:], nil
+	return coalescingConsumer(ctx, args, coalesceToFloat64)
 }
 
 func numberConsumer(ctx types.Context, args []cachedExpression) (asserted []any, remaining []cachedExpression, err error) {
-	if len(args) == 0 {
-		return nil, nil, nil
-	}
-
-	evaluated, err := args[0].Eval(ctx)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	coalesced, err := ctx.Coalesce().ToNumber(evaluated)
-	if err != nil {
-		return nil, args, nil
-	}
-
-	return []any{coalesced}, args[1:], nil
+	return coalescingConsumer(ctx, args, coalesceToNumber)
 }
 
 func stringConsumer(ctx types.Context, args []cachedExpression) (asserted []any, remaining []cachedExpression, err error) {
-	if len(args) == 0 {
-		return nil, nil, nil
-	}
-
-	evaluated, err := args[0].Eval(ctx)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	coalesced, err := ctx.Coalesce().ToString(evaluated)
-	if err != nil {
-		return nil, args, nil
-	}
-
-	return []any{coalesced}, args[1:], nil
+	return coalescingConsumer(ctx, args, coalesceToString)
 }
 
 func vectorConsumer(ctx types.Context, args []cachedExpression) (asserted []any, remaining []cachedExpression, err error) {
-	if len(args) == 0 {
-		return nil, nil, nil
-	}
-
-	evaluated, err := args[0].Eval(ctx)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	coalesced, err := ctx.Coalesce().ToVector(evaluated)
-	if err != nil {
-		return nil, args, nil
-	}
-
-	return []any{coalesced}, args[1:], nil
+	return coalescingConsumer(ctx, args, coalesceToVector)
 }
 
 func objectConsumer(ctx types.Context, args []cachedExpression) (asserted []any, remaining []cachedExpression, err error) {
-	if len(args) == 0 {
-		return nil, nil, nil
-	}
-
-	evaluated, err := args[0].Eval(ctx)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	coalesced, err := ctx.Coalesce().ToObject(evaluated)
-	if err != nil {
-		return nil, args, nil
-	}
-
-	return []any{coalesced}, args[1:], nil
+	return coalescingConsumer(ctx, args, coalesceToObject)
 }
 
 func anyConsumer(ctx types.Context, args []cachedExpression) (asserted []any, remaining []cachedExpression, err error) {
@@ -235,7 +156,7 @@ func toVariadicConsumer(singleConsumer argsConsumer) argsConsumer {
 		}
 
 		leftover := args
-		result := []any{}
+		result := make([]any, 0, len(args))
 
 		for len(leftover) > 0 {
 			var (
@@ -109,7 +109,7 @@ func (c *argsMatcher) Match(ctx types.Context, args []cachedExpression) ([]any,
 		return nil, false, nil
 	}
 
-	result := []any{}
+	result := make([]any, 0, len(c.consumers)+len(args))
 	remaining := args
 
 	// Run each consumer func in succession, making each consume as many args as it wants.
@@ -11,10 +11,12 @@ import (
 )
 
 type Builder struct {
-	forms       []form
-	coalescer   coalescing.Coalescer
-	bangHandler BangHandlerFunc
-	description string
+	forms        []form
+	coalescer    coalescing.Coalescer
+	bangHandler  BangHandlerFunc
+	bangFunction types.TupleFunction
+	description  string
+	pure         bool
 }
 
 func NewBuilder(forms ...any) *Builder {
@@ -49,13 +51,36 @@ func (b *Builder) WithCoalescer(c coalescing.Coalescer) *Builder {
 	return b
 }
 
+// Pure marks the function as pure, i.e. free of side effects and always returning
+// the same result for the same arguments. Only pure functions are evaluated ahead
+// of time by the optimizer.
+func (b *Builder) Pure() *Builder {
+	b.pure = true
+	return b
+}
+
 func (b *Builder) WithBangHandler(h BangHandlerFunc) *Builder {
 	b.bangHandler = h
 	return b
 }
 
+// WithBangFunction sets a function that is called instead of the regular forms
+// and the bang handler when the function is called with the bang modifier, see
+// types.BangFunction.
+func (b *Builder) WithBangFunction(f types.TupleFunction) *Builder {
+	b.bangFunction = f
+	return b
+}
+
 func (b *Builder) Build() types.Function {
-	f := newRegularFunction(b.forms, b.coalescer, b.description)
+	f := newRegularFunction(b.forms, b.coalescer, b.description, b.pure)
+
+	if b.bangFunction != nil {
+		return &bangFunction{
+			regularFunction: f,
+			bangFunction:    b.bangFunction,
+		}
+	}
 
 	if b.bangHandler != nil {
 		return &extendedFunction{
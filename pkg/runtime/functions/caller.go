@@ -0,0 +1,220 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package functions
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+)
+
+// caller calls a form's function with the already matched arguments.
+type caller func(args []any) (any, error)
+
+// newCaller returns a caller for the given function. Calling functions via
+// reflection is comparatively slow, so for the most common signatures, typed
+// adapters are used instead.
+func newCaller(fun any) caller {
+	switch f := fun.(type) {
+	case func() (any, error):
+		return func([]any) (any, error) { return f() }
+
+	// single argument
+
+	case func(any) (any, error):
+		return unary(f)
+	case func(bool) (any, error):
+		return unary(f)
+	case func(int64) (any, error):
+		return unary(f)
+	case func(float64) (any, error):
+		return unary(f)
+	case func(string) (any, error):
+		return unary(f)
+	case func(ast.Number) (any, error):
+		return unary(f)
+	case func([]any) (any, error):
+		return unary(f)
+	case func(map[string]any) (any, error):
+		return unary(f)
+
+	// two arguments
+
+	case func(any, any) (any, error):
+		return binary(f)
+	case func(string, string) (any, error):
+		return binary(f)
+	case func(types.Context, any) (any, error):
+		return binary(f)
+	case func(types.Context, ast.Expression) (any, error):
+		return binary(f)
+
+	// three arguments
+
+	case func(types.Context, any, any) (any, error):
+		return ternary(f)
+	case func(string, string, string) (any, error):
+		return ternary(f)
+	case func(types.Context, ast.Expression, ast.Expression) (any, error):
+		return ternary(f)
+
+	// variadic functions
+
+	case func(...any) (any, error):
+		return variadic(f)
+	case func(...bool) (any, error):
+		return variadic(f)
+	case func(types.Context, ...ast.Expression) (any, error):
+		return variadic1(f)
+	case func(any, ...any) (any, error):
+		return variadic1(f)
+	case func(bool, ...bool) (any, error):
+		return variadic1(f)
+	case func(int64, ...int64) (any, error):
+		return variadic1(f)
+	case func(float64, ...float64) (any, error):
+		return variadic1(f)
+	case func(string, ...string) (any, error):
+		return variadic1(f)
+	case func(ast.Number, ...ast.Number) (any, error):
+		return variadic1(f)
+	case func([]any, ...any) (any, error):
+		return variadic1(f)
+	}
+
+	return reflectCaller(fun)
+}
+
+// arg converts a matched argument to the function's parameter type. The
+// argsMatcher should already have ensured the correct types, so a failed
+// conversion indicates a mismatch between a form and its adapter. The only
+// legitimate case is a nil value, for which the zero value is used.
+func arg[T any](args []any, index int) (T, error) {
+	value := args[index]
+
+	converted, ok := value.(T)
+	if !ok && value != nil {
+		return converted, fmt.Errorf("argument #%d: expected %v, got %T", index, reflect.TypeOf((*T)(nil)).Elem(), value)
+	}
+
+	return converted, nil
+}
+
+func unary[A any](f func(A) (any, error)) caller {
+	return func(args []any) (any, error) {
+		a, err := arg[A](args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return f(a)
+	}
+}
+
+func binary[A, B any](f func(A, B) (any, error)) caller {
+	return func(args []any) (any, error) {
+		a, err := arg[A](args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := arg[B](args, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		return f(a, b)
+	}
+}
+
+func ternary[A, B, C any](f func(A, B, C) (any, error)) caller {
+	return func(args []any) (any, error) {
+		a, err := arg[A](args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := arg[B](args, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := arg[C](args, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		return f(a, b, c)
+	}
+}
+
+func variadic[V any](f func(...V) (any, error)) caller {
+	return func(args []any) (any, error) {
+		rest, err := variadicArgs[V](args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return f(rest...)
+	}
+}
+
+func variadic1[A, V any](f func(A, ...V) (any, error)) caller {
+	return func(args []any) (any, error) {
+		a, err := arg[A](args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		rest, err := variadicArgs[V](args, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		return f(a, rest...)
+	}
+}
+
+// variadicArgs converts all arguments starting at the given offset.
+func variadicArgs[V any](args []any, offset int) ([]V, error) {
+	result := make([]V, len(args)-offset)
+	for i := range result {
+		converted, err := arg[V](args, offset+i)
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = converted
+	}
+
+	return result, nil
+}
+
+func reflectCaller(fun any) caller {
+	funValue := reflect.ValueOf(fun)
+
+	return func(args []any) (any, error) {
+		reflectArgs := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			if arg == nil {
+				var e any
+				reflectArgs[i] = reflect.ValueOf(&e).Elem()
+			} else {
+				reflectArgs[i] = reflect.ValueOf(arg)
+			}
+		}
+
+		results := funValue.Call(reflectArgs)
+
+		// Forms can only be constructed with valid signatures,
+		// no need to check that 2 values were returned.
+		if err := results[1].Interface(); err != nil {
+			return nil, err.(error)
+		}
+
+		return results[0].Interface(), nil
+	}
+}
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package functions
+
+import (
+	"testing"
+)
+
+type customObject struct{}
+
+func TestCallerConvertsArguments(t *testing.T) {
+	call := newCaller(func(a string, b ...string) (any, error) {
+		return a + "-" + b[0] + b[1], nil
+	})
+
+	result, err := call([]any{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("Failed to call function: %v", err)
+	}
+
+	if result != "a-bc" {
+		t.Fatalf("Expected %q, got %v", "a-bc", result)
+	}
+}
+
+func TestCallerAcceptsNilForAny(t *testing.T) {
+	call := newCaller(func(a any) (any, error) {
+		return a == nil, nil
+	})
+
+	result, err := call([]any{nil})
+	if err != nil {
+		t.Fatalf("Failed to call function: %v", err)
+	}
+
+	if result != true {
+		t.Fatalf("Expected the function to receive nil, got %v", result)
+	}
+}
+
+func TestCallerRejectsMismatchedArguments(t *testing.T) {
+	testcases := []struct {
+		name string
+		fun  any
+		args []any
+	}{
+		{
+			name: "unary",
+			fun:  func(map[string]any) (any, error) { return nil, nil },
+			args: []any{customObject{}},
+		},
+		{
+			name: "binary",
+			fun:  func(string, string) (any, error) { return nil, nil },
+			args: []any{"a", 1},
+		},
+		{
+			name: "ternary",
+			fun:  func(string, string, string) (any, error) { return nil, nil },
+			args: []any{"a", "b", true},
+		},
+		{
+			name: "variadic",
+			fun:  func(string, ...string) (any, error) { return nil, nil },
+			args: []any{"a", "b", customObject{}},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newCaller(tc.fun)(tc.args)
+			if err == nil {
+				t.Fatal("Expected an error, but got none.")
+			}
+		})
+	}
+}
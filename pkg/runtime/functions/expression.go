@@ -16,8 +16,28 @@ type cachedExpression struct {
 	result    any
 	evaluated bool
 	expr      ast.Expression
+	evaluator Evaluator
+
+	// the result of the most recent coalescing, as multiple forms often
+	// expect the same type at the same position
+	coalesceKind coalesceKind
+	coalesced    any
+	coalesceOK   bool
 }
 
+type coalesceKind int
+
+const (
+	coalesceNothing coalesceKind = iota
+	coalesceToBool
+	coalesceToInt64
+	coalesceToFloat64
+	coalesceToNumber
+	coalesceToString
+	coalesceToVector
+	coalesceToObject
+)
+
 func convertArgs(args []ast.Expression) []cachedExpression {
 	result := make([]cachedExpression, len(args))
 	for i := range args {
@@ -26,9 +46,29 @@ func convertArgs(args []ast.Expression) []cachedExpression {
 	return result
 }
 
+// convertCompiledArgs is like convertArgs, but uses the given evaluators
+// instead of the runtime to evaluate the expressions.
+func convertCompiledArgs(args []ast.Expression, evaluators []Evaluator) []cachedExpression {
+	result := make([]cachedExpression, len(args))
+	for i := range args {
+		result[i] = cachedExpression{expr: args[i], evaluator: evaluators[i]}
+	}
+	return result
+}
+
 func (e *cachedExpression) Eval(ctx types.Context) (any, error) {
 	if !e.evaluated {
-		result, err := ctx.Runtime().EvalExpression(ctx, e.expr)
+		var (
+			result any
+			err    error
+		)
+
+		if e.evaluator != nil {
+			result, err = e.evaluator(ctx)
+		} else {
+			result, err = ctx.Runtime().EvalExpression(ctx, e.expr)
+		}
+
 		if err != nil {
 			return nil, err
 		}
@@ -39,3 +79,47 @@ func (e *cachedExpression) Eval(ctx types.Context) (any, error) {
 
 	return e.result, nil
 }
+
+// Coalesce evaluates the expression and converts the result into the given
+// kind. The bool return value indicates whether the conversion was
+// successful; an error is only returned if the evaluation failed.
+func (e *cachedExpression) Coalesce(ctx types.Context, kind coalesceKind) (any, bool, error) {
+	evaluated, err := e.Eval(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if e.coalesceKind == kind {
+		return e.coalesced, e.coalesceOK, nil
+	}
+
+	var (
+		coalesced any
+		coalescer = ctx.Coalesce()
+	)
+
+	switch kind {
+	case coalesceToBool:
+		coalesced, err = coalescer.ToBool(evaluated)
+	case coalesceToInt64:
+		coalesced, err = coalescer.ToInt64(evaluated)
+	case coalesceToFloat64:
+		coalesced, err = coalescer.ToFloat64(evaluated)
+	case coalesceToNumber:
+		coalesced, err = coalescer.ToNumber(evaluated)
+	case coalesceToString:
+		coalesced, err = coalescer.ToString(evaluated)
+	case coalesceToVector:
+		coalesced, err = coalescer.ToVector(evaluated)
+	case coalesceToObject:
+		coalesced, err = coalescer.ToObject(evaluated)
+	default:
+		coalesced = evaluated
+	}
+
+	e.coalesceKind = kind
+	e.coalesced = coalesced
+	e.coalesceOK = err == nil
+
+	return e.coalesced, e.coalesceOK, nil
+}
@@ -11,7 +11,8 @@ import (
 )
 
 type form struct {
-	fun any
+	fun    any
+	caller caller
 
 	matcher *argsMatcher
 
@@ -33,6 +34,7 @@ func newForm(fun any) (form, error) {
 
 	return form{
 		fun:     fun,
+		caller:  newCaller(fun),
 		matcher: matcher,
 	}, nil
 }
@@ -53,23 +55,5 @@ func (f *form) Match(ctx types.Context, args []cachedExpression) (bool, error) {
 }
 
 func (f *form) Call(ctx types.Context) (any, error) {
-	reflectArgs := make([]reflect.Value, len(f.args))
-	for i, arg := range f.args {
-		if arg == nil {
-			var e any
-			reflectArgs[i] = reflect.ValueOf(&e).Elem()
-		} else {
-			reflectArgs[i] = reflect.ValueOf(f.args[i])
-		}
-	}
-
-	results := reflect.ValueOf(f.fun).Call(reflectArgs)
-
-	// Forms can only be constructed with valid signatures,
-	// no need to check that 2 values were returned.
-	if err := results[1].Interface(); err != nil {
-		return nil, err.(error)
-	}
-
-	return results[0].Interface(), nil
+	return f.caller(f.args)
 }
@@ -12,19 +12,41 @@ import (
 	"go.xrstf.de/rudi/pkg/runtime/types"
 )
 
+// Evaluator is a pre-compiled expression.
+type Evaluator func(ctx types.Context) (any, error)
+
+// CompiledCall evaluates a function call with pre-compiled arguments.
+type CompiledCall func(ctx types.Context, args []ast.Expression, evaluators []Evaluator) (any, error)
+
+// CompiledFunction is implemented by all functions created using a Builder. It
+// allows runtimes to prepare calls to a function ahead of time.
+type CompiledFunction interface {
+	types.Function
+
+	// Compile prepares a call with the given number of arguments. Forms that
+	// cannot match this many arguments are ruled out right away. The returned
+	// call uses the given evaluators (one for each argument) instead of the
+	// runtime to evaluate the arguments, but still only evaluates arguments
+	// when needed.
+	Compile(numArgs int) CompiledCall
+}
+
 type regularFunction struct {
 	forms       []form
 	coalescer   coalescing.Coalescer
 	description string
+	pure        bool
 }
 
-var _ types.Function = &regularFunction{}
+var _ CompiledFunction = &regularFunction{}
+var _ types.PureFunction = &regularFunction{}
 
-func newRegularFunction(forms []form, coalescer coalescing.Coalescer, description string) regularFunction {
+func newRegularFunction(forms []form, coalescer coalescing.Coalescer, description string, pure bool) regularFunction {
 	return regularFunction{
 		forms:       forms,
 		coalescer:   coalescer,
 		description: description,
+		pure:        pure,
 	}
 }
 
@@ -32,6 +54,10 @@ func (b *regularFunction) Description() string {
 	return b.description
 }
 
+func (b *regularFunction) IsPure() bool {
+	return b.pure
+}
+
 func (b *regularFunction) Evaluate(ctx types.Context, args []ast.Expression) (any, error) {
 	cachedArgs := convertArgs(args)
 
@@ -53,6 +79,39 @@ func (b *regularFunction) Evaluate(ctx types.Context, args []ast.Expression) (an
 	return nil, errors.New("none of the available forms matched the given expressions")
 }
 
+func (b *regularFunction) Compile(numArgs int) CompiledCall {
+	// remember the original index of each form for the error messages
+	indices := []int{}
+	for i, form := range b.forms {
+		if form.matcher.matchArgCount(numArgs) {
+			indices = append(indices, i)
+		}
+	}
+
+	return func(ctx types.Context, args []ast.Expression, evaluators []Evaluator) (any, error) {
+		cachedArgs := convertCompiledArgs(args, evaluators)
+
+		if b.coalescer != nil {
+			ctx = ctx.WithCoalescer(b.coalescer)
+		}
+
+		for _, i := range indices {
+			form := b.forms[i]
+
+			matched, err := form.Match(ctx, cachedArgs)
+			if err != nil {
+				return nil, fmt.Errorf("form#%d: %w", i, err)
+			}
+
+			if matched {
+				return form.Call(ctx)
+			}
+		}
+
+		return nil, errors.New("none of the available forms matched the given expressions")
+	}
+}
+
 type BangHandlerFunc func(ctx types.Context, originalArgs []ast.Expression, value any) (any, error)
 
 type extendedFunction struct {
@@ -69,3 +128,18 @@ var (
 func (f *extendedFunction) BangHandler(ctx types.Context, originalArgs []ast.Expression, value any) (any, error) {
 	return f.bangHandler(ctx, originalArgs, value)
 }
+
+type bangFunction struct {
+	regularFunction
+
+	bangFunction types.TupleFunction
+}
+
+var (
+	_ types.Function     = &bangFunction{}
+	_ types.BangFunction = &bangFunction{}
+)
+
+func (f *bangFunction) EvaluateBang(ctx types.Context, args []ast.Expression) (any, error) {
+	return f.bangFunction(ctx, args)
+}
@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package functions
+
+import (
+	"context"
+	"testing"
+
+	"go.xrstf.de/rudi/pkg/coalescing"
+	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/runtime/interpreter"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+)
+
+type countingCoalescer struct {
+	coalescing.Coalescer
+
+	calls int
+}
+
+func (c *countingCoalescer) ToString(val any) (string, error) {
+	c.calls++
+	return c.Coalescer.ToString(val)
+}
+
+func TestFormsReuseCoalescedArguments(t *testing.T) {
+	fun := NewBuilder(
+		func(a string, b int64) (any, error) { return "int", nil },
+		func(a string, b bool) (any, error) { return "bool", nil },
+		func(a string, b string) (any, error) { return "string", nil },
+	).Build()
+
+	coalescer := &countingCoalescer{Coalescer: coalescing.NewStrict()}
+
+	ctx, err := types.NewContext(interpreter.New(), context.Background(), types.Document{}, nil, nil, coalescer)
+	if err != nil {
+		t.Fatalf("Failed to create context: %v", err)
+	}
+
+	result, err := fun.Evaluate(ctx, []ast.Expression{ast.String("a"), ast.String("b")})
+	if err != nil {
+		t.Fatalf("Failed to evaluate function: %v", err)
+	}
+
+	if result != "string" {
+		t.Fatalf("Expected third form to match, but got %v", result)
+	}
+
+	// first argument is coalesced once, second argument once in the third form
+	if coalescer.calls != 2 {
+		t.Fatalf("Expected 2 calls to ToString, but got %d", coalescer.calls)
+	}
+}
+
+// The following functions mimic some of the built-in functions, which cannot
+// be imported here.
+
+func benchIntegerAdd(base int64, extra ...int64) (any, error) {
+	for _, num := range extra {
+		base += num
+	}
+
+	return base, nil
+}
+
+func benchNumberAdd(base ast.Number, extra ...ast.Number) (any, error) {
+	sum := base.MustToFloat()
+	for _, num := range extra {
+		sum += num.MustToFloat()
+	}
+
+	return sum, nil
+}
+
+func benchEqual(ctx types.Context, left, right any) (any, error) {
+	return left == right, nil
+}
+
+func benchStringLen(s string) (any, error) {
+	return len(s), nil
+}
+
+func benchVectorLen(vec []any) (any, error) {
+	return len(vec), nil
+}
+
+func benchObjectLen(obj map[string]any) (any, error) {
+	return len(obj), nil
+}
+
+func BenchmarkFunctions(b *testing.B) {
+	add := NewBuilder(benchIntegerAdd, benchNumberAdd).Build()
+	eq := NewBuilder(benchEqual).Build()
+	length := NewBuilder(benchStringLen, benchVectorLen, benchObjectLen).Build()
+
+	benchmarks := []struct {
+		name string
+		fun  types.Function
+		args []ast.Expression
+	}{
+		{
+			name: "add ints",
+			fun:  add,
+			args: []ast.Expression{ast.Number{Value: int64(1)}, ast.Number{Value: int64(2)}, ast.Number{Value: int64(3)}},
+		},
+		{
+			name: "add floats",
+			fun:  add,
+			args: []ast.Expression{ast.Number{Value: int64(1)}, ast.Number{Value: 2.5}, ast.Number{Value: int64(3)}},
+		},
+		{
+			name: "eq",
+			fun:  eq,
+			args: []ast.Expression{ast.String("foo"), ast.String("bar")},
+		},
+		{
+			name: "len of string",
+			fun:  length,
+			args: []ast.Expression{ast.String("foo")},
+		},
+		{
+			name: "len of object",
+			fun:  length,
+			args: []ast.Expression{ast.ObjectNode{Data: []ast.KeyValuePair{{Key: ast.String("foo"), Value: ast.Null{}}}}},
+		},
+	}
+
+	ctx, err := types.NewContext(interpreter.New(), context.Background(), types.Document{}, nil, nil, nil)
+	if err != nil {
+		b.Fatalf("Failed to create context: %v", err)
+	}
+
+	for _, bb := range benchmarks {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := bb.fun.Evaluate(ctx, bb.args); err != nil {
+					b.Fatalf("Failed to evaluate function: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -8,19 +8,25 @@ import (
 	"fmt"
 
 	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/ordered"
 	"go.xrstf.de/rudi/pkg/runtime/pathexpr"
 	"go.xrstf.de/rudi/pkg/runtime/types"
 )
 
 func (i *interpreter) EvalObjectNode(ctx types.Context, obj ast.ObjectNode) (any, error) {
-	result := map[string]any{}
-
 	var (
-		key   any
-		value any
-		err   error
+		result        = map[string]any{}
+		orderedResult *ordered.Object
+		key           any
+		value         any
+		err           error
 	)
 
+	if ctx.OrderedObjects() {
+		object := ordered.NewObject()
+		orderedResult = &object
+	}
+
 	for _, pair := range obj.Data {
 		switch asserted := pair.Key.(type) {
 		// as a convenience feature, we allow unquoted object keys, which are parsed as bare identifiers
@@ -29,7 +35,7 @@ func (i *interpreter) EvalObjectNode(ctx types.Context, obj ast.ObjectNode) (any
 				return nil, errors.New("cannot use bang modifier in object keys")
 			}
 
-			key = asserted.Name
+			key = asserted.FullName()
 		default:
 			key, err = i.EvalExpression(ctx, pair.Key)
 			if err != nil {
@@ -47,10 +53,19 @@ func (i *interpreter) EvalObjectNode(ctx types.Context, obj ast.ObjectNode) (any
 			return nil, fmt.Errorf("failed to evaluate object value %s: %w", pair.Value.String(), err)
 		}
 
-		result[keyString] = value
+		if orderedResult != nil {
+			orderedResult.Set(keyString, value)
+		} else {
+			result[keyString] = value
+		}
+	}
+
+	var evaluated any = result
+	if orderedResult != nil {
+		evaluated = *orderedResult
 	}
 
-	deeper, err := pathexpr.Apply(ctx, result, obj.PathExpression)
+	deeper, err := pathexpr.Apply(ctx, evaluated, obj.PathExpression)
 	if err != nil {
 		return nil, err
 	}
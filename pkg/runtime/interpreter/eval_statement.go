@@ -9,5 +9,11 @@ import (
 )
 
 func (i *interpreter) EvalStatement(ctx types.Context, stmt ast.Statement) (any, error) {
+	if ctx.Tracer() != nil {
+		return types.Trace(ctx, types.TraceEvent{Kind: types.StatementEvent, Expression: stmt}, func() (any, error) {
+			return i.EvalExpression(ctx, stmt.Expression)
+		})
+	}
+
 	return i.EvalExpression(ctx, stmt.Expression)
 }
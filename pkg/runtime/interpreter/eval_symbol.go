@@ -12,7 +12,17 @@ import (
 	"go.xrstf.de/rudi/pkg/runtime/types"
 )
 
-func (*interpreter) EvalSymbol(ctx types.Context, sym ast.Symbol) (any, error) {
+func (i *interpreter) EvalSymbol(ctx types.Context, sym ast.Symbol) (any, error) {
+	if ctx.Tracer() != nil {
+		return types.Trace(ctx, types.TraceEvent{Kind: types.SymbolEvent, Expression: sym}, func() (any, error) {
+			return i.evalSymbol(ctx, sym)
+		})
+	}
+
+	return i.evalSymbol(ctx, sym)
+}
+
+func (*interpreter) evalSymbol(ctx types.Context, sym ast.Symbol) (any, error) {
 	rootValue := ctx.GetDocument().Data()
 
 	// sanity check
@@ -7,7 +7,6 @@ import (
 	"errors"
 	"fmt"
 
-	"go.xrstf.de/rudi/pkg/deepcopy"
 	"go.xrstf.de/rudi/pkg/jsonpath"
 	"go.xrstf.de/rudi/pkg/lang/ast"
 	"go.xrstf.de/rudi/pkg/runtime/pathexpr"
@@ -15,6 +14,16 @@ import (
 )
 
 func (i *interpreter) EvalTuple(ctx types.Context, tup ast.Tuple) (any, error) {
+	if ctx.Tracer() != nil {
+		return types.Trace(ctx, types.TraceEvent{Kind: types.TupleEvent, Expression: tup}, func() (any, error) {
+			return i.evalTuple(ctx, tup)
+		})
+	}
+
+	return i.evalTuple(ctx, tup)
+}
+
+func (i *interpreter) evalTuple(ctx types.Context, tup ast.Tuple) (any, error) {
 	// Function calls are the only place where we check if the Go context has been cancelled.
 	// This error should not be caught and swallowed by any other function, like `try` or `default`.
 	if err := ctx.GoContext().Err(); err != nil {
@@ -43,17 +52,47 @@ func (i *interpreter) EvalTuple(ctx types.Context, tup ast.Tuple) (any, error) {
 	return deeper, nil
 }
 
-func (*interpreter) CallFunction(ctx types.Context, fun ast.Identifier, args []ast.Expression) (any, error) {
-	funcName := fun.Name
+func (i *interpreter) CallFunction(ctx types.Context, fun ast.Identifier, args []ast.Expression) (any, error) {
+	if ctx.Tracer() != nil {
+		return types.Trace(ctx, types.TraceEvent{Kind: types.CallEvent, Expression: fun, Arguments: args}, func() (any, error) {
+			return i.callFunction(ctx, fun, args)
+		})
+	}
+
+	return i.callFunction(ctx, fun, args)
+}
+
+func (*interpreter) callFunction(ctx types.Context, fun ast.Identifier, args []ast.Expression) (any, error) {
+	funcName := fun.FullName()
 	function, ok := ctx.GetFunction(funcName)
 	if !ok {
 		return nil, fmt.Errorf("unknown function %s", funcName)
 	}
 
+	// Bang calls on paths that match multiple values (like `(append! .items[*].tags "new")`)
+	// apply the function to every match individually.
+	if fun.Bang {
+		// some functions handle the bang modifier entirely on their own
+		if custom, ok := function.(types.BangFunction); ok {
+			result, err := custom.EvaluateBang(ctx, args)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", fun.FullName(), err)
+			}
+
+			return result, nil
+		}
+
+		if _, ok := function.(types.BangHandler); !ok && len(args) > 0 {
+			if symbol, ok := args[0].(ast.Symbol); ok && symbol.PathExpression != nil && symbol.PathExpression.IsMulti() {
+				return callFunctionOnMatches(ctx, fun, function, symbol, args)
+			}
+		}
+	}
+
 	// call the function
 	result, err := function.Evaluate(ctx, args)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", funcName, err)
+		return nil, fmt.Errorf("%s: %w", fun.FullName(), err)
 	}
 
 	// if desired, update the context and introduce side effects
@@ -83,6 +122,7 @@ func (*interpreter) CallFunction(ctx types.Context, fun ast.Identifier, args []a
 		// but for setting the new variable/document, we need the _whole_ new value, which might be
 		// the result of combining the current + setting a value deep somewhere.
 		updatedValue := result
+		updatedPath := jsonpath.Path{}
 
 		// if the symbol has a path to traverse, do so
 		if updateSymbol.PathExpression != nil {
@@ -104,13 +144,12 @@ func (*interpreter) CallFunction(ctx types.Context, fun ast.Identifier, args []a
 				currentValue = ctx.GetDocument().Data()
 			}
 
-			currentValue, err = deepcopy.Clone(currentValue)
-			if err != nil {
-				return nil, err
-			}
-
-			// apply the path expression
-			updatedValue, err = jsonpath.Set(currentValue, jsonpath.FromEvaluatedPath(*pathExpr), updatedValue)
+			// apply the path expression; this does not modify currentValue, but
+			// only copies the vectors and objects along the path
+			updatedValue, err = jsonpath.UpdateWithPaths(currentValue, jsonpath.FromEvaluatedPath(*pathExpr), func(path jsonpath.Path, _ any) (any, error) {
+				updatedPath = path
+				return result, nil
+			})
 			if err != nil {
 				return nil, fmt.Errorf("cannot set value in %T at %s: %w", currentValue, pathExpr, err)
 			}
@@ -120,9 +159,63 @@ func (*interpreter) CallFunction(ctx types.Context, fun ast.Identifier, args []a
 			varName := string(*updateSymbol.Variable)
 			ctx.SetVariable(varName, updatedValue)
 		} else {
-			ctx.GetDocument().Set(updatedValue)
+			ctx.GetDocument().Update(updatedValue, types.Change{
+				Operation: types.SetOperation,
+				Path:      updatedPath,
+				Value:     result,
+			})
 		}
 	}
 
 	return result, nil
 }
+
+// callFunctionOnMatches calls the function once for every value matched by the
+// symbol's path expression, with the match as the first argument, and replaces
+// each match with the function's result. The results are returned as a vector.
+func callFunctionOnMatches(ctx types.Context, fun ast.Identifier, function types.Function, symbol ast.Symbol, args []ast.Expression) (any, error) {
+	pathExpr, err := pathexpr.Eval(ctx, symbol.PathExpression)
+	if err != nil {
+		return nil, fmt.Errorf("argument #0: invalid path expression: %w", err)
+	}
+
+	var currentValue any
+
+	if symbol.Variable != nil {
+		currentValue, _ = ctx.GetVariable(string(*symbol.Variable))
+	} else {
+		currentValue = ctx.GetDocument().Data()
+	}
+
+	results := []any{}
+	changes := []types.Change{}
+
+	updatedValue, err := jsonpath.UpdateWithPaths(currentValue, jsonpath.FromEvaluatedPath(*pathExpr), func(path jsonpath.Path, match any) (any, error) {
+		matchArgs := append([]ast.Expression{types.MakeShim(match)}, args[1:]...)
+
+		result, err := function.Evaluate(ctx, matchArgs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fun.FullName(), err)
+		}
+
+		results = append(results, result)
+		changes = append(changes, types.Change{
+			Operation: types.SetOperation,
+			Path:      path,
+			Value:     result,
+		})
+
+		return result, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot set value in %T at %s: %w", currentValue, pathExpr, err)
+	}
+
+	if symbol.Variable != nil {
+		ctx.SetVariable(string(*symbol.Variable), updatedValue)
+	} else {
+		ctx.GetDocument().Update(updatedValue, changes...)
+	}
+
+	return results, nil
+}
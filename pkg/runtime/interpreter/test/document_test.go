@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"go.xrstf.de/rudi/pkg/jsonpath"
+	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/lang/parser"
+	"go.xrstf.de/rudi/pkg/runtime/interpreter"
+	"go.xrstf.de/rudi/pkg/runtime/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDocumentChangeTracking(t *testing.T) {
+	doc, err := types.NewDocument(map[string]any{"a": int64(1), "b": []any{"x"}})
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+
+	doc.TrackChanges()
+
+	ctx, err := types.NewContext(interpreter.New(), nil, doc, nil, dummyFunctions, nil)
+	if err != nil {
+		t.Fatalf("Failed to create context: %v", err)
+	}
+
+	got, err := parser.ParseReader("test.go", strings.NewReader(`(set! .a 2) (set! $var 3) .a (set! .b[0] "y")`))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	program := got.(ast.Program)
+
+	if _, err := ctx.Runtime().EvalProgram(ctx, &program); err != nil {
+		t.Fatalf("Failed to run program: %v", err)
+	}
+
+	expected := []types.Change{
+		{
+			Operation: types.SetOperation,
+			Path:      jsonpath.Path{"a"},
+			Value:     int64(2),
+		},
+		{
+			Operation: types.SetOperation,
+			Path:      jsonpath.Path{"b", 0},
+			Value:     "y",
+		},
+	}
+
+	changes := ctx.GetDocument().Changes()
+	if !cmp.Equal(expected, changes) {
+		t.Fatalf("Unexpected changes:\n%s", cmp.Diff(expected, changes))
+	}
+
+	original := map[string]any{"a": int64(1), "b": []any{"x"}}
+	if !cmp.Equal(original, ctx.GetDocument().Original()) {
+		t.Fatalf("Original document was modified:\n%s", cmp.Diff(original, ctx.GetDocument().Original()))
+	}
+}
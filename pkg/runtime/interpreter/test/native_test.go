@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"go.xrstf.de/rudi"
+	"go.xrstf.de/rudi/pkg/testutil"
+)
+
+type nativeContainer struct {
+	Name  string `json:"name"`
+	Image string `json:"image,omitempty"`
+}
+
+type nativeSpec struct {
+	Replicas   int               `json:"replicas"`
+	Labels     map[string]string `json:"labels"`
+	Containers []nativeContainer `json:"containers"`
+	Paused     *bool             `json:"paused"`
+}
+
+type nativeDocument struct {
+	Kind string      `json:"kind"`
+	Spec *nativeSpec `json:"spec"`
+}
+
+func makeNativeDocument() nativeDocument {
+	return nativeDocument{
+		Kind: "Deployment",
+		Spec: &nativeSpec{
+			Replicas: 2,
+			Labels:   map[string]string{"app": "rudi"},
+			Containers: []nativeContainer{
+				{Name: "a", Image: "img-a"},
+				{Name: "b"},
+			},
+		},
+	}
+}
+
+func TestEvalNativeDocument(t *testing.T) {
+	updated := makeNativeDocument()
+	updated.Spec.Replicas = 5
+	updated.Spec.Labels["tier"] = "backend"
+	updated.Spec.Containers[1].Image = "img-b"
+
+	testcases := []testutil.Testcase{
+		{
+			Expression:       `.kind`,
+			Document:         makeNativeDocument(),
+			Expected:         "Deployment",
+			ExpectedDocument: makeNativeDocument(),
+		},
+		{
+			Expression:       `.spec.replicas`,
+			Document:         makeNativeDocument(),
+			Expected:         int64(2),
+			ExpectedDocument: makeNativeDocument(),
+		},
+		{
+			Expression:       `.spec.labels.app`,
+			Document:         makeNativeDocument(),
+			Expected:         "rudi",
+			ExpectedDocument: makeNativeDocument(),
+		},
+		{
+			Expression:       `.spec.containers[-1].name`,
+			Document:         makeNativeDocument(),
+			Expected:         "b",
+			ExpectedDocument: makeNativeDocument(),
+		},
+		{
+			Expression:       `.spec.containers[*].name`,
+			Document:         makeNativeDocument(),
+			Expected:         []any{"a", "b"},
+			ExpectedDocument: makeNativeDocument(),
+		},
+		{
+			Expression:       `.spec.containers[?(eval true)].image`,
+			Document:         makeNativeDocument(),
+			Expected:         []any{"img-a", ""},
+			ExpectedDocument: makeNativeDocument(),
+		},
+		{
+			Expression:       `.spec.paused`,
+			Document:         makeNativeDocument(),
+			Expected:         nil,
+			ExpectedDocument: makeNativeDocument(),
+		},
+		{
+			Expression:       `.spec.unknown?`,
+			Document:         makeNativeDocument(),
+			Expected:         nil,
+			ExpectedDocument: makeNativeDocument(),
+		},
+		{
+			Expression: `.spec.unknown`,
+			Document:   makeNativeDocument(),
+			Invalid:    true,
+		},
+		{
+			Expression: `.spec.containers[2]`,
+			Document:   makeNativeDocument(),
+			Invalid:    true,
+		},
+		{
+			Expression:       `(set! .spec.replicas 5) (set! .spec.labels.tier "backend") (set! .spec.containers[1].image "img-b")`,
+			Document:         makeNativeDocument(),
+			Expected:         "img-b",
+			ExpectedDocument: updated,
+		},
+		{
+			Expression: `(set! .spec.replicas "many")`,
+			Document:   makeNativeDocument(),
+			Invalid:    true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		testcase.Functions = dummyFunctions
+
+		t.Run(testcase.String(), testcase.Run)
+	}
+}
+
+func TestRunNativeDocument(t *testing.T) {
+	testcases := []struct {
+		script   string
+		expected any
+	}{
+		{
+			script:   `.kind`,
+			expected: "Deployment",
+		},
+		{
+			script:   `.spec`,
+			expected: *makeNativeDocument().Spec,
+		},
+		{
+			script:   `.spec.containers`,
+			expected: makeNativeDocument().Spec.Containers,
+		},
+		{
+			script:   `.spec.containers[0]`,
+			expected: makeNativeDocument().Spec.Containers[0],
+		},
+		{
+			script:   `(set! .spec.containers[1].image "img-b") .spec.containers[1]`,
+			expected: nativeContainer{Name: "b", Image: "img-b"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.script, func(t *testing.T) {
+			program, err := rudi.Parse("test", tc.script)
+			if err != nil {
+				t.Fatalf("Failed to parse: %v", err)
+			}
+
+			doc := makeNativeDocument()
+
+			_, result, err := program.Run(context.Background(), &doc, nil, dummyFunctions, nil)
+			if err != nil {
+				t.Fatalf("Failed to run: %v", err)
+			}
+
+			if !cmp.Equal(tc.expected, result) {
+				t.Fatalf("Expected %#v, but got %#v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestRunKeepsDocumentPointer(t *testing.T) {
+	program, err := rudi.Parse("test", `(set! .kind "StatefulSet")`)
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	doc := makeNativeDocument()
+
+	updated, _, err := program.Run(context.Background(), &doc, nil, dummyFunctions, nil)
+	if err != nil {
+		t.Fatalf("Failed to run: %v", err)
+	}
+
+	pointer, ok := updated.(*nativeDocument)
+	if !ok {
+		t.Fatalf("Expected document to be a *nativeDocument, got %T.", updated)
+	}
+
+	if pointer == &doc {
+		t.Fatal("Expected a new pointer, but got the original one.")
+	}
+
+	if pointer.Kind != "StatefulSet" {
+		t.Fatalf("Expected kind to be updated, got %q.", pointer.Kind)
+	}
+
+	if doc.Kind != "Deployment" {
+		t.Fatalf("Original document should not have been modified, but kind is %q.", doc.Kind)
+	}
+
+	// values are returned as values
+	updated, _, err = program.Run(context.Background(), doc, nil, dummyFunctions, nil)
+	if err != nil {
+		t.Fatalf("Failed to run: %v", err)
+	}
+
+	if _, ok := updated.(nativeDocument); !ok {
+		t.Fatalf("Expected document to be a nativeDocument, got %T.", updated)
+	}
+}
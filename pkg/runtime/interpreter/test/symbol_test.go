@@ -4,11 +4,14 @@
 package test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 
 	"go.xrstf.de/rudi/pkg/jsonpath"
 	"go.xrstf.de/rudi/pkg/lang/ast"
+	"go.xrstf.de/rudi/pkg/runtime/interpreter"
 	"go.xrstf.de/rudi/pkg/runtime/types"
 	"go.xrstf.de/rudi/pkg/testutil"
 )
@@ -152,3 +155,204 @@ func TestEvalSymbol(t *testing.T) {
 		t.Run(testcase.String(), testcase.Run)
 	}
 }
+
+func TestEvalMultiValuePathSymbol(t *testing.T) {
+	testDocument := func() any {
+		return map[string]any{
+			"items": []any{
+				map[string]any{"name": "a", "image": "img-a"},
+				map[string]any{"name": "b", "image": "img-b"},
+				map[string]any{"name": "c"},
+			},
+		}
+	}
+
+	testcases := []testutil.Testcase{
+		{
+			Expression:       `.items[*].name`,
+			Document:         testDocument(),
+			ExpectedDocument: testDocument(),
+			Expected:         []any{"a", "b", "c"},
+		},
+		{
+			Expression:       `.items[*].image`,
+			Document:         testDocument(),
+			ExpectedDocument: testDocument(),
+			Expected:         []any{"img-a", "img-b"},
+		},
+		{
+			Expression:       `.items[1:].name`,
+			Document:         testDocument(),
+			ExpectedDocument: testDocument(),
+			Expected:         []any{"b", "c"},
+		},
+		{
+			Expression:       `.items[:$end].name`,
+			Document:         testDocument(),
+			Variables:        types.Variables{"end": -1},
+			ExpectedDocument: testDocument(),
+			Expected:         []any{"a", "b"},
+		},
+		{
+			Expression:       `.items[-1].name`,
+			Document:         testDocument(),
+			ExpectedDocument: testDocument(),
+			Expected:         "c",
+		},
+		{
+			Expression: `.items[?.image].name`,
+			Document: map[string]any{
+				"items": []any{
+					map[string]any{"name": "a", "image": true},
+					map[string]any{"name": "b", "image": false},
+				},
+			},
+			ExpectedDocument: map[string]any{
+				"items": []any{
+					map[string]any{"name": "a", "image": true},
+					map[string]any{"name": "b", "image": false},
+				},
+			},
+			Expected: []any{"a"},
+		},
+		{
+			// the predicate must evaluate to a bool
+			Expression:       `.items[?.name]`,
+			Document:         testDocument(),
+			ExpectedDocument: testDocument(),
+			Invalid:          true,
+		},
+		{
+			// predicates can still access variables
+			Expression:       `.items[?(eval $match)].name`,
+			Document:         testDocument(),
+			Variables:        types.Variables{"match": true},
+			ExpectedDocument: testDocument(),
+			Expected:         []any{"a", "b", "c"},
+		},
+		{
+			Expression:       `..image`,
+			Document:         testDocument(),
+			ExpectedDocument: testDocument(),
+			Expected:         []any{"img-a", "img-b"},
+		},
+		{
+			Expression: `$var..[0]`,
+			Variables:  types.Variables{"var": []any{[]any{1, 2}, 3}},
+			Expected:   []any{[]any{1, 2}, 1},
+		},
+		{
+			// a bang function is applied to every match individually
+			Expression: `(set! .items[*].name "x")`,
+			Document:   testDocument(),
+			Expected:   []any{"x", "x", "x"},
+			ExpectedDocument: map[string]any{
+				"items": []any{
+					map[string]any{"name": "x", "image": "img-a"},
+					map[string]any{"name": "x", "image": "img-b"},
+					map[string]any{"name": "x"},
+				},
+			},
+		},
+		{
+			Expression:       `(set! .items[*].name "x")`,
+			Document:         map[string]any{"items": "not-a-vector"},
+			ExpectedDocument: map[string]any{"items": "not-a-vector"},
+			Invalid:          true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		testcase.Functions = dummyFunctions
+		t.Run(testcase.String(), testcase.Run)
+	}
+}
+
+func TestEvalOptionalPathSymbol(t *testing.T) {
+	testcases := []testutil.Testcase{
+		{
+			Expression:       `.spec?.template?.labels`,
+			Document:         map[string]any{},
+			ExpectedDocument: map[string]any{},
+			Expected:         nil,
+		},
+		{
+			Expression:       `.spec?.template?.labels`,
+			Document:         map[string]any{"spec": nil},
+			ExpectedDocument: map[string]any{"spec": nil},
+			Expected:         nil,
+		},
+		{
+			Expression:       `.spec?.template?.labels`,
+			Document:         map[string]any{"spec": map[string]any{"template": map[string]any{"labels": "yes"}}},
+			ExpectedDocument: map[string]any{"spec": map[string]any{"template": map[string]any{"labels": "yes"}}},
+			Expected:         "yes",
+		},
+		{
+			// only the marked steps are optional
+			Expression:       `.spec?.template`,
+			Document:         map[string]any{"spec": map[string]any{}},
+			ExpectedDocument: map[string]any{"spec": map[string]any{}},
+			Invalid:          true,
+		},
+		{
+			Expression: `$var[5]?`,
+			Variables:  types.Variables{"var": []any{1, 2}},
+			Expected:   nil,
+		},
+		{
+			Expression: `$var[(eval 0)]?`,
+			Variables:  types.Variables{"var": []any{int64(1), int64(2)}},
+			Expected:   int64(1),
+		},
+		{
+			// type errors are not hidden by optional steps
+			Expression: `$var.foo?`,
+			Variables:  types.Variables{"var": []any{1, 2}},
+			Invalid:    true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		testcase.Functions = dummyFunctions
+		t.Run(testcase.String(), testcase.Run)
+	}
+}
+
+func TestEvalSymbolPathErrors(t *testing.T) {
+	doc, err := types.NewDocument(map[string]any{
+		"spec": map[string]any{"items": []any{1, 2}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+
+	ctx, err := types.NewContext(interpreter.New(), context.Background(), doc, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create context: %v", err)
+	}
+
+	sym := makeSymbol("", &ast.PathExpression{Steps: []ast.Expression{
+		ast.Identifier{Name: "spec"},
+		ast.Identifier{Name: "items"},
+		ast.Number{Value: int64(5)},
+	}})
+
+	_, err = ctx.Runtime().EvalSymbol(ctx, sym)
+	if err == nil {
+		t.Fatal("Should have returned an error.")
+	}
+
+	var boundsErr *jsonpath.IndexOutOfBoundsError
+	if !errors.As(err, &boundsErr) {
+		t.Fatalf("Expected an IndexOutOfBoundsError, got %#v", err)
+	}
+
+	if boundsErr.StepIndex != 2 || boundsErr.Index != 5 || boundsErr.Length != 2 {
+		t.Fatalf("Unexpected error details: %#v", boundsErr)
+	}
+
+	if !errors.Is(err, jsonpath.ErrNotFound) {
+		t.Fatal("Error should match jsonpath.ErrNotFound.")
+	}
+}
@@ -176,6 +176,48 @@ func TestEvalTuple(t *testing.T) {
 	}
 }
 
+func TestEvalTupleNamespaced(t *testing.T) {
+	testcases := []testutil.Testcase{
+		{
+			Expression: `(eval "foo")`,
+			Expected:   "foo",
+		},
+		{
+			Expression: `(test/eval "foo")`,
+			Expected:   "foo",
+		},
+		{
+			Expression: `(test/set! $var "foo")`,
+			Variables: types.Variables{
+				"var": "bar",
+			},
+			Expected: "foo",
+			ExpectedVariables: types.Variables{
+				"var": "foo",
+			},
+		},
+		{
+			Expression: `{test/eval "foo"}`,
+			Expected:   map[string]any{"test/eval": "foo"},
+		},
+		{
+			Expression: `(test/unknown)`,
+			Invalid:    true,
+		},
+		{
+			Expression: `(other/eval "foo")`,
+			Invalid:    true,
+		},
+	}
+
+	funcs := types.NewFunctions().Add(dummyFunctions).AddNamespaced("test", dummyFunctions)
+
+	for _, testcase := range testcases {
+		testcase.Functions = funcs
+		t.Run(testcase.String(), testcase.Run)
+	}
+}
+
 func TestEvalTupleBangModifier(t *testing.T) {
 	testcases := []testutil.Testcase{
 		// (set!)
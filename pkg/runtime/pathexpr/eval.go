@@ -40,35 +40,121 @@ func Eval(ctx types.Context, path *ast.PathExpression) (*ast.EvaluatedPathExpres
 		return result, nil
 	}
 
+	for _, step := range path.Steps {
+		evaledAccessor, err := evalStep(ctx, step)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Steps = append(result.Steps, *evaledAccessor)
+	}
+
+	return result, nil
+}
+
+func evalStep(ctx types.Context, step ast.Expression) (*ast.EvaluatedPathStep, error) {
 	runtime := ctx.Runtime()
 
-	for _, step := range path.Steps {
-		var (
-			evaluated any
-			err       error
-		)
-
-		// keep accumulating context changes, so you _could_ in theory do
-		// $var[(set $bla 2)][(add $bla 2)] <-- would be $var[2][4]
-		switch asserted := step.(type) {
-		case ast.Identifier:
-			evaluated = asserted.Name
-		default:
-			evaluated, err = runtime.EvalExpression(ctx, step)
-			if err != nil {
-				return nil, fmt.Errorf("invalid accessor: %w", err)
-			}
+	// keep accumulating context changes, so you _could_ in theory do
+	// $var[(set $bla 2)][(add $bla 2)] <-- would be $var[2][4]
+	switch asserted := step.(type) {
+	case ast.Identifier:
+		return convertToAccessor(asserted.Name)
+
+	case ast.WildcardStep:
+		return &ast.EvaluatedPathStep{Wildcard: true}, nil
+
+	case ast.SliceStep:
+		from, err := evalSliceBound(ctx, asserted.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slice start: %w", err)
 		}
 
-		evaledAccessor, err := convertToAccessor(evaluated)
+		to, err := evalSliceBound(ctx, asserted.To)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slice end: %w", err)
+		}
+
+		return &ast.EvaluatedPathStep{
+			Slice: &ast.EvaluatedSlice{From: from, To: to},
+		}, nil
+
+	case ast.FilterStep:
+		return &ast.EvaluatedPathStep{
+			Filter: &ast.EvaluatedFilter{
+				Predicate: asserted.Predicate,
+				Matches:   filterFunc(ctx, asserted.Predicate),
+			},
+		}, nil
+
+	case ast.DescentStep:
+		inner, err := evalStep(ctx, asserted.Step)
 		if err != nil {
 			return nil, err
 		}
 
-		result.Steps = append(result.Steps, *evaledAccessor)
+		inner.Descent = true
+
+		return inner, nil
+
+	case ast.OptionalStep:
+		inner, err := evalStep(ctx, asserted.Step)
+		if err != nil {
+			return nil, err
+		}
+
+		inner.Optional = true
+
+		return inner, nil
+
+	default:
+		evaluated, err := runtime.EvalExpression(ctx, step)
+		if err != nil {
+			return nil, fmt.Errorf("invalid accessor: %w", err)
+		}
+
+		return convertToAccessor(evaluated)
+	}
+}
+
+func evalSliceBound(ctx types.Context, bound ast.Expression) (*int64, error) {
+	if bound == nil {
+		return nil, nil
 	}
 
-	return result, nil
+	evaluated, err := ctx.Runtime().EvalExpression(ctx, bound)
+	if err != nil {
+		return nil, err
+	}
+
+	accessor, err := convertToAccessor(evaluated)
+	if err != nil {
+		return nil, err
+	}
+
+	if accessor.IntegerValue == nil {
+		return nil, fmt.Errorf("cannot use %T as slice bound", evaluated)
+	}
+
+	return accessor.IntegerValue, nil
+}
+
+// filterFunc returns a function that evaluates the predicate with the given
+// value as the document, so that ".name" refers to the value's name.
+func filterFunc(ctx types.Context, predicate ast.Expression) func(value any) (bool, error) {
+	return func(value any) (bool, error) {
+		doc, err := types.NewDocument(value)
+		if err != nil {
+			return false, err
+		}
+
+		result, err := ctx.Runtime().EvalExpression(ctx.WithDocument(doc), predicate)
+		if err != nil {
+			return false, fmt.Errorf("invalid filter: %w", err)
+		}
+
+		return ctx.Coalesce().ToBool(result)
+	}
 }
 
 func ptrTo[T any](s T) *T {
@@ -21,6 +21,9 @@ type Context struct {
 	tempVariables   Variables
 	coalescer       coalescing.Coalescer
 	runtime         Runtime
+	tracer          Tracer
+	orderedObjects  bool
+	loader          Loader
 }
 
 func NewContext(runtime Runtime, ctx context.Context, doc Document, variables Variables, funcs Functions, coalescer coalescing.Coalescer) (Context, error) {
@@ -85,6 +88,24 @@ func (c Context) Runtime() Runtime {
 	return c.runtime
 }
 
+// Tracer returns the tracer that is informed about evaluation steps, or nil
+// if tracing is not enabled.
+func (c Context) Tracer() Tracer {
+	return c.tracer
+}
+
+// OrderedObjects returns true if object literals should evaluate to objects
+// that keep the order of their keys, see WithOrderedObjects().
+func (c Context) OrderedObjects() bool {
+	return c.orderedObjects
+}
+
+// Loader returns the loader used to import libraries, or nil if importing is
+// not possible.
+func (c Context) Loader() Loader {
+	return c.loader
+}
+
 func (c Context) GetDocument() *Document {
 	return c.document
 }
@@ -126,6 +147,58 @@ func (c Context) WithCoalescer(coalescer coalescing.Coalescer) Context {
 	return clone
 }
 
+// WithTracer returns a context that informs the given tracer about every
+// evaluated tuple, symbol and function call. Passing nil disables tracing.
+func (c Context) WithTracer(tracer Tracer) Context {
+	clone := c.shallowCopy()
+	clone.tracer = tracer
+
+	return clone
+}
+
+// WithOrderedObjects returns a context in which object literals like {b 1 a 2}
+// evaluate to ordered.Object values instead of maps, so that the order of
+// keys is kept when the result is encoded.
+func (c Context) WithOrderedObjects(enabled bool) Context {
+	clone := c.shallowCopy()
+	clone.orderedObjects = enabled
+
+	return clone
+}
+
+// WithLoader returns a context that uses the given loader to find libraries
+// imported by the import function.
+func (c Context) WithLoader(loader Loader) Context {
+	clone := c.shallowCopy()
+	clone.loader = loader
+
+	return clone
+}
+
+// WithRudispaceFunctions returns a context that uses the given set of functions
+// for all functions defined using func! in it, instead of sharing them with the
+// current context. This allows to isolate libraries from each other.
+func (c Context) WithRudispaceFunctions(funcs Functions) Context {
+	clone := c.shallowCopy()
+	clone.userFuncs = funcs
+
+	return clone
+}
+
+// RudispaceFunctions returns all functions that were defined using func!.
+func (c Context) RudispaceFunctions() Functions {
+	return c.userFuncs
+}
+
+// WithDocument returns a context that uses the given document instead of the
+// current one, for example to evaluate expressions relative to an item.
+func (c Context) WithDocument(doc Document) Context {
+	clone := c.shallowCopy()
+	clone.document = &doc
+
+	return clone
+}
+
 func (c Context) SetVariable(name string, val any) {
 	var vars Variables
 
@@ -161,6 +234,9 @@ func (c Context) shallowCopy() Context {
 		tempVariables:   c.tempVariables,
 		coalescer:       c.coalescer,
 		runtime:         c.runtime,
+		tracer:          c.tracer,
+		orderedObjects:  c.orderedObjects,
+		loader:          c.loader,
 	}
 }
 
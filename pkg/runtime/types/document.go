@@ -3,13 +3,47 @@
 
 package types
 
+import (
+	"go.xrstf.de/rudi/pkg/jsonpath"
+	"go.xrstf.de/rudi/pkg/native"
+)
+
 type Document struct {
-	data any
+	data     any
+	tracking bool
+	original any
+	changes  []Change
+}
+
+// ChangeOperation describes what a Change did to the document.
+type ChangeOperation string
+
+const (
+	SetOperation    ChangeOperation = "set"
+	DeleteOperation ChangeOperation = "delete"
+)
+
+// Change is a single mutation of a document, as made by a bang function like
+// set! or delete!. Path is the concrete path that was modified (empty if the
+// entire document was replaced) and Value the new value at that path (nil for
+// deletions). Paths never contain wildcards, slices or filters; a call like
+// (set! .items[*].name "x") results in one change for every matched item.
+type Change struct {
+	Operation ChangeOperation
+	Path      jsonpath.Path
+	Value     any
 }
 
+// NewDocument wraps the data as a document. Go values that Rudi cannot handle
+// natively, like structs or typed slices, are wrapped using native.Wrap.
 func NewDocument(data any) (Document, error) {
+	wrapped, err := native.Wrap(data)
+	if err != nil {
+		return Document{}, err
+	}
+
 	return Document{
-		data: data,
+		data: wrapped,
 	}, nil
 }
 
@@ -17,6 +51,48 @@ func (d *Document) Data() any {
 	return d.data
 }
 
+// Set replaces the entire document. If changes are tracked, this is recorded
+// as a set operation with an empty path; use Update to record a more specific
+// change.
 func (d *Document) Set(wrappedData any) {
+	d.Update(wrappedData, Change{
+		Operation: SetOperation,
+		Path:      jsonpath.Path{},
+		Value:     wrappedData,
+	})
+}
+
+// Update replaces the document with the given data, which is the result of
+// applying the changes to the current data. If changes are tracked, the
+// changes are recorded.
+func (d *Document) Update(wrappedData any, changes ...Change) {
+	if d.tracking {
+		d.changes = append(d.changes, changes...)
+	}
+
 	d.data = wrappedData
 }
+
+// TrackChanges makes the document remember its current data as the original
+// and record every following change. Use Changes and Original to inspect what
+// a program did to the document, for example using the patch package.
+func (d *Document) TrackChanges() {
+	d.tracking = true
+	d.original = d.data
+	d.changes = nil
+}
+
+// IsTracking returns true if TrackChanges was called.
+func (d *Document) IsTracking() bool {
+	return d.tracking
+}
+
+// Original returns the document's data at the time TrackChanges was called.
+func (d *Document) Original() any {
+	return d.original
+}
+
+// Changes returns all changes recorded since TrackChanges was called.
+func (d *Document) Changes() []Change {
+	return d.changes
+}
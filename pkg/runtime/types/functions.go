@@ -24,6 +24,21 @@ type BangHandler interface {
 	BangHandler(ctx Context, args []ast.Expression, value any) (any, error)
 }
 
+type BangFunction interface {
+	// EvaluateBang is called instead of Evaluate (and BangHandler) when the function is called
+	// with the bang modifier. This is useful for functions whose side effects depend on how their
+	// arguments were evaluated, like "set!", which needs to know which paths it modified, and
+	// which must not evaluate the path expression a second time just to find out.
+	EvaluateBang(ctx Context, args []ast.Expression) (any, error)
+}
+
+type PureFunction interface {
+	// IsPure returns true if the function has no side effects and always returns the same
+	// result for the same arguments. Calls to pure functions with only literal arguments can
+	// be evaluated ahead of time by optimizers.
+	IsPure() bool
+}
+
 type TupleFunction func(ctx Context, args []ast.Expression) (any, error)
 
 type basicFunc struct {
@@ -85,6 +100,18 @@ func (f Functions) Add(other Functions) Functions {
 	return f
 }
 
+// AddNamespaced adds all functions from other to the current set, prefixed
+// with the given namespace, so that they can be called as "namespace/name".
+// This allows to use modules whose function names would otherwise collide.
+// The namespace must match ast.NamespacePattern.
+// The function returns the same Functions to allow fluent access.
+func (f Functions) AddNamespaced(namespace string, other Functions) Functions {
+	for name, fun := range other {
+		f[ast.Identifier{Namespace: namespace, Name: name}.FullName()] = fun
+	}
+	return f
+}
+
 // Remove removes all functions from this set that are part of the other set,
 // to enable constructs like AllFunctions.Remove(MathFunctions)
 // The function returns the same Functions to allow fluent access.
@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package types
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Loader provides the source code of Rudi libraries for the import function,
+// see Context.WithLoader().
+type Loader interface {
+	// Load returns the source code of the library at the given path. The
+	// returned name must uniquely identify the library, as it is used to detect
+	// import cycles and in error messages.
+	Load(ctx context.Context, path string) (name string, source string, err error)
+}
+
+// LoaderFunc allows to use a plain function as a Loader.
+type LoaderFunc func(ctx context.Context, path string) (name string, source string, err error)
+
+var _ Loader = LoaderFunc(nil)
+
+func (f LoaderFunc) Load(ctx context.Context, path string) (string, string, error) {
+	return f(ctx, path)
+}
+
+// NewFSLoader returns a loader that reads libraries from the given filesystem,
+// for example an embed.FS or os.DirFS(). All paths are relative to the root of
+// the filesystem.
+func NewFSLoader(fsys fs.FS) Loader {
+	return fsLoader{fsys: fsys}
+}
+
+type fsLoader struct {
+	fsys fs.FS
+}
+
+var _ Loader = fsLoader{}
+
+func (l fsLoader) Load(_ context.Context, name string) (string, string, error) {
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+
+	content, err := fs.ReadFile(l.fsys, name)
+	if err != nil {
+		return "", "", err
+	}
+
+	return name, string(content), nil
+}
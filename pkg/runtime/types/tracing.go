@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package types
+
+import (
+	"time"
+
+	"go.xrstf.de/rudi/pkg/lang/ast"
+)
+
+type TraceEventKind int
+
+const (
+	// StatementEvent is emitted when a top-level statement of a program is evaluated.
+	StatementEvent TraceEventKind = iota
+	// TupleEvent is emitted when a tuple (including its path expression) is evaluated.
+	TupleEvent
+	// SymbolEvent is emitted when a variable or path expression on the document is evaluated.
+	SymbolEvent
+	// CallEvent is emitted when a function is called, either as part of a tuple
+	// or directly by another function (like `map` calling a function by name).
+	CallEvent
+)
+
+func (k TraceEventKind) String() string {
+	switch k {
+	case StatementEvent:
+		return "statement"
+	case TupleEvent:
+		return "tuple"
+	case SymbolEvent:
+		return "symbol"
+	case CallEvent:
+		return "call"
+	default:
+		return "unknown"
+	}
+}
+
+type TraceEvent struct {
+	Kind TraceEventKind
+
+	// Expression is the statement, tuple or symbol that is evaluated. For
+	// CallEvents, this is the identifier of the function that is called.
+	Expression ast.Expression
+
+	// Arguments are the (unevaluated) arguments of a function call and only set
+	// for CallEvents.
+	Arguments []ast.Expression
+
+	// Result, Error and Duration are only set when the evaluation is done.
+	Result   any
+	Error    error
+	Duration time.Duration
+}
+
+// Tracer receives events whenever a runtime evaluates a statement, tuple or
+// symbol or calls a function. Events are properly nested, i.e. every Enter is
+// followed by exactly one Exit for the same event, unless Enter returned an
+// error.
+type Tracer interface {
+	// Enter is called right before the evaluation starts. Returning an error
+	// aborts the evaluation, the error is returned as the result.
+	Enter(ctx Context, event TraceEvent) error
+	// Exit is called once the evaluation is done, with result, error and
+	// duration set on the event.
+	Exit(ctx Context, event TraceEvent)
+}
+
+// Trace runs eval and informs the context's tracer (if any) about it. Runtimes
+// should check ctx.Tracer() first to avoid allocating closures needlessly.
+func Trace(ctx Context, event TraceEvent, eval func() (any, error)) (any, error) {
+	tracer := ctx.Tracer()
+	if tracer == nil {
+		return eval()
+	}
+
+	if err := tracer.Enter(ctx, event); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	result, err := eval()
+
+	event.Result = result
+	event.Error = err
+	event.Duration = time.Since(start)
+
+	tracer.Exit(ctx, event)
+
+	return result, err
+}
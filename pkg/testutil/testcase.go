@@ -13,6 +13,7 @@ import (
 	"go.xrstf.de/rudi/pkg/coalescing"
 	"go.xrstf.de/rudi/pkg/lang/ast"
 	"go.xrstf.de/rudi/pkg/lang/parser"
+	"go.xrstf.de/rudi/pkg/native"
 	"go.xrstf.de/rudi/pkg/runtime/interpreter"
 	"go.xrstf.de/rudi/pkg/runtime/types"
 
@@ -30,6 +31,7 @@ type Testcase struct {
 	Functions types.Functions
 	Coalescer coalescing.Coalescer
 	Runtime   types.Runtime
+	Loader    types.Loader
 
 	Expected          any
 	ExpectedDocument  any
@@ -90,6 +92,10 @@ func (tc *Testcase) eval(t *testing.T) (types.Context, any, error) {
 		t.Fatalf("Failed to create context: %v", err)
 	}
 
+	if tc.Loader != nil {
+		progContext = progContext.WithLoader(tc.Loader)
+	}
+
 	if tc.Expression != "" {
 		prog := strings.NewReader(tc.Expression)
 
@@ -141,13 +147,15 @@ func renderDiff(expected any, actual any) string {
 }
 
 func assertResultValue(t *testing.T, expected any, actual any) {
+	actual = native.Unwrap(actual)
+
 	if !cmp.Equal(expected, actual) {
 		t.Errorf("Resulting value does not match expectation:\n\n%s\n", renderDiff(expected, actual))
 	}
 }
 
 func assertDocument(t *testing.T, expected any, ctx types.Context) {
-	resultDoc := ctx.GetDocument().Data()
+	resultDoc := native.Unwrap(ctx.GetDocument().Data())
 
 	if !cmp.Equal(expected, resultDoc) {
 		t.Errorf("Resulting document does not match expectation:\n\n%s\n", renderDiff(expected, resultDoc))
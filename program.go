@@ -7,9 +7,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"reflect"
 
 	"go.xrstf.de/rudi/pkg/lang/ast"
 	"go.xrstf.de/rudi/pkg/lang/parser"
+	"go.xrstf.de/rudi/pkg/native"
+	"go.xrstf.de/rudi/pkg/optimizer"
 	"go.xrstf.de/rudi/pkg/printer"
 	"go.xrstf.de/rudi/pkg/runtime/interpreter"
 )
@@ -21,12 +24,14 @@ type Program interface {
 	fmt.Stringer
 
 	// Run will evaluate the program. The given data value is used as the program's
-	// document (i.e. available with bare path expressions like `.foo`). Variables
+	// document (i.e. available with bare path expressions like `.foo`) and can
+	// also be a Go struct, typed map or slice or a pointer to one. Variables
 	// can be left empty if desired, but funcs must effectively always be set,
 	// as programs without functions are very limited. Use NewBuiltInFunctions()
 	// to get the default set of functions in Rudi.
 	// When no error occurs, Run() returns both the final document value and the
-	// result of the final expression. Otherwise an error is returned.
+	// result of the final expression. Otherwise an error is returned. If data
+	// is a pointer to a Go value, the document is returned as a new pointer.
 	Run(ctx context.Context, data any, variables Variables, funcs Functions, coalescer Coalescer) (document any, result any, err error)
 
 	// RunContext is like Run(), but uses a pre-setup Context and returns the
@@ -70,7 +75,8 @@ func Parse(name, script string) (Program, error) {
 }
 
 // Run will evaluate the program. The given data value is used as the program's
-// document (i.e. available with bare path expressions like `.foo`). Variables
+// document (i.e. available with bare path expressions like `.foo`) and can
+// also be a Go struct, typed map or slice or a pointer to one. Variables
 // can be left empty if desired, but funcs must effectively always be set,
 // as programs without functions are very limited. Use NewBuiltInFunctions()
 // to get the default set of functions in Rudi.
@@ -92,10 +98,34 @@ func (p *rudiProgram) Run(ctx context.Context, data any, variables Variables, fu
 		return nil, nil, fmt.Errorf("script failed: %w", err)
 	}
 
-	// get current state of the document
-	docData := rudiCtx.GetDocument().Data()
+	// get current state of the document, unwrapping Go values wrapped by NewDocument;
+	// the result can also be (a part of) such a wrapped value
+	docData := unwrapDocument(rudiCtx.GetDocument().Data(), data)
 
-	return docData, result, nil
+	return docData, native.Unwrap(result), nil
+}
+
+// unwrapDocument unwraps the document's Go value. If the original data was a
+// pointer (like *MyStruct), a pointer to the updated value is returned, so
+// that callers get back the same type they passed in. The original value is
+// never modified.
+func unwrapDocument(value any, original any) any {
+	unwrapped := native.Unwrap(value)
+
+	originalType := reflect.TypeOf(original)
+	if unwrapped == nil || originalType == nil || originalType.Kind() != reflect.Pointer {
+		return unwrapped
+	}
+
+	v := reflect.ValueOf(unwrapped)
+	if v.Type() != originalType.Elem() {
+		return unwrapped
+	}
+
+	pointer := reflect.New(v.Type())
+	pointer.Elem().Set(v)
+
+	return pointer.Interface()
 }
 
 // RunContext is like Run(), but uses a pre-setup Context and returns the
@@ -124,3 +154,20 @@ func (p *rudiProgram) DumpSyntaxTree(out io.Writer) error {
 func (p *rudiProgram) DumpRudi(out io.Writer) error {
 	return printer.NewRudiPrinter(out).Program(p.prog)
 }
+
+// Optimize returns an optimized copy of the program. Calls to pure
+// functions with only literal arguments are evaluated ahead of time,
+// unreachable branches in `if` and `case` are removed and nested `do`
+// calls are flattened. funcs must be the same functions that are later
+// used to run the program. Programs that were not created by Parse are
+// returned unchanged.
+func Optimize(program Program, funcs Functions) Program {
+	p, ok := program.(*rudiProgram)
+	if !ok {
+		return program
+	}
+
+	return &rudiProgram{
+		prog: optimizer.Optimize(p.prog, funcs),
+	}
+}